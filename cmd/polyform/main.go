@@ -8,6 +8,8 @@ import (
 	"github.com/EliCDavis/polyform/generator/schema"
 
 	// Import these so they register their nodes with the generator
+	_ "github.com/EliCDavis/polyform/drawing/texturing/bake"
+
 	_ "github.com/EliCDavis/polyform/formats/colmap"
 	_ "github.com/EliCDavis/polyform/formats/gltf"
 	_ "github.com/EliCDavis/polyform/formats/opensfm"
@@ -28,10 +30,15 @@ import (
 	_ "github.com/EliCDavis/polyform/modeling/extrude"
 	_ "github.com/EliCDavis/polyform/modeling/meshops"
 	_ "github.com/EliCDavis/polyform/modeling/meshops/gausops"
+	_ "github.com/EliCDavis/polyform/modeling/meshops/repair"
 	_ "github.com/EliCDavis/polyform/modeling/primitives"
 	_ "github.com/EliCDavis/polyform/modeling/repeat"
+	_ "github.com/EliCDavis/polyform/modeling/simplify"
+	_ "github.com/EliCDavis/polyform/modeling/unwrap"
 
 	_ "github.com/EliCDavis/polyform/nodes/experimental"
+
+	_ "github.com/EliCDavis/polyform/rendering/splatting"
 )
 
 func main() {
@@ -0,0 +1,109 @@
+package bake
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+func toColor(v vector4.Float64) color.RGBA {
+	channel := func(f float64) uint8 {
+		return uint8(math.Round(math.Max(0, math.Min(1, f)) * 255))
+	}
+	return color.RGBA{
+		R: channel(v.X()),
+		G: channel(v.Y()),
+		B: channel(v.Z()),
+		A: channel(v.W()),
+	}
+}
+
+func (b *buffer) image(background color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, b.width, b.height))
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			if !b.covered[y*b.width+x] {
+				img.SetRGBA(x, y, background)
+				continue
+			}
+			img.SetRGBA(x, y, toColor(b.values[y*b.width+x]))
+		}
+	}
+	return img
+}
+
+// attributeReader builds a function for interpolating the attribute across a
+// triangle, expanding the attribute to RGBA
+func attributeReader(m modeling.Mesh, attribute string) (func(tri int, barycentric vector3.Float64) vector4.Float64, error) {
+	indices := m.Indices()
+	corner := func(tri int, barycentric vector3.Float64, read func(i int) vector4.Float64) vector4.Float64 {
+		return read(indices.At(tri * 3)).Scale(barycentric.X()).
+			Add(read(indices.At(tri*3 + 1)).Scale(barycentric.Y())).
+			Add(read(indices.At(tri*3 + 2)).Scale(barycentric.Z()))
+	}
+
+	switch {
+	case m.HasFloat1Attribute(attribute):
+		data := m.Float1Attribute(attribute)
+		return func(tri int, barycentric vector3.Float64) vector4.Float64 {
+			return corner(tri, barycentric, func(i int) vector4.Float64 {
+				v := data.At(i)
+				return vector4.New(v, v, v, 1)
+			})
+		}, nil
+
+	case m.HasFloat3Attribute(attribute):
+		data := m.Float3Attribute(attribute)
+		return func(tri int, barycentric vector3.Float64) vector4.Float64 {
+			return corner(tri, barycentric, func(i int) vector4.Float64 {
+				v := data.At(i)
+				return vector4.New(v.X(), v.Y(), v.Z(), 1)
+			})
+		}, nil
+
+	case m.HasFloat4Attribute(attribute):
+		data := m.Float4Attribute(attribute)
+		return func(tri int, barycentric vector3.Float64) vector4.Float64 {
+			return corner(tri, barycentric, data.At)
+		}, nil
+	}
+
+	return nil, fmt.Errorf("mesh has no float1, float3, or float4 attribute %q to bake", attribute)
+}
+
+// Attribute bakes the float1, float3, or float4 attribute into an image using
+// the mesh's texture coordinates. Float1 attributes are written as grayscale,
+// float3 as RGB, and float4 as RGBA, with all values clamped between 0 and 1.
+// Pixels not covered by any triangle are left transparent.
+func Attribute(m modeling.Mesh, attribute string, settings Settings) (*image.RGBA, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return nil, err
+	}
+
+	if err := meshops.RequireV2Attribute(m, modeling.TexCoordAttribute); err != nil {
+		return nil, err
+	}
+
+	read, err := attributeReader(m, attribute)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := newBuffer(settings.Width, settings.Height)
+	Rasterize(m, settings.Width, settings.Height, func(x, y, tri int, barycentric vector3.Float64) {
+		buf.set(x, y, read(tri, barycentric))
+	})
+	buf.dilate(settings.Dilation)
+
+	return buf.image(color.RGBA{}), nil
+}
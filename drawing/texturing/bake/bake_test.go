@@ -0,0 +1,185 @@
+package bake_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/EliCDavis/polyform/drawing/texturing/bake"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// quad builds a unit square on the XY plane facing +Z, with texture
+// coordinates matching it's position
+func quad(indices ...int) modeling.Mesh {
+	if len(indices) == 0 {
+		indices = []int{0, 1, 2, 0, 2, 3}
+	}
+	return modeling.NewTriangleMesh(indices).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(1., 0., 0.),
+			vector3.New(1., 1., 0.),
+			vector3.New(0., 1., 0.),
+		}).
+		SetFloat3Attribute(modeling.NormalAttribute, []vector3.Float64{
+			vector3.New(0., 0., 1.),
+			vector3.New(0., 0., 1.),
+			vector3.New(0., 0., 1.),
+			vector3.New(0., 0., 1.),
+		}).
+		SetFloat2Attribute(modeling.TexCoordAttribute, []vector2.Float64{
+			vector2.New(0., 0.),
+			vector2.New(1., 0.),
+			vector2.New(1., 1.),
+			vector2.New(0., 1.),
+		})
+}
+
+func TestAttribute(t *testing.T) {
+	m := quad().SetFloat3Attribute(modeling.ColorAttribute, []vector3.Float64{
+		vector3.New(1., 0., 0.),
+		vector3.New(0., 1., 0.),
+		vector3.New(0., 1., 0.),
+		vector3.New(1., 0., 0.),
+	})
+
+	img, err := bake.Attribute(m, modeling.ColorAttribute, bake.Settings{Width: 8, Height: 8})
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 8, 8), img.Bounds())
+
+	// Red on the left fading to green on the right
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			c := img.RGBAAt(x, y)
+			expected := (float64(x) + 0.5) / 8.
+			assert.InDelta(t, (1-expected)*255, float64(c.R), 1)
+			assert.InDelta(t, expected*255, float64(c.G), 1)
+			assert.Equal(t, uint8(0), c.B)
+			assert.Equal(t, uint8(255), c.A)
+		}
+	}
+}
+
+func TestAttribute_Float1(t *testing.T) {
+	m := quad().SetFloat1Attribute("AO", []float64{0.5, 0.5, 0.5, 0.5})
+
+	img, err := bake.Attribute(m, "AO", bake.Settings{Width: 4, Height: 4})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 128, G: 128, B: 128, A: 255}, img.RGBAAt(2, 2))
+
+	_, err = bake.Attribute(m, "Missing", bake.Settings{Width: 4, Height: 4})
+	assert.Error(t, err)
+
+	_, err = bake.Attribute(m, "AO", bake.Settings{})
+	assert.ErrorIs(t, err, bake.ErrInvalidResolution)
+}
+
+func TestAttribute_Dilation(t *testing.T) {
+	// Only the lower triangle of the quad, covering the top right of the
+	// image
+	m := quad(0, 1, 2).SetFloat1Attribute("Value", []float64{1, 1, 1, 1})
+
+	img, err := bake.Attribute(m, "Value", bake.Settings{Width: 16, Height: 16})
+	require.NoError(t, err)
+	assert.Equal(t, uint8(255), img.RGBAAt(12, 4).A)
+	assert.Equal(t, uint8(0), img.RGBAAt(4, 12).A)
+	assert.Equal(t, uint8(0), img.RGBAAt(6, 8).A)
+
+	dilated, err := bake.Attribute(m, "Value", bake.Settings{Width: 16, Height: 16, Dilation: 2})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, dilated.RGBAAt(6, 8))
+	assert.Equal(t, uint8(0), dilated.RGBAAt(4, 12).A)
+}
+
+func TestNormals(t *testing.T) {
+	tests := map[string]struct {
+		highNormal vector3.Float64
+		expected   color.RGBA
+	}{
+		"flat": {
+			highNormal: vector3.New(0., 0., 1.),
+			expected:   color.RGBA{R: 128, G: 128, B: 255, A: 255},
+		},
+		"tangent": {
+			highNormal: vector3.New(1., 0., 1.).Normalized(),
+			expected:   color.RGBA{R: 218, G: 128, B: 218, A: 255},
+		},
+		"bitangent": {
+			highNormal: vector3.New(0., 1., 1.).Normalized(),
+			expected:   color.RGBA{R: 128, G: 218, B: 218, A: 255},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			// High poly surface floating just above the low poly one
+			high := quad().
+				Translate(vector3.New(0., 0., 0.01)).
+				SetFloat3Attribute(modeling.NormalAttribute, []vector3.Float64{
+					tc.highNormal, tc.highNormal, tc.highNormal, tc.highNormal,
+				})
+
+			img, err := bake.Normals(quad(), high, 0.1, bake.Settings{Width: 8, Height: 8})
+			require.NoError(t, err)
+
+			for y := 0; y < 8; y++ {
+				for x := 0; x < 8; x++ {
+					c := img.RGBAAt(x, y)
+					assert.InDelta(t, float64(tc.expected.R), float64(c.R), 1)
+					assert.InDelta(t, float64(tc.expected.G), float64(c.G), 1)
+					assert.InDelta(t, float64(tc.expected.B), float64(c.B), 1)
+				}
+			}
+		})
+	}
+}
+
+func TestNormals_Miss(t *testing.T) {
+	high := quad().Translate(vector3.New(5., 0., 0.))
+	img, err := bake.Normals(quad(0, 1, 2), high, 0.1, bake.Settings{Width: 4, Height: 4})
+	require.NoError(t, err)
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			c := img.RGBAAt(x, y)
+			assert.InDelta(t, 128, float64(c.R), 1)
+			assert.InDelta(t, 128, float64(c.G), 1)
+			assert.Equal(t, uint8(255), c.B)
+		}
+	}
+}
+
+func TestNodes(t *testing.T) {
+	m := quad().SetFloat3Attribute(modeling.ColorAttribute, []vector3.Float64{
+		vector3.New(1., 1., 1.),
+		vector3.New(1., 1., 1.),
+		vector3.New(1., 1., 1.),
+		vector3.New(1., 1., 1.),
+	})
+
+	attribute := (&bake.AttributeNode{
+		Data: bake.AttributeNodeData{
+			Mesh:   nodes.Value(m),
+			Width:  nodes.Value(4),
+			Height: nodes.Value(2),
+		},
+	}).Out().Value()
+	assert.Equal(t, image.Rect(0, 0, 4, 2), attribute.Bounds())
+	assert.Equal(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, attribute.At(1, 1))
+
+	normals := (&bake.NormalsNode{
+		Data: bake.NormalsNodeData{
+			Low:    nodes.Value(m),
+			High:   nodes.Value(m),
+			Width:  nodes.Value(4),
+			Height: nodes.Value(4),
+		},
+	}).Out().Value()
+	assert.Equal(t, color.RGBA{R: 128, G: 128, B: 255, A: 255}, normals.At(1, 1))
+}
@@ -0,0 +1,75 @@
+package bake
+
+import (
+	"image"
+
+	"github.com/EliCDavis/polyform/drawing/texturing/normals"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+func settingsFromNodes(width, height, dilation nodes.NodeOutput[int]) Settings {
+	return Settings{
+		Width:    nodes.TryGetOutputValue(width, 1024),
+		Height:   nodes.TryGetOutputValue(height, 1024),
+		Dilation: nodes.TryGetOutputValue(dilation, 4),
+	}
+}
+
+type AttributeNode = nodes.Struct[image.Image, AttributeNodeData]
+
+type AttributeNodeData struct {
+	Mesh      nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+	Width     nodes.NodeOutput[int]
+	Height    nodes.NodeOutput[int]
+	Dilation  nodes.NodeOutput[int]
+}
+
+func (AttributeNodeData) Description() string {
+	return "Bakes a float1, float3, or float4 attribute of the mesh into an image using the mesh's texture coordinates"
+}
+
+func (an AttributeNodeData) Process() (image.Image, error) {
+	settings := settingsFromNodes(an.Width, an.Height, an.Dilation)
+	if an.Mesh == nil {
+		return image.NewRGBA(image.Rect(0, 0, settings.Width, settings.Height)), nil
+	}
+
+	return Attribute(
+		an.Mesh.Value(),
+		nodes.TryGetOutputValue(an.Attribute, modeling.ColorAttribute),
+		settings,
+	)
+}
+
+type NormalsNode = nodes.Struct[image.Image, NormalsNodeData]
+
+type NormalsNodeData struct {
+	Low         nodes.NodeOutput[modeling.Mesh]
+	High        nodes.NodeOutput[modeling.Mesh]
+	MaxDistance nodes.NodeOutput[float64]
+	Width       nodes.NodeOutput[int]
+	Height      nodes.NodeOutput[int]
+	Dilation    nodes.NodeOutput[int]
+}
+
+func (NormalsNodeData) Description() string {
+	return "Bakes the surface detail of a high poly mesh into a tangent space normal map laid out with the low poly mesh's texture coordinates"
+}
+
+func (nnd NormalsNodeData) Process() (image.Image, error) {
+	settings := settingsFromNodes(nnd.Width, nnd.Height, nnd.Dilation)
+	if nnd.Low == nil || nnd.High == nil {
+		img := image.NewRGBA(image.Rect(0, 0, settings.Width, settings.Height))
+		normals.Fill(img)
+		return img, nil
+	}
+
+	return Normals(
+		nnd.Low.Value(),
+		nnd.High.Value(),
+		nodes.TryGetOutputValue(nnd.MaxDistance, 0.),
+		settings,
+	)
+}
@@ -0,0 +1,269 @@
+package bake
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/EliCDavis/polyform/math/geometry"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/trees"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+// Color of a tangent space normal pointing straight out of the surface
+var flatNormal = color.RGBA{R: 128, G: 128, B: 255, A: 255}
+
+// vertexTangents calculates a tangent for each vertex aligned with the
+// direction U increases across the surface, with W storing the handedness of
+// the bitangent
+func vertexTangents(m modeling.Mesh, normals []vector3.Float64) []vector4.Float64 {
+	positions := m.Float3Attribute(modeling.PositionAttribute)
+	uvs := m.Float2Attribute(modeling.TexCoordAttribute)
+	indices := m.Indices()
+
+	tangents := make([]vector3.Float64, positions.Len())
+	bitangents := make([]vector3.Float64, positions.Len())
+	for i := 0; i < indices.Len(); i += 3 {
+		i0, i1, i2 := indices.At(i), indices.At(i+1), indices.At(i+2)
+
+		e1 := positions.At(i1).Sub(positions.At(i0))
+		e2 := positions.At(i2).Sub(positions.At(i0))
+		d1 := uvs.At(i1).Sub(uvs.At(i0))
+		d2 := uvs.At(i2).Sub(uvs.At(i0))
+
+		det := d1.X()*d2.Y() - d2.X()*d1.Y()
+		if math.Abs(det) < 1e-12 {
+			continue
+		}
+		r := 1. / det
+
+		t := e1.Scale(d2.Y()).Sub(e2.Scale(d1.Y())).Scale(r)
+		b := e2.Scale(d1.X()).Sub(e1.Scale(d2.X())).Scale(r)
+		for _, v := range [3]int{i0, i1, i2} {
+			tangents[v] = tangents[v].Add(t)
+			bitangents[v] = bitangents[v].Add(b)
+		}
+	}
+
+	out := make([]vector4.Float64, len(tangents))
+	for v, t := range tangents {
+		n := normals[v]
+
+		// Gram-Schmidt orthogonalize against the normal
+		t = t.Sub(n.Scale(n.Dot(t)))
+		if t.LengthSquared() < 1e-24 {
+			t = n.Perpendicular()
+		}
+		t = t.Normalized()
+
+		w := 1.
+		if n.Cross(t).Dot(bitangents[v]) < 0 {
+			w = -1
+		}
+		out[v] = vector4.New(t.X(), t.Y(), t.Z(), w)
+	}
+	return out
+}
+
+// vertexNormals returns the normals of the mesh, falling back to the area
+// weighted average of the faces surrounding each vertex when the mesh has
+// none
+func vertexNormals(m modeling.Mesh) []vector3.Float64 {
+	if !m.HasFloat3Attribute(modeling.NormalAttribute) {
+		m = meshops.SmoothNormals(m)
+	}
+
+	normals := m.Float3Attribute(modeling.NormalAttribute)
+	out := make([]vector3.Float64, normals.Len())
+	for i := range out {
+		out[i] = normals.At(i)
+	}
+	return out
+}
+
+type highPolySurface struct {
+	positions []vector3.Float64
+	normals   []vector3.Float64 // Nil when the surface has no vertex normals
+	indices   []int
+	tree      *trees.OctTree
+}
+
+func newHighPolySurface(m modeling.Mesh) *highPolySurface {
+	surface := &highPolySurface{
+		tree: m.OctTree(),
+	}
+
+	positions := m.Float3Attribute(modeling.PositionAttribute)
+	surface.positions = make([]vector3.Float64, positions.Len())
+	for i := range surface.positions {
+		surface.positions[i] = positions.At(i)
+	}
+
+	if m.HasFloat3Attribute(modeling.NormalAttribute) {
+		normals := m.Float3Attribute(modeling.NormalAttribute)
+		surface.normals = make([]vector3.Float64, normals.Len())
+		for i := range surface.normals {
+			surface.normals[i] = normals.At(i)
+		}
+	}
+
+	indices := m.Indices()
+	surface.indices = make([]int, indices.Len())
+	for i := range surface.indices {
+		surface.indices[i] = indices.At(i)
+	}
+
+	return surface
+}
+
+// intersect finds where the ray crosses the triangle using the Moller-Trumbore
+// method, returning the distance along the ray and the barycentric
+// coordinates of the hit
+func (s highPolySurface) intersect(tri int, ray geometry.Ray) (float64, vector3.Float64, bool) {
+	p0 := s.positions[s.indices[tri*3]]
+	p1 := s.positions[s.indices[tri*3+1]]
+	p2 := s.positions[s.indices[tri*3+2]]
+
+	e1 := p1.Sub(p0)
+	e2 := p2.Sub(p0)
+	pvec := ray.Direction().Cross(e2)
+	det := e1.Dot(pvec)
+	if math.Abs(det) < 1e-12 {
+		return 0, vector3.Zero[float64](), false
+	}
+	invDet := 1. / det
+
+	tvec := ray.Origin().Sub(p0)
+	u := tvec.Dot(pvec) * invDet
+	if u < 0 || u > 1 {
+		return 0, vector3.Zero[float64](), false
+	}
+
+	qvec := tvec.Cross(e1)
+	v := ray.Direction().Dot(qvec) * invDet
+	if v < 0 || u+v > 1 {
+		return 0, vector3.Zero[float64](), false
+	}
+
+	return e2.Dot(qvec) * invDet, vector3.New(1-u-v, u, v), true
+}
+
+func (s highPolySurface) normal(tri int, barycentric vector3.Float64) vector3.Float64 {
+	i0, i1, i2 := s.indices[tri*3], s.indices[tri*3+1], s.indices[tri*3+2]
+	if s.normals != nil {
+		n := s.normals[i0].Scale(barycentric.X()).
+			Add(s.normals[i1].Scale(barycentric.Y())).
+			Add(s.normals[i2].Scale(barycentric.Z()))
+		if n.LengthSquared() > 0 {
+			return n.Normalized()
+		}
+	}
+	return s.positions[i1].Sub(s.positions[i0]).Cross(s.positions[i2].Sub(s.positions[i0])).Normalized()
+}
+
+// sample casts a ray through the point along the direction provided, in both
+// directions up to the max distance, returning the normal of the high poly
+// surface found closest to the point
+func (s highPolySurface) sample(p, dir vector3.Float64, maxDistance float64) (vector3.Float64, bool) {
+	ray := geometry.NewRay(p.Add(dir.Scale(maxDistance)), dir.Scale(-1))
+
+	best := math.Inf(1)
+	var normal vector3.Float64
+	for _, tri := range s.tree.ElementsIntersectingRay(ray, 0, maxDistance*2) {
+		t, barycentric, ok := s.intersect(tri, ray)
+		if !ok || t < 0 || t > maxDistance*2 {
+			continue
+		}
+
+		offset := math.Abs(t - maxDistance)
+		if offset < best {
+			best = offset
+			normal = s.normal(tri, barycentric)
+		}
+	}
+
+	return normal, !math.IsInf(best, 1)
+}
+
+// Normals bakes the surface detail of the high poly mesh into a tangent
+// space normal map for the low poly mesh, laid out using the low poly mesh's
+// texture coordinates. For each pixel a ray is cast along the low poly
+// surface's normal, in both directions up to the max distance, and the high
+// poly surface found closest is recorded. Max distances less than or equal to
+// 0 default to 5% of the size of the low poly mesh. Pixels that miss the high
+// poly mesh, or aren't covered by the low poly mesh, are left flat.
+//
+// Tangents are derived from the low poly mesh's texture coordinates, with the
+// bitangent following the direction V increases, matching glTF's convention
+// of green pointing towards the top of the image.
+func Normals(low, high modeling.Mesh, maxDistance float64, settings Settings) (*image.RGBA, error) {
+	if err := settings.validate(); err != nil {
+		return nil, err
+	}
+
+	for _, m := range []modeling.Mesh{low, high} {
+		if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+			return nil, err
+		}
+
+		if err := meshops.RequireV3Attribute(m, modeling.PositionAttribute); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := meshops.RequireV2Attribute(low, modeling.TexCoordAttribute); err != nil {
+		return nil, err
+	}
+
+	if maxDistance <= 0 {
+		maxDistance = low.BoundingBox(modeling.PositionAttribute).Size().Length() * 0.05
+	}
+
+	positions := low.Float3Attribute(modeling.PositionAttribute)
+	normals := vertexNormals(low)
+	tangents := vertexTangents(low, normals)
+	indices := low.Indices()
+	surface := newHighPolySurface(high)
+
+	buf := newBuffer(settings.Width, settings.Height)
+	Rasterize(low, settings.Width, settings.Height, func(x, y, tri int, barycentric vector3.Float64) {
+		p := vector3.Zero[float64]()
+		n := vector3.Zero[float64]()
+		t := vector4.Zero[float64]()
+		for i := 0; i < 3; i++ {
+			v := indices.At(tri*3 + i)
+			w := barycentric.Component(i)
+			p = p.Add(positions.At(v).Scale(w))
+			n = n.Add(normals[v].Scale(w))
+			t = t.Add(tangents[v].Scale(w))
+		}
+		n = n.Normalized()
+
+		tangent := t.XYZ()
+		tangent = tangent.Sub(n.Scale(n.Dot(tangent))).Normalized()
+		handedness := 1.
+		if t.W() < 0 {
+			handedness = -1
+		}
+		bitangent := n.Cross(tangent).Scale(handedness)
+
+		highNormal, ok := surface.sample(p, n, maxDistance)
+		if !ok {
+			buf.set(x, y, vector4.New(0.5, 0.5, 1, 1))
+			return
+		}
+
+		ts := vector3.New(
+			highNormal.Dot(tangent),
+			highNormal.Dot(bitangent),
+			highNormal.Dot(n),
+		)
+		buf.set(x, y, vector4.New(ts.X()*0.5+0.5, ts.Y()*0.5+0.5, ts.Z()*0.5+0.5, 1))
+	})
+	buf.dilate(settings.Dilation)
+
+	return buf.image(flatNormal), nil
+}
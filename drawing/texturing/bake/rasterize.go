@@ -0,0 +1,145 @@
+package bake
+
+import (
+	"errors"
+	"math"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+var ErrInvalidResolution = errors.New("bake resolution must be greater than 0")
+
+// Settings describes the image being baked
+type Settings struct {
+	Width  int
+	Height int
+
+	// Number of pixels to grow the edges of each UV chart by, hiding seams
+	// once the texture is filtered or mipmapped
+	Dilation int
+}
+
+func (s Settings) validate() error {
+	if s.Width <= 0 || s.Height <= 0 {
+		return ErrInvalidResolution
+	}
+	return nil
+}
+
+// buffer holds the value baked to each pixel, along with whether or not any
+// triangle covered it
+type buffer struct {
+	width, height int
+	values        []vector4.Float64
+	covered       []bool
+}
+
+func newBuffer(width, height int) *buffer {
+	return &buffer{
+		width:   width,
+		height:  height,
+		values:  make([]vector4.Float64, width*height),
+		covered: make([]bool, width*height),
+	}
+}
+
+func (b *buffer) set(x, y int, v vector4.Float64) {
+	b.values[y*b.width+x] = v
+	b.covered[y*b.width+x] = true
+}
+
+// dilate grows the covered region of the buffer outward one pixel at a time,
+// with each new pixel taking on the average of it's covered neighbors
+func (b *buffer) dilate(iterations int) {
+	for i := 0; i < iterations; i++ {
+		values := append([]vector4.Float64(nil), b.values...)
+		covered := append([]bool(nil), b.covered...)
+		grew := false
+
+		for y := 0; y < b.height; y++ {
+			for x := 0; x < b.width; x++ {
+				if b.covered[y*b.width+x] {
+					continue
+				}
+
+				sum := vector4.Zero[float64]()
+				count := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := x+dx, y+dy
+						if nx < 0 || ny < 0 || nx >= b.width || ny >= b.height || !b.covered[ny*b.width+nx] {
+							continue
+						}
+						sum = sum.Add(b.values[ny*b.width+nx])
+						count++
+					}
+				}
+
+				if count == 0 {
+					continue
+				}
+
+				values[y*b.width+x] = sum.Scale(1. / float64(count))
+				covered[y*b.width+x] = true
+				grew = true
+			}
+		}
+
+		b.values = values
+		b.covered = covered
+		if !grew {
+			return
+		}
+	}
+}
+
+// Rasterize visits the center of every pixel covered by a triangle of the
+// mesh when laid out in UV space, providing the triangle and the barycentric
+// coordinates of the pixel within it. UVs follow the glTF convention, with
+// (0, 0) being the top left corner of the image. Pixels covered by more than
+// one triangle are visited once for each.
+func Rasterize(m modeling.Mesh, width, height int, visit func(x, y, tri int, barycentric vector3.Float64)) {
+	uvs := m.Float2Attribute(modeling.TexCoordAttribute)
+	indices := m.Indices()
+	size := vector2.New(float64(width), float64(height))
+
+	// Small allowance so pixels centered on a shared edge aren't dropped by
+	// both triangles due to rounding
+	const epsilon = 1e-9
+
+	for tri := 0; tri < indices.Len()/3; tri++ {
+		a := uvs.At(indices.At(tri * 3)).MultByVector(size)
+		b := uvs.At(indices.At(tri*3 + 1)).MultByVector(size)
+		c := uvs.At(indices.At(tri*3 + 2)).MultByVector(size)
+
+		area := cross(b.Sub(a), c.Sub(a))
+		if area == 0 || math.IsNaN(area) {
+			continue
+		}
+
+		minX := max(0, int(math.Floor(math.Min(a.X(), math.Min(b.X(), c.X())))))
+		minY := max(0, int(math.Floor(math.Min(a.Y(), math.Min(b.Y(), c.Y())))))
+		maxX := min(width-1, int(math.Ceil(math.Max(a.X(), math.Max(b.X(), c.X())))))
+		maxY := min(height-1, int(math.Ceil(math.Max(a.Y(), math.Max(b.Y(), c.Y())))))
+
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				p := vector2.New(float64(x)+0.5, float64(y)+0.5)
+				w0 := cross(b.Sub(p), c.Sub(p)) / area
+				w1 := cross(c.Sub(p), a.Sub(p)) / area
+				w2 := 1 - w0 - w1
+				if w0 < -epsilon || w1 < -epsilon || w2 < -epsilon {
+					continue
+				}
+				visit(x, y, tri, vector3.New(w0, w1, w2))
+			}
+		}
+	}
+}
+
+func cross(a, b vector2.Float64) float64 {
+	return a.X()*b.Y() - a.Y()*b.X()
+}
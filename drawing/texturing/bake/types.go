@@ -0,0 +1,15 @@
+package bake
+
+import (
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/refutil"
+)
+
+func init() {
+	factory := &refutil.TypeFactory{}
+
+	refutil.RegisterType[AttributeNode](factory)
+	refutil.RegisterType[NormalsNode](factory)
+
+	generator.RegisterTypes(factory)
+}
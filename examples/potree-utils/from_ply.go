@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/EliCDavis/polyform/formats/ply"
+	"github.com/EliCDavis/polyform/formats/potree"
+	"github.com/urfave/cli/v2"
+)
+
+var FromPlyCommand = &cli.Command{
+	Name: "from-ply",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "in",
+			Required: true,
+			Usage:    "PLY point cloud to convert",
+		},
+		&cli.StringFlag{
+			Name:  "out",
+			Value: ".",
+			Usage: "Folder to write metadata.json, hierarchy.bin and octree.bin to",
+		},
+		&cli.Float64Flag{
+			Name:  "scale",
+			Value: potree.DefaultWriterOptions.Scale,
+			Usage: "Precision to store positions with",
+		},
+		&cli.IntFlag{
+			Name:  "max-points-per-node",
+			Value: potree.DefaultWriterOptions.MaxPointsPerNode,
+			Usage: "Number of points a node can hold before being subdivided",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		start := time.Now()
+		cloud, err := ply.Load(ctx.String("in"))
+		if err != nil {
+			return err
+		}
+
+		options := potree.DefaultWriterOptions
+		options.Name = strings.TrimSuffix(filepath.Base(ctx.String("in")), filepath.Ext(ctx.String("in")))
+		options.Scale = ctx.Float64("scale")
+		options.MaxPointsPerNode = ctx.Int("max-points-per-node")
+
+		fmt.Fprintf(ctx.App.Writer, "Writing octree with %d points to %s\n", cloud.PrimitiveCount(), ctx.String("out"))
+		if err := potree.WriteWithOptions(ctx.String("out"), *cloud, options); err != nil {
+			return err
+		}
+		log.Printf("Octree written in %s", time.Since(start))
+		return nil
+	},
+}
@@ -14,7 +14,7 @@ func main() {
 		Authors: []*cli.Author{
 			{Name: "Eli Davis"},
 		},
-		Description: "Different utilities for inspecting potree files",
+		Description: "Different utilities for inspecting and creating potree files",
 		Commands: []*cli.Command{
 			{
 				Name: "hierarchy",
@@ -25,6 +25,7 @@ func main() {
 				},
 			},
 			ToPlyCommand,
+			FromPlyCommand,
 		},
 	}
 
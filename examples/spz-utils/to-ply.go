@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"encoding/binary"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"strings"
 
@@ -77,10 +79,6 @@ var ToPlyCommand = &cli.Command{
 		},
 	},
 	Action: func(ctx *cli.Context) error {
-
-		includeHarmonics := ctx.Bool("spherical-harmonics")
-		propertyFormat := plyFormatEnum(ctx.String("format"))
-
 		cloud, err := spz.Load(inFilePath)
 		if err != nil {
 			return err
@@ -91,154 +89,170 @@ var ToPlyCommand = &cli.Command{
 			return err
 		}
 		defer plyFile.Close()
-		out := bufio.NewWriter(plyFile)
-
-		props := []ply.Property{
-			ply.ScalarProperty{PropertyName: "x", Type: ply.Float},
-			ply.ScalarProperty{PropertyName: "y", Type: ply.Float},
-			ply.ScalarProperty{PropertyName: "z", Type: ply.Float},
-		}
 
-		var scalarType ply.ScalarPropertyType
-		switch propertyFormat {
-		case SPZ:
-			scalarType = ply.UChar
-
-		case Splat:
-			scalarType = ply.Float
-		}
-
-		switch lowerNoSpace(ctx.String("color")) {
-		case RGB:
-			props = append(
-				props,
-				ply.ScalarProperty{PropertyName: "r", Type: scalarType},
-				ply.ScalarProperty{PropertyName: "g", Type: scalarType},
-				ply.ScalarProperty{PropertyName: "b", Type: scalarType},
-			)
-
-		case FDC:
-			props = append(
-				props,
-				ply.ScalarProperty{PropertyName: "f_dc_0", Type: scalarType},
-				ply.ScalarProperty{PropertyName: "f_dc_1", Type: scalarType},
-				ply.ScalarProperty{PropertyName: "f_dc_2", Type: scalarType},
-			)
-		}
+		return writePly(
+			plyFile,
+			cloud,
+			plyFormatEnum(ctx.String("format")),
+			lowerNoSpace(ctx.String("color")),
+			ctx.Bool("spherical-harmonics"),
+		)
+	},
+}
 
+func writePly(
+	dst io.Writer,
+	cloud *spz.Cloud,
+	propertyFormat PropertyFormat,
+	colorFormat ColorPropertyFormat,
+	includeHarmonics bool,
+) error {
+	out := bufio.NewWriter(dst)
+
+	props := []ply.Property{
+		ply.ScalarProperty{PropertyName: "x", Type: ply.Float},
+		ply.ScalarProperty{PropertyName: "y", Type: ply.Float},
+		ply.ScalarProperty{PropertyName: "z", Type: ply.Float},
+	}
+
+	var scalarType ply.ScalarPropertyType
+	switch propertyFormat {
+	case SPZ:
+		scalarType = ply.UChar
+
+	case Splat:
+		scalarType = ply.Float
+	}
+
+	switch colorFormat {
+	case RGB:
 		props = append(
 			props,
-			ply.ScalarProperty{PropertyName: "scale_0", Type: scalarType},
-			ply.ScalarProperty{PropertyName: "scale_1", Type: scalarType},
-			ply.ScalarProperty{PropertyName: "scale_2", Type: scalarType},
-			ply.ScalarProperty{PropertyName: "opacity", Type: scalarType},
-			ply.ScalarProperty{PropertyName: "rot_0", Type: scalarType},
-			ply.ScalarProperty{PropertyName: "rot_1", Type: scalarType},
-			ply.ScalarProperty{PropertyName: "rot_2", Type: scalarType},
+			ply.ScalarProperty{PropertyName: "r", Type: scalarType},
+			ply.ScalarProperty{PropertyName: "g", Type: scalarType},
+			ply.ScalarProperty{PropertyName: "b", Type: scalarType},
 		)
 
-		if propertyFormat == Splat {
-			props = append(props, ply.ScalarProperty{PropertyName: "rot_3", Type: scalarType})
-		}
-
-		cloudDimensions, err := cloud.Header.ShDimensions()
-		if err != nil {
-			return err
+	case FDC:
+		props = append(
+			props,
+			ply.ScalarProperty{PropertyName: "f_dc_0", Type: scalarType},
+			ply.ScalarProperty{PropertyName: "f_dc_1", Type: scalarType},
+			ply.ScalarProperty{PropertyName: "f_dc_2", Type: scalarType},
+		)
+	}
+
+	props = append(
+		props,
+		ply.ScalarProperty{PropertyName: "scale_0", Type: scalarType},
+		ply.ScalarProperty{PropertyName: "scale_1", Type: scalarType},
+		ply.ScalarProperty{PropertyName: "scale_2", Type: scalarType},
+		ply.ScalarProperty{PropertyName: "opacity", Type: scalarType},
+		ply.ScalarProperty{PropertyName: "rot_0", Type: scalarType},
+		ply.ScalarProperty{PropertyName: "rot_1", Type: scalarType},
+		ply.ScalarProperty{PropertyName: "rot_2", Type: scalarType},
+	)
+
+	if propertyFormat == Splat {
+		props = append(props, ply.ScalarProperty{PropertyName: "rot_3", Type: scalarType})
+	}
+
+	cloudDimensions, err := cloud.Header.ShDimensions()
+	if err != nil {
+		return err
+	}
+
+	// PLY lays out f_rest channel major, with every coefficient of red
+	// followed by every coefficient of green and then blue
+	shArrays := make([]*iter.ArrayIterator[vector3.Float64], cloudDimensions)
+	if includeHarmonics {
+		for i := 0; i < cloudDimensions; i++ {
+			shArrays[i] = cloud.Mesh.Float3Attribute(modeling.SHAttribute(i))
 		}
-
-		shArrays := make([]*iter.ArrayIterator[vector3.Float64], cloudDimensions)
-		if includeHarmonics {
-			for i := 0; i < cloudDimensions; i++ {
-				i3 := i * 3
-				props = append(props, ply.ScalarProperty{
-					Type:         scalarType,
-					PropertyName: fmt.Sprintf("f_rest_%d", i3),
-				})
-				props = append(props, ply.ScalarProperty{
-					Type:         scalarType,
-					PropertyName: fmt.Sprintf("f_rest_%d", i3+1),
-				})
-				props = append(props, ply.ScalarProperty{
-					Type:         scalarType,
-					PropertyName: fmt.Sprintf("f_rest_%d", i3+2),
-				})
-				shArrays[i] = cloud.Mesh.Float3Attribute(fmt.Sprintf("SH_%d", i))
-			}
+		for i := 0; i < cloudDimensions*3; i++ {
+			props = append(props, ply.ScalarProperty{
+				Type:         scalarType,
+				PropertyName: fmt.Sprintf("f_rest_%d", i),
+			})
 		}
-		endian := binary.LittleEndian
-		header := ply.Header{
-			Format: ply.BinaryLittleEndian,
-			Elements: []ply.Element{
-				{
-					Name:       "vertex",
-					Count:      int64(cloud.Header.NumPoints),
-					Properties: props,
-				},
+	}
+	endian := binary.LittleEndian
+	header := ply.Header{
+		Format: ply.BinaryLittleEndian,
+		Elements: []ply.Element{
+			{
+				Name:       "vertex",
+				Count:      int64(cloud.Header.NumPoints),
+				Properties: props,
 			},
-		}
-
-		err = header.Write(out)
-		if err != nil {
-			return err
-		}
-
-		scales := cloud.Mesh.Float3Attribute(modeling.ScaleAttribute)
-		colors := cloud.Mesh.Float3Attribute(modeling.FDCAttribute)
-		positions := cloud.Mesh.Float3Attribute(modeling.PositionAttribute)
-		rotations := cloud.Mesh.Float4Attribute(modeling.RotationAttribute)
-		alphas := cloud.Mesh.Float1Attribute(modeling.OpacityAttribute)
-
-		switch propertyFormat {
-		case SPZ:
-			for i := 0; i < int(cloud.Header.NumPoints); i++ {
-				positions.
-					At(i).
-					ToFloat32().
-					Write(out, endian)
-
-				color := colors.At(i).Clamp(0, 1).Scale(255)
-				binary.Write(out, endian, byte(color.X()))
-				binary.Write(out, endian, byte(color.Y()))
-				binary.Write(out, endian, byte(color.Z()))
-
-				scale := scales.At(i).Clamp(0, 1).Scale(255)
-				binary.Write(out, endian, byte(scale.X()))
-				binary.Write(out, endian, byte(scale.Y()))
-				binary.Write(out, endian, byte(scale.Z()))
-
-				binary.Write(out, endian, byte(alphas.At(i)*255))
-
-				rotation := rotations.At(i).Clamp(0, 1).Scale(255)
-				binary.Write(out, endian, byte(rotation.X()))
-				binary.Write(out, endian, byte(rotation.Y()))
-				binary.Write(out, endian, byte(rotation.Z()))
-
-				if includeHarmonics {
+		},
+	}
+
+	err = header.Write(out)
+	if err != nil {
+		return err
+	}
+
+	scales := cloud.Mesh.Float3Attribute(modeling.ScaleAttribute)
+	colors := cloud.Mesh.Float3Attribute(modeling.FDCAttribute)
+	positions := cloud.Mesh.Float3Attribute(modeling.PositionAttribute)
+	rotations := cloud.Mesh.Float4Attribute(modeling.RotationAttribute)
+	alphas := cloud.Mesh.Float1Attribute(modeling.OpacityAttribute)
+
+	switch propertyFormat {
+	case SPZ:
+		for i := 0; i < int(cloud.Header.NumPoints); i++ {
+			positions.
+				At(i).
+				ToFloat32().
+				Write(out, endian)
+
+			color := colors.At(i).Clamp(0, 1).Scale(255)
+			binary.Write(out, endian, byte(color.X()))
+			binary.Write(out, endian, byte(color.Y()))
+			binary.Write(out, endian, byte(color.Z()))
+
+			scale := scales.At(i).Clamp(0, 1).Scale(255)
+			binary.Write(out, endian, byte(scale.X()))
+			binary.Write(out, endian, byte(scale.Y()))
+			binary.Write(out, endian, byte(scale.Z()))
+
+			// Opacity is read pre-sigmoid
+			binary.Write(out, endian, byte(255/(1+math.Exp(-alphas.At(i)))))
+
+			// Only the imaginary part of the (w, x, y, z) rotation is kept
+			rotation := rotations.At(i).Clamp(0, 1).Scale(255)
+			binary.Write(out, endian, byte(rotation.Y()))
+			binary.Write(out, endian, byte(rotation.Z()))
+			binary.Write(out, endian, byte(rotation.W()))
+
+			if includeHarmonics {
+				for channel := 0; channel < 3; channel++ {
 					for _, arr := range shArrays {
 						sh := arr.At(i).Clamp(0, 1).Scale(255)
-						binary.Write(out, endian, byte(sh.X()))
-						binary.Write(out, endian, byte(sh.Y()))
-						binary.Write(out, endian, byte(sh.Z()))
+						binary.Write(out, endian, byte(sh.Component(channel)))
 					}
 				}
 			}
+		}
 
-		case Splat:
-			for i := 0; i < int(cloud.Header.NumPoints); i++ {
-				positions.At(i).ToFloat32().Write(out, endian)
-				colors.At(i).Clamp(0, 1).ToFloat32().Write(out, endian)
-				scales.At(i).Clamp(0, 1).ToFloat32().Write(out, endian)
-				binary.Write(out, endian, float32(alphas.At(i)))
-				rotations.At(i).Clamp(0, 1).ToFloat32().Write(out, endian)
+	case Splat:
+		for i := 0; i < int(cloud.Header.NumPoints); i++ {
+			positions.At(i).ToFloat32().Write(out, endian)
+			colors.At(i).Clamp(0, 1).ToFloat32().Write(out, endian)
+			scales.At(i).Clamp(0, 1).ToFloat32().Write(out, endian)
+			binary.Write(out, endian, float32(alphas.At(i)))
+			rotations.At(i).Clamp(0, 1).ToFloat32().Write(out, endian)
 
-				if includeHarmonics {
+			if includeHarmonics {
+				for channel := 0; channel < 3; channel++ {
 					for _, arr := range shArrays {
-						arr.At(i).ToFloat32().Write(out, endian)
+						binary.Write(out, endian, float32(arr.At(i).Component(channel)))
 					}
 				}
 			}
 		}
+	}
 
-		return out.Flush()
-	},
+	return out.Flush()
 }
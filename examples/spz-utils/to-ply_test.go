@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/EliCDavis/polyform/formats/ply"
+	"github.com/EliCDavis/polyform/formats/spz"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePly_SphericalHarmonicsRoundTrip(t *testing.T) {
+	v3Data := map[string][]vector3.Float64{
+		modeling.PositionAttribute: {vector3.New(0., 1., 2.), vector3.New(3., 4., 5.)},
+		modeling.ScaleAttribute:    {vector3.New(-1., -2., -3.), vector3.New(-3., -2., -1.)},
+		modeling.FDCAttribute:      {vector3.New(0., 0.5, 1.), vector3.New(1., 0.5, 0.)},
+	}
+	for i := 0; i < modeling.SHCoefficients(1); i++ {
+		v := float64(i+1) / 4
+		v3Data[modeling.SHAttribute(i)] = []vector3.Float64{
+			vector3.New(v, -v, 0.5),
+			vector3.New(-0.25, 0.25, -v),
+		}
+	}
+	mesh := modeling.NewPointCloud(
+		map[string][]vector4.Float64{
+			modeling.RotationAttribute: {vector4.New(1., 0., 0., 0.), vector4.New(1., 0., 0., 0.)},
+		},
+		v3Data,
+		nil,
+		map[string][]float64{modeling.OpacityAttribute: {0, 1}},
+		nil,
+	)
+
+	spzBuf := &bytes.Buffer{}
+	require.NoError(t, spz.Write(spzBuf, mesh))
+	cloud, err := spz.Read(spzBuf)
+	require.NoError(t, err)
+
+	plyBuf := &bytes.Buffer{}
+	require.NoError(t, writePly(plyBuf, cloud, Splat, FDC, true))
+	back, err := ply.ReadMesh(plyBuf)
+	require.NoError(t, err)
+
+	for i := 0; i < modeling.SHCoefficients(1); i++ {
+		attr := modeling.SHAttribute(i)
+		require.True(t, back.HasFloat3Attribute(attr), attr)
+		expected := cloud.Mesh.Float3Attribute(attr)
+		actual := back.Float3Attribute(attr)
+		require.Equal(t, expected.Len(), actual.Len())
+		for p := 0; p < expected.Len(); p++ {
+			assert.InDelta(t, expected.At(p).X(), actual.At(p).X(), 1e-6)
+			assert.InDelta(t, expected.At(p).Y(), actual.At(p).Y(), 1e-6)
+			assert.InDelta(t, expected.At(p).Z(), actual.At(p).Z(), 1e-6)
+		}
+	}
+}
@@ -52,5 +52,37 @@ type Accessor struct {
 	Count         int                   `json:"count"`                // The number of elements referenced by this accessor, not to be confused with the number of bytes or number of components.
 	Max           []float64             `json:"max,omitempty"`        // Maximum value of each component in this accessor.  Array elements **MUST** be treated as having the same data type as accessor's `componentType`. Both `min` and `max` arrays have the same length.  The length is determined by the value of the `type` property; it can be 1, 2, 3, 4, 9, or 16.\n\n`normalized` property has no effect on array values: they always correspond to the actual values stored in the buffer. When the accessor is sparse, this property **MUST** contain maximum values of accessor data with sparse substitution applied.
 	Min           []float64             `json:"min,omitempty"`        // Minimum value of each component in this accessor.  Array elements **MUST** be treated as having the same data type as accessor's `componentType`. Both `min` and `max` arrays have the same length.  The length is determined by the value of the `type` property; it can be 1, 2, 3, 4, 9, or 16.\n\n`normalized` property has no effect on array values: they always correspond to the actual values stored in the buffer. When the accessor is sparse, this property **MUST** contain minimum values of accessor data with sparse substitution applied.
-	// Sparse        Sparse                `json:"sparse,omitempty,omitempty"` // Sparse storage of elements that deviate from their initialization value.
+	Sparse        *AccessorSparse       `json:"sparse,omitempty"`     // Sparse storage of elements that deviate from their initialization value.
+}
+
+// Sparse storage of accessor values that deviate from their initialization
+// value.
+// https://github.com/KhronosGroup/glTF/blob/main/specification/2.0/schema/accessor.sparse.schema.json
+type AccessorSparse struct {
+	Property
+	Count   int                   `json:"count"`   // Number of deviating accessor values stored in the sparse array.
+	Indices AccessorSparseIndices `json:"indices"` // An object pointing to a buffer view containing the indices of deviating accessor values. The number of indices is equal to `count`. Indices **MUST** strictly increase.
+	Values  AccessorSparseValues  `json:"values"`  // An object pointing to a buffer view containing the deviating accessor values.
+}
+
+// An object pointing to a buffer view containing the indices of deviating
+// accessor values. The number of indices is equal to `accessor.sparse.count`.
+// Indices **MUST** strictly increase.
+// https://github.com/KhronosGroup/glTF/blob/main/specification/2.0/schema/accessor.sparse.indices.schema.json
+type AccessorSparseIndices struct {
+	Property
+	BufferView    GltfId                `json:"bufferView"`           // The index of the buffer view with sparse indices. The referenced buffer view **MUST NOT** have its `target` or `byteStride` properties defined. The buffer view and the optional `byteOffset` **MUST** be aligned to the `componentType` byte length.
+	ByteOffset    int                   `json:"byteOffset,omitempty"` // The offset relative to the start of the buffer view in bytes.
+	ComponentType AccessorComponentType `json:"componentType"`        // The indices data type. Valid values are UNSIGNED_BYTE, UNSIGNED_SHORT and UNSIGNED_INT.
+}
+
+// An object pointing to a buffer view containing the deviating accessor
+// values. The number of elements is equal to `accessor.sparse.count` times
+// number of components. The elements have the same component type as the
+// base accessor.
+// https://github.com/KhronosGroup/glTF/blob/main/specification/2.0/schema/accessor.sparse.values.schema.json
+type AccessorSparseValues struct {
+	Property
+	BufferView GltfId `json:"bufferView"`           // The index of the bufferView with sparse values. The referenced buffer view **MUST NOT** have its `target` or `byteStride` properties defined.
+	ByteOffset int    `json:"byteOffset,omitempty"` // The offset relative to the start of the bufferView in bytes.
 }
@@ -13,7 +13,7 @@ const (
 // https://github.com/KhronosGroup/glTF/blob/main/specification/2.0/schema/animation.channel.target.schema.json
 type AnimationChannelTarget struct {
 	Property
-	Node GltfId                     `json:"node,omitempty"`
+	Node *GltfId                    `json:"node,omitempty"` // The index of the node to animate. When undefined, the animated object **MAY** be defined by an extension.
 	Path AnimationChannelTargetPath `json:"path"`
 }
 
@@ -0,0 +1,69 @@
+package gltf
+
+import "fmt"
+
+type CameraType string
+
+const (
+	CameraType_PERSPECTIVE  CameraType = "perspective"
+	CameraType_ORTHOGRAPHIC CameraType = "orthographic"
+)
+
+// A perspective camera containing properties to create a perspective projection matrix.
+// https://github.com/KhronosGroup/glTF/blob/main/specification/2.0/schema/camera.perspective.schema.json
+type CameraPerspective struct {
+	Property
+	AspectRatio *float64 `json:"aspectRatio,omitempty"` // The floating-point aspect ratio of the field of view. When undefined, the aspect ratio of the rendering viewport **MUST** be used.
+	Yfov        float64  `json:"yfov"`                  // The floating-point vertical field of view in radians. This value **SHOULD** be less than π.
+	Zfar        *float64 `json:"zfar,omitempty"`        // The floating-point distance to the far clipping plane. When defined, `zfar` **MUST** be greater than `znear`. If `zfar` is undefined, client implementations **SHOULD** use infinite projection matrix.
+	Znear       float64  `json:"znear"`                 // The floating-point distance to the near clipping plane.
+}
+
+// An orthographic camera containing properties to create an orthographic projection matrix.
+// https://github.com/KhronosGroup/glTF/blob/main/specification/2.0/schema/camera.orthographic.schema.json
+type CameraOrthographic struct {
+	Property
+	Xmag  float64 `json:"xmag"`  // The floating-point horizontal magnification of the view. This value **MUST NOT** be equal to zero. This value **SHOULD NOT** be negative.
+	Ymag  float64 `json:"ymag"`  // The floating-point vertical magnification of the view. This value **MUST NOT** be equal to zero. This value **SHOULD NOT** be negative.
+	Zfar  float64 `json:"zfar"`  // The floating-point distance to the far clipping plane. This value **MUST NOT** be equal to zero. `zfar` **MUST** be greater than `znear`.
+	Znear float64 `json:"znear"` // The floating-point distance to the near clipping plane.
+}
+
+// A camera's projection. A node **MAY** reference a camera to apply a transform to place the camera in the scene.
+// https://github.com/KhronosGroup/glTF/blob/main/specification/2.0/schema/camera.schema.json
+type Camera struct {
+	ChildOfRootProperty
+	Orthographic *CameraOrthographic `json:"orthographic,omitempty"` // An orthographic camera containing properties to create an orthographic projection matrix. This property **MUST NOT** be defined when `perspective` is defined.
+	Perspective  *CameraPerspective  `json:"perspective,omitempty"`  // A perspective camera containing properties to create a perspective projection matrix. This property **MUST NOT** be defined when `orthographic` is defined.
+	Type         CameraType          `json:"type"`                   // Specifies if the camera uses a perspective or orthographic projection.
+}
+
+func (c Camera) validate() error {
+	switch c.Type {
+	case CameraType_PERSPECTIVE:
+		if c.Perspective == nil || c.Orthographic != nil {
+			return fmt.Errorf("perspective camera %q must only define perspective properties", c.Name)
+		}
+		if c.Perspective.Yfov <= 0 || c.Perspective.Znear <= 0 {
+			return fmt.Errorf("perspective camera %q requires a yfov and znear greater than 0", c.Name)
+		}
+		if c.Perspective.Zfar != nil && *c.Perspective.Zfar <= c.Perspective.Znear {
+			return fmt.Errorf("perspective camera %q zfar must be greater than znear", c.Name)
+		}
+
+	case CameraType_ORTHOGRAPHIC:
+		if c.Orthographic == nil || c.Perspective != nil {
+			return fmt.Errorf("orthographic camera %q must only define orthographic properties", c.Name)
+		}
+		if c.Orthographic.Xmag == 0 || c.Orthographic.Ymag == 0 {
+			return fmt.Errorf("orthographic camera %q magnification must not be 0", c.Name)
+		}
+		if c.Orthographic.Zfar <= c.Orthographic.Znear {
+			return fmt.Errorf("orthographic camera %q zfar must be greater than znear", c.Name)
+		}
+
+	default:
+		return fmt.Errorf("camera %q has unrecognized type %q", c.Name, c.Type)
+	}
+	return nil
+}
@@ -54,3 +54,17 @@ func Save(modelPath string, scene PolyformScene) error {
 		panic(fmt.Errorf("don't know how to save file with extension: %s", ext))
 	}
 }
+
+// Load reads the glTF or GLB file at the path specified, resolving any
+// external buffers relative to the file's directory
+func Load(gltfPath string) (*PolyformScene, error) {
+	f, err := os.Open(gltfPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(bufio.NewReader(f), &ReaderOptions{
+		BasePath: filepath.Dir(gltfPath),
+	})
+}
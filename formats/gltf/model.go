@@ -13,6 +13,40 @@ import (
 type PolyformScene struct {
 	Models []PolyformModel
 	Lights []KHR_LightsPunctual
+
+	// Root nodes of the scene's hierarchy, written alongside the scene's
+	// models and lights
+	Nodes []PolyformNode
+}
+
+// PolyformNode is a node within a scene's hierarchy. It's transform is
+// relative to it's parent, and is applied to everything attached to it along
+// with all of it's children.
+type PolyformNode struct {
+	Name   string
+	Extras map[string]any
+
+	Translation *vector3.Float64
+	Scale       *vector3.Float64
+	Rotation    *quaternion.Quaternion
+
+	// Models are written as children of the node, with their transforms
+	// relative to it. Models sharing a mesh and material reference the same
+	// glTF mesh, instancing it across nodes.
+	Models []PolyformModel
+
+	// Light attached to the node, positioned and oriented by the node's
+	// transform. The light's own position is ignored.
+	Light *KHR_LightsPunctual
+
+	// Camera attached to the node, looking down the node's -Z axis
+	Camera *Camera
+
+	// Sequences animating the node's transform. Sequences must not reference
+	// a joint.
+	Animations []animation.Sequence
+
+	Children []PolyformNode
 }
 
 // PolyformModel is a utility structure for reading/writing to GLTF format within
@@ -30,10 +64,27 @@ type PolyformModel struct {
 	// without increasing the mesh data footprint on the GPU
 	GpuInstances []trs.TRS
 
-	Skeleton   *animation.Skeleton
+	// Morph targets the mesh can blend towards, either through their
+	// default weights or sequences animating the model's weights
+	MorphTargets []PolyformMorphTarget
+
+	Skeleton *animation.Skeleton
+
+	// Sequences animating the joints of the skeleton, or the model itself
+	// when the sequence has no joint
 	Animations []animation.Sequence
 }
 
+// PolyformMorphTarget references the float3 attributes of the model's mesh
+// containing per vertex displacements. Attributes referenced by a morph
+// target are written as part of the target rather than the base mesh.
+type PolyformMorphTarget struct {
+	Name     string
+	Position string  // Attribute of position displacements
+	Normal   string  // Optional attribute of normal displacements
+	Weight   float64 // Default weight of the target
+}
+
 type PolyformMaterial struct {
 	Name                 string
 	Extras               map[string]any
@@ -27,7 +27,8 @@ func (mt materialIndices) findExistingMaterialID(mat *PolyformMaterial) (*int, b
 // materialEntry tracks a unique material and its corresponding GLTF material index
 type meshEntry struct {
 	polyMesh      *modeling.Mesh
-	materialIndex int // -1 is a valid value for absence of material
+	materialIndex int    // -1 is a valid value for absence of material
+	morphTargets  string // Summary of the morph targets the mesh was written with
 }
 
 // materialIndices handle deduplication of GLTF materials
@@ -38,7 +39,20 @@ type textureIndices map[*PolyformTexture]int
 
 type writtenMeshData struct {
 	attribute map[string]GltfId
+	targets   []map[string]GltfId
 	indices   *GltfId
 }
 
-type attributeIndices map[*modeling.Mesh]writtenMeshData
+// writtenMeshKey identifies mesh data written to the buffer. Morph targets
+// are a part of the key, as they change which attributes make up the base
+// mesh
+type writtenMeshKey struct {
+	polyMesh     *modeling.Mesh
+	morphTargets string
+}
+
+type attributeIndices map[writtenMeshKey]writtenMeshData
+
+// tangentMeshes tracks the meshes with generated tangents, so a mesh shared
+// by multiple models only has it's tangents generated and written once
+type tangentMeshes map[*modeling.Mesh]*modeling.Mesh
@@ -1,6 +1,8 @@
 package gltf
 
 import (
+	"bytes"
+	"fmt"
 	"image/color"
 	"io"
 
@@ -19,12 +21,14 @@ func init() {
 	factory := &refutil.TypeFactory{}
 
 	refutil.RegisterType[ArtifactNode](factory)
+	refutil.RegisterType[GroupNode](factory)
 	refutil.RegisterType[MaterialAnisotropyExtensionNode](factory)
 	refutil.RegisterType[MaterialClearcoatExtensionNode](factory)
 	refutil.RegisterType[MaterialNode](factory)
 	refutil.RegisterType[MaterialTransmissionExtensionNode](factory)
 	refutil.RegisterType[MaterialVolumeExtensionNode](factory)
 	refutil.RegisterType[ModelNode](factory)
+	refutil.RegisterType[ReadNode](factory)
 	refutil.RegisterType[TextureNode](factory)
 
 	generator.RegisterTypes(factory)
@@ -46,23 +50,162 @@ type ArtifactNode = nodes.Struct[artifact.Artifact, ArtifactNodeData]
 
 type ArtifactNodeData struct {
 	Models []nodes.NodeOutput[PolyformModel]
+	Groups []nodes.NodeOutput[PolyformNode]
 }
 
 func (gad ArtifactNodeData) Process() (artifact.Artifact, error) {
-	models := make([]PolyformModel, 0, len(gad.Models))
+	return &Artifact{
+		Scene: PolyformScene{
+			Models: outputValues(gad.Models),
+			Nodes:  outputValues(gad.Groups),
+		},
+	}, nil
+}
 
-	for _, m := range gad.Models {
-		if m == nil {
+// outputValues collects the values of every output that's been connected
+func outputValues[T any](outputs []nodes.NodeOutput[T]) []T {
+	values := make([]T, 0, len(outputs))
+	for _, o := range outputs {
+		if o == nil {
 			continue
 		}
-		models = append(models, m.Value())
+		values = append(values, o.Value())
 	}
+	return values
+}
 
-	return &Artifact{
-		Scene: PolyformScene{
-			Models: models,
-		},
-	}, nil
+type GroupNode = nodes.Struct[PolyformNode, GroupNodeData]
+
+type GroupNodeData struct {
+	Name   nodes.NodeOutput[string]
+	Models []nodes.NodeOutput[PolyformModel]
+	Groups []nodes.NodeOutput[PolyformNode]
+
+	Translation nodes.NodeOutput[vector3.Float64]
+	Rotation    nodes.NodeOutput[quaternion.Quaternion]
+	Scale       nodes.NodeOutput[vector3.Float64]
+}
+
+func (GroupNodeData) Description() string {
+	return "Groups models and other groups under a shared transform, written as a node within the glTF scene's hierarchy"
+}
+
+func (gnd GroupNodeData) Process() (PolyformNode, error) {
+	node := PolyformNode{
+		Name:     nodes.TryGetOutputValue(gnd.Name, "Group"),
+		Models:   outputValues(gnd.Models),
+		Children: outputValues(gnd.Groups),
+	}
+
+	if gnd.Translation != nil {
+		v := gnd.Translation.Value()
+		node.Translation = &v
+	}
+
+	if gnd.Rotation != nil {
+		v := gnd.Rotation.Value()
+		node.Rotation = &v
+	}
+
+	if gnd.Scale != nil {
+		v := gnd.Scale.Value()
+		node.Scale = &v
+	}
+
+	return node, nil
+}
+
+type ReadNode = nodes.Struct[modeling.Mesh, ReadNodeData]
+
+type ReadNodeData struct {
+	Data nodes.NodeOutput[[]byte]
+}
+
+func (ReadNodeData) Description() string {
+	return "Reads a glTF or GLB file, combining every model in the scene into a single mesh with each model's transform applied. Every model must share the same topology. Only geometry is kept, any materials and GPU instances the models reference are dropped"
+}
+
+func (rnd ReadNodeData) Process() (modeling.Mesh, error) {
+	if rnd.Data == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	data := rnd.Data.Value()
+	if len(data) == 0 {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	scene, err := Read(bytes.NewReader(data), nil)
+	if err != nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), err
+	}
+
+	var combined *modeling.Mesh
+	for _, model := range scene.Models {
+		if model.Mesh == nil {
+			continue
+		}
+
+		mesh := bakeModelTransform(model)
+		if combined == nil {
+			combined = &mesh
+			continue
+		}
+
+		// Meshes can only be combined when they share a topology
+		if combined.Topology() != mesh.Topology() {
+			return modeling.EmptyMesh(modeling.TriangleTopology), fmt.Errorf(
+				"model %q has %s topology, which can not be combined with the %s topology of the models before it",
+				model.Name, mesh.Topology(), combined.Topology(),
+			)
+		}
+
+		appended := combined.Append(mesh)
+		combined = &appended
+	}
+
+	if combined == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+	return *combined, nil
+}
+
+func bakeModelTransform(model PolyformModel) modeling.Mesh {
+	mesh := *model.Mesh
+	if model.Translation == nil && model.Rotation == nil && model.Scale == nil {
+		return mesh
+	}
+
+	position := vector3.Zero[float64]()
+	rotation := quaternion.Identity()
+	scale := vector3.One[float64]()
+
+	if model.Translation != nil {
+		position = *model.Translation
+	}
+
+	if model.Rotation != nil {
+		rotation = *model.Rotation
+	}
+
+	if model.Scale != nil {
+		scale = *model.Scale
+	}
+
+	if mesh.HasFloat3Attribute(modeling.PositionAttribute) {
+		mesh = mesh.ApplyTRS(trs.New(position, rotation, scale))
+	}
+
+	if mesh.HasFloat3Attribute(modeling.NormalAttribute) {
+		// Normals are transformed by the inverse transpose, which for a
+		// TRS is just the rotation with the scale inverted
+		inverseScale := vector3.New(1/scale.X(), 1/scale.Y(), 1/scale.Z())
+		mesh = mesh.ModifyFloat3Attribute(modeling.NormalAttribute, func(i int, v vector3.Float64) vector3.Float64 {
+			return rotation.Rotate(v.MultByVector(inverseScale)).Normalized()
+		})
+	}
+
+	return mesh
 }
 
 type ModelNode = nodes.Struct[PolyformModel, ModelNodeData]
@@ -0,0 +1,1406 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EliCDavis/polyform/math/mat"
+	"github.com/EliCDavis/polyform/math/quaternion"
+	"github.com/EliCDavis/polyform/math/trs"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/animation"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+const (
+	glbMagic     = 0x46546C67
+	glbChunkJSON = 0x4E4F534A
+	glbChunkBIN  = 0x004E4942
+)
+
+var ErrInvalidGLB = errors.New("invalid glb")
+
+// ReaderOptions controls how resources referenced by a glTF document are
+// resolved while reading
+type ReaderOptions struct {
+	// Directory that relative URIs for external buffers are resolved against
+	BasePath string
+
+	// Optional function for opening external buffers. When nil, the buffer
+	// is read from disk relative to BasePath
+	OpenURI func(uri string) (io.ReadCloser, error)
+}
+
+// Read interprets the contents of the reader as either a glTF JSON document
+// or a GLB binary container, and converts it into a PolyformScene.
+//
+// Every primitive of every mesh referenced by the default scene becomes its
+// own PolyformModel, with the model's TRS set to the world transform of the
+// node that referenced it. Skins are converted into skeletons, and any
+// translation, rotation and scale channels targeting their joints into
+// animation sequences.
+func Read(in io.Reader, options *ReaderOptions) (*PolyformScene, error) {
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, bin, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &reader{
+		doc:       *doc,
+		bin:       bin,
+		textures:  make(map[int]*PolyformTexture),
+		materials: make(map[int]*PolyformMaterial),
+	}
+	if options != nil {
+		r.options = *options
+	}
+	return r.scene()
+}
+
+// parse splits the data into the glTF document and the optional GLB binary
+// chunk that accompanies it
+func parse(data []byte) (*Gltf, []byte, error) {
+	if len(data) < 4 || binary.LittleEndian.Uint32(data) != glbMagic {
+		doc := &Gltf{}
+		if err := json.Unmarshal(data, doc); err != nil {
+			return nil, nil, fmt.Errorf("unable to parse gltf json: %w", err)
+		}
+		return doc, nil, nil
+	}
+
+	if len(data) < 20 {
+		return nil, nil, fmt.Errorf("%w: header truncated", ErrInvalidGLB)
+	}
+
+	if version := binary.LittleEndian.Uint32(data[4:]); version != 2 {
+		return nil, nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidGLB, version)
+	}
+
+	length := int(binary.LittleEndian.Uint32(data[8:]))
+	if length > len(data) {
+		return nil, nil, fmt.Errorf("%w: header declares %d bytes but only %d are present", ErrInvalidGLB, length, len(data))
+	}
+
+	var doc *Gltf
+	var bin []byte
+	offset := 12
+	for offset+8 <= length {
+		chunkLength := int(binary.LittleEndian.Uint32(data[offset:]))
+		chunkType := binary.LittleEndian.Uint32(data[offset+4:])
+		offset += 8
+
+		if offset+chunkLength > length {
+			return nil, nil, fmt.Errorf("%w: chunk exceeds file length", ErrInvalidGLB)
+		}
+		chunk := data[offset : offset+chunkLength]
+		offset += chunkLength
+
+		switch chunkType {
+		case glbChunkJSON:
+			doc = &Gltf{}
+			if err := json.Unmarshal(chunk, doc); err != nil {
+				return nil, nil, fmt.Errorf("unable to parse glb json chunk: %w", err)
+			}
+
+		case glbChunkBIN:
+			if bin == nil {
+				bin = chunk
+			}
+		}
+	}
+
+	if doc == nil {
+		return nil, nil, fmt.Errorf("%w: missing json chunk", ErrInvalidGLB)
+	}
+
+	return doc, bin, nil
+}
+
+// ============================================================================
+
+type reader struct {
+	doc     Gltf
+	bin     []byte
+	options ReaderOptions
+
+	buffers   [][]byte
+	parents   []int
+	world     []*mat.Matrix4x4
+	textures  map[int]*PolyformTexture
+	materials map[int]*PolyformMaterial
+}
+
+func (r *reader) scene() (*PolyformScene, error) {
+	if err := r.loadBuffers(); err != nil {
+		return nil, err
+	}
+
+	r.parents = make([]int, len(r.doc.Nodes))
+	for i := range r.parents {
+		r.parents[i] = -1
+	}
+	for i, node := range r.doc.Nodes {
+		for _, child := range node.Children {
+			if child < 0 || child >= len(r.doc.Nodes) {
+				return nil, fmt.Errorf("node %d references child %d which does not exist", i, child)
+			}
+			r.parents[child] = i
+		}
+	}
+	r.world = make([]*mat.Matrix4x4, len(r.doc.Nodes))
+
+	lights, err := r.lights()
+	if err != nil {
+		return nil, err
+	}
+
+	scene := &PolyformScene{}
+	visited := make([]bool, len(r.doc.Nodes))
+	var visit func(node int) error
+	visit = func(node int) error {
+		if visited[node] {
+			return fmt.Errorf("node %d is referenced more than once in the node hierarchy", node)
+		}
+		visited[node] = true
+
+		models, err := r.nodeModels(node)
+		if err != nil {
+			return err
+		}
+		scene.Models = append(scene.Models, models...)
+
+		if light, ok := r.nodeLight(node, lights); ok {
+			scene.Lights = append(scene.Lights, light)
+		}
+
+		for _, child := range r.doc.Nodes[node].Children {
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, root := range r.rootNodes() {
+		if root < 0 || root >= len(r.doc.Nodes) {
+			return nil, fmt.Errorf("scene references node %d which does not exist", root)
+		}
+		if err := visit(root); err != nil {
+			return nil, err
+		}
+	}
+
+	return scene, nil
+}
+
+// rootNodes returns the root nodes of the default scene, or every parentless
+// node if the document doesn't define any scenes
+func (r *reader) rootNodes() []int {
+	if len(r.doc.Scenes) > 0 {
+		scene := r.doc.Scene
+		if scene < 0 || scene >= len(r.doc.Scenes) {
+			scene = 0
+		}
+		return r.doc.Scenes[scene].Nodes
+	}
+
+	roots := make([]int, 0)
+	for i, parent := range r.parents {
+		if parent == -1 {
+			roots = append(roots, i)
+		}
+	}
+	return roots
+}
+
+// Buffers ====================================================================
+
+func (r *reader) loadBuffers() error {
+	r.buffers = make([][]byte, len(r.doc.Buffers))
+	for i, buffer := range r.doc.Buffers {
+		data, err := r.loadBuffer(i, buffer)
+		if err != nil {
+			return fmt.Errorf("unable to load buffer %d: %w", i, err)
+		}
+
+		if len(data) < buffer.ByteLength {
+			return fmt.Errorf("buffer %d declares %d bytes but only %d are available", i, buffer.ByteLength, len(data))
+		}
+		r.buffers[i] = data[:buffer.ByteLength]
+	}
+	return nil
+}
+
+func (r *reader) loadBuffer(index int, buffer Buffer) ([]byte, error) {
+	if buffer.URI == "" {
+		if index != 0 || r.bin == nil {
+			return nil, errors.New("buffer has no uri and there is no glb binary chunk")
+		}
+		return r.bin, nil
+	}
+
+	if strings.HasPrefix(buffer.URI, "data:") {
+		data, _, err := decodeDataURI(buffer.URI)
+		return data, err
+	}
+
+	return r.readExternal(buffer.URI)
+}
+
+func (r *reader) readExternal(uri string) ([]byte, error) {
+	unescaped, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.options.OpenURI != nil {
+		f, err := r.options.OpenURI(unescaped)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+
+	return os.ReadFile(filepath.Join(r.options.BasePath, filepath.FromSlash(unescaped)))
+}
+
+// decodeDataURI returns the data contained within the URI along with it's
+// media type
+func decodeDataURI(uri string) ([]byte, string, error) {
+	header, payload, found := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !found {
+		return nil, "", errors.New("malformed data uri")
+	}
+
+	mediaType := header
+	isBase64 := false
+	if strings.HasSuffix(header, ";base64") {
+		mediaType = strings.TrimSuffix(header, ";base64")
+		isBase64 = true
+	}
+
+	if isBase64 {
+		data, err := base64.StdEncoding.DecodeString(payload)
+		return data, mediaType, err
+	}
+
+	data, err := url.PathUnescape(payload)
+	return []byte(data), mediaType, err
+}
+
+func (r *reader) bufferView(index int) ([]byte, *BufferView, error) {
+	if index < 0 || index >= len(r.doc.BufferViews) {
+		return nil, nil, fmt.Errorf("buffer view %d does not exist", index)
+	}
+
+	view := r.doc.BufferViews[index]
+	if view.Buffer < 0 || view.Buffer >= len(r.buffers) {
+		return nil, nil, fmt.Errorf("buffer view %d references buffer %d which does not exist", index, view.Buffer)
+	}
+
+	buffer := r.buffers[view.Buffer]
+	if view.ByteOffset+view.ByteLength > len(buffer) {
+		return nil, nil, fmt.Errorf("buffer view %d exceeds the length of buffer %d", index, view.Buffer)
+	}
+
+	return buffer[view.ByteOffset : view.ByteOffset+view.ByteLength], &view, nil
+}
+
+// Accessors ==================================================================
+
+func (at AccessorType) components() (int, error) {
+	switch at {
+	case AccessorType_SCALAR:
+		return 1, nil
+	case AccessorType_VEC2:
+		return 2, nil
+	case AccessorType_VEC3:
+		return 3, nil
+	case AccessorType_VEC4, AccessorType_MAT2:
+		return 4, nil
+	case AccessorType_MAT3:
+		return 9, nil
+	case AccessorType_MAT4:
+		return 16, nil
+	}
+	return 0, fmt.Errorf("unknown accessor type: %q", at)
+}
+
+func readComponent(data []byte, componentType AccessorComponentType, normalized bool) float64 {
+	switch componentType {
+	case AccessorComponentType_BYTE:
+		v := float64(int8(data[0]))
+		if normalized {
+			return math.Max(v/127, -1)
+		}
+		return v
+
+	case AccessorComponentType_UNSIGNED_BYTE:
+		v := float64(data[0])
+		if normalized {
+			return v / 255
+		}
+		return v
+
+	case AccessorComponentType_SHORT:
+		v := float64(int16(binary.LittleEndian.Uint16(data)))
+		if normalized {
+			return math.Max(v/32767, -1)
+		}
+		return v
+
+	case AccessorComponentType_UNSIGNED_SHORT:
+		v := float64(binary.LittleEndian.Uint16(data))
+		if normalized {
+			return v / 65535
+		}
+		return v
+
+	case AccessorComponentType_UNSIGNED_INT:
+		return float64(binary.LittleEndian.Uint32(data))
+	}
+
+	return float64(math.Float32frombits(binary.LittleEndian.Uint32(data)))
+}
+
+func (r *reader) checkAccessor(index int) error {
+	if index < 0 || index >= len(r.doc.Accessors) {
+		return fmt.Errorf("accessor %d does not exist", index)
+	}
+	return nil
+}
+
+// accessor reads all elements of the accessor into a flat array, returning
+// the number of components that make up a single element
+func (r *reader) accessor(index int) ([]float64, int, error) {
+	if err := r.checkAccessor(index); err != nil {
+		return nil, 0, err
+	}
+
+	accessor := r.doc.Accessors[index]
+	components, err := accessor.Type.components()
+	if err != nil {
+		return nil, 0, fmt.Errorf("accessor %d: %w", index, err)
+	}
+
+	switch accessor.ComponentType {
+	case AccessorComponentType_BYTE, AccessorComponentType_UNSIGNED_BYTE,
+		AccessorComponentType_SHORT, AccessorComponentType_UNSIGNED_SHORT,
+		AccessorComponentType_UNSIGNED_INT, AccessorComponentType_FLOAT:
+	default:
+		return nil, 0, fmt.Errorf("accessor %d has unknown component type %d", index, accessor.ComponentType)
+	}
+
+	// An accessor without a buffer view is initialized with zeros
+	values := make([]float64, accessor.Count*components)
+	componentSize := accessor.ComponentType.Size()
+	elementSize := componentSize * components
+
+	if accessor.BufferView != nil {
+		data, view, err := r.bufferView(*accessor.BufferView)
+		if err != nil {
+			return nil, 0, fmt.Errorf("accessor %d: %w", index, err)
+		}
+
+		stride := elementSize
+		if view.ByteStride != nil && *view.ByteStride > 0 {
+			stride = *view.ByteStride
+		}
+
+		if accessor.Count > 0 && accessor.ByteOffset+(accessor.Count-1)*stride+elementSize > len(data) {
+			return nil, 0, fmt.Errorf("accessor %d exceeds the length of its buffer view", index)
+		}
+
+		for i := 0; i < accessor.Count; i++ {
+			start := accessor.ByteOffset + i*stride
+			for c := 0; c < components; c++ {
+				values[i*components+c] = readComponent(data[start+c*componentSize:], accessor.ComponentType, accessor.Normalized)
+			}
+		}
+	}
+
+	if accessor.Sparse != nil {
+		if err := r.applySparse(accessor, components, values); err != nil {
+			return nil, 0, fmt.Errorf("accessor %d: %w", index, err)
+		}
+	}
+
+	return values, components, nil
+}
+
+// applySparse overwrites the elements of the accessor's values that its
+// sparse storage says deviate
+func (r *reader) applySparse(accessor Accessor, components int, values []float64) error {
+	sparse := accessor.Sparse
+
+	switch sparse.Indices.ComponentType {
+	case AccessorComponentType_UNSIGNED_BYTE, AccessorComponentType_UNSIGNED_SHORT, AccessorComponentType_UNSIGNED_INT:
+	default:
+		return fmt.Errorf("sparse indices have invalid component type %d", sparse.Indices.ComponentType)
+	}
+
+	indexData, _, err := r.bufferView(sparse.Indices.BufferView)
+	if err != nil {
+		return fmt.Errorf("sparse indices: %w", err)
+	}
+
+	indexSize := sparse.Indices.ComponentType.Size()
+	if sparse.Indices.ByteOffset+sparse.Count*indexSize > len(indexData) {
+		return errors.New("sparse indices exceed the length of their buffer view")
+	}
+
+	valueData, _, err := r.bufferView(sparse.Values.BufferView)
+	if err != nil {
+		return fmt.Errorf("sparse values: %w", err)
+	}
+
+	componentSize := accessor.ComponentType.Size()
+	elementSize := componentSize * components
+	if sparse.Values.ByteOffset+sparse.Count*elementSize > len(valueData) {
+		return errors.New("sparse values exceed the length of their buffer view")
+	}
+
+	for i := 0; i < sparse.Count; i++ {
+		element := int(readComponent(indexData[sparse.Indices.ByteOffset+i*indexSize:], sparse.Indices.ComponentType, false))
+		if element >= accessor.Count {
+			return fmt.Errorf("sparse index %d is out of range of the accessor's %d elements", element, accessor.Count)
+		}
+
+		start := sparse.Values.ByteOffset + i*elementSize
+		for c := 0; c < components; c++ {
+			values[element*components+c] = readComponent(valueData[start+c*componentSize:], accessor.ComponentType, accessor.Normalized)
+		}
+	}
+
+	return nil
+}
+
+func (r *reader) accessorWithComponents(index, components int) ([]float64, error) {
+	values, actual, err := r.accessor(index)
+	if err != nil {
+		return nil, err
+	}
+	if actual != components {
+		return nil, fmt.Errorf("accessor %d was expected to have %d components, but has %d", index, components, actual)
+	}
+	return values, nil
+}
+
+func toVector2Array(values []float64) []vector2.Float64 {
+	out := make([]vector2.Float64, len(values)/2)
+	for i := range out {
+		out[i] = vector2.New(values[i*2], values[i*2+1])
+	}
+	return out
+}
+
+func toVector3Array(values []float64) []vector3.Float64 {
+	out := make([]vector3.Float64, len(values)/3)
+	for i := range out {
+		out[i] = vector3.New(values[i*3], values[i*3+1], values[i*3+2])
+	}
+	return out
+}
+
+func toVector4Array(values []float64) []vector4.Float64 {
+	out := make([]vector4.Float64, len(values)/4)
+	for i := range out {
+		out[i] = vector4.New(values[i*4], values[i*4+1], values[i*4+2], values[i*4+3])
+	}
+	return out
+}
+
+// Transforms =================================================================
+
+func matrixFromColumnMajor(m [16]float64) mat.Matrix4x4 {
+	return mat.Matrix4x4{
+		X00: m[0], X01: m[4], X02: m[8], X03: m[12],
+		X10: m[1], X11: m[5], X12: m[9], X13: m[13],
+		X20: m[2], X21: m[6], X22: m[10], X23: m[14],
+		X30: m[3], X31: m[7], X32: m[11], X33: m[15],
+	}
+}
+
+func (r *reader) localMatrix(node Node) mat.Matrix4x4 {
+	if node.Matrix != nil {
+		return matrixFromColumnMajor(*node.Matrix)
+	}
+
+	position := vector3.Zero[float64]()
+	rotation := quaternion.Identity()
+	scale := vector3.One[float64]()
+
+	if node.Translation != nil {
+		position = vector3.New(node.Translation[0], node.Translation[1], node.Translation[2])
+	}
+
+	if node.Rotation != nil {
+		rotation = quaternion.New(vector3.New(node.Rotation[0], node.Rotation[1], node.Rotation[2]), node.Rotation[3])
+	}
+
+	if node.Scale != nil {
+		scale = vector3.New(node.Scale[0], node.Scale[1], node.Scale[2])
+	}
+
+	return trs.New(position, rotation, scale).Matrix()
+}
+
+func (r *reader) worldMatrix(node int) mat.Matrix4x4 {
+	if r.world[node] != nil {
+		return *r.world[node]
+	}
+
+	m := r.localMatrix(r.doc.Nodes[node])
+	if parent := r.parents[node]; parent != -1 {
+		m = r.worldMatrix(parent).Multiply(m)
+	}
+	r.world[node] = &m
+	return m
+}
+
+// decompose splits an affine matrix into it's translation, rotation and scale
+func decompose(m mat.Matrix4x4) (vector3.Float64, quaternion.Quaternion, vector3.Float64) {
+	translation := vector3.New(m.X03, m.X13, m.X23)
+	scale := vector3.New(
+		vector3.New(m.X00, m.X10, m.X20).Length(),
+		vector3.New(m.X01, m.X11, m.X21).Length(),
+		vector3.New(m.X02, m.X12, m.X22).Length(),
+	)
+
+	if scale.X() == 0 || scale.Y() == 0 || scale.Z() == 0 {
+		return translation, quaternion.Identity(), scale
+	}
+
+	// quaternion.FromMatrix expects the transpose of the rotation matrix
+	rotation := quaternion.FromMatrix(mat.Matrix4x4{
+		X00: m.X00 / scale.X(), X01: m.X10 / scale.X(), X02: m.X20 / scale.X(),
+		X10: m.X01 / scale.Y(), X11: m.X11 / scale.Y(), X12: m.X21 / scale.Y(),
+		X20: m.X02 / scale.Z(), X21: m.X12 / scale.Z(), X22: m.X22 / scale.Z(),
+		X33: 1,
+	})
+
+	return translation, rotation.Normalize(), scale
+}
+
+// Materials ==================================================================
+
+func floatsToColor(values []float64) color.Color {
+	c := color.RGBA64{A: math.MaxUint16}
+	channel := func(v float64) uint16 {
+		return uint16(math.Round(math.Max(0, math.Min(1, v)) * math.MaxUint16))
+	}
+
+	if len(values) > 0 {
+		c.R = channel(values[0])
+	}
+	if len(values) > 1 {
+		c.G = channel(values[1])
+	}
+	if len(values) > 2 {
+		c.B = channel(values[2])
+	}
+	if len(values) > 3 {
+		c.A = channel(values[3])
+	}
+	return c
+}
+
+func (r *reader) image(index int) (string, error) {
+	if index < 0 || index >= len(r.doc.Images) {
+		return "", fmt.Errorf("image %d does not exist", index)
+	}
+
+	img := r.doc.Images[index]
+	if img.URI != "" {
+		return img.URI, nil
+	}
+
+	// Images embedded within a buffer view get converted to data URIs, so
+	// they survive being written back out
+	data, _, err := r.bufferView(img.BufferView)
+	if err != nil {
+		return "", fmt.Errorf("image %d: %w", index, err)
+	}
+
+	mimeType := img.MimeType
+	if mimeType == "" {
+		mimeType = ImageMimeType_PNG
+	}
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data)), nil
+}
+
+func (r *reader) texture(index int) (*PolyformTexture, error) {
+	if tex, ok := r.textures[index]; ok {
+		return tex, nil
+	}
+
+	if index < 0 || index >= len(r.doc.Textures) {
+		return nil, fmt.Errorf("texture %d does not exist", index)
+	}
+
+	gltfTex := r.doc.Textures[index]
+	tex := &PolyformTexture{}
+
+	if gltfTex.Source != nil {
+		uri, err := r.image(*gltfTex.Source)
+		if err != nil {
+			return nil, fmt.Errorf("texture %d: %w", index, err)
+		}
+		tex.URI = uri
+	}
+
+	if gltfTex.Sampler != nil {
+		if *gltfTex.Sampler < 0 || *gltfTex.Sampler >= len(r.doc.Samplers) {
+			return nil, fmt.Errorf("texture %d references sampler %d which does not exist", index, *gltfTex.Sampler)
+		}
+		sampler := r.doc.Samplers[*gltfTex.Sampler]
+		tex.Sampler = &sampler
+	}
+
+	r.textures[index] = tex
+	return tex, nil
+}
+
+func (r *reader) textureInfo(info *TextureInfo) (*PolyformTexture, error) {
+	if info == nil {
+		return nil, nil
+	}
+
+	tex, err := r.texture(info.Index)
+	if err != nil {
+		return nil, err
+	}
+
+	transform, ok := info.Extensions[khrTextureTransformID]
+	if !ok {
+		return tex, nil
+	}
+
+	ext, err := r.decodeTextureTransform(transform)
+	if err != nil {
+		return nil, err
+	}
+
+	withExtensions := *tex
+	withExtensions.Extensions = []TextureExtension{ext}
+	return &withExtensions, nil
+}
+
+func (r *reader) material(index int) (*PolyformMaterial, error) {
+	if mat, ok := r.materials[index]; ok {
+		return mat, nil
+	}
+
+	if index < 0 || index >= len(r.doc.Materials) {
+		return nil, fmt.Errorf("material %d does not exist", index)
+	}
+
+	gltfMat := r.doc.Materials[index]
+	mat := &PolyformMaterial{
+		Name:        gltfMat.Name,
+		Extras:      gltfMat.Extras,
+		AlphaMode:   gltfMat.AlphaMode,
+		AlphaCutoff: gltfMat.AlphaCutoff,
+	}
+
+	if gltfMat.EmissiveFactor != nil {
+		mat.EmissiveFactor = floatsToColor(gltfMat.EmissiveFactor[:])
+	}
+
+	var err error
+	if pbr := gltfMat.PbrMetallicRoughness; pbr != nil {
+		mat.PbrMetallicRoughness = &PolyformPbrMetallicRoughness{
+			MetallicFactor:  pbr.MetallicFactor,
+			RoughnessFactor: pbr.RoughnessFactor,
+		}
+
+		if pbr.BaseColorFactor != nil {
+			mat.PbrMetallicRoughness.BaseColorFactor = floatsToColor(pbr.BaseColorFactor[:])
+		}
+
+		if mat.PbrMetallicRoughness.BaseColorTexture, err = r.textureInfo(pbr.BaseColorTexture); err != nil {
+			return nil, fmt.Errorf("material %d: %w", index, err)
+		}
+
+		if mat.PbrMetallicRoughness.MetallicRoughnessTexture, err = r.textureInfo(pbr.MetallicRoughnessTexture); err != nil {
+			return nil, fmt.Errorf("material %d: %w", index, err)
+		}
+	}
+
+	if gltfMat.NormalTexture != nil {
+		tex, err := r.textureInfo(&gltfMat.NormalTexture.TextureInfo)
+		if err != nil {
+			return nil, fmt.Errorf("material %d: %w", index, err)
+		}
+		mat.NormalTexture = &PolyformNormal{
+			PolyformTexture: tex,
+			Scale:           gltfMat.NormalTexture.Scale,
+		}
+	}
+
+	if gltfMat.OcclusionTexture != nil {
+		tex, err := r.textureInfo(&gltfMat.OcclusionTexture.TextureInfo)
+		if err != nil {
+			return nil, fmt.Errorf("material %d: %w", index, err)
+		}
+		mat.OcclusionTexture = &PolyformOcclusion{
+			PolyformTexture: tex,
+			Strength:        gltfMat.OcclusionTexture.Strength,
+		}
+	}
+
+	for id, data := range gltfMat.Extensions {
+		ext, err := r.decodeMaterialExtension(id, data)
+		if err != nil {
+			return nil, fmt.Errorf("material %d: %w", index, err)
+		}
+		if ext != nil {
+			mat.Extensions = append(mat.Extensions, ext)
+		}
+	}
+
+	r.materials[index] = mat
+	return mat, nil
+}
+
+// Meshes =====================================================================
+
+func gltfToPolyformAttribute(key string) string {
+	switch key {
+	case POSITION:
+		return modeling.PositionAttribute
+
+	case COLOR_0:
+		return modeling.ColorAttribute
+
+	case JOINTS_0:
+		return modeling.JointAttribute
+
+	case WEIGHTS_0:
+		return modeling.WeightAttribute
+
+	case TEXCOORD_0:
+		return modeling.TexCoordAttribute
+
+	case NORMAL:
+		return modeling.NormalAttribute
+
+	case TANGENT:
+		return modeling.TangentAttribute
+	}
+	return key
+}
+
+func (r *reader) primitive(p Primitive, skin *skinData) (modeling.Mesh, error) {
+	position, ok := p.Attributes[POSITION]
+	if !ok {
+		return modeling.Mesh{}, errors.New("primitive is missing a POSITION attribute")
+	}
+
+	for key, accessor := range p.Attributes {
+		if err := r.checkAccessor(accessor); err != nil {
+			return modeling.Mesh{}, fmt.Errorf("attribute %s: %w", key, err)
+		}
+	}
+
+	if p.Indices != nil {
+		if err := r.checkAccessor(*p.Indices); err != nil {
+			return modeling.Mesh{}, fmt.Errorf("indices: %w", err)
+		}
+	}
+
+	vertexCount := r.doc.Accessors[position].Count
+
+	v4 := make(map[string][]vector4.Float64)
+	v3 := make(map[string][]vector3.Float64)
+	v2 := make(map[string][]vector2.Float64)
+	v1 := make(map[string][]float64)
+
+	for key, accessor := range p.Attributes {
+		values, components, err := r.accessor(accessor)
+		if err != nil {
+			return modeling.Mesh{}, fmt.Errorf("attribute %s: %w", key, err)
+		}
+
+		if len(values) != vertexCount*components {
+			return modeling.Mesh{}, fmt.Errorf("attribute %s has a different vertex count than POSITION", key)
+		}
+
+		attr := gltfToPolyformAttribute(key)
+		switch components {
+		case 1:
+			v1[attr] = values
+		case 2:
+			v2[attr] = toVector2Array(values)
+		case 3:
+			v3[attr] = toVector3Array(values)
+		case 4:
+			v4[attr] = toVector4Array(values)
+		default:
+			return modeling.Mesh{}, fmt.Errorf("attribute %s has unsupported component count %d", key, components)
+		}
+	}
+
+	if joints, ok := v4[modeling.JointAttribute]; ok && skin != nil {
+		for i, j := range joints {
+			joints[i] = vector4.New(
+				float64(skin.jointIndex(int(j.X()))),
+				float64(skin.jointIndex(int(j.Y()))),
+				float64(skin.jointIndex(int(j.Z()))),
+				float64(skin.jointIndex(int(j.W()))),
+			)
+		}
+	}
+
+	var indices []int
+	if p.Indices != nil {
+		values, err := r.accessorWithComponents(*p.Indices, 1)
+		if err != nil {
+			return modeling.Mesh{}, fmt.Errorf("indices: %w", err)
+		}
+		indices = make([]int, len(values))
+		for i, v := range values {
+			indices[i] = int(v)
+			if indices[i] >= vertexCount {
+				return modeling.Mesh{}, fmt.Errorf("index %d references vertex %d, but only %d vertices exist", i, indices[i], vertexCount)
+			}
+		}
+	} else {
+		indices = make([]int, vertexCount)
+		for i := range indices {
+			indices[i] = i
+		}
+	}
+
+	mode := PrimitiveMode_TRIANGLES
+	if p.Mode != nil {
+		mode = *p.Mode
+	}
+
+	topology, indices, err := convertPrimitiveMode(mode, indices)
+	if err != nil {
+		return modeling.Mesh{}, err
+	}
+
+	return modeling.NewMesh(topology, indices).
+		SetFloat4Data(v4).
+		SetFloat3Data(v3).
+		SetFloat2Data(v2).
+		SetFloat1Data(v1), nil
+}
+
+// convertPrimitiveMode converts the indices into one of the topologies
+// supported by modeling.Mesh, expanding strips, fans and loops as needed
+func convertPrimitiveMode(mode PrimitiveMode, indices []int) (modeling.Topology, []int, error) {
+	switch mode {
+	case PrimitiveMode_POINTS:
+		return modeling.PointTopology, indices, nil
+
+	case PrimitiveMode_LINES:
+		return modeling.LineTopology, indices[:len(indices)-len(indices)%2], nil
+
+	case PrimitiveMode_LINE_STRIP, PrimitiveMode_LINE_LOOP:
+		lines := make([]int, 0, len(indices)*2)
+		for i := 1; i < len(indices); i++ {
+			lines = append(lines, indices[i-1], indices[i])
+		}
+		if mode == PrimitiveMode_LINE_LOOP && len(indices) > 2 {
+			lines = append(lines, indices[len(indices)-1], indices[0])
+		}
+		return modeling.LineTopology, lines, nil
+
+	case PrimitiveMode_TRIANGLES:
+		return modeling.TriangleTopology, indices[:len(indices)-len(indices)%3], nil
+
+	case PrimitiveMode_TRIANGLE_STRIP:
+		tris := make([]int, 0, len(indices)*3)
+		for i := 2; i < len(indices); i++ {
+			if i%2 == 0 {
+				tris = append(tris, indices[i-2], indices[i-1], indices[i])
+			} else {
+				tris = append(tris, indices[i-1], indices[i-2], indices[i])
+			}
+		}
+		return modeling.TriangleTopology, tris, nil
+
+	case PrimitiveMode_TRIANGLE_FAN:
+		tris := make([]int, 0, len(indices)*3)
+		for i := 2; i < len(indices); i++ {
+			tris = append(tris, indices[0], indices[i-1], indices[i])
+		}
+		return modeling.TriangleTopology, tris, nil
+	}
+
+	return 0, nil, fmt.Errorf("unknown primitive mode: %d", mode)
+}
+
+func (r *reader) gpuInstances(node Node) ([]trs.TRS, error) {
+	data, ok := node.Extensions[extGpuInstancingID]
+	if !ok {
+		return nil, nil
+	}
+
+	ext := ExtGpuInstancing{}
+	if err := decodeExtension(data, &ext); err != nil {
+		return nil, fmt.Errorf("%s: %w", extGpuInstancingID, err)
+	}
+
+	var translations, scales []vector3.Float64
+	var rotations []vector4.Float64
+	count := -1
+
+	if accessor, ok := ext.Attributes["TRANSLATION"]; ok {
+		values, err := r.accessorWithComponents(accessor, 3)
+		if err != nil {
+			return nil, err
+		}
+		translations = toVector3Array(values)
+		count = len(translations)
+	}
+
+	if accessor, ok := ext.Attributes["ROTATION"]; ok {
+		values, err := r.accessorWithComponents(accessor, 4)
+		if err != nil {
+			return nil, err
+		}
+		rotations = toVector4Array(values)
+		count = len(rotations)
+	}
+
+	if accessor, ok := ext.Attributes["SCALE"]; ok {
+		values, err := r.accessorWithComponents(accessor, 3)
+		if err != nil {
+			return nil, err
+		}
+		scales = toVector3Array(values)
+		count = len(scales)
+	}
+
+	instances := make([]trs.TRS, max(count, 0))
+	for i := range instances {
+		position := vector3.Zero[float64]()
+		rotation := quaternion.Identity()
+		scale := vector3.One[float64]()
+
+		if i < len(translations) {
+			position = translations[i]
+		}
+		if i < len(rotations) {
+			rotation = quaternion.New(rotations[i].XYZ(), rotations[i].W())
+		}
+		if i < len(scales) {
+			scale = scales[i]
+		}
+		instances[i] = trs.New(position, rotation, scale)
+	}
+	return instances, nil
+}
+
+func (r *reader) nodeModels(nodeIndex int) ([]PolyformModel, error) {
+	node := r.doc.Nodes[nodeIndex]
+	if node.Mesh == nil {
+		return nil, nil
+	}
+
+	if *node.Mesh < 0 || *node.Mesh >= len(r.doc.Meshes) {
+		return nil, fmt.Errorf("node %d references mesh %d which does not exist", nodeIndex, *node.Mesh)
+	}
+	gltfMesh := r.doc.Meshes[*node.Mesh]
+
+	var skin *skinData
+	if node.Skin != nil {
+		var err error
+		if skin, err = r.skin(*node.Skin); err != nil {
+			return nil, fmt.Errorf("node %d: %w", nodeIndex, err)
+		}
+	}
+
+	instances, err := r.gpuInstances(node)
+	if err != nil {
+		return nil, fmt.Errorf("node %d: %w", nodeIndex, err)
+	}
+
+	// Animations of the node are relative to it's parent, which is lost as
+	// we flatten the hierarchy, so they're only accurate for root nodes
+	nodeAnimations, err := r.animations(map[int]string{nodeIndex: ""})
+	if err != nil {
+		return nil, fmt.Errorf("node %d: %w", nodeIndex, err)
+	}
+
+	translation, rotation, scale := decompose(r.worldMatrix(nodeIndex))
+
+	name := node.Name
+	if name == "" {
+		name = gltfMesh.Name
+	}
+
+	models := make([]PolyformModel, 0, len(gltfMesh.Primitives))
+	for i, p := range gltfMesh.Primitives {
+		mesh, err := r.primitive(p, skin)
+		if err != nil {
+			return nil, fmt.Errorf("mesh %d primitive %d: %w", *node.Mesh, i, err)
+		}
+
+		model := PolyformModel{
+			Name:         name,
+			Mesh:         &mesh,
+			GpuInstances: instances,
+		}
+
+		if len(gltfMesh.Primitives) > 1 {
+			model.Name = fmt.Sprintf("%s_%d", name, i)
+		}
+
+		if p.Material != nil {
+			if model.Material, err = r.material(*p.Material); err != nil {
+				return nil, err
+			}
+		}
+
+		if translation != vector3.Zero[float64]() {
+			v := translation
+			model.Translation = &v
+		}
+
+		if rotation != quaternion.Identity() {
+			v := rotation
+			model.Rotation = &v
+		}
+
+		if scale != vector3.One[float64]() {
+			v := scale
+			model.Scale = &v
+		}
+
+		if skin != nil {
+			model.Skeleton = &skin.skeleton
+			model.Animations = append(model.Animations, skin.animations...)
+		}
+		model.Animations = append(model.Animations, nodeAnimations...)
+
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+// Lights =====================================================================
+
+type khrLightsPunctualDocument struct {
+	Lights []struct {
+		Type      KHR_LightsPunctualType `json:"type"`
+		Name      *string                `json:"name"`
+		Color     []float64              `json:"color"`
+		Intensity *float64               `json:"intensity"`
+		Range     *float64               `json:"range"`
+	} `json:"lights"`
+}
+
+type khrLightsPunctualNode struct {
+	Light int `json:"light"`
+}
+
+func (r *reader) lights() ([]KHR_LightsPunctual, error) {
+	data, ok := r.doc.Extensions[khrLightsPunctualID]
+	if !ok {
+		return nil, nil
+	}
+
+	doc := khrLightsPunctualDocument{}
+	if err := decodeExtension(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", khrLightsPunctualID, err)
+	}
+
+	lights := make([]KHR_LightsPunctual, len(doc.Lights))
+	for i, l := range doc.Lights {
+		lights[i] = KHR_LightsPunctual{
+			Type:      l.Type,
+			Name:      l.Name,
+			Intensity: l.Intensity,
+			Range:     l.Range,
+		}
+		if l.Color != nil {
+			lights[i].Color = floatsToColor(l.Color)
+		}
+	}
+	return lights, nil
+}
+
+func (r *reader) nodeLight(node int, lights []KHR_LightsPunctual) (KHR_LightsPunctual, bool) {
+	data, ok := r.doc.Nodes[node].Extensions[khrLightsPunctualID]
+	if !ok {
+		return KHR_LightsPunctual{}, false
+	}
+
+	ref := khrLightsPunctualNode{}
+	if err := decodeExtension(data, &ref); err != nil || ref.Light < 0 || ref.Light >= len(lights) {
+		return KHR_LightsPunctual{}, false
+	}
+
+	light := lights[ref.Light]
+	world := r.worldMatrix(node)
+	light.Position = vector3.New(world.X03, world.X13, world.X23)
+	return light, true
+}
+
+// Skins ======================================================================
+
+type skinData struct {
+	skeleton   animation.Skeleton
+	jointPaths []string // Path of each joint found in the glTF skin
+	animations []animation.Sequence
+}
+
+func (sd skinData) jointIndex(gltfJoint int) int {
+	if gltfJoint < 0 || gltfJoint >= len(sd.jointPaths) {
+		return 0
+	}
+	return sd.skeleton.Lookup(sd.jointPaths[gltfJoint])
+}
+
+func jointName(node Node, index int) string {
+	name := strings.ReplaceAll(node.Name, "/", "_")
+	if name == "" {
+		name = fmt.Sprintf("Joint%d", index)
+	}
+	return name
+}
+
+func (r *reader) skin(index int) (*skinData, error) {
+	if index < 0 || index >= len(r.doc.Skins) {
+		return nil, fmt.Errorf("skin %d does not exist", index)
+	}
+
+	skin := r.doc.Skins[index]
+	if len(skin.Joints) == 0 {
+		return nil, fmt.Errorf("skin %d has no joints", index)
+	}
+
+	isJoint := make(map[int]bool)
+	for _, j := range skin.Joints {
+		if j < 0 || j >= len(r.doc.Nodes) {
+			return nil, fmt.Errorf("skin %d references node %d which does not exist", index, j)
+		}
+		isJoint[j] = true
+	}
+
+	// Joints may be separated by nodes that aren't a part of the skin, so we
+	// skip over them when determining the hierarchy
+	var jointChildren func(node int) []int
+	jointChildren = func(node int) []int {
+		children := make([]int, 0)
+		for _, c := range r.doc.Nodes[node].Children {
+			if isJoint[c] {
+				children = append(children, c)
+			} else {
+				children = append(children, jointChildren(c)...)
+			}
+		}
+		return children
+	}
+
+	roots := make([]int, 0)
+	for _, j := range skin.Joints {
+		parent := r.parents[j]
+		for parent != -1 && !isJoint[parent] {
+			parent = r.parents[parent]
+		}
+		if parent == -1 {
+			roots = append(roots, j)
+		}
+	}
+
+	paths := make(map[int]string)
+	var buildJoint func(node int, parentPath string, name string) animation.Joint
+	buildJoint = func(node int, parentPath string, name string) animation.Joint {
+		path := name
+		if parentPath != "" {
+			path = parentPath + "/" + name
+		}
+		paths[node] = path
+
+		children := make([]animation.Joint, 0)
+		used := make(map[string]bool)
+		for _, c := range jointChildren(node) {
+			childName := jointName(r.doc.Nodes[c], c)
+			for used[childName] {
+				childName = fmt.Sprintf("%s_%d", childName, c)
+			}
+			used[childName] = true
+			children = append(children, buildJoint(c, path, childName))
+		}
+
+		world := r.worldMatrix(node)
+		_, rotation, _ := decompose(world)
+		return animation.NewJoint(
+			name,
+			1,
+			vector3.New(world.X03, world.X13, world.X23),
+			rotation.Rotate(vector3.Up[float64]()),
+			rotation.Rotate(vector3.Forward[float64]()),
+			children...,
+		)
+	}
+
+	var root animation.Joint
+	if len(roots) == 1 {
+		root = buildJoint(roots[0], "", jointName(r.doc.Nodes[roots[0]], roots[0]))
+	} else {
+		// Skeletons only support a single root, so we introduce one
+		children := make([]animation.Joint, 0, len(roots))
+		used := make(map[string]bool)
+		for _, j := range roots {
+			name := jointName(r.doc.Nodes[j], j)
+			for used[name] {
+				name = fmt.Sprintf("%s_%d", name, j)
+			}
+			used[name] = true
+			children = append(children, buildJoint(j, "Root", name))
+		}
+		root = animation.NewJoint("Root", 1, vector3.Zero[float64](), vector3.Up[float64](), vector3.Forward[float64](), children...)
+	}
+
+	data := &skinData{
+		skeleton:   animation.NewSkeleton(root),
+		jointPaths: make([]string, len(skin.Joints)),
+	}
+
+	for i, j := range skin.Joints {
+		data.jointPaths[i] = paths[j]
+	}
+
+	animations, err := r.animations(paths)
+	if err != nil {
+		return nil, fmt.Errorf("skin %d: %w", index, err)
+	}
+	data.animations = animations
+
+	return data, nil
+}
+
+// animations builds a sequence for every translation, rotation, and scale
+// channel targeting one of the nodes provided, with each sequence's joint
+// being the path the node maps to. Morph target weights are skipped, as
+// morph targets aren't read.
+func (r *reader) animations(targets map[int]string) ([]animation.Sequence, error) {
+	sequences := make([]animation.Sequence, 0)
+	for a, anim := range r.doc.Animations {
+		for c, channel := range anim.Channels {
+			if channel.Target.Node == nil {
+				continue
+			}
+
+			joint, ok := targets[*channel.Target.Node]
+			if !ok {
+				continue
+			}
+
+			components := 3
+			switch channel.Target.Path {
+			case AnimationChannelTargetPath_TRANSLATION, AnimationChannelTargetPath_SCALE:
+			case AnimationChannelTargetPath_ROTATION:
+				components = 4
+			default:
+				continue
+			}
+
+			if channel.Sampler < 0 || channel.Sampler >= len(anim.Samplers) {
+				return nil, fmt.Errorf("animation %d channel %d references sampler %d which does not exist", a, c, channel.Sampler)
+			}
+			sampler := anim.Samplers[channel.Sampler]
+
+			times, err := r.accessorWithComponents(sampler.Input, 1)
+			if err != nil {
+				return nil, fmt.Errorf("animation %d sampler input: %w", a, err)
+			}
+
+			values, err := r.accessorWithComponents(sampler.Output, components)
+			if err != nil {
+				return nil, fmt.Errorf("animation %d sampler output: %w", a, err)
+			}
+
+			var interpolation animation.Interpolation
+			switch sampler.Interpolation {
+			case AnimationSamplerInterpolation_STEP:
+				interpolation = animation.StepInterpolation
+			case AnimationSamplerInterpolation_CUBICSPLINE:
+				interpolation = animation.CubicSplineInterpolation
+			default:
+				interpolation = animation.LinearInterpolation
+			}
+
+			// Cubic splines store an in-tangent, value, and out-tangent for
+			// every keyframe
+			stride := 1
+			if interpolation == animation.CubicSplineInterpolation {
+				stride = 3
+			}
+
+			if len(values) < len(times)*stride*components {
+				return nil, fmt.Errorf("animation %d sampler %d has fewer outputs than keyframes", a, channel.Sampler)
+			}
+
+			if components == 4 {
+				rotations := toVector4Array(values)
+				rotation := func(i int) quaternion.Quaternion {
+					return quaternion.New(rotations[i].XYZ(), rotations[i].W())
+				}
+
+				frames := make([]animation.Frame[quaternion.Quaternion], len(times))
+				for i, t := range times {
+					if stride == 3 {
+						frames[i] = animation.NewCubicSplineFrame(t, rotation(i*3), rotation(i*3+1), rotation(i*3+2))
+					} else {
+						frames[i] = animation.NewFrame(t, rotation(i))
+					}
+				}
+				sequences = append(sequences, animation.NewRotationSequence(joint, interpolation, frames))
+				continue
+			}
+
+			vectors := toVector3Array(values)
+			frames := make([]animation.Frame[vector3.Float64], len(times))
+			for i, t := range times {
+				if stride == 3 {
+					frames[i] = animation.NewCubicSplineFrame(t, vectors[i*3], vectors[i*3+1], vectors[i*3+2])
+				} else {
+					frames[i] = animation.NewFrame(t, vectors[i])
+				}
+			}
+
+			if channel.Target.Path == AnimationChannelTargetPath_SCALE {
+				sequences = append(sequences, animation.NewScaleSequence(joint, interpolation, frames))
+			} else {
+				sequences = append(sequences, animation.NewTranslationSequence(joint, interpolation, frames))
+			}
+		}
+	}
+	return sequences, nil
+}
+
+func decodeExtension(data any, out any) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(bytes.NewReader(encoded)).Decode(out)
+}
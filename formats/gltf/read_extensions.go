@@ -0,0 +1,244 @@
+package gltf
+
+import (
+	"fmt"
+	"image/color"
+	"slices"
+
+	"github.com/EliCDavis/vector/vector2"
+)
+
+const (
+	khrLightsPunctualID   = "KHR_lights_punctual"
+	khrTextureTransformID = "KHR_texture_transform"
+)
+
+// decodeMaterialExtension converts the JSON representation of a material
+// extension into it's Polyform counterpart. Unrecognized extensions are
+// skipped, returning nil
+func (r *reader) decodeMaterialExtension(id string, data any) (MaterialExtension, error) {
+	var err error
+	tex := func(info *TextureInfo) *PolyformTexture {
+		if err != nil || info == nil {
+			return nil
+		}
+		var t *PolyformTexture
+		t, err = r.textureInfo(info)
+		return t
+	}
+
+	toColor := func(values []float64) color.Color {
+		if values == nil {
+			return nil
+		}
+		return floatsToColor(values)
+	}
+
+	switch id {
+	case "KHR_materials_pbrSpecularGlossiness":
+		raw := struct {
+			DiffuseFactor             []float64    `json:"diffuseFactor"`
+			DiffuseTexture            *TextureInfo `json:"diffuseTexture"`
+			SpecularFactor            []float64    `json:"specularFactor"`
+			GlossinessFactor          *float64     `json:"glossinessFactor"`
+			SpecularGlossinessTexture *TextureInfo `json:"specularGlossinessTexture"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ext := PolyformPbrSpecularGlossiness{
+			DiffuseFactor:             toColor(raw.DiffuseFactor),
+			DiffuseTexture:            tex(raw.DiffuseTexture),
+			SpecularFactor:            toColor(raw.SpecularFactor),
+			GlossinessFactor:          raw.GlossinessFactor,
+			SpecularGlossinessTexture: tex(raw.SpecularGlossinessTexture),
+		}
+		return ext, err
+
+	case "KHR_materials_transmission":
+		raw := struct {
+			TransmissionFactor  float64      `json:"transmissionFactor"`
+			TransmissionTexture *TextureInfo `json:"transmissionTexture"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ext := PolyformTransmission{
+			Factor:  raw.TransmissionFactor,
+			Texture: tex(raw.TransmissionTexture),
+		}
+		return ext, err
+
+	case "KHR_materials_volume":
+		raw := struct {
+			ThicknessFactor     float64      `json:"thicknessFactor"`
+			ThicknessTexture    *TextureInfo `json:"thicknessTexture"`
+			AttenuationDistance *float64     `json:"attenuationDistance"`
+			AttenuationColor    []float64    `json:"attenuationColor"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ext := PolyformVolume{
+			ThicknessFactor:     raw.ThicknessFactor,
+			ThicknessTexture:    tex(raw.ThicknessTexture),
+			AttenuationDistance: raw.AttenuationDistance,
+			AttenuationColor:    toColor(raw.AttenuationColor),
+		}
+		return ext, err
+
+	case "KHR_materials_ior":
+		raw := struct {
+			IOR *float64 `json:"ior"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		return PolyformIndexOfRefraction{IOR: raw.IOR}, nil
+
+	case "KHR_materials_specular":
+		raw := struct {
+			SpecularFactor       *float64     `json:"specularFactor"`
+			SpecularTexture      *TextureInfo `json:"specularTexture"`
+			SpecularColorFactor  []float64    `json:"specularColorFactor"`
+			SpecularColorTexture *TextureInfo `json:"specularColorTexture"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ext := PolyformSpecular{
+			Factor:       raw.SpecularFactor,
+			Texture:      tex(raw.SpecularTexture),
+			ColorFactor:  toColor(raw.SpecularColorFactor),
+			ColorTexture: tex(raw.SpecularColorTexture),
+		}
+		return ext, err
+
+	case "KHR_materials_unlit":
+		return PolyformUnlit{}, nil
+
+	case "KHR_materials_clearcoat":
+		raw := struct {
+			ClearcoatFactor           float64      `json:"clearcoatFactor"`
+			ClearcoatTexture          *TextureInfo `json:"clearcoatTexture"`
+			ClearcoatRoughnessFactor  float64      `json:"clearcoatRoughnessFactor"`
+			ClearcoatRoughnessTexture *TextureInfo `json:"clearcoatRoughnessTexture"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ext := PolyformClearcoat{
+			ClearcoatFactor:           raw.ClearcoatFactor,
+			ClearcoatTexture:          tex(raw.ClearcoatTexture),
+			ClearcoatRoughnessFactor:  raw.ClearcoatRoughnessFactor,
+			ClearcoatRoughnessTexture: tex(raw.ClearcoatRoughnessTexture),
+		}
+		return ext, err
+
+	case "KHR_materials_emissive_strength":
+		raw := struct {
+			EmissiveStrength *float64 `json:"emissiveStrength"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		return PolyformEmissiveStrength{EmissiveStrength: raw.EmissiveStrength}, nil
+
+	case "KHR_materials_iridescence":
+		raw := struct {
+			IridescenceFactor           float64      `json:"iridescenceFactor"`
+			IridescenceTexture          *TextureInfo `json:"iridescenceTexture"`
+			IridescenceIor              *float64     `json:"iridescenceIor"`
+			IridescenceThicknessMinimum *float64     `json:"iridescenceThicknessMinimum"`
+			IridescenceThicknessMaximum *float64     `json:"iridescenceThicknessMaximum"`
+			IridescenceThicknessTexture *TextureInfo `json:"iridescenceThicknessTexture"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ext := PolyformIridescence{
+			IridescenceFactor:           raw.IridescenceFactor,
+			IridescenceTexture:          tex(raw.IridescenceTexture),
+			IridescenceIor:              raw.IridescenceIor,
+			IridescenceThicknessMinimum: raw.IridescenceThicknessMinimum,
+			IridescenceThicknessMaximum: raw.IridescenceThicknessMaximum,
+			IridescenceThicknessTexture: tex(raw.IridescenceThicknessTexture),
+		}
+		return ext, err
+
+	case "KHR_materials_sheen":
+		raw := struct {
+			SheenColorFactor      []float64    `json:"sheenColorFactor"`
+			SheenColorTexture     *TextureInfo `json:"sheenColorTexture"`
+			SheenRoughnessFactor  float64      `json:"sheenRoughnessFactor"`
+			SheenRoughnessTexture *TextureInfo `json:"sheenRoughnessTexture"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ext := PolyformSheen{
+			SheenColorFactor:      toColor(raw.SheenColorFactor),
+			SheenColorTexture:     tex(raw.SheenColorTexture),
+			SheenRoughnessFactor:  raw.SheenRoughnessFactor,
+			SheenRoughnessTexture: tex(raw.SheenRoughnessTexture),
+		}
+		return ext, err
+
+	case "KHR_materials_anisotropy":
+		raw := struct {
+			AnisotropyStrength float64      `json:"anisotropyStrength"`
+			AnisotropyRotation float64      `json:"anisotropyRotation"`
+			AnisotropyTexture  *TextureInfo `json:"anisotropyTexture"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		ext := PolyformAnisotropy{
+			AnisotropyStrength: raw.AnisotropyStrength,
+			AnisotropyRotation: raw.AnisotropyRotation,
+			AnisotropyTexture:  tex(raw.AnisotropyTexture),
+		}
+		return ext, err
+
+	case "KHR_materials_dispersion":
+		raw := struct {
+			Dispersion float64 `json:"dispersion"`
+		}{}
+		if err := decodeExtension(data, &raw); err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		return PolyformDispersion{Dispersion: raw.Dispersion}, nil
+	}
+
+	return nil, nil
+}
+
+func (r *reader) decodeTextureTransform(data any) (PolyformTextureTransform, error) {
+	raw := struct {
+		Offset   *[2]float64 `json:"offset"`
+		Rotation *float64    `json:"rotation"`
+		Scale    *[2]float64 `json:"scale"`
+		TexCoord *int        `json:"texCoord"`
+	}{}
+	if err := decodeExtension(data, &raw); err != nil {
+		return PolyformTextureTransform{}, fmt.Errorf("%s: %w", khrTextureTransformID, err)
+	}
+
+	ext := PolyformTextureTransform{
+		Required: slices.Contains(r.doc.ExtensionsRequired, khrTextureTransformID),
+		Rotation: raw.Rotation,
+		TexCoord: raw.TexCoord,
+	}
+
+	if raw.Offset != nil {
+		v := vector2.New(raw.Offset[0], raw.Offset[1])
+		ext.Offset = &v
+	}
+
+	if raw.Scale != nil {
+		v := vector2.New(raw.Scale[0], raw.Scale[1])
+		ext.Scale = &v
+	}
+
+	return ext, nil
+}
@@ -0,0 +1,446 @@
+package gltf_test
+
+import (
+	"bytes"
+	"image/color"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EliCDavis/polyform/formats/gltf"
+	"github.com/EliCDavis/polyform/math/quaternion"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/animation"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestTri() modeling.Mesh {
+	return modeling.NewTriangleMesh([]int{0, 1, 2}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(0., 1., 0.),
+			vector3.New(1., 0., 0.),
+		}).
+		SetFloat3Attribute(modeling.NormalAttribute, []vector3.Float64{
+			vector3.New(0., 0., 1.),
+			vector3.New(0., 0., 1.),
+			vector3.New(0., 0., 1.),
+		}).
+		SetFloat2Attribute(modeling.TexCoordAttribute, []vector2.Float64{
+			vector2.New(0., 0.),
+			vector2.New(0., 1.),
+			vector2.New(1., 0.),
+		})
+}
+
+func assertVector3InDelta(t *testing.T, expected, actual vector3.Float64) {
+	t.Helper()
+	assert.InDelta(t, expected.X(), actual.X(), 0.0001)
+	assert.InDelta(t, expected.Y(), actual.Y(), 0.0001)
+	assert.InDelta(t, expected.Z(), actual.Z(), 0.0001)
+}
+
+func TestRead_RoundTrip(t *testing.T) {
+	tri := readTestTri()
+	translation := vector3.New(1., 2., 3.)
+	metallic := 0.25
+	intensity := 3.
+
+	scene := gltf.PolyformScene{
+		Models: []gltf.PolyformModel{
+			{
+				Name:        "tri",
+				Mesh:        &tri,
+				Translation: &translation,
+				Material: &gltf.PolyformMaterial{
+					Name: "red",
+					PbrMetallicRoughness: &gltf.PolyformPbrMetallicRoughness{
+						BaseColorFactor: color.RGBA{R: 255, A: 255},
+						MetallicFactor:  &metallic,
+						BaseColorTexture: &gltf.PolyformTexture{
+							URI: "albedo.png",
+						},
+					},
+					Extensions: []gltf.MaterialExtension{
+						gltf.PolyformUnlit{},
+					},
+				},
+			},
+		},
+		Lights: []gltf.KHR_LightsPunctual{
+			{
+				Type:      gltf.KHR_LightsPunctualType_Point,
+				Intensity: &intensity,
+				Position:  vector3.New(4., 5., 6.),
+			},
+		},
+	}
+
+	tests := map[string]func(gltf.PolyformScene, *bytes.Buffer) error{
+		"text": func(s gltf.PolyformScene, b *bytes.Buffer) error {
+			return gltf.WriteText(s, b)
+		},
+		"binary": func(s gltf.PolyformScene, b *bytes.Buffer) error {
+			return gltf.WriteBinary(s, b)
+		},
+	}
+
+	for name, write := range tests {
+		t.Run(name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			require.NoError(t, write(scene, &buf))
+
+			read, err := gltf.Read(&buf, nil)
+			require.NoError(t, err)
+			require.Len(t, read.Models, 1)
+
+			model := read.Models[0]
+			assert.Equal(t, "tri", model.Name)
+			require.NotNil(t, model.Translation)
+			assertVector3InDelta(t, translation, *model.Translation)
+			assert.Nil(t, model.Rotation)
+			assert.Nil(t, model.Scale)
+
+			mesh := model.Mesh
+			require.NotNil(t, mesh)
+			assert.Equal(t, modeling.TriangleTopology, mesh.Topology())
+			assert.Equal(t, 1, mesh.PrimitiveCount())
+			for _, attr := range []string{modeling.PositionAttribute, modeling.NormalAttribute} {
+				require.True(t, mesh.HasFloat3Attribute(attr), attr)
+				for i := 0; i < 3; i++ {
+					assertVector3InDelta(t, tri.Float3Attribute(attr).At(i), mesh.Float3Attribute(attr).At(i))
+				}
+			}
+			require.True(t, mesh.HasFloat2Attribute(modeling.TexCoordAttribute))
+			assert.Equal(t, vector2.New(1., 0.), mesh.Float2Attribute(modeling.TexCoordAttribute).At(2))
+
+			mat := model.Material
+			require.NotNil(t, mat)
+			assert.Equal(t, "red", mat.Name)
+			require.NotNil(t, mat.PbrMetallicRoughness)
+			r, g, b, a := mat.PbrMetallicRoughness.BaseColorFactor.RGBA()
+			assert.Equal(t, []uint32{0xffff, 0, 0, 0xffff}, []uint32{r, g, b, a})
+			assert.Equal(t, metallic, *mat.PbrMetallicRoughness.MetallicFactor)
+			require.NotNil(t, mat.PbrMetallicRoughness.BaseColorTexture)
+			assert.Equal(t, "albedo.png", mat.PbrMetallicRoughness.BaseColorTexture.URI)
+			assert.Equal(t, []gltf.MaterialExtension{gltf.PolyformUnlit{}}, mat.Extensions)
+
+			require.Len(t, read.Lights, 1)
+			assert.Equal(t, gltf.KHR_LightsPunctualType_Point, read.Lights[0].Type)
+			assert.Equal(t, intensity, *read.Lights[0].Intensity)
+			assertVector3InDelta(t, vector3.New(4., 5., 6.), read.Lights[0].Position)
+		})
+	}
+}
+
+func TestRead_RotationAndScale(t *testing.T) {
+	tri := readTestTri()
+	rotation := quaternion.FromTheta(0.5, vector3.Up[float64]())
+	scale := vector3.New(2., 3., 4.)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, gltf.WriteBinary(gltf.PolyformScene{
+		Models: []gltf.PolyformModel{
+			{Name: "tri", Mesh: &tri, Rotation: &rotation, Scale: &scale},
+		},
+	}, &buf))
+
+	read, err := gltf.Read(&buf, nil)
+	require.NoError(t, err)
+	require.Len(t, read.Models, 1)
+	require.NotNil(t, read.Models[0].Rotation)
+	require.NotNil(t, read.Models[0].Scale)
+	assertVector3InDelta(t, scale, *read.Models[0].Scale)
+
+	v := vector3.New(1., 2., 3.)
+	assertVector3InDelta(t, rotation.Rotate(v), read.Models[0].Rotation.Rotate(v))
+}
+
+func TestRead_Skeleton(t *testing.T) {
+	skeleton := animation.NewSkeleton(animation.NewJoint(
+		"Hips", 1, vector3.New(0., 1., 0.), vector3.Up[float64](), vector3.Forward[float64](),
+		animation.NewJoint("Spine", 1, vector3.New(0., 2., 0.), vector3.Up[float64](), vector3.Forward[float64]()),
+	))
+
+	tri := readTestTri().
+		SetFloat4Attribute(modeling.JointAttribute, []vector4.Float64{
+			vector4.New(0., 0., 0., 0.),
+			vector4.New(1., 0., 0., 0.),
+			vector4.New(1., 0., 0., 0.),
+		}).
+		SetFloat4Attribute(modeling.WeightAttribute, []vector4.Float64{
+			vector4.New(1., 0., 0., 0.),
+			vector4.New(1., 0., 0., 0.),
+			vector4.New(1., 0., 0., 0.),
+		})
+
+	buf := bytes.Buffer{}
+	require.NoError(t, gltf.WriteBinary(gltf.PolyformScene{
+		Models: []gltf.PolyformModel{
+			{
+				Name:     "skinned",
+				Mesh:     &tri,
+				Skeleton: &skeleton,
+				Animations: []animation.Sequence{
+					animation.NewSequence("Hips/Spine", []animation.Frame[vector3.Float64]{
+						animation.NewFrame(0, vector3.New(0., 1., 0.)),
+						animation.NewFrame(1, vector3.New(0., 2., 0.)),
+					}),
+				},
+			},
+		},
+	}, &buf))
+
+	read, err := gltf.Read(&buf, nil)
+	require.NoError(t, err)
+	require.Len(t, read.Models, 1)
+
+	readSkeleton := read.Models[0].Skeleton
+	require.NotNil(t, readSkeleton)
+	assert.Equal(t, 2, readSkeleton.JointCount())
+	spine := readSkeleton.Lookup("Hips/Spine")
+	assertVector3InDelta(t, vector3.New(0., 2., 0.), readSkeleton.WorldPosition(spine))
+
+	joints := read.Models[0].Mesh.Float4Attribute(modeling.JointAttribute)
+	assert.Equal(t, float64(readSkeleton.Lookup("Hips")), joints.At(0).X())
+	assert.Equal(t, float64(spine), joints.At(1).X())
+
+	require.Len(t, read.Models[0].Animations, 1)
+	assert.Equal(t, "Hips/Spine", read.Models[0].Animations[0].Joint())
+	frames := read.Models[0].Animations[0].Frames()
+	require.Len(t, frames, 2)
+	assert.Equal(t, 1., frames[1].Time())
+}
+
+func TestRead_Animations(t *testing.T) {
+	tri := readTestTri()
+	up := vector3.New(0., 1., 0.)
+	half := quaternion.FromTheta(math.Pi/2, up)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, gltf.WriteBinary(gltf.PolyformScene{
+		Models: []gltf.PolyformModel{
+			{
+				Name: "animated",
+				Mesh: &tri,
+				Animations: []animation.Sequence{
+					animation.NewRotationSequence("", animation.CubicSplineInterpolation, []animation.Frame[quaternion.Quaternion]{
+						animation.NewCubicSplineFrame(0, quaternion.Zero(), quaternion.Identity(), quaternion.Zero()),
+						animation.NewCubicSplineFrame(1, quaternion.Zero(), half, quaternion.Zero()),
+					}),
+					animation.NewScaleSequence("", animation.StepInterpolation, []animation.Frame[vector3.Float64]{
+						animation.NewFrame(0, vector3.New(1., 1., 1.)),
+						animation.NewFrame(2, vector3.New(2., 2., 2.)),
+					}),
+				},
+			},
+		},
+	}, &buf))
+
+	read, err := gltf.Read(&buf, nil)
+	require.NoError(t, err)
+	require.Len(t, read.Models, 1)
+	require.Len(t, read.Models[0].Animations, 2)
+
+	rotation := read.Models[0].Animations[0]
+	assert.Equal(t, "", rotation.Joint())
+	assert.Equal(t, animation.RotationProperty, rotation.Property())
+	assert.Equal(t, animation.CubicSplineInterpolation, rotation.Interpolation())
+	require.Len(t, rotation.RotationFrames(), 2)
+	assert.InDelta(t, half.W(), rotation.RotationFrames()[1].Val().W(), 0.0001)
+	assert.InDelta(t, half.Dir().Y(), rotation.Rotation(1).Dir().Y(), 0.0001)
+
+	scale := read.Models[0].Animations[1]
+	assert.Equal(t, animation.ScaleProperty, scale.Property())
+	assert.Equal(t, animation.StepInterpolation, scale.Interpolation())
+	assertVector3InDelta(t, vector3.New(1., 1., 1.), scale.Vector3(1.9))
+	assertVector3InDelta(t, vector3.New(2., 2., 2.), scale.Vector3(2))
+}
+
+func TestRead_ExternalBuffer(t *testing.T) {
+	doc := `{
+		"asset": {"version": "2.0"},
+		"buffers": [{"byteLength": 24, "uri": "tri.bin"}],
+		"bufferViews": [{"buffer": 0, "byteLength": 24}],
+		"accessors": [{"bufferView": 0, "componentType": 5126, "type": "VEC2", "count": 3}],
+		"meshes": [{"primitives": [{"attributes": {"TEXCOORD_0": 0, "POSITION": 1}, "mode": 6}]}],
+		"nodes": [{"mesh": 0}]
+	}`
+
+	// POSITION references an accessor that doesn't exist
+	_, err := gltf.Read(bytes.NewBufferString(doc), &gltf.ReaderOptions{BasePath: t.TempDir()})
+	assert.Error(t, err)
+
+	dir := t.TempDir()
+	buf := bytes.Buffer{}
+	tri := readTestTri()
+	require.NoError(t, gltf.WriteText(gltf.PolyformScene{
+		Models: []gltf.PolyformModel{{Name: "tri", Mesh: &tri}},
+	}, &buf))
+	gltfPath := filepath.Join(dir, "tri.gltf")
+	require.NoError(t, os.WriteFile(gltfPath, buf.Bytes(), 0644))
+
+	scene, err := gltf.Load(gltfPath)
+	require.NoError(t, err)
+	require.Len(t, scene.Models, 1)
+	assert.Equal(t, 3, scene.Models[0].Mesh.AttributeLength())
+}
+
+func TestRead_TriangleFan(t *testing.T) {
+	// Four float32 VEC3 positions, packed little endian and base64 encoded
+	doc := `{
+		"asset": {"version": "2.0"},
+		"buffers": [{"byteLength": 48, "uri": "data:application/octet-stream;base64,AAAAAAAAAAAAAAAAAACAPwAAAAAAAAAAAACAPwAAgD8AAAAAAAAAAAAAgD8AAAAA"}],
+		"bufferViews": [{"buffer": 0, "byteLength": 48}],
+		"accessors": [{"bufferView": 0, "componentType": 5126, "type": "VEC3", "count": 4}],
+		"meshes": [{"name": "quad", "primitives": [{"attributes": {"POSITION": 0}, "mode": 6}]}],
+		"nodes": [{"mesh": 0}]
+	}`
+
+	scene, err := gltf.Read(bytes.NewBufferString(doc), nil)
+	require.NoError(t, err)
+	require.Len(t, scene.Models, 1)
+	assert.Equal(t, "quad", scene.Models[0].Name)
+
+	mesh := scene.Models[0].Mesh
+	assert.Equal(t, 2, mesh.PrimitiveCount())
+	indices := mesh.Indices()
+	actual := make([]int, indices.Len())
+	for i := range actual {
+		actual[i] = indices.At(i)
+	}
+	assert.Equal(t, []int{0, 1, 2, 0, 2, 3}, actual)
+	assertVector3InDelta(t, vector3.New(1., 1., 0.), mesh.Float3Attribute(modeling.PositionAttribute).At(2))
+}
+
+func TestRead_SparseAccessor(t *testing.T) {
+	// Three positions initialized to zero, with the last replaced by a sparse
+	// value of (1, 2, 3). The float32 values are followed by a byte index.
+	doc := `{
+		"asset": {"version": "2.0"},
+		"buffers": [{"byteLength": 16, "uri": "data:application/octet-stream;base64,AACAPwAAAEAAAEBAAgAAAA=="}],
+		"bufferViews": [
+			{"buffer": 0, "byteLength": 12},
+			{"buffer": 0, "byteOffset": 12, "byteLength": 1}
+		],
+		"accessors": [{
+			"componentType": 5126,
+			"type": "VEC3",
+			"count": 3,
+			"sparse": {
+				"count": 1,
+				"indices": {"bufferView": 1, "componentType": 5121},
+				"values": {"bufferView": 0}
+			}
+		}],
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}}]}],
+		"nodes": [{"mesh": 0}]
+	}`
+
+	scene, err := gltf.Read(bytes.NewBufferString(doc), nil)
+	require.NoError(t, err)
+	require.Len(t, scene.Models, 1)
+
+	positions := scene.Models[0].Mesh.Float3Attribute(modeling.PositionAttribute)
+	require.Equal(t, 3, positions.Len())
+	assertVector3InDelta(t, vector3.Zero[float64](), positions.At(0))
+	assertVector3InDelta(t, vector3.Zero[float64](), positions.At(1))
+	assertVector3InDelta(t, vector3.New(1., 2., 3.), positions.At(2))
+}
+
+func TestRead_SparseAccessorIndexOutOfRange(t *testing.T) {
+	doc := `{
+		"asset": {"version": "2.0"},
+		"buffers": [{"byteLength": 16, "uri": "data:application/octet-stream;base64,AACAPwAAAEAAAEBAAgAAAA=="}],
+		"bufferViews": [
+			{"buffer": 0, "byteLength": 12},
+			{"buffer": 0, "byteOffset": 12, "byteLength": 1}
+		],
+		"accessors": [{
+			"componentType": 5126,
+			"type": "VEC3",
+			"count": 2,
+			"sparse": {
+				"count": 1,
+				"indices": {"bufferView": 1, "componentType": 5121},
+				"values": {"bufferView": 0}
+			}
+		}],
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "mode": 0}]}],
+		"nodes": [{"mesh": 0}]
+	}`
+
+	_, err := gltf.Read(bytes.NewBufferString(doc), nil)
+	assert.ErrorContains(t, err, "accessor 0: sparse index 2 is out of range of the accessor's 2 elements")
+}
+
+func TestRead_MissingAccessor(t *testing.T) {
+	doc := `{
+		"asset": {"version": "2.0"},
+		"meshes": [{"primitives": [{"attributes": {"POSITION": 3}}]}],
+		"nodes": [{"mesh": 0}]
+	}`
+
+	_, err := gltf.Read(bytes.NewBufferString(doc), nil)
+	assert.ErrorContains(t, err, "attribute POSITION: accessor 3 does not exist")
+}
+
+func TestRead_InvalidGLB(t *testing.T) {
+	data := []byte{0x67, 0x6C, 0x54, 0x46, 2, 0, 0, 0}
+	_, err := gltf.Read(bytes.NewReader(data), nil)
+	assert.ErrorIs(t, err, gltf.ErrInvalidGLB)
+}
+
+func TestReadNode(t *testing.T) {
+	a := readTestTri()
+	translation := vector3.New(10., 0., 0.)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, gltf.WriteBinary(gltf.PolyformScene{
+		Models: []gltf.PolyformModel{
+			{Name: "a", Mesh: &a},
+			{Name: "b", Mesh: &a, Translation: &translation},
+		},
+	}, &buf))
+
+	node := &gltf.ReadNode{
+		Data: gltf.ReadNodeData{
+			Data: nodes.Value(buf.Bytes()),
+		},
+	}
+
+	mesh := node.Out().Value()
+	assert.Equal(t, 2, mesh.PrimitiveCount())
+	assert.Equal(t, 6, mesh.AttributeLength())
+	assertVector3InDelta(t, vector3.New(11., 0., 0.), mesh.Float3Attribute(modeling.PositionAttribute).At(5))
+}
+
+func TestReadNode_MixedTopology(t *testing.T) {
+	tri := readTestTri()
+	points := modeling.NewPointCloud(nil, map[string][]vector3.Float64{
+		modeling.PositionAttribute: {vector3.Zero[float64]()},
+	}, nil, nil, nil)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, gltf.WriteBinary(gltf.PolyformScene{
+		Models: []gltf.PolyformModel{
+			{Name: "tri", Mesh: &tri},
+			{Name: "points", Mesh: &points},
+		},
+	}, &buf))
+
+	node := &gltf.ReadNode{
+		Data: gltf.ReadNodeData{
+			Data: nodes.Value(buf.Bytes()),
+		},
+	}
+
+	_, err := node.Data.Process()
+	assert.EqualError(t, err, `model "points" has point topology, which can not be combined with the triangle topology of the models before it`)
+}
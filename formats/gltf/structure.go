@@ -140,8 +140,6 @@ type Skin struct {
 	Joints              []GltfId `json:"joints"`                        // Indices of skeleton nodes, used as joints in this skin.
 }
 
-type Camera struct{}
-
 // The root object for a glTF asset.
 // https://github.com/KhronosGroup/glTF/blob/main/specification/2.0/schema/glTF.schema.json
 type Gltf struct {
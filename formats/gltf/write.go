@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/EliCDavis/polyform/math/mat"
 	"github.com/EliCDavis/polyform/modeling"
 	"github.com/EliCDavis/polyform/modeling/animation"
+	"github.com/EliCDavis/polyform/modeling/meshops"
 )
 
 func defaultAsset() Asset {
@@ -48,10 +50,38 @@ func polyformToGLTFAttribute(key string) string {
 
 	case modeling.NormalAttribute:
 		return NORMAL
+
+	case modeling.TangentAttribute:
+		return TANGENT
 	}
 	return key
 }
 
+// requiresTangents determines whether or not the model uses a normal map but
+// is missing the tangents required to interpret it
+func requiresTangents(model PolyformModel) bool {
+	if model.Material == nil || model.Material.NormalTexture == nil {
+		return false
+	}
+
+	m := model.Mesh
+	return m.Topology() == modeling.TriangleTopology &&
+		!m.HasFloat4Attribute(modeling.TangentAttribute) &&
+		m.HasFloat3Attribute(modeling.PositionAttribute) &&
+		m.HasFloat3Attribute(modeling.NormalAttribute) &&
+		m.HasFloat2Attribute(modeling.TexCoordAttribute)
+}
+
+func (w *Writer) tangentMesh(m *modeling.Mesh) *modeling.Mesh {
+	if withTangents, ok := w.tangentMeshes[m]; ok {
+		return withTangents
+	}
+
+	withTangents := meshops.GenerateTangents(*m)
+	w.tangentMeshes[m] = &withTangents
+	return &withTangents
+}
+
 func isVec4Atr(key string) bool {
 	return key == modeling.JointAttribute || key == modeling.WeightAttribute
 }
@@ -68,8 +98,8 @@ func flattenSkeletonToNodes(offset int, skeleton animation.Skeleton, out *bytes.
 	nodes := make([]Node, 0)
 
 	for i := 0; i < skeleton.JointCount(); i++ {
-		children := skeleton.Children(i)
-		for i, c := range children {
+		children := make([]int, len(skeleton.Children(i)))
+		for i, c := range skeleton.Children(i) {
 			children[i] = c + offset
 		}
 
@@ -77,7 +107,9 @@ func flattenSkeletonToNodes(offset int, skeleton animation.Skeleton, out *bytes.
 
 		pos := skeleton.RelativePosition(i)
 
+		jointPath := skeleton.Path(i)
 		node := Node{
+			Name:        jointPath[strings.LastIndex(jointPath, "/")+1:],
 			Translation: &[3]float64{pos.X(), pos.Y(), pos.Z()},
 			// Matrix: &[16]float64{
 			// 	relativeMatrix.X00,
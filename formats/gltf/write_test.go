@@ -2,6 +2,7 @@ package gltf_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"image/color"
 	"math"
@@ -10,9 +11,11 @@ import (
 	"github.com/EliCDavis/polyform/formats/gltf"
 	"github.com/EliCDavis/polyform/math/quaternion"
 	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/animation"
 	"github.com/EliCDavis/vector/vector2"
 	"github.com/EliCDavis/vector/vector3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type mockTextureExtension struct{}
@@ -1298,6 +1301,24 @@ func TestWrite_TexturedTriWithMaterialWithColor_NormalOcclusion(t *testing.T) {
         {
             "bufferView": 0,
             "componentType": 5126,
+            "type": "VEC4",
+            "count": 3,
+            "max": [
+                1,
+                0,
+                1,
+                -1
+            ],
+            "min": [
+                0,
+                0,
+                -1,
+                -1
+            ]
+        },
+        {
+            "bufferView": 1,
+            "componentType": 5126,
             "type": "VEC3",
             "count": 3,
             "max": [
@@ -1312,7 +1333,7 @@ func TestWrite_TexturedTriWithMaterialWithColor_NormalOcclusion(t *testing.T) {
             ]
         },
         {
-            "bufferView": 1,
+            "bufferView": 2,
             "componentType": 5126,
             "type": "VEC3",
             "count": 3,
@@ -1328,7 +1349,7 @@ func TestWrite_TexturedTriWithMaterialWithColor_NormalOcclusion(t *testing.T) {
             ]
         },
         {
-            "bufferView": 2,
+            "bufferView": 3,
             "componentType": 5126,
             "type": "VEC2",
             "count": 3,
@@ -1342,7 +1363,7 @@ func TestWrite_TexturedTriWithMaterialWithColor_NormalOcclusion(t *testing.T) {
             ]
         },
         {
-            "bufferView": 3,
+            "bufferView": 4,
             "componentType": 5123,
             "type": "SCALAR",
             "count": 3
@@ -1354,31 +1375,37 @@ func TestWrite_TexturedTriWithMaterialWithColor_NormalOcclusion(t *testing.T) {
     },
     "buffers": [
         {
-            "byteLength": 102,
-            "uri": "data:application/octet-stream;base64,AACAPwAAAAAAAAAAAAAAAAAAgD8AAAAAAAAAAAAAAAAAAIA/AAAAAAAAAAAAAAAAAAAAAAAAgD8AAAAAAACAPwAAAAAAAAAAAAAAAAAAAAAAAAAAAACAPwAAgD8AAAAAAAABAAIA"
+            "byteLength": 150,
+            "uri": "data:application/octet-stream;base64,AAAAAAAAAAAAAIA/AACAvwAAAAAAAAAAAACAvwAAgL8AAIA/AAAAAAAAAAAAAIC/AACAPwAAAAAAAAAAAAAAAAAAgD8AAAAAAAAAAAAAAAAAAIA/AAAAAAAAAAAAAAAAAAAAAAAAgD8AAAAAAACAPwAAAAAAAAAAAAAAAAAAAAAAAAAAAACAPwAAgD8AAAAAAAABAAIA"
         }
     ],
     "bufferViews": [
         {
             "buffer": 0,
+            "byteLength": 48,
+            "target": 34962
+        },
+        {
+            "buffer": 0,
+            "byteOffset": 48,
             "byteLength": 36,
             "target": 34962
         },
         {
             "buffer": 0,
-            "byteOffset": 36,
+            "byteOffset": 84,
             "byteLength": 36,
             "target": 34962
         },
         {
             "buffer": 0,
-            "byteOffset": 72,
+            "byteOffset": 120,
             "byteLength": 24,
             "target": 34962
         },
         {
             "buffer": 0,
-            "byteOffset": 96,
+            "byteOffset": 144,
             "byteLength": 6,
             "target": 34963
         }
@@ -1425,11 +1452,12 @@ func TestWrite_TexturedTriWithMaterialWithColor_NormalOcclusion(t *testing.T) {
             "primitives": [
                 {
                     "attributes": {
-                        "NORMAL": 0,
-                        "POSITION": 1,
-                        "TEXCOORD_0": 2
+                        "NORMAL": 1,
+                        "POSITION": 2,
+                        "TANGENT": 0,
+                        "TEXCOORD_0": 3
                     },
-                    "indices": 3,
+                    "indices": 4,
                     "material": 0
                 }
             ]
@@ -2831,3 +2859,274 @@ func TestWriteEmptyMesh(t *testing.T) {
     ]
 }`, buf.String())
 }
+
+func TestWrite_NodeAnimationsAndMorphTargets(t *testing.T) {
+	// ARRANGE ================================================================
+	tri := modeling.NewTriangleMesh([]int{0, 1, 2}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(0., 1., 0.),
+			vector3.New(1., 0., 0.),
+		}).
+		SetFloat3Attribute("Smile", []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(0., 1., 0.),
+			vector3.New(0., 0., 0.),
+		})
+	buf := bytes.Buffer{}
+
+	// ACT ====================================================================
+	err := gltf.WriteText(gltf.PolyformScene{
+		Models: []gltf.PolyformModel{
+			{
+				Name: "mesh",
+				Mesh: &tri,
+				MorphTargets: []gltf.PolyformMorphTarget{
+					{Name: "Smile", Position: "Smile", Weight: 0.5},
+				},
+				Animations: []animation.Sequence{
+					animation.NewRotationSequence("", animation.StepInterpolation, []animation.Frame[quaternion.Quaternion]{
+						animation.NewFrame(0, quaternion.Identity()),
+						animation.NewFrame(1, quaternion.FromTheta(math.Pi, vector3.New(0., 1., 0.))),
+					}),
+					animation.NewWeightsSequence(animation.CubicSplineInterpolation, []animation.Frame[[]float64]{
+						animation.NewFrame(0, []float64{0}),
+						animation.NewFrame(1, []float64{1}),
+					}),
+					// Same target as the first sequence, requiring a second
+					// animation
+					animation.NewRotationSequence("", animation.LinearInterpolation, []animation.Frame[quaternion.Quaternion]{
+						animation.NewFrame(0, quaternion.Identity()),
+					}),
+				},
+			},
+		},
+	}, &buf)
+
+	// ASSERT =================================================================
+	require.NoError(t, err)
+
+	doc := gltf.Gltf{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	require.Len(t, doc.Meshes, 1)
+	primitive := doc.Meshes[0].Primitives[0]
+	assert.Len(t, primitive.Attributes, 1)
+	require.Len(t, primitive.Targets, 1)
+	assert.Contains(t, primitive.Targets[0], gltf.POSITION)
+	assert.Equal(t, []float64{0.5}, doc.Meshes[0].Weights)
+	assert.Equal(t, []any{"Smile"}, doc.Meshes[0].Extras["targetNames"])
+
+	require.Len(t, doc.Animations, 2)
+	assert.Equal(t, "mesh", doc.Animations[0].Name)
+	assert.Equal(t, "mesh_1", doc.Animations[1].Name)
+
+	first := doc.Animations[0]
+	require.Len(t, first.Channels, 2)
+	require.Len(t, first.Samplers, 2)
+	for i, channel := range first.Channels {
+		assert.Equal(t, i, channel.Sampler)
+		require.NotNil(t, channel.Target.Node)
+		assert.Equal(t, 0, *channel.Target.Node)
+	}
+	assert.Equal(t, gltf.AnimationChannelTargetPath_ROTATION, first.Channels[0].Target.Path)
+	assert.Equal(t, gltf.AnimationSamplerInterpolation_STEP, first.Samplers[0].Interpolation)
+	assert.Equal(t, gltf.AnimationChannelTargetPath_WEIGHTS, first.Channels[1].Target.Path)
+	assert.Equal(t, gltf.AnimationSamplerInterpolation_CUBICSPLINE, first.Samplers[1].Interpolation)
+
+	// In-tangent, value, and out-tangent for each of the 2 keyframes
+	assert.Equal(t, 6, doc.Accessors[first.Samplers[1].Output].Count)
+	assert.Equal(t, gltf.AccessorType_SCALAR, doc.Accessors[first.Samplers[1].Output].Type)
+	assert.Equal(t, gltf.AccessorType_VEC4, doc.Accessors[first.Samplers[0].Output].Type)
+	assert.Equal(t, 2, doc.Accessors[first.Samplers[0].Input].Count)
+}
+
+func TestWrite_AnimationErrors(t *testing.T) {
+	tri := modeling.NewTriangleMesh([]int{0, 1, 2}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(0., 1., 0.),
+			vector3.New(1., 0., 0.),
+		})
+
+	frames := []animation.Frame[vector3.Float64]{
+		animation.NewFrame(0, vector3.New(0., 0., 0.)),
+	}
+
+	tests := map[string]gltf.PolyformModel{
+		"joint without skeleton": {
+			Animations: []animation.Sequence{animation.NewSequence("Hips", frames)},
+		},
+		"weights without morph targets": {
+			Animations: []animation.Sequence{
+				animation.NewWeightsSequence(animation.LinearInterpolation, []animation.Frame[[]float64]{
+					animation.NewFrame(0, []float64{1}),
+				}),
+			},
+		},
+		"invalid sequence": {
+			Animations: []animation.Sequence{animation.NewSequence("", nil)},
+		},
+		"missing morph target attribute": {
+			MorphTargets: []gltf.PolyformMorphTarget{{Position: "Missing"}},
+		},
+	}
+
+	for name, model := range tests {
+		t.Run(name, func(t *testing.T) {
+			model.Name = "mesh"
+			model.Mesh = &tri
+			err := gltf.WriteText(gltf.PolyformScene{Models: []gltf.PolyformModel{model}}, &bytes.Buffer{})
+			assert.ErrorIs(t, err, gltf.ErrInvalidInput)
+		})
+	}
+}
+
+func TestWrite_SceneGraph(t *testing.T) {
+	// ARRANGE ================================================================
+	tri := modeling.NewTriangleMesh([]int{0, 1, 2}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(0., 1., 0.),
+			vector3.New(1., 0., 0.),
+		})
+	left := vector3.New(-1., 0., 0.)
+	right := vector3.New(1., 0., 0.)
+	yfov := 0.8
+	intensity := 2.
+	buf := bytes.Buffer{}
+
+	// ACT ====================================================================
+	err := gltf.WriteText(gltf.PolyformScene{
+		Nodes: []gltf.PolyformNode{
+			{
+				Name:   "root",
+				Extras: map[string]any{"id": "abc"},
+				Children: []gltf.PolyformNode{
+					{
+						Name:        "left",
+						Translation: &left,
+						Models:      []gltf.PolyformModel{{Name: "tri", Mesh: &tri}},
+					},
+					{
+						Name:        "right",
+						Translation: &right,
+						Models:      []gltf.PolyformModel{{Name: "tri", Mesh: &tri}},
+						Animations: []animation.Sequence{
+							animation.NewSequence("", []animation.Frame[vector3.Float64]{
+								animation.NewFrame(0, right),
+								animation.NewFrame(1, left),
+							}),
+						},
+					},
+					{
+						Name:  "lamp",
+						Light: &gltf.KHR_LightsPunctual{Intensity: &intensity},
+					},
+					{
+						Name: "camera",
+						Camera: &gltf.Camera{
+							Type:        gltf.CameraType_PERSPECTIVE,
+							Perspective: &gltf.CameraPerspective{Yfov: yfov, Znear: 0.1},
+						},
+					},
+				},
+			},
+		},
+	}, &buf)
+
+	// ASSERT =================================================================
+	require.NoError(t, err)
+
+	doc := gltf.Gltf{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	require.Len(t, doc.Scenes, 1)
+	require.Equal(t, []int{0}, doc.Scenes[0].Nodes)
+
+	names := make([]string, len(doc.Nodes))
+	for i, n := range doc.Nodes {
+		names[i] = n.Name
+	}
+	assert.Equal(t, []string{"root", "left", "tri", "right", "tri", "lamp", "camera"}, names)
+
+	root := doc.Nodes[0]
+	assert.Equal(t, []int{1, 3, 5, 6}, root.Children)
+	assert.Equal(t, "abc", root.Extras["id"])
+
+	assert.Equal(t, []int{2}, doc.Nodes[1].Children)
+	assert.Equal(t, &[3]float64{-1, 0, 0}, doc.Nodes[1].Translation)
+	assert.Equal(t, []int{4}, doc.Nodes[3].Children)
+
+	// The same mesh is instanced by both models
+	require.Len(t, doc.Meshes, 1)
+	assert.Equal(t, 0, *doc.Nodes[2].Mesh)
+	assert.Equal(t, 0, *doc.Nodes[4].Mesh)
+
+	assert.Contains(t, doc.Nodes[5].Extensions, "KHR_lights_punctual")
+	assert.Contains(t, doc.ExtensionsUsed, "KHR_lights_punctual")
+
+	require.Len(t, doc.Cameras, 1)
+	require.NotNil(t, doc.Nodes[6].Camera)
+	assert.Equal(t, 0, *doc.Nodes[6].Camera)
+	assert.Equal(t, yfov, doc.Cameras[0].Perspective.Yfov)
+
+	require.Len(t, doc.Animations, 1)
+	assert.Equal(t, "right", doc.Animations[0].Name)
+	assert.Equal(t, 3, *doc.Animations[0].Channels[0].Target.Node)
+}
+
+func TestWrite_SceneGraphInvalidCamera(t *testing.T) {
+	err := gltf.WriteText(gltf.PolyformScene{
+		Nodes: []gltf.PolyformNode{
+			{
+				Name: "camera",
+				Camera: &gltf.Camera{
+					Type:         gltf.CameraType_ORTHOGRAPHIC,
+					Orthographic: &gltf.CameraOrthographic{Xmag: 1, Ymag: 1, Znear: 1, Zfar: 0.5},
+				},
+			},
+		},
+	}, &bytes.Buffer{})
+	assert.ErrorIs(t, err, gltf.ErrInvalidInput)
+}
+
+func TestWrite_MultipleSkins(t *testing.T) {
+	// ARRANGE ================================================================
+	skeleton := animation.NewSkeleton(animation.NewJoint(
+		"Hips", 1, vector3.New(0., 1., 0.), vector3.Up[float64](), vector3.Forward[float64](),
+		animation.NewJoint("Spine", 1, vector3.New(0., 2., 0.), vector3.Up[float64](), vector3.Forward[float64]()),
+	))
+
+	tri := modeling.NewTriangleMesh([]int{0, 1, 2}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(0., 1., 0.),
+			vector3.New(1., 0., 0.),
+		})
+	buf := bytes.Buffer{}
+
+	// ACT ====================================================================
+	err := gltf.WriteText(gltf.PolyformScene{
+		Models: []gltf.PolyformModel{
+			{Name: "a", Mesh: &tri, Skeleton: &skeleton},
+			{Name: "b", Mesh: &tri, Skeleton: &skeleton},
+		},
+	}, &buf)
+
+	// ASSERT =================================================================
+	require.NoError(t, err)
+
+	doc := gltf.Gltf{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+
+	require.Len(t, doc.Skins, 2)
+	assert.Equal(t, []int{1, 2}, doc.Skins[0].Joints)
+	assert.Equal(t, []int{4, 5}, doc.Skins[1].Joints)
+	assert.Equal(t, []int{2}, doc.Nodes[1].Children)
+	assert.Equal(t, []int{5}, doc.Nodes[4].Children)
+	assert.Equal(t, 1, *doc.Nodes[3].Skin)
+
+	// Writing doesn't modify the skeleton
+	assert.Equal(t, []int{1}, skeleton.Children(0))
+}
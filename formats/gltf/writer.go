@@ -37,9 +37,11 @@ type Writer struct {
 	meshIndices     meshIndices      // Tracks and deduplicates unique meshes&materials
 	writtenMeshData attributeIndices // Tracks and deduplicate written mesh data
 	textureIndices  textureIndices   // Tracks and deduplicates unique textures
+	tangentMeshes   tangentMeshes    // Meshes with tangents generated for normal mapping
 
 	skins      []Skin
 	animations []Animation
+	cameras    []Camera
 
 	textures     []Texture
 	images       []Image
@@ -70,6 +72,7 @@ func NewWriter() *Writer {
 		meshIndices:     make(meshIndices),
 		writtenMeshData: make(attributeIndices),
 		textureIndices:  make(textureIndices),
+		tangentMeshes:   make(tangentMeshes),
 
 		// Extensions
 		lights: make([]KHR_LightsPunctual, 0),
@@ -340,85 +343,194 @@ func rgbToFloatArr(c color.Color) [3]float64 {
 
 func (w *Writer) AddScene(scene PolyformScene) error {
 	for _, model := range scene.Models {
-		meshIndex, err := w.AddMesh(model)
-		if err != nil {
-			return fmt.Errorf("failed to add model %q: %w", model.Name, err)
-		} else if meshIndex == -1 {
-			continue // mesh was not added to scene, ignore and continue
+		if err := w.addModel(model, nil); err != nil {
+			return err
 		}
+	}
+
+	// Add lights
+	for _, light := range scene.Lights {
+		w.AddLight(light)
+	}
 
-		// Create node with transforms for this model
-		nodeIndex := len(w.nodes)
-		newNode := Node{
-			Mesh: &meshIndex,
-			Name: model.Name,
+	for _, node := range scene.Nodes {
+		if err := w.AddNode(node); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// attach adds the node as a child of the parent, or as a root of the scene
+// when there is no parent
+func (w *Writer) attach(node int, parent *int) {
+	if parent == nil {
+		w.scene = append(w.scene, node)
+		return
+	}
+	w.nodes[*parent].Children = append(w.nodes[*parent].Children, node)
+}
+
+// AddNode writes the node, along with everything attached to it and all of
+// it's children, as a root of the scene
+func (w *Writer) AddNode(node PolyformNode) error {
+	return w.addNode(node, nil)
+}
+
+func (w *Writer) addNode(node PolyformNode, parent *int) error {
+	nodeIndex := len(w.nodes)
+	newNode := Node{
+		ChildOfRootProperty: ChildOfRootProperty{
+			Property: Property{Extras: node.Extras},
+		},
+		Name: node.Name,
+	}
+
+	if node.Translation != nil {
+		arr := node.Translation.ToFixedArr()
+		newNode.Translation = &arr
+	}
 
-		if model.Translation != nil {
-			arr := model.Translation.ToFixedArr()
-			newNode.Translation = &arr
+	if node.Rotation != nil {
+		arr := node.Rotation.ToArr()
+		newNode.Rotation = &arr
+	}
+
+	if node.Scale != nil {
+		arr := node.Scale.ToFixedArr()
+		newNode.Scale = &arr
+	}
+
+	if node.Camera != nil {
+		if err := node.Camera.validate(); err != nil {
+			return fmt.Errorf("%w: node %q: %w", ErrInvalidInput, node.Name, err)
 		}
+		newNode.Camera = ptrI(len(w.cameras))
+		w.cameras = append(w.cameras, *node.Camera)
+	}
 
-		if model.Rotation != nil {
-			arr := model.Rotation.ToArr()
-			newNode.Rotation = &arr
+	if node.Light != nil {
+		newNode.Extensions = w.lightExtension(*node.Light)
+	}
+
+	w.nodes = append(w.nodes, newNode)
+	w.attach(nodeIndex, parent)
+
+	for _, model := range node.Models {
+		if err := w.addModel(model, &nodeIndex); err != nil {
+			return fmt.Errorf("node %q: %w", node.Name, err)
 		}
+	}
 
-		if model.Scale != nil {
-			arr := model.Scale.ToFixedArr()
-			newNode.Scale = &arr
+	for _, child := range node.Children {
+		if err := w.addNode(child, &nodeIndex); err != nil {
+			return err
 		}
+	}
 
-		if len(model.GpuInstances) > 0 {
-			if newNode.Extensions == nil {
-				newNode.Extensions = make(map[string]any)
+	if len(node.Animations) > 0 {
+		err := w.writeAnimations(node.Name, node.Animations, func(joint string) (int, error) {
+			if joint != "" {
+				return -1, fmt.Errorf("sequence animates joint %q of a node without a skeleton", joint)
 			}
-			w.extensionsUsed[extGpuInstancingID] = true
+			return nodeIndex, nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add animations of node %q: %w", node.Name, err)
+		}
+	}
 
-			instances := ExtGpuInstancing{
-				Attributes: make(map[string]int),
-			}
+	return nil
+}
 
-			positions := make([]vector3.Float64, len(model.GpuInstances))
-			rotations := make([]vector4.Float64, len(model.GpuInstances))
-			scales := make([]vector3.Float64, len(model.GpuInstances))
-			for i, t := range model.GpuInstances {
-				positions[i] = t.Position()
-				rotations[i] = t.Rotation().Vector4()
-				scales[i] = t.Scale()
-			}
+// addModel writes the model as a new node, attached to the parent provided
+// or the root of the scene when there is no parent
+func (w *Writer) addModel(model PolyformModel, parent *int) error {
+	meshIndex, err := w.AddMesh(model)
+	if err != nil {
+		return fmt.Errorf("failed to add model %q: %w", model.Name, err)
+	} else if meshIndex == -1 {
+		return nil // mesh was not added to scene, ignore and continue
+	}
 
-			instances.Attributes["TRANSLATION"] = len(w.accessors)
-			w.WriteVector3(AccessorComponentType_FLOAT, iter.Array(positions))
+	// Create node with transforms for this model
+	nodeIndex := len(w.nodes)
+	newNode := Node{
+		Mesh: &meshIndex,
+		Name: model.Name,
+	}
 
-			instances.Attributes["SCALE"] = len(w.accessors)
-			w.WriteVector3(AccessorComponentType_FLOAT, iter.Array(scales))
+	if model.Translation != nil {
+		arr := model.Translation.ToFixedArr()
+		newNode.Translation = &arr
+	}
 
-			instances.Attributes["ROTATION"] = len(w.accessors)
-			w.WriteVector4(AccessorComponentType_FLOAT, iter.Array(rotations))
+	if model.Rotation != nil {
+		arr := model.Rotation.ToArr()
+		newNode.Rotation = &arr
+	}
 
-			newNode.Extensions[extGpuInstancingID] = instances
-		}
+	if model.Scale != nil {
+		arr := model.Scale.ToFixedArr()
+		newNode.Scale = &arr
+	}
 
-		w.nodes = append(w.nodes, newNode)
-		w.scene = append(w.scene, nodeIndex)
+	if len(model.GpuInstances) > 0 {
+		if newNode.Extensions == nil {
+			newNode.Extensions = make(map[string]any)
+		}
+		w.extensionsUsed[extGpuInstancingID] = true
 
-		skinNode := nodeIndex
-		// Handle any skeleton/animation data
-		if model.Skeleton != nil {
-			var skinIndex *int
-			skinIndex, skinNode = w.AddSkin(*model.Skeleton)
-			w.nodes[nodeIndex].Skin = skinIndex
+		instances := ExtGpuInstancing{
+			Attributes: make(map[string]int),
 		}
 
-		if len(model.Animations) > 0 {
-			w.AddAnimations(model.Animations, *model.Skeleton, skinNode)
+		positions := make([]vector3.Float64, len(model.GpuInstances))
+		rotations := make([]vector4.Float64, len(model.GpuInstances))
+		scales := make([]vector3.Float64, len(model.GpuInstances))
+		for i, t := range model.GpuInstances {
+			positions[i] = t.Position()
+			rotations[i] = t.Rotation().Vector4()
+			scales[i] = t.Scale()
 		}
+
+		instances.Attributes["TRANSLATION"] = len(w.accessors)
+		w.WriteVector3(AccessorComponentType_FLOAT, iter.Array(positions))
+
+		instances.Attributes["SCALE"] = len(w.accessors)
+		w.WriteVector3(AccessorComponentType_FLOAT, iter.Array(scales))
+
+		instances.Attributes["ROTATION"] = len(w.accessors)
+		w.WriteVector4(AccessorComponentType_FLOAT, iter.Array(rotations))
+
+		newNode.Extensions[extGpuInstancingID] = instances
 	}
 
-	// Add lights
-	for _, light := range scene.Lights {
-		w.AddLight(light)
+	w.nodes = append(w.nodes, newNode)
+	w.attach(nodeIndex, parent)
+
+	skinNode := nodeIndex
+	// Handle any skeleton/animation data
+	if model.Skeleton != nil {
+		var skinIndex *int
+		skinIndex, skinNode = w.AddSkin(*model.Skeleton)
+		w.nodes[nodeIndex].Skin = skinIndex
+	}
+
+	if len(model.Animations) > 0 {
+		err := w.writeAnimations(model.Name, model.Animations, func(joint string) (int, error) {
+			if joint == "" {
+				return nodeIndex, nil
+			}
+			if model.Skeleton == nil {
+				return -1, fmt.Errorf("sequence animates joint %q but the model has no skeleton", joint)
+			}
+			return skeletonJointNode(*model.Skeleton, skinNode, joint)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add animations of model %q: %w", model.Name, err)
+		}
 	}
 
 	return nil
@@ -434,6 +546,17 @@ func (w *Writer) AddMesh(model PolyformModel) (_ int, err error) {
 		return -1, nil // return -1 to signal that mesh was not added, but do not error out
 	}
 
+	// Normal maps are only interpreted correctly with the tangents they were
+	// baked against, so we generate them rather than leave it to the viewer
+	if requiresTangents(model) {
+		model.Mesh = w.tangentMesh(model.Mesh)
+	}
+
+	if err := validateMorphTargets(model); err != nil {
+		return -1, err
+	}
+	morphTargets := fmt.Sprint(model.MorphTargets)
+
 	var matIndex *int
 	if model.Material != nil {
 		matIndex, err = w.AddMaterial(model.Material)
@@ -443,7 +566,7 @@ func (w *Writer) AddMesh(model PolyformModel) (_ int, err error) {
 		}
 	}
 
-	uniqueMesh := meshEntry{model.Mesh, -1}
+	uniqueMesh := meshEntry{model.Mesh, -1, morphTargets}
 	if matIndex != nil {
 		uniqueMesh.materialIndex = *matIndex
 	}
@@ -460,14 +583,23 @@ func (w *Writer) AddMesh(model PolyformModel) (_ int, err error) {
 	// Create the mesh - process geometry, materials etc
 
 	var primitiveAttributes map[string]int
+	var primitiveTargets []map[string]int
 	var indicesIndex int
 
-	writtenData, alreadyWrittenMesh := w.writtenMeshData[model.Mesh]
+	writtenKey := writtenMeshKey{model.Mesh, morphTargets}
+	writtenData, alreadyWrittenMesh := w.writtenMeshData[writtenKey]
 
 	if alreadyWrittenMesh {
 		primitiveAttributes = writtenData.attribute
+		primitiveTargets = writtenData.targets
 		indicesIndex = *writtenData.indices
 	} else {
+		targetAttributes := make(map[string]bool)
+		for _, target := range model.MorphTargets {
+			targetAttributes[target.Position] = true
+			targetAttributes[target.Normal] = true
+		}
+
 		primitiveAttributes = make(map[string]int)
 		for _, val := range model.Mesh.Float4Attributes() {
 			primitiveAttributes[polyformToGLTFAttribute(val)] = len(w.accessors)
@@ -475,6 +607,9 @@ func (w *Writer) AddMesh(model PolyformModel) (_ int, err error) {
 		}
 
 		for _, val := range model.Mesh.Float3Attributes() {
+			if targetAttributes[val] {
+				continue
+			}
 			primitiveAttributes[polyformToGLTFAttribute(val)] = len(w.accessors)
 			w.WriteVector3(attributeType(val), model.Mesh.Float3Attribute(val))
 		}
@@ -484,11 +619,26 @@ func (w *Writer) AddMesh(model PolyformModel) (_ int, err error) {
 			w.WriteVector2(attributeType(val), model.Mesh.Float2Attribute(val))
 		}
 
+		for _, target := range model.MorphTargets {
+			targetAttributes := make(map[string]int)
+			if target.Position != "" {
+				targetAttributes[POSITION] = len(w.accessors)
+				w.WriteVector3(AccessorComponentType_FLOAT, model.Mesh.Float3Attribute(target.Position))
+			}
+
+			if target.Normal != "" {
+				targetAttributes[NORMAL] = len(w.accessors)
+				w.WriteVector3(AccessorComponentType_FLOAT, model.Mesh.Float3Attribute(target.Normal))
+			}
+			primitiveTargets = append(primitiveTargets, targetAttributes)
+		}
+
 		indicesIndex = len(w.accessors)
 		w.WriteIndices(model.Mesh.Indices(), model.Mesh.AttributeLength())
 
-		w.writtenMeshData[model.Mesh] = writtenMeshData{
+		w.writtenMeshData[writtenKey] = writtenMeshData{
 			attribute: primitiveAttributes,
+			targets:   primitiveTargets,
 			indices:   &indicesIndex,
 		}
 	}
@@ -499,21 +649,56 @@ func (w *Writer) AddMesh(model PolyformModel) (_ int, err error) {
 		mode = &p
 	}
 
-	w.meshes = append(w.meshes, Mesh{
+	mesh := Mesh{
 		ChildOfRootProperty: ChildOfRootProperty{Name: model.Name},
 		Primitives: []Primitive{
 			{
 				Indices:    &indicesIndex,
 				Attributes: primitiveAttributes,
 				Material:   matIndex,
+				Targets:    primitiveTargets,
 				Mode:       mode,
 			},
 		},
-	})
+	}
+
+	if len(model.MorphTargets) > 0 {
+		mesh.Weights = make([]float64, len(model.MorphTargets))
+		names := make([]string, len(model.MorphTargets))
+		named := false
+		for i, target := range model.MorphTargets {
+			mesh.Weights[i] = target.Weight
+			names[i] = target.Name
+			named = named || target.Name != ""
+		}
+
+		// targetNames isn't a part of the specification, but is the
+		// convention most tools follow for naming morph targets
+		if named {
+			mesh.Extras = Extra{"targetNames": names}
+		}
+	}
+
+	w.meshes = append(w.meshes, mesh)
 
 	return meshIndex, nil
 }
 
+func validateMorphTargets(model PolyformModel) error {
+	for i, target := range model.MorphTargets {
+		if target.Position == "" && target.Normal == "" {
+			return fmt.Errorf("%w: morph target %d of model %q has no position or normal attribute", ErrInvalidInput, i, model.Name)
+		}
+
+		for _, attr := range []string{target.Position, target.Normal} {
+			if attr != "" && !model.Mesh.HasFloat3Attribute(attr) {
+				return fmt.Errorf("%w: morph target %d of model %q references missing float3 attribute %q", ErrInvalidInput, i, model.Name, attr)
+			}
+		}
+	}
+	return nil
+}
+
 func (w *Writer) AddTexture(polyTex *PolyformTexture) *TextureInfo {
 	texExt, texInfoExt := polyTex.prepareExtensions(w)
 
@@ -681,13 +866,14 @@ func (w *Writer) AddMaterial(mat *PolyformMaterial) (*int, error) {
 }
 
 func (w *Writer) AddSkin(skeleton animation.Skeleton) (*int, int) {
-	skeletonNodes := flattenSkeletonToNodes(1, skeleton, w.buf)
-	w.scene = append(w.scene, len(w.nodes))
+	offset := len(w.nodes)
+	skeletonNodes := flattenSkeletonToNodes(offset, skeleton, w.buf)
+	w.scene = append(w.scene, offset)
 	w.nodes = append(w.nodes, skeletonNodes...)
 
 	jointIndices := make([]int, len(skeletonNodes))
 	for i := 0; i < len(skeletonNodes); i++ {
-		jointIndices[i] = i + 1 // +1 because we're offsetting from mesh node
+		jointIndices[i] = i + offset
 	}
 
 	w.accessors = append(w.accessors, Accessor{
@@ -709,115 +895,286 @@ func (w *Writer) AddSkin(skeleton animation.Skeleton) (*int, int) {
 	})
 	w.bytesWritten += inverseBindMAtrixLen
 
-	w.skins = []Skin{
-		{
-			Joints:              jointIndices,
-			InverseBindMatrices: len(w.accessors) - 1,
-		},
+	w.skins = append(w.skins, Skin{
+		Joints:              jointIndices,
+		InverseBindMatrices: len(w.accessors) - 1,
+	})
+	return ptrI(len(w.skins) - 1), offset
+}
+
+// AddAnimations writes the sequences as animations of the skeleton's joints,
+// with the skeleton's root joint being the node provided
+func (w *Writer) AddAnimations(animations []animation.Sequence, skeleton animation.Skeleton, skeletonNode int) error {
+	return w.writeAnimations("", animations, func(joint string) (int, error) {
+		return skeletonJointNode(skeleton, skeletonNode, joint)
+	})
+}
+
+// AddNodeAnimations writes the sequences as animations of the node provided.
+// Sequences must not reference a joint.
+func (w *Writer) AddNodeAnimations(animations []animation.Sequence, node int) error {
+	return w.writeAnimations("", animations, func(joint string) (int, error) {
+		if joint != "" {
+			return -1, fmt.Errorf("sequence animates joint %q of a node without a skeleton", joint)
+		}
+		return node, nil
+	})
+}
+
+func skeletonJointNode(skeleton animation.Skeleton, skeletonNode int, joint string) (int, error) {
+	index, ok := skeleton.TryLookup(joint)
+	if !ok {
+		return -1, fmt.Errorf("skeleton does not contain a joint with the path %q", joint)
 	}
-	return ptrI(len(w.skins) - 1), w.scene[len(w.scene)-1]
+	return index + skeletonNode, nil
 }
 
-func (w *Writer) AddAnimations(animations []animation.Sequence, skeleton animation.Skeleton, skeletonNode int) {
-	for i, animation := range animations {
+// morphTargetCount is the number of morph targets of the node's mesh
+func (w *Writer) morphTargetCount(node int) int {
+	mesh := w.nodes[node].Mesh
+	if mesh == nil || len(w.meshes[*mesh].Primitives) == 0 {
+		return 0
+	}
+	return len(w.meshes[*mesh].Primitives[0].Targets)
+}
 
-		min := vector3.New(math.MaxFloat64, math.MaxFloat64, math.MaxFloat64)
-		max := vector3.New(-math.MaxFloat64, -math.MaxFloat64, -math.MaxFloat64)
+func gltfInterpolation(interpolation animation.Interpolation) (AnimationSamplerInterpolation, error) {
+	switch interpolation {
+	case animation.LinearInterpolation:
+		return AnimationSamplerInterpolation_LINEAR, nil
+	case animation.StepInterpolation:
+		return AnimationSamplerInterpolation_STEP, nil
+	case animation.CubicSplineInterpolation:
+		return AnimationSamplerInterpolation_CUBICSPLINE, nil
+	}
+	return "", fmt.Errorf("unrecognized interpolation %s", interpolation)
+}
 
-		for _, frame := range animation.Frames() {
-			v := frame.Val()
-			min = vector3.Min(min, v)
-			max = vector3.Max(max, v)
-			w.WriteVector3AsFloat32(v)
-		}
+func gltfAnimationPath(property animation.Property) (AnimationChannelTargetPath, error) {
+	switch property {
+	case animation.TranslationProperty:
+		return AnimationChannelTargetPath_TRANSLATION, nil
+	case animation.RotationProperty:
+		return AnimationChannelTargetPath_ROTATION, nil
+	case animation.ScaleProperty:
+		return AnimationChannelTargetPath_SCALE, nil
+	case animation.WeightsProperty:
+		return AnimationChannelTargetPath_WEIGHTS, nil
+	}
+	return "", fmt.Errorf("unrecognized animation property %s", property)
+}
+
+// writeAnimationData writes the values as float32s, returning the index of
+// the accessor referencing them
+func (w *Writer) writeAnimationData(values []float64, accessorType AccessorType, components int) int {
+	min := make([]float64, components)
+	max := make([]float64, components)
+	for i := range min {
+		min[i] = math.MaxFloat64
+		max[i] = -math.MaxFloat64
+	}
+
+	for i, v := range values {
+		w.bitW.Float32(float32(v))
+		min[i%components] = math.Min(min[i%components], v)
+		max[i%components] = math.Max(max[i%components], v)
+	}
+
+	datasize := len(values) * 4
+	w.accessors = append(w.accessors, Accessor{
+		BufferView:    ptrI(len(w.bufferViews)),
+		ComponentType: AccessorComponentType_FLOAT,
+		Type:          accessorType,
+		Count:         len(values) / components,
+		Min:           min,
+		Max:           max,
+	})
+
+	w.bufferViews = append(w.bufferViews, BufferView{
+		Buffer:     0,
+		ByteOffset: w.bytesWritten,
+		ByteLength: datasize,
+	})
+	w.bytesWritten += datasize
+
+	return len(w.accessors) - 1
+}
 
-		datasize := len(animation.Frames()) * 3 * 4
+// writeAnimationSampler writes the keyframe times and values of the sequence
+func (w *Writer) writeAnimationSampler(sequence animation.Sequence, interpolation AnimationSamplerInterpolation) AnimationSampler {
+	cubic := sequence.Interpolation() == animation.CubicSplineInterpolation
 
-		animationDataBufferView := BufferView{
-			Buffer:     0,
-			ByteOffset: w.bytesWritten,
-			ByteLength: datasize,
+	times := make([]float64, sequence.Len())
+	for i := range times {
+		times[i] = sequence.Time(i)
+	}
+
+	// Cubic splines store an in-tangent, value, and out-tangent for every
+	// keyframe
+	values := make([]float64, 0)
+	var accessorType AccessorType
+	components := 1
+	switch sequence.Property() {
+	case animation.TranslationProperty, animation.ScaleProperty:
+		accessorType, components = AccessorType_VEC3, 3
+		for _, frame := range sequence.Frames() {
+			if cubic {
+				values = append(values, frame.InTangent().X(), frame.InTangent().Y(), frame.InTangent().Z())
+			}
+			values = append(values, frame.Val().X(), frame.Val().Y(), frame.Val().Z())
+			if cubic {
+				values = append(values, frame.OutTangent().X(), frame.OutTangent().Y(), frame.OutTangent().Z())
+			}
 		}
-		animationDataBufferViewIndex := len(w.bufferViews)
 
-		animationDataAccessor := Accessor{
-			BufferView:    ptrI(animationDataBufferViewIndex),
-			ComponentType: AccessorComponentType_FLOAT,
-			Type:          AccessorType_VEC3,
-			Count:         len(animation.Frames()),
-			Min:           []float64{min.X(), min.Y(), min.Z()},
-			Max:           []float64{max.X(), max.Y(), max.Z()},
+	case animation.RotationProperty:
+		accessorType, components = AccessorType_VEC4, 4
+		for _, frame := range sequence.RotationFrames() {
+			if cubic {
+				in := frame.InTangent().ToArr()
+				values = append(values, in[:]...)
+			}
+			val := frame.Val().ToArr()
+			values = append(values, val[:]...)
+			if cubic {
+				out := frame.OutTangent().ToArr()
+				values = append(values, out[:]...)
+			}
+		}
+
+	case animation.WeightsProperty:
+		accessorType = AccessorType_SCALAR
+		for _, frame := range sequence.WeightFrames() {
+			// Frames created without tangents are flat
+			tangent := func(t []float64) []float64 {
+				if t == nil {
+					return make([]float64, len(frame.Val()))
+				}
+				return t
+			}
+
+			if cubic {
+				values = append(values, tangent(frame.InTangent())...)
+			}
+			values = append(values, frame.Val()...)
+			if cubic {
+				values = append(values, tangent(frame.OutTangent())...)
+			}
 		}
-		animationDataAccessorIndex := len(w.accessors)
+	}
 
-		w.accessors = append(w.accessors, animationDataAccessor)
-		w.bufferViews = append(w.bufferViews, animationDataBufferView)
+	return AnimationSampler{
+		Input:         w.writeAnimationData(times, AccessorType_SCALAR, 1),
+		Output:        w.writeAnimationData(values, accessorType, components),
+		Interpolation: interpolation,
+	}
+}
 
-		w.bytesWritten += datasize
+// writeAnimations writes a sampler and channel for every sequence, with the
+// target function resolving the node each sequence's joint refers to.
+// Sequences are grouped into as few animations as possible, only starting a
+// new animation when one would otherwise animate the same property of a node
+// twice.
+func (w *Writer) writeAnimations(name string, sequences []animation.Sequence, target func(joint string) (int, error)) error {
+	type channelTarget struct {
+		node int
+		path AnimationChannelTargetPath
+	}
 
-		// Time Data ============================================================
+	targets := make([]channelTarget, len(sequences))
+	interpolations := make([]AnimationSamplerInterpolation, len(sequences))
 
-		minTime := math.MaxFloat64
-		maxTime := -math.MaxFloat64
+	// Validate everything up front so we don't leave partially written data
+	// in the buffer
+	for i, sequence := range sequences {
+		if err := sequence.Validate(); err != nil {
+			return fmt.Errorf("%w: sequence %d: %w", ErrInvalidInput, i, err)
+		}
 
-		for _, frame := range animation.Frames() {
-			minTime = math.Min(minTime, frame.Time())
-			maxTime = math.Max(maxTime, frame.Time())
-			w.bitW.Float32(float32(frame.Time()))
+		node, err := target(sequence.Joint())
+		if err != nil {
+			return fmt.Errorf("%w: sequence %d: %w", ErrInvalidInput, i, err)
 		}
 
-		datasize = len(animation.Frames()) * 4
+		path, err := gltfAnimationPath(sequence.Property())
+		if err != nil {
+			return fmt.Errorf("%w: sequence %d: %w", ErrInvalidInput, i, err)
+		}
+
+		if path == AnimationChannelTargetPath_WEIGHTS {
+			morphTargets := w.morphTargetCount(node)
+			if morphTargets == 0 {
+				return fmt.Errorf("%w: sequence %d animates weights of a node without morph targets", ErrInvalidInput, i)
+			}
 
-		timeBufferView := BufferView{
-			Buffer:     0,
-			ByteOffset: w.bytesWritten,
-			ByteLength: datasize,
+			if weights := len(sequence.WeightFrames()[0].Val()); weights != morphTargets {
+				return fmt.Errorf("%w: sequence %d has %d weights for %d morph targets", ErrInvalidInput, i, weights, morphTargets)
+			}
 		}
-		timeBufferViewIndex := len(w.bufferViews)
 
-		timeAccessor := Accessor{
-			BufferView:    ptrI(timeBufferViewIndex),
-			ComponentType: AccessorComponentType_FLOAT,
-			Type:          AccessorType_SCALAR,
-			Count:         len(animation.Frames()),
-			Min:           []float64{minTime},
-			Max:           []float64{maxTime},
+		interpolation, err := gltfInterpolation(sequence.Interpolation())
+		if err != nil {
+			return fmt.Errorf("%w: sequence %d: %w", ErrInvalidInput, i, err)
 		}
 
-		timeAccessorIndex := len(w.accessors)
-		w.accessors = append(w.accessors, timeAccessor)
-		w.bufferViews = append(w.bufferViews, timeBufferView)
+		targets[i] = channelTarget{node: node, path: path}
+		interpolations[i] = interpolation
+	}
 
-		w.bytesWritten += datasize
+	animations := make([]Animation, 0)
+	animated := make([]map[channelTarget]bool, 0)
+	for i, sequence := range sequences {
+		clip := 0
+		for clip < len(animations) && animated[clip][targets[i]] {
+			clip++
+		}
 
-		w.animations = append(w.animations, Animation{
-			Samplers: []AnimationSampler{
-				{
-					Interpolation: AnimationSamplerInterpolation_LINEAR,
-					Input:         timeAccessorIndex,
-					Output:        animationDataAccessorIndex,
-				},
-			},
-			Channels: []AnimationChannel{
-				{
-					Target: AnimationChannelTarget{
-						Path: AnimationChannelTargetPath_TRANSLATION,
-						Node: skeleton.Lookup(animation.Joint()) + skeletonNode,
-					},
-					Sampler: i,
-				},
+		if clip == len(animations) {
+			clipName := name
+			if name != "" && clip > 0 {
+				clipName = fmt.Sprintf("%s_%d", name, clip)
+			}
+			animations = append(animations, Animation{
+				ChildOfRootProperty: ChildOfRootProperty{Name: clipName},
+				Samplers:            make([]AnimationSampler, 0),
+				Channels:            make([]AnimationChannel, 0),
+			})
+			animated = append(animated, make(map[channelTarget]bool))
+		}
+
+		animated[clip][targets[i]] = true
+		animations[clip].Samplers = append(animations[clip].Samplers, w.writeAnimationSampler(sequence, interpolations[i]))
+		animations[clip].Channels = append(animations[clip].Channels, AnimationChannel{
+			Sampler: len(animations[clip].Samplers) - 1,
+			Target: AnimationChannelTarget{
+				Node: ptrI(targets[i].node),
+				Path: targets[i].path,
 			},
 		})
 	}
+
+	w.animations = append(w.animations, animations...)
+	return nil
+}
+
+// lightExtension adds the light to the document, returning the node
+// extensions referencing it
+func (w *Writer) lightExtension(light KHR_LightsPunctual) map[string]any {
+	lightIndex := len(w.lights)
+	w.lights = append(w.lights, light)
+	w.extensionsUsed["KHR_lights_punctual"] = true
+
+	return map[string]any{
+		"KHR_lights_punctual": map[string]any{
+			"light": lightIndex,
+		},
+	}
 }
 
 func (w *Writer) AddLight(light KHR_LightsPunctual) {
 	nodeIndex := len(w.nodes)
 	w.scene = append(w.scene, nodeIndex)
 
-	lightIndex := len(w.lights)
-	w.lights = append(w.lights, light)
-
 	var translation = [3]float64{
 		light.Position.X(),
 		light.Position.Y(),
@@ -827,16 +1184,11 @@ func (w *Writer) AddLight(light KHR_LightsPunctual) {
 	w.nodes = append(w.nodes, Node{
 		ChildOfRootProperty: ChildOfRootProperty{
 			Property: Property{
-				Extensions: map[string]any{
-					"KHR_lights_punctual": map[string]any{
-						"light": lightIndex,
-					},
-				},
+				Extensions: w.lightExtension(light),
 			},
 		},
 		Translation: &translation,
 	})
-	w.extensionsUsed["KHR_lights_punctual"] = true
 }
 
 type BufferEmbeddingStrategy int
@@ -901,6 +1253,7 @@ func (w Writer) ToGLTF(embeddingStrategy BufferEmbeddingStrategy) Gltf {
 		Animations: w.animations,
 
 		Nodes:     w.nodes,
+		Cameras:   w.cameras,
 		Meshes:    w.meshes,
 		Materials: w.materials,
 		Textures:  w.textures,
@@ -309,6 +309,7 @@ var defaultReader MeshReader = MeshReader{
 			PlyPropertyZ:   "rot_2",
 			PlyPropertyW:   "rot_3",
 		},
+		&SHPropertyReader{},
 		// <<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 	},
 }
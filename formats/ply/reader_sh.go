@@ -0,0 +1,128 @@
+package ply
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/EliCDavis/polyform/modeling"
+)
+
+// SHPropertyReader reads 3D Gaussian Splatting's f_rest_N properties into
+// the spherical harmonic attributes defined by modeling.SHAttribute. The
+// properties hold all of the red coefficients, followed by all of the green,
+// then all of the blue.
+type SHPropertyReader struct{}
+
+// coefficientReaders builds a Vector3PropertyReader for each coefficient of
+// the highest degree the element's f_rest properties fully cover
+func (shpr SHPropertyReader) coefficientReaders(element Element) []Vector3PropertyReader {
+	names := make(map[string]struct{}, len(element.Properties))
+	for _, prop := range element.Properties {
+		names[prop.Name()] = struct{}{}
+	}
+
+	rest := 0
+	for {
+		if _, ok := names[fmt.Sprintf("f_rest_%d", rest)]; !ok {
+			break
+		}
+		rest++
+	}
+
+	stride := rest / 3
+	coefficients := modeling.SHCoefficients(modeling.SHDegreeForCoefficients(stride))
+
+	readers := make([]Vector3PropertyReader, coefficients)
+	for i := range readers {
+		readers[i] = Vector3PropertyReader{
+			ModelAttribute: modeling.SHAttribute(i),
+			PlyPropertyX:   fmt.Sprintf("f_rest_%d", i),
+			PlyPropertyY:   fmt.Sprintf("f_rest_%d", i+stride),
+			PlyPropertyZ:   fmt.Sprintf("f_rest_%d", i+(stride*2)),
+		}
+	}
+	return readers
+}
+
+func (shpr SHPropertyReader) buildBinary(element Element, endian binary.ByteOrder) binaryPropertyReader {
+	coefficientReaders := shpr.coefficientReaders(element)
+	if len(coefficientReaders) == 0 {
+		return nil
+	}
+
+	built := make([]binaryPropertyReader, len(coefficientReaders))
+	for i, reader := range coefficientReaders {
+		built[i] = reader.buildBinary(element, endian)
+		if built[i] == nil {
+			return nil
+		}
+	}
+	return builtBinarySHPropertyReader(built)
+}
+
+func (shpr SHPropertyReader) buildAscii(element Element) asciiPropertyReader {
+	coefficientReaders := shpr.coefficientReaders(element)
+	if len(coefficientReaders) == 0 {
+		return nil
+	}
+
+	built := make([]asciiPropertyReader, len(coefficientReaders))
+	for i, reader := range coefficientReaders {
+		built[i] = reader.buildAscii(element)
+		if built[i] == nil {
+			return nil
+		}
+	}
+	return builtAsciiSHPropertyReader(built)
+}
+
+type builtAsciiSHPropertyReader []asciiPropertyReader
+
+func (basr builtAsciiSHPropertyReader) ClaimsProperty(prop Property) bool {
+	for _, reader := range basr {
+		if reader.ClaimsProperty(prop) {
+			return true
+		}
+	}
+	return false
+}
+
+func (basr builtAsciiSHPropertyReader) Read(buf []string, i int64) error {
+	for _, reader := range basr {
+		if err := reader.Read(buf, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (basr builtAsciiSHPropertyReader) UpdateMesh(m modeling.Mesh) modeling.Mesh {
+	for _, reader := range basr {
+		m = reader.UpdateMesh(m)
+	}
+	return m
+}
+
+type builtBinarySHPropertyReader []binaryPropertyReader
+
+func (bbsr builtBinarySHPropertyReader) ClaimsProperty(prop Property) bool {
+	for _, reader := range bbsr {
+		if reader.ClaimsProperty(prop) {
+			return true
+		}
+	}
+	return false
+}
+
+func (bbsr builtBinarySHPropertyReader) Read(buf []byte, i int64) {
+	for _, reader := range bbsr {
+		reader.Read(buf, i)
+	}
+}
+
+func (bbsr builtBinarySHPropertyReader) UpdateMesh(m modeling.Mesh) modeling.Mesh {
+	for _, reader := range bbsr {
+		m = reader.UpdateMesh(m)
+	}
+	return m
+}
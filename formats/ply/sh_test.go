@@ -0,0 +1,89 @@
+package ply_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/EliCDavis/polyform/formats/ply"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shCloud(degree int) modeling.Mesh {
+	v3Data := map[string][]vector3.Float64{
+		modeling.PositionAttribute: {vector3.New(1., 2., 3.), vector3.New(4., 5., 6.)},
+	}
+	for i := 0; i < modeling.SHCoefficients(degree); i++ {
+		v := float64(i)
+		v3Data[modeling.SHAttribute(i)] = []vector3.Float64{
+			vector3.New(v, v+100, v+200),
+			vector3.New(-v, -v-100, -v-200),
+		}
+	}
+	return modeling.NewPointCloud(nil, v3Data, nil, nil, nil)
+}
+
+func TestSHPropertyWriter_ChannelMajor(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, ply.Write(buf, shCloud(1), ply.ASCII))
+
+	header, err := ply.ReadHeader(buf)
+	require.NoError(t, err)
+
+	names := make([]string, 0)
+	for _, prop := range header.Elements[0].Properties {
+		names = append(names, prop.Name())
+	}
+	assert.Equal(t, []string{
+		"x", "y", "z",
+		"f_rest_0", "f_rest_1", "f_rest_2", "f_rest_3", "f_rest_4",
+		"f_rest_5", "f_rest_6", "f_rest_7", "f_rest_8",
+	}, names)
+
+	// All red coefficients come first, then green, then blue
+	assert.Equal(t, "1 2 3 0 1 2 100 101 102 200 201 202\n", readLine(t, buf))
+}
+
+func readLine(t *testing.T, buf *bytes.Buffer) string {
+	t.Helper()
+	line, err := buf.ReadString('\n')
+	require.NoError(t, err)
+	return line
+}
+
+func TestSHPropertyReaderWriter_RoundTrip(t *testing.T) {
+	for _, format := range []ply.Format{ply.ASCII, ply.BinaryLittleEndian, ply.BinaryBigEndian} {
+		for degree := 1; degree <= modeling.MaxSHDegree; degree++ {
+			t.Run(fmt.Sprintf("%s degree %d", format, degree), func(t *testing.T) {
+				in := shCloud(degree)
+
+				buf := &bytes.Buffer{}
+				require.NoError(t, ply.Write(buf, in, format))
+
+				out, err := ply.ReadMesh(buf)
+				require.NoError(t, err)
+
+				assert.Equal(t, degree, modeling.SHDegree(*out))
+				assert.False(t, out.HasFloat1Attribute("f_rest_0"))
+				for i := 0; i < modeling.SHCoefficients(degree); i++ {
+					attr := modeling.SHAttribute(i)
+					assert.Equal(t, in.Float3Attribute(attr).At(0), out.Float3Attribute(attr).At(0))
+					assert.Equal(t, in.Float3Attribute(attr).At(1), out.Float3Attribute(attr).At(1))
+				}
+			})
+		}
+	}
+}
+
+func TestSplatPly_WritesSH(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, ply.SplatPly{Mesh: shCloud(2)}.Write(buf))
+
+	out, err := ply.ReadMesh(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, modeling.SHDegree(*out))
+	assert.False(t, out.HasFloat3Attribute(modeling.SHAttribute(8)))
+}
@@ -60,6 +60,9 @@ var defaultWriter = MeshWriter{
 			PlyPropertyZ:   "rot_2",
 			PlyPropertyW:   "rot_3",
 		},
+		&SHPropertyWriter{
+			Type: Float,
+		},
 		// <<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<<
 	},
 }
@@ -22,6 +22,12 @@ type builtPropertyWriter interface {
 	Write(out io.Writer, i int) error
 }
 
+// meshDependentPropertyWriter is a PropertyWriter whose properties depend on
+// the mesh being written
+type meshDependentPropertyWriter interface {
+	propertiesFor(mesh modeling.Mesh) []Property
+}
+
 // ============================================================================
 
 type MeshWriter struct {
@@ -66,6 +72,14 @@ func (mw MeshWriter) Write(mesh modeling.Mesh, writer io.Writer) error {
 			claimedV2[v.ModelAttribute] = true
 		case *Vector1PropertyWriter:
 			claimedV1[v.ModelAttribute] = true
+		case SHPropertyWriter:
+			for _, attr := range v.Attributes(mesh) {
+				claimedV3[attr] = true
+			}
+		case *SHPropertyWriter:
+			for _, attr := range v.Attributes(mesh) {
+				claimedV3[attr] = true
+			}
 		default:
 			panic("what is this type")
 		}
@@ -122,6 +136,10 @@ func (mw MeshWriter) Write(mesh modeling.Mesh, writer io.Writer) error {
 
 	for _, prop := range writers {
 		builtWriters = append(builtWriters, prop.build(mesh, mw.Format))
+		if dependent, ok := prop.(meshDependentPropertyWriter); ok {
+			properties = append(properties, dependent.propertiesFor(mesh)...)
+			continue
+		}
 		properties = append(properties, prop.Properties()...)
 	}
 
@@ -0,0 +1,118 @@
+package ply
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/EliCDavis/iter"
+	"github.com/EliCDavis/polyform/modeling"
+)
+
+// SHPropertyWriter writes the spherical harmonic attributes defined by
+// modeling.SHAttribute as 3D Gaussian Splatting's f_rest_N properties, all of
+// the red coefficients first, followed by all of the green, then all of the
+// blue.
+type SHPropertyWriter struct {
+	Type ScalarPropertyType
+}
+
+func (shpw SHPropertyWriter) MeshQualifies(mesh modeling.Mesh) bool {
+	return modeling.SHDegree(mesh) > 0
+}
+
+// Attributes is the spherical harmonic attributes written for the mesh
+func (shpw SHPropertyWriter) Attributes(mesh modeling.Mesh) []string {
+	attributes := make([]string, modeling.SHCoefficients(modeling.SHDegree(mesh)))
+	for i := range attributes {
+		attributes[i] = modeling.SHAttribute(i)
+	}
+	return attributes
+}
+
+func (shpw SHPropertyWriter) properties(coefficients int) []Property {
+	properties := make([]Property, coefficients*3)
+	for i := range properties {
+		properties[i] = ScalarProperty{PropertyName: fmt.Sprintf("f_rest_%d", i), Type: shpw.Type}
+	}
+	return properties
+}
+
+// Properties is the f_rest properties of a degree 3 spherical harmonic. The
+// properties written for a mesh depend on the degree of the mesh's spherical
+// harmonics.
+func (shpw SHPropertyWriter) Properties() []Property {
+	return shpw.properties(modeling.SHCoefficients(modeling.MaxSHDegree))
+}
+
+func (shpw SHPropertyWriter) propertiesFor(mesh modeling.Mesh) []Property {
+	return shpw.properties(modeling.SHCoefficients(modeling.SHDegree(mesh)))
+}
+
+func (shpw SHPropertyWriter) build(mesh modeling.Mesh, format Format) builtPropertyWriter {
+	attributes := shpw.Attributes(mesh)
+
+	// Split each coefficient out into the channels it's written as
+	channels := make([][]float64, len(attributes)*3)
+	for i := range channels {
+		channels[i] = make([]float64, mesh.AttributeLength())
+	}
+
+	for coefficient, attribute := range attributes {
+		data := mesh.Float3Attribute(attribute)
+		for v := 0; v < data.Len(); v++ {
+			value := data.At(v)
+			channels[coefficient][v] = value.X()
+			channels[coefficient+len(attributes)][v] = value.Y()
+			channels[coefficient+(len(attributes)*2)][v] = value.Z()
+		}
+	}
+
+	var endian binary.ByteOrder = binary.LittleEndian
+	if format == BinaryBigEndian {
+		endian = binary.BigEndian
+	}
+
+	built := builtSHPropertyWriter{
+		ascii:   format == ASCII,
+		writers: make([]builtPropertyWriter, len(channels)),
+	}
+	for i, channel := range channels {
+		if format == ASCII {
+			built.writers[i] = &asciiVector1PropertyWriter{
+				arr:    iter.Array(channel),
+				format: shpw.Type,
+				buf:    make([]byte, 0),
+			}
+			continue
+		}
+
+		built.writers[i] = builtVector1PropertyWriter{
+			arr:    iter.Array(channel),
+			format: shpw.Type,
+			buf:    make([]byte, shpw.Type.Size()),
+			endian: endian,
+		}
+	}
+	return built
+}
+
+type builtSHPropertyWriter struct {
+	ascii   bool
+	writers []builtPropertyWriter
+}
+
+func (bshpw builtSHPropertyWriter) Write(out io.Writer, i int) error {
+	for w, writer := range bshpw.writers {
+		if bshpw.ascii && w > 0 {
+			if _, err := out.Write([]byte{' '}); err != nil {
+				return err
+			}
+		}
+
+		if err := writer.Write(out, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -63,6 +63,12 @@ func ReadMetadata(in io.Reader) (*Metadata, error) {
 	return m, json.Unmarshal(data, m)
 }
 
+func (m Metadata) Write(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(m)
+}
+
 func (m Metadata) OffsetF() vector3.Float64 {
 	return vector3.New(m.Offset[0], m.Offset[1], m.Offset[2])
 }
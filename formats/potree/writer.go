@@ -0,0 +1,442 @@
+package potree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/EliCDavis/polyform/math/geometry"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+type WriterOptions struct {
+	Name        string
+	Description string
+	Projection  string
+
+	// Precision positions are stored with. Positions are kept as 32-bit
+	// integers, so the smaller the scale, the smaller the point cloud can be
+	Scale float64
+
+	// Number of points a node can hold before it's subdivided into children
+	MaxPointsPerNode int
+
+	// Number of points loaded into memory at once while building a section
+	// of the octree. Sections containing more are split into their octants on
+	// disk first, keeping memory bounded regardless of the size of the cloud
+	MaxPointsInMemory int
+
+	// Number of levels of the octree stored in each chunk of hierarchy.bin
+	HierarchyStepSize int
+}
+
+var DefaultWriterOptions = WriterOptions{
+	Scale:             0.001,
+	MaxPointsPerNode:  20_000,
+	MaxPointsInMemory: 5_000_000,
+	HierarchyStepSize: 4,
+}
+
+// Number of cells along each axis of the grid a node's points are sampled
+// from, matching PotreeConverter
+const samplingGridSize = 128
+
+// Nodes past this level are never subdivided, keeping duplicate points from
+// recursing forever
+const maxOctreeDepth = 20
+
+// pointAttribute maps a mesh attribute to the attribute written to Potree
+type pointAttribute struct {
+	meshAttribute string
+	components    int
+	Attribute
+}
+
+// Writer builds a Potree 2.0 octree from a point cloud that's added to it in
+// chunks. Points are spilled to disk as they're added, so the entire cloud
+// never needs to fit in memory. The octree is built and written once the
+// writer is closed.
+type Writer struct {
+	dir     string
+	options WriterOptions
+	bounds  geometry.AABB
+	offset  vector3.Float64
+
+	// Length of each side of the octree's cube
+	size float64
+
+	// Largest integer a position can be stored as within the bounds
+	maxFixed float64
+
+	attributes    []pointAttribute
+	bytesPerPoint int
+
+	spill  *os.File
+	buffer *bufio.Writer
+	points int64
+}
+
+// Write builds a Potree 2.0 octree from the point cloud using the default
+// options, writing metadata.json, hierarchy.bin and octree.bin to the folder
+func Write(dir string, cloud modeling.Mesh) error {
+	return WriteWithOptions(dir, cloud, DefaultWriterOptions)
+}
+
+// WriteWithOptions builds a Potree 2.0 octree from the point cloud, writing
+// metadata.json, hierarchy.bin and octree.bin to the folder
+func WriteWithOptions(dir string, cloud modeling.Mesh, options WriterOptions) error {
+	bounds := geometry.NewAABB(vector3.Zero[float64](), vector3.Zero[float64]())
+	if cloud.PrimitiveCount() > 0 && cloud.HasFloat3Attribute(modeling.PositionAttribute) {
+		bounds = cloud.BoundingBox(modeling.PositionAttribute)
+	}
+
+	writer, err := NewWriter(dir, bounds, options)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.Add(cloud); err != nil {
+		writer.abort()
+		return err
+	}
+
+	return writer.Close()
+}
+
+// NewWriter starts a Potree 2.0 octree in the folder provided. Every point
+// later added must fall within the bounds.
+func NewWriter(dir string, bounds geometry.AABB, options WriterOptions) (*Writer, error) {
+	if options.Scale <= 0 {
+		return nil, fmt.Errorf("scale must be greater than 0, got %g", options.Scale)
+	}
+
+	if options.MaxPointsPerNode < 1 {
+		return nil, fmt.Errorf("max points per node must be greater than 0, got %d", options.MaxPointsPerNode)
+	}
+
+	if options.MaxPointsInMemory < options.MaxPointsPerNode {
+		return nil, fmt.Errorf("max points in memory (%d) must be at least max points per node (%d)", options.MaxPointsInMemory, options.MaxPointsPerNode)
+	}
+
+	if options.HierarchyStepSize < 1 {
+		return nil, fmt.Errorf("hierarchy step size must be greater than 0, got %d", options.HierarchyStepSize)
+	}
+
+	// Potree's octree is a cube starting at the minimum of the bounds
+	size := bounds.Size().MaxComponent()
+	if size == 0 {
+		size = 1
+	}
+
+	if size/options.Scale > math.MaxInt32 {
+		return nil, fmt.Errorf("point cloud spanning %g units can not be represented with a scale of %g", size, options.Scale)
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	spill, err := os.CreateTemp(dir, "potree-*.spill")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		dir:      dir,
+		options:  options,
+		bounds:   geometry.NewAABBFromPoints(bounds.Min(), bounds.Min().Add(vector3.Fill(size))),
+		offset:   bounds.Min(),
+		size:     size,
+		maxFixed: math.Floor(size / options.Scale),
+		spill:    spill,
+		buffer:   bufio.NewWriter(spill),
+	}, nil
+}
+
+// setupAttributes determines the attributes written for every point from the
+// first chunk added. Color is written the way Potree expects RGB, with every
+// other attribute written as doubles under its own name.
+func (w *Writer) setupAttributes(chunk modeling.Mesh) {
+	w.attributes = []pointAttribute{{
+		meshAttribute: modeling.PositionAttribute,
+		components:    3,
+		Attribute:     Attribute{Name: "position", Type: Int32AttributeType},
+	}}
+
+	if chunk.HasFloat3Attribute(modeling.ColorAttribute) {
+		w.attributes = append(w.attributes, pointAttribute{
+			meshAttribute: modeling.ColorAttribute,
+			components:    3,
+			Attribute:     Attribute{Name: "rgb", Type: UInt16AttributeType},
+		})
+	}
+
+	generic := func(attributes []string, components int) {
+		for _, attr := range attributes {
+			if attr == modeling.PositionAttribute || attr == modeling.ColorAttribute {
+				continue
+			}
+			w.attributes = append(w.attributes, pointAttribute{
+				meshAttribute: attr,
+				components:    components,
+				Attribute:     Attribute{Name: attr, Type: DoubleAttributeType},
+			})
+		}
+	}
+	generic(chunk.Float1Attributes(), 1)
+	generic(chunk.Float2Attributes(), 2)
+	generic(chunk.Float3Attributes(), 3)
+	generic(chunk.Float4Attributes(), 4)
+
+	w.bytesPerPoint = 0
+	for i := range w.attributes {
+		attr := &w.attributes[i]
+		attr.NumElements = attr.components
+		attr.ElementSize = attr.Type.Size()
+		attr.Size = attr.components * attr.ElementSize
+		attr.Min = make([]float64, attr.components)
+		attr.Max = make([]float64, attr.components)
+		for c := 0; c < attr.components; c++ {
+			attr.Min[c] = math.Inf(1)
+			attr.Max[c] = math.Inf(-1)
+		}
+		w.bytesPerPoint += attr.Size
+	}
+}
+
+// componentReader reads the components of the attribute for a single point
+func componentReader(m modeling.Mesh, attr string, components int) func(i int, out []float64) {
+	switch components {
+	case 1:
+		data := m.Float1Attribute(attr)
+		return func(i int, out []float64) { out[0] = data.At(i) }
+	case 2:
+		data := m.Float2Attribute(attr)
+		return func(i int, out []float64) {
+			v := data.At(i)
+			out[0], out[1] = v.X(), v.Y()
+		}
+	case 3:
+		data := m.Float3Attribute(attr)
+		return func(i int, out []float64) {
+			v := data.At(i)
+			out[0], out[1], out[2] = v.X(), v.Y(), v.Z()
+		}
+	default:
+		data := m.Float4Attribute(attr)
+		return func(i int, out []float64) {
+			v := data.At(i)
+			out[0], out[1], out[2], out[3] = v.X(), v.Y(), v.Z(), v.W()
+		}
+	}
+}
+
+func hasAttribute(m modeling.Mesh, attr string, components int) bool {
+	switch components {
+	case 1:
+		return m.HasFloat1Attribute(attr)
+	case 2:
+		return m.HasFloat2Attribute(attr)
+	case 3:
+		return m.HasFloat3Attribute(attr)
+	default:
+		return m.HasFloat4Attribute(attr)
+	}
+}
+
+// fixed converts the component of a position to the integer it's stored as,
+// reporting whether it falls within the bounds. Positions are truncated
+// rather than rounded, keeping points within the nodes they're placed in
+func (w *Writer) fixed(v float64, component int) (uint32, bool) {
+	fixed := math.Floor((v - w.offset.Component(component)) / w.options.Scale)
+	if fixed < 0 || fixed > w.maxFixed || math.IsNaN(fixed) {
+		return 0, false
+	}
+	return uint32(fixed), true
+}
+
+// Add spills the chunk of the point cloud to disk. Every chunk must share
+// the attributes of the first chunk added.
+func (w *Writer) Add(chunk modeling.Mesh) error {
+	if w.spill == nil {
+		return errors.New("writer has already been closed")
+	}
+
+	if chunk.Topology() != modeling.PointTopology {
+		return fmt.Errorf("mesh must be point topology, was instead %s", chunk.Topology())
+	}
+
+	count := chunk.AttributeLength()
+	if count == 0 {
+		return nil
+	}
+
+	if !chunk.HasFloat3Attribute(modeling.PositionAttribute) {
+		return fmt.Errorf("required attribute not present on mesh: %s", modeling.PositionAttribute)
+	}
+
+	// Validated up front so a bad chunk never leaves half its points behind
+	positions := chunk.Float3Attribute(modeling.PositionAttribute)
+	for i := 0; i < count; i++ {
+		p := positions.At(i)
+		for c := 0; c < 3; c++ {
+			if _, ok := w.fixed(p.Component(c), c); !ok {
+				return fmt.Errorf("position (%g, %g, %g) falls outside the bounds of the octree", p.X(), p.Y(), p.Z())
+			}
+		}
+	}
+
+	if w.attributes == nil {
+		w.setupAttributes(chunk)
+	}
+
+	readers := make([]func(i int, out []float64), len(w.attributes))
+	for a, attr := range w.attributes {
+		if !hasAttribute(chunk, attr.meshAttribute, attr.components) {
+			return fmt.Errorf("required attribute not present on mesh: %s", attr.meshAttribute)
+		}
+		readers[a] = componentReader(chunk, attr.meshAttribute, attr.components)
+	}
+
+	record := make([]byte, w.bytesPerPoint)
+	values := make([]float64, 4)
+	endian := binary.LittleEndian
+	for i := 0; i < count; i++ {
+		offset := 0
+		for a := range w.attributes {
+			attr := &w.attributes[a]
+			readers[a](i, values)
+
+			for c := 0; c < attr.components; c++ {
+				v := values[c]
+				switch attr.Type {
+				case Int32AttributeType:
+					fixed, _ := w.fixed(v, c)
+					endian.PutUint32(record[offset:], fixed)
+
+				case UInt16AttributeType:
+					// 8-bit color in the upper byte, which Potree recognizes
+					// as 16-bit color
+					color := uint16(math.Round(math.Max(0, math.Min(1, v))*255)) << 8
+					endian.PutUint16(record[offset:], color)
+					v = float64(color)
+
+				default:
+					endian.PutUint64(record[offset:], math.Float64bits(v))
+				}
+
+				attr.Min[c] = math.Min(attr.Min[c], v)
+				attr.Max[c] = math.Max(attr.Max[c], v)
+				offset += attr.ElementSize
+			}
+		}
+
+		if _, err := w.buffer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.points += int64(count)
+	return nil
+}
+
+// abort cleans up after a writer that's never going to be closed
+func (w *Writer) abort() {
+	if w.spill == nil {
+		return
+	}
+	w.spill.Close()
+	os.Remove(w.spill.Name())
+	w.spill = nil
+}
+
+// Close builds the octree from every point added, writing out
+// metadata.json, hierarchy.bin and octree.bin
+func (w *Writer) Close() error {
+	if w.spill == nil {
+		return errors.New("writer has already been closed")
+	}
+
+	if w.attributes == nil {
+		w.setupAttributes(modeling.EmptyPointcloud())
+	}
+
+	spillName := w.spill.Name()
+	err := w.buffer.Flush()
+	if closeErr := w.spill.Close(); err == nil {
+		err = closeErr
+	}
+	w.spill = nil
+	if err != nil {
+		os.Remove(spillName)
+		return err
+	}
+
+	root, err := w.buildOctree(spill{path: spillName, count: w.points})
+	if err != nil {
+		return err
+	}
+
+	firstChunkSize, err := w.writeHierarchy(root)
+	if err != nil {
+		return err
+	}
+
+	return w.writeMetadata(root, firstChunkSize)
+}
+
+func (w *Writer) rootSpacing() float64 {
+	return w.size / samplingGridSize
+}
+
+func (w *Writer) writeMetadata(root *hierarchyNode, firstChunkSize uint64) error {
+	attributes := make([]Attribute, len(w.attributes))
+	for i, attr := range w.attributes {
+		attributes[i] = attr.Attribute
+		if w.points == 0 {
+			attributes[i].Min = make([]float64, attr.components)
+			attributes[i].Max = make([]float64, attr.components)
+		}
+	}
+
+	scale := w.options.Scale
+	min := w.offset
+	max := w.offset.Add(vector3.Fill(w.size))
+	metadata := Metadata{
+		Version:     "2.0",
+		Name:        w.options.Name,
+		Description: w.options.Description,
+		Points:      w.points,
+		Projection:  w.options.Projection,
+		Hierarchy: MetadataHierarchy{
+			FirstChunkSize: firstChunkSize,
+			StepSize:       w.options.HierarchyStepSize,
+			Depth:          root.depth(),
+		},
+		Offset:  []float64{w.offset.X(), w.offset.Y(), w.offset.Z()},
+		Scale:   []float64{scale, scale, scale},
+		Spacing: w.rootSpacing(),
+		BoundingBox: MetadataBounds{
+			Min: []float64{min.X(), min.Y(), min.Z()},
+			Max: []float64{max.X(), max.Y(), max.Z()},
+		},
+		Encoding:   "DEFAULT",
+		Attributes: attributes,
+	}
+
+	f, err := os.Create(filepath.Join(w.dir, "metadata.json"))
+	if err != nil {
+		return err
+	}
+
+	err = metadata.Write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
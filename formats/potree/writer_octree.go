@@ -0,0 +1,441 @@
+package potree
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/EliCDavis/polyform/math/geometry"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// spill is a file of encoded points waiting to be placed in the octree
+type spill struct {
+	path  string
+	count int64
+}
+
+// point is an encoded point, along with its decoded position
+type point struct {
+	position vector3.Float64
+	record   []byte
+}
+
+// hierarchyNode is a node of the octree being written, after its points have
+// been written to octree.bin
+type hierarchyNode struct {
+	level      int
+	children   [8]*hierarchyNode
+	numPoints  uint32
+	byteOffset uint64
+	byteSize   uint64
+}
+
+func (hn hierarchyNode) childMask() uint8 {
+	mask := uint8(0)
+	for i, child := range hn.children {
+		if child != nil {
+			mask |= 1 << i
+		}
+	}
+	return mask
+}
+
+func (hn hierarchyNode) leaf() bool {
+	return hn.childMask() == 0
+}
+
+func (hn hierarchyNode) depth() int {
+	depth := hn.level
+	for _, child := range hn.children {
+		if child != nil {
+			depth = max(depth, child.depth())
+		}
+	}
+	return depth
+}
+
+// octreeBuilder places points into the octree, writing each node's points
+// to octree.bin as soon as they're final
+type octreeBuilder struct {
+	*Writer
+	octree  *bufio.Writer
+	written uint64
+}
+
+func (w *Writer) buildOctree(source spill) (*hierarchyNode, error) {
+	f, err := os.Create(filepath.Join(w.dir, "octree.bin"))
+	if err != nil {
+		os.Remove(source.path)
+		return nil, err
+	}
+	defer f.Close()
+
+	builder := &octreeBuilder{Writer: w, octree: bufio.NewWriter(f)}
+	root, points, err := builder.build(w.bounds, 0, source)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := builder.writePoints(root, points); err != nil {
+		return nil, err
+	}
+
+	if err := builder.octree.Flush(); err != nil {
+		return nil, err
+	}
+	return root, f.Close()
+}
+
+func (ob *octreeBuilder) decodePosition(record []byte) vector3.Float64 {
+	endian := binary.LittleEndian
+	return vector3.New(
+		float64(endian.Uint32(record)),
+		float64(endian.Uint32(record[4:])),
+		float64(endian.Uint32(record[8:])),
+	).Scale(ob.options.Scale).Add(ob.offset)
+}
+
+func (ob *octreeBuilder) writePoints(node *hierarchyNode, points []point) error {
+	node.numPoints = uint32(len(points))
+	node.byteOffset = ob.written
+	node.byteSize = uint64(len(points) * ob.bytesPerPoint)
+	for _, p := range points {
+		if _, err := ob.octree.Write(p.record); err != nil {
+			return err
+		}
+	}
+	ob.written += node.byteSize
+	return nil
+}
+
+// childIndex follows the ordering Potree uses for a node's children, with X
+// as the most significant bit and Z as the least
+func childIndex(bounds geometry.AABB, p vector3.Float64) int {
+	center := bounds.Center()
+	index := 0
+	if p.X() >= center.X() {
+		index |= 0b100
+	}
+	if p.Y() >= center.Y() {
+		index |= 0b010
+	}
+	if p.Z() >= center.Z() {
+		index |= 0b001
+	}
+	return index
+}
+
+// build constructs the section of the octree within the bounds from the
+// points spilled to disk. The node is returned with the points it holds
+// still in memory, for its parent to sample from.
+func (ob *octreeBuilder) build(bounds geometry.AABB, level int, source spill) (*hierarchyNode, []point, error) {
+	if source.count <= int64(ob.options.MaxPointsInMemory) || level >= maxOctreeDepth {
+		points, err := ob.load(source)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ob.buildInMemory(bounds, level, points)
+	}
+
+	octants, err := ob.split(bounds, source)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var children [8]*hierarchyNode
+	var childPoints [8][]point
+	for i, octant := range octants {
+		if octant.count == 0 {
+			os.Remove(octant.path)
+			continue
+		}
+
+		children[i], childPoints[i], err = ob.build(createChildAABB(bounds, i), level+1, octant)
+		if err != nil {
+			for _, remaining := range octants[i+1:] {
+				os.Remove(remaining.path)
+			}
+			return nil, nil, err
+		}
+	}
+
+	return ob.subsample(bounds, level, children, childPoints)
+}
+
+// buildInMemory constructs the section of the octree within the bounds
+func (ob *octreeBuilder) buildInMemory(bounds geometry.AABB, level int, points []point) (*hierarchyNode, []point, error) {
+	if len(points) <= ob.options.MaxPointsPerNode || level >= maxOctreeDepth {
+		return &hierarchyNode{level: level}, points, nil
+	}
+
+	var octants [8][]point
+	for _, p := range points {
+		i := childIndex(bounds, p.position)
+		octants[i] = append(octants[i], p)
+	}
+
+	var children [8]*hierarchyNode
+	var childPoints [8][]point
+	for i, octant := range octants {
+		if len(octant) == 0 {
+			continue
+		}
+
+		var err error
+		children[i], childPoints[i], err = ob.buildInMemory(createChildAABB(bounds, i), level+1, octant)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return ob.subsample(bounds, level, children, childPoints)
+}
+
+// subsample builds the level of detail for a node, moving up an evenly
+// spaced selection of its children's points. Potree octrees are additive,
+// with each point stored in exactly one node, so whatever isn't selected
+// stays with the children, which are then final and written out.
+func (ob *octreeBuilder) subsample(bounds geometry.AABB, level int, children [8]*hierarchyNode, childPoints [8][]point) (*hierarchyNode, []point, error) {
+	type candidate struct {
+		child    int
+		index    int
+		distance float64
+	}
+
+	origin := bounds.Min()
+	cellSize := bounds.Size().X() / samplingGridSize
+	cells := make(map[int]candidate)
+
+	for c, points := range childPoints {
+		for i, p := range points {
+			cell := p.position.Sub(origin).DivByConstant(cellSize)
+			x := clampCell(int(cell.X()))
+			y := clampCell(int(cell.Y()))
+			z := clampCell(int(cell.Z()))
+			key := x + y*samplingGridSize + z*samplingGridSize*samplingGridSize
+
+			// Favor the point closest to the center of the cell
+			center := vector3.New(float64(x), float64(y), float64(z)).Add(vector3.Fill(0.5))
+			distance := cell.DistanceSquared(center)
+			if existing, ok := cells[key]; !ok || distance < existing.distance {
+				cells[key] = candidate{child: c, index: i, distance: distance}
+			}
+		}
+	}
+
+	var selected [8][]bool
+	for c, points := range childPoints {
+		selected[c] = make([]bool, len(points))
+	}
+	for _, chosen := range cells {
+		selected[chosen.child][chosen.index] = true
+	}
+
+	node := &hierarchyNode{level: level}
+	nodePoints := make([]point, 0, len(cells))
+	for c, points := range childPoints {
+		if children[c] == nil {
+			continue
+		}
+
+		remaining := make([]point, 0, len(points))
+		for i, p := range points {
+			if selected[c][i] {
+				nodePoints = append(nodePoints, p)
+			} else {
+				remaining = append(remaining, p)
+			}
+		}
+
+		// Everything moved up, leaving nothing behind
+		if len(remaining) == 0 && children[c].leaf() {
+			continue
+		}
+
+		if err := ob.writePoints(children[c], remaining); err != nil {
+			return nil, nil, err
+		}
+		node.children[c] = children[c]
+	}
+
+	return node, nodePoints, nil
+}
+
+func clampCell(v int) int {
+	return max(0, min(samplingGridSize-1, v))
+}
+
+// load reads every point spilled to disk into memory, removing the spill
+func (ob *octreeBuilder) load(source spill) ([]point, error) {
+	defer os.Remove(source.path)
+
+	data, err := os.ReadFile(source.path)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]point, len(data)/ob.bytesPerPoint)
+	for i := range points {
+		record := data[i*ob.bytesPerPoint : (i+1)*ob.bytesPerPoint]
+		points[i] = point{
+			position: ob.decodePosition(record),
+			record:   record,
+		}
+	}
+	return points, nil
+}
+
+// split streams the points spilled to disk into a spill for each octant of
+// the bounds, removing the original spill
+func (ob *octreeBuilder) split(bounds geometry.AABB, source spill) (octants [8]spill, err error) {
+	defer os.Remove(source.path)
+
+	in, err := os.Open(source.path)
+	if err != nil {
+		return octants, err
+	}
+	defer in.Close()
+
+	var files [8]*os.File
+	var writers [8]*bufio.Writer
+	defer func() {
+		for i, f := range files {
+			if f == nil {
+				continue
+			}
+			if flushErr := writers[i].Flush(); err == nil {
+				err = flushErr
+			}
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(f.Name())
+			}
+		}
+	}()
+
+	for i := range files {
+		files[i], err = os.CreateTemp(ob.dir, "potree-*.spill")
+		if err != nil {
+			return octants, err
+		}
+		writers[i] = bufio.NewWriter(files[i])
+		octants[i].path = files[i].Name()
+	}
+
+	reader := bufio.NewReader(in)
+	record := make([]byte, ob.bytesPerPoint)
+	for {
+		if _, err = io.ReadFull(reader, record); err != nil {
+			if err == io.EOF {
+				err = nil
+				break
+			}
+			return octants, err
+		}
+
+		i := childIndex(bounds, ob.decodePosition(record))
+		if _, err = writers[i].Write(record); err != nil {
+			return octants, err
+		}
+		octants[i].count++
+	}
+
+	return octants, nil
+}
+
+// hierarchyChunk is a section of the hierarchy that's loaded all at once,
+// spanning a number of levels of the octree. Nodes on the last level of a
+// chunk that have children are proxies, pointing to the chunk they start.
+type hierarchyChunk struct {
+	nodes  []*hierarchyNode
+	offset uint64
+}
+
+func (hc hierarchyChunk) size() uint64 {
+	return uint64(len(hc.nodes)) * 22
+}
+
+// hierarchyChunks breaks the hierarchy up into chunks, each listing its
+// nodes breadth first, as Potree's loader expects
+func hierarchyChunks(root *hierarchyNode, stepSize int) ([]*hierarchyChunk, map[*hierarchyNode]*hierarchyChunk) {
+	chunks := make([]*hierarchyChunk, 0)
+	lookup := make(map[*hierarchyNode]*hierarchyChunk)
+
+	offset := uint64(0)
+	roots := []*hierarchyNode{root}
+	for len(roots) > 0 {
+		chunkRoot := roots[0]
+		roots = roots[1:]
+
+		chunk := &hierarchyChunk{
+			nodes:  []*hierarchyNode{chunkRoot},
+			offset: offset,
+		}
+
+		for i := 0; i < len(chunk.nodes); i++ {
+			node := chunk.nodes[i]
+			if node != chunkRoot && node.level-chunkRoot.level == stepSize && !node.leaf() {
+				roots = append(roots, node)
+				continue
+			}
+
+			for _, child := range node.children {
+				if child != nil {
+					chunk.nodes = append(chunk.nodes, child)
+				}
+			}
+		}
+
+		offset += chunk.size()
+		chunks = append(chunks, chunk)
+		lookup[chunkRoot] = chunk
+	}
+
+	return chunks, lookup
+}
+
+// writeHierarchy writes hierarchy.bin, returning the size of the first chunk
+func (w *Writer) writeHierarchy(root *hierarchyNode) (uint64, error) {
+	f, err := os.Create(filepath.Join(w.dir, "hierarchy.bin"))
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	out := bufio.NewWriter(f)
+	chunks, lookup := hierarchyChunks(root, w.options.HierarchyStepSize)
+	for _, chunk := range chunks {
+		for i, node := range chunk.nodes {
+			entry := HierarchyNodeEntry{
+				ChildMask:  node.childMask(),
+				NumPoints:  node.numPoints,
+				ByteOffset: node.byteOffset,
+				ByteSize:   node.byteSize,
+			}
+
+			if node.leaf() {
+				entry.Type = 1
+			}
+
+			if proxied, ok := lookup[node]; ok && i > 0 {
+				entry.Type = 2
+				entry.ByteOffset = proxied.offset
+				entry.ByteSize = proxied.size()
+			}
+
+			if err := binary.Write(out, binary.LittleEndian, entry); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := out.Flush(); err != nil {
+		return 0, err
+	}
+	return chunks[0].size(), f.Close()
+}
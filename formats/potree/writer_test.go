@@ -0,0 +1,210 @@
+package potree_test
+
+import (
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/EliCDavis/polyform/formats/potree"
+	"github.com/EliCDavis/polyform/math/geometry"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomCloud(r *rand.Rand, count int) modeling.Mesh {
+	positions := make([]vector3.Float64, count)
+	colors := make([]vector3.Float64, count)
+	intensity := make([]float64, count)
+	for i := range positions {
+		positions[i] = vector3.New(r.Float64()*10, r.Float64()*5, r.Float64()*2).Add(vector3.New(-3., 1., 0.5))
+		colors[i] = vector3.New(float64(i%256), float64((i/256)%256), 0).DivByConstant(255)
+		intensity[i] = float64(i)
+	}
+
+	return modeling.NewPointCloud(
+		nil,
+		map[string][]vector3.Float64{
+			modeling.PositionAttribute: positions,
+			modeling.ColorAttribute:    colors,
+		},
+		nil,
+		map[string][]float64{
+			"Intensity": intensity,
+		},
+		nil,
+	)
+}
+
+type writtenPoint struct {
+	position  vector3.Float64
+	intensity float64
+}
+
+// readOctree loads every point written, checking each falls within the node
+// it was written to
+func readOctree(t *testing.T, dir string) (*potree.Metadata, *potree.OctreeNode, []writtenPoint) {
+	t.Helper()
+
+	metadata, err := potree.LoadMetadata(filepath.Join(dir, "metadata.json"))
+	require.NoError(t, err)
+
+	root, err := metadata.LoadHierarchy(filepath.Join(dir, "hierarchy.bin"))
+	require.NoError(t, err)
+
+	octree, err := os.Open(filepath.Join(dir, "octree.bin"))
+	require.NoError(t, err)
+	defer octree.Close()
+
+	_, intensityOffset := metadata.Attribute("Intensity")
+	require.GreaterOrEqual(t, intensityOffset, 0)
+
+	points := make([]writtenPoint, 0)
+	root.Walk(func(node *potree.OctreeNode) bool {
+		buf := make([]byte, node.ByteSize)
+		_, err := node.Read(octree, buf)
+		require.NoError(t, err)
+
+		positions := make([]vector3.Float64, node.NumPoints)
+		potree.LoadNodePositionDataIntoArray(metadata, buf, positions)
+		// Bounds are reconstructed from their center, so give them some slack
+		bounds := geometry.NewAABB(node.BoundingBox.Center(), node.BoundingBox.Size().Add(vector3.Fill(1e-9)))
+		for i, p := range positions {
+			assert.True(t, bounds.Contains(p), "%s does not contain %v", node.Name, p)
+
+			bits := uint64(0)
+			for b := 0; b < 8; b++ {
+				bits |= uint64(buf[i*metadata.BytesPerPoint()+intensityOffset+b]) << (8 * b)
+			}
+			points = append(points, writtenPoint{position: p, intensity: math.Float64frombits(bits)})
+		}
+		return true
+	})
+
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].intensity < points[j].intensity
+	})
+	return metadata, root, points
+}
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	cloud := randomCloud(rand.New(rand.NewSource(42)), 20_000)
+
+	options := potree.DefaultWriterOptions
+	options.Name = "test"
+	options.MaxPointsPerNode = 500
+	options.MaxPointsInMemory = 4_000
+	options.HierarchyStepSize = 2
+	require.NoError(t, potree.WriteWithOptions(dir, cloud, options))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name()
+	}
+	assert.Equal(t, []string{"hierarchy.bin", "metadata.json", "octree.bin"}, names)
+
+	metadata, root, points := readOctree(t, dir)
+	assert.Equal(t, "2.0", metadata.Version)
+	assert.Equal(t, "test", metadata.Name)
+	assert.Equal(t, "DEFAULT", metadata.Encoding)
+	assert.Equal(t, int64(20_000), metadata.Points)
+	bounds := cloud.BoundingBox(modeling.PositionAttribute)
+	assert.Equal(t, bounds.Min(), metadata.BoundingBox.MinF())
+	assert.Equal(t, bounds.Min().Add(vector3.Fill(bounds.Size().X())), metadata.BoundingBox.MaxF())
+	assert.Equal(t, root.Height(), metadata.Hierarchy.Depth)
+	assert.Greater(t, metadata.Hierarchy.Depth, options.HierarchyStepSize)
+
+	names = make([]string, len(metadata.Attributes))
+	for i, attr := range metadata.Attributes {
+		names[i] = attr.Name
+	}
+	assert.Equal(t, []string{"position", "rgb", "Intensity"}, names)
+	assert.Equal(t, 12+6+8, metadata.BytesPerPoint())
+
+	// Every point makes it in exactly once
+	assert.Equal(t, uint64(20_000), root.PointCount())
+	require.Len(t, points, 20_000)
+	positions := cloud.Float3Attribute(modeling.PositionAttribute)
+	for i, p := range points {
+		require.Equal(t, float64(i), p.intensity)
+		require.InDelta(t, 0, positions.At(i).Distance(p.position), 0.002)
+	}
+
+	// Levels of detail are subsampled from the levels below
+	assert.Greater(t, root.NumPoints, uint32(0))
+	for _, child := range root.Children {
+		assert.Less(t, child.Spacing, root.Spacing)
+	}
+}
+
+func TestWriter_Chunks(t *testing.T) {
+	dir := t.TempDir()
+	r := rand.New(rand.NewSource(7))
+
+	bounds := geometry.NewAABBFromPoints(vector3.New(-3., 1., 0.5), vector3.New(7., 6., 2.5))
+	options := potree.DefaultWriterOptions
+	options.MaxPointsPerNode = 200
+	options.MaxPointsInMemory = 1_000
+
+	writer, err := potree.NewWriter(dir, bounds, options)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, writer.Add(randomCloud(r, 1_000)))
+	}
+	require.NoError(t, writer.Close())
+
+	metadata, root, _ := readOctree(t, dir)
+	assert.Equal(t, int64(5_000), metadata.Points)
+	assert.Equal(t, uint64(5_000), root.PointCount())
+	assert.Error(t, writer.Close())
+}
+
+func TestWriter_Empty(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, potree.Write(dir, modeling.EmptyPointcloud()))
+
+	metadata, err := potree.LoadMetadata(filepath.Join(dir, "metadata.json"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), metadata.Points)
+
+	root, err := metadata.LoadHierarchy(filepath.Join(dir, "hierarchy.bin"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), root.PointCount())
+}
+
+func TestWriter_Errors(t *testing.T) {
+	bounds := geometry.NewAABBFromPoints(vector3.Zero[float64](), vector3.One[float64]())
+
+	_, err := potree.NewWriter(t.TempDir(), bounds, potree.WriterOptions{})
+	assert.EqualError(t, err, "scale must be greater than 0, got 0")
+
+	options := potree.DefaultWriterOptions
+	options.Scale = 1e-12
+	_, err = potree.NewWriter(t.TempDir(), bounds, options)
+	assert.EqualError(t, err, "point cloud spanning 1 units can not be represented with a scale of 1e-12")
+
+	writer, err := potree.NewWriter(t.TempDir(), bounds, potree.DefaultWriterOptions)
+	require.NoError(t, err)
+
+	assert.EqualError(t, writer.Add(modeling.EmptyMesh(modeling.TriangleTopology)), "mesh must be point topology, was instead triangle")
+
+	outside := modeling.NewPointCloud(nil, map[string][]vector3.Float64{
+		modeling.PositionAttribute: {vector3.New(2., 0., 0.)},
+	}, nil, nil, nil)
+	assert.EqualError(t, writer.Add(outside), "position (2, 0, 0) falls outside the bounds of the octree")
+
+	colored := randomCloud(rand.New(rand.NewSource(1)), 1).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{vector3.Fill(0.5)})
+	require.NoError(t, writer.Add(colored))
+
+	uncolored := colored.SetFloat3Attribute(modeling.ColorAttribute, nil)
+	assert.EqualError(t, writer.Add(uncolored), "required attribute not present on mesh: Color")
+	require.NoError(t, writer.Close())
+}
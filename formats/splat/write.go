@@ -13,6 +13,10 @@ import (
 
 const SH_C0 = 0.28209479177387814
 
+// Write serializes the mesh in the SPLAT format. SPLAT only has room for the
+// DC term of the spherical harmonics, so any coefficients past it
+// (modeling.SHAttribute) are dropped.
+//
 // https://github.com/antimatter15/splat/blob/main/convert.py#L10
 func Write(out io.Writer, mesh modeling.Mesh) error {
 
@@ -12,6 +12,10 @@ import (
 
 const magicNum uint32 = 0x5053474e
 
+// Smallest opacity read, well under what a byte can represent, so the logit
+// stays finite
+const minAlpha = 1e-6
+
 type Header struct {
 	Magic          uint32 `json:"magic"`     // Must be 0x5053474e (NGSP = Niantic gaussian splat)
 	Version        uint32 `json:"version"`   // Must be 2
@@ -149,7 +153,10 @@ func (pgh Header) readRotations(in io.Reader) ([]vector4.Float64, error) {
 			(float64(rotationData[i3+1])*scale)-1,
 			(float64(rotationData[i3+2])*scale)-1,
 		)
-		rotations[i] = vector4.New(v.X(), v.Y(), v.Z(), math.Sqrt(math.Max(0, 1-v.Dot(v))))
+		w := math.Sqrt(math.Max(0, 1-v.Dot(v)))
+
+		// Rotations are (w, x, y, z), with the real component stored in X
+		rotations[i] = vector4.New(w, v.X(), v.Y(), v.Z())
 	}
 	return rotations, nil
 
@@ -193,12 +200,10 @@ func (pgh Header) readAlphas(in io.Reader) ([]float64, error) {
 
 	alphas := make([]float64, pgh.NumPoints)
 	for i := 0; i < len(alphas); i++ {
-		x := float64(alpha[i]) / 255.
-
-		// I feel like this is right but it looks worse when I put this in
-		// alphas[i] = math.Log(x / (1.0 - x))
-
-		alphas[i] = x
+		// Opacity is kept pre-sigmoid, clamped away from 0 and 1 so fully
+		// transparent and opaque splats don't become infinite
+		x := math.Max(minAlpha, math.Min(1-minAlpha, float64(alpha[i])/255.))
+		alphas[i] = math.Log(x / (1.0 - x))
 	}
 	return alphas, nil
 }
@@ -0,0 +1,287 @@
+package spz_test
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/polyform/formats/ply"
+	"github.com/EliCDavis/polyform/formats/spz"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vector3InDelta(t *testing.T, a, b vector3.Float64, delta float64) {
+	assert.InDelta(t, a.X(), b.X(), delta)
+	assert.InDelta(t, a.Y(), b.Y(), delta)
+	assert.InDelta(t, a.Z(), b.Z(), delta)
+}
+
+func vector4InDelta(t *testing.T, a, b vector4.Float64, delta float64) {
+	assert.InDelta(t, a.X(), b.X(), delta)
+	assert.InDelta(t, a.Y(), b.Y(), delta)
+	assert.InDelta(t, a.Z(), b.Z(), delta)
+	assert.InDelta(t, a.W(), b.W(), delta)
+}
+
+func testCloud(shDim int) modeling.Mesh {
+	v3Data := map[string][]vector3.Vector[float64]{
+		modeling.PositionAttribute: {
+			vector3.New(0., 1., 2.),
+			vector3.New(-100.125, 0.5, 1000.),
+		},
+		modeling.ScaleAttribute: {
+			vector3.New(-4., -2., 0.),
+			vector3.New(1., 2., 3.),
+		},
+		modeling.FDCAttribute: {
+			vector3.New(0., 0.5, 1),
+			vector3.New(-1., -2, 3),
+		},
+	}
+
+	for i := 0; i < shDim; i++ {
+		v := float64(i) / float64(shDim)
+		v3Data[modeling.SHAttribute(i)] = []vector3.Float64{
+			vector3.New(v, -v, 0.5),
+			vector3.New(-0.25, 0.25, -v),
+		}
+	}
+
+	return modeling.NewPointCloud(
+		map[string][]vector4.Vector[float64]{
+			modeling.RotationAttribute: {
+				vector4.New(.8, 0., .2, .4).Normalized(),
+				// A negative real part gets flipped to the equivalent rotation
+				vector4.New(-.5, .5, .5, .5),
+			},
+		},
+		v3Data,
+		nil,
+		map[string][]float64{
+			modeling.OpacityAttribute: {0, 2},
+		},
+		nil,
+	)
+}
+
+func TestWrite_ErrorOnNonPointcloud(t *testing.T) {
+	in := modeling.
+		NewTriangleMesh([]int{1, 2, 3}).
+		SetFloat1Attribute("blah", []float64{1, 2, 3})
+	err := spz.Write(nil, in)
+	assert.EqualError(t, err, "mesh must be point topology, was instead triangle")
+}
+
+func TestWrite_ErrorOnMissingPosition(t *testing.T) {
+	in := modeling.
+		NewMesh(modeling.PointTopology, []int{0}).
+		SetFloat1Attribute("blah", []float64{1})
+	err := spz.Write(&bytes.Buffer{}, in)
+	assert.EqualError(t, err, "required attribute not present on mesh: Position")
+}
+
+func TestWrite_ErrorOnPositionOutOfRange(t *testing.T) {
+	in := modeling.NewPointCloud(nil, map[string][]vector3.Vector[float64]{
+		modeling.PositionAttribute: {vector3.New(0., 5000., 0.)},
+	}, nil, nil, nil)
+
+	err := spz.Write(&bytes.Buffer{}, in)
+	assert.EqualError(t, err, "position 0 (0, 5000, 0) can not be represented with 12 fractional bits")
+
+	opts := spz.DefaultWriterOptions
+	opts.FractionalBits = 8
+	assert.NoError(t, spz.WriteWithOptions(&bytes.Buffer{}, in, opts))
+}
+
+func TestWrite_EmptyCloud(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, spz.Write(buf, modeling.EmptyPointcloud()))
+
+	cloud, err := spz.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), cloud.Header.NumPoints)
+	assert.Equal(t, 0, cloud.Mesh.PrimitiveCount())
+}
+
+func TestReadWrite(t *testing.T) {
+	for degree, shDim := range []int{0, 3, 8, 15} {
+		t.Run(fmt.Sprintf("degree %d", degree), func(t *testing.T) {
+			in := testCloud(shDim)
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, spz.Write(buf, in))
+
+			header, err := spz.ReadHeader(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			assert.Equal(t, uint32(2), header.Version)
+			assert.Equal(t, uint32(2), header.NumPoints)
+			assert.Equal(t, uint8(degree), header.ShDegree)
+			assert.Equal(t, uint8(12), header.FractionalBits)
+
+			cloud, err := spz.Read(bytes.NewReader(buf.Bytes()))
+			require.NoError(t, err)
+			out := cloud.Mesh
+			require.Equal(t, 2, out.PrimitiveCount())
+
+			for i := 0; i < 2; i++ {
+				vector3InDelta(
+					t,
+					in.Float3Attribute(modeling.PositionAttribute).At(i),
+					out.Float3Attribute(modeling.PositionAttribute).At(i),
+					1./4096,
+				)
+
+				vector3InDelta(
+					t,
+					in.Float3Attribute(modeling.ScaleAttribute).At(i),
+					out.Float3Attribute(modeling.ScaleAttribute).At(i),
+					1./32,
+				)
+
+				vector3InDelta(
+					t,
+					in.Float3Attribute(modeling.FDCAttribute).At(i),
+					out.Float3Attribute(modeling.FDCAttribute).At(i),
+					.02,
+				)
+
+				assert.InDelta(
+					t,
+					in.Float1Attribute(modeling.OpacityAttribute).At(i),
+					out.Float1Attribute(modeling.OpacityAttribute).At(i),
+					.05,
+				)
+
+				for d := 0; d < shDim; d++ {
+					attr := modeling.SHAttribute(d)
+					vector3InDelta(t, in.Float3Attribute(attr).At(i), out.Float3Attribute(attr).At(i), 1./16)
+				}
+				assert.False(t, out.HasFloat3Attribute(modeling.SHAttribute(shDim)))
+			}
+
+			vector4InDelta(
+				t,
+				in.Float4Attribute(modeling.RotationAttribute).At(0),
+				out.Float4Attribute(modeling.RotationAttribute).At(0),
+				.02,
+			)
+			vector4InDelta(
+				t,
+				in.Float4Attribute(modeling.RotationAttribute).At(1).Scale(-1),
+				out.Float4Attribute(modeling.RotationAttribute).At(1),
+				.02,
+			)
+		})
+	}
+}
+
+func TestReadWrite_PlyConventions(t *testing.T) {
+	// Rotations are (w, x, y, z) with rot_0 the real part, and opacities are
+	// pre-sigmoid, the same as 3D Gaussian Splatting's PLY files
+	plyData := `ply
+format ascii 1.0
+element vertex 3
+property float x
+property float y
+property float z
+property float opacity
+property float rot_0
+property float rot_1
+property float rot_2
+property float rot_3
+end_header
+0 0 0 -3 1 0 0 0
+1 0 0 0 0.7071068 0.7071068 0 0
+0 1 0 4 0.5 0.5 -0.5 -0.5
+`
+
+	in, err := ply.ReadMesh(strings.NewReader(plyData))
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, spz.Write(buf, *in))
+	cloud, err := spz.Read(buf)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		vector4InDelta(
+			t,
+			in.Float4Attribute(modeling.RotationAttribute).At(i),
+			cloud.Mesh.Float4Attribute(modeling.RotationAttribute).At(i),
+			.02,
+		)
+		assert.InDelta(
+			t,
+			in.Float1Attribute(modeling.OpacityAttribute).At(i),
+			cloud.Mesh.Float1Attribute(modeling.OpacityAttribute).At(i),
+			.1,
+		)
+	}
+}
+
+func TestRead_OpacityStaysFinite(t *testing.T) {
+	in := modeling.NewPointCloud(
+		nil,
+		map[string][]vector3.Float64{
+			modeling.PositionAttribute: {vector3.Zero[float64](), vector3.Zero[float64]()},
+		},
+		nil,
+		map[string][]float64{
+			modeling.OpacityAttribute: {-100, 100},
+		},
+		nil,
+	)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, spz.Write(buf, in))
+	cloud, err := spz.Read(buf)
+	require.NoError(t, err)
+
+	opacities := cloud.Mesh.Float1Attribute(modeling.OpacityAttribute)
+	assert.Less(t, opacities.At(0), -10.)
+	assert.Greater(t, opacities.At(1), 10.)
+	assert.False(t, math.IsInf(opacities.At(0), 0))
+	assert.False(t, math.IsInf(opacities.At(1), 0))
+}
+
+func TestWriteWithOptions(t *testing.T) {
+	opts := spz.WriterOptions{
+		FractionalBits: 4,
+		MaxShDegree:    1,
+		Antialiased:    true,
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, spz.WriteWithOptions(buf, testCloud(15), opts))
+
+	cloud, err := spz.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(1), cloud.Header.ShDegree)
+	assert.Equal(t, uint8(4), cloud.Header.FractionalBits)
+	assert.Equal(t, spz.FlagAntialiased, cloud.Header.Flags)
+
+	assert.True(t, cloud.Mesh.HasFloat3Attribute(modeling.SHAttribute(2)))
+	assert.False(t, cloud.Mesh.HasFloat3Attribute(modeling.SHAttribute(3)))
+
+	// -100.125 is exactly representable with 4 fractional bits, 0.5 too
+	vector3InDelta(t, vector3.New(-100.125, 0.5, 1000.), cloud.Mesh.Float3Attribute(modeling.PositionAttribute).At(1), 0)
+}
+
+func TestArtifactNode(t *testing.T) {
+	artifact, err := spz.ArtifactNodeData{}.Process()
+	require.NoError(t, err)
+	assert.Equal(t, "application/octet-stream", artifact.Mime())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, artifact.Write(buf))
+
+	cloud, err := spz.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(0), cloud.Header.NumPoints)
+}
@@ -4,7 +4,6 @@ import (
 	"bufio"
 	"compress/gzip"
 	"encoding/binary"
-	"fmt"
 	"io"
 	"math"
 	"os"
@@ -14,22 +13,6 @@ import (
 	"github.com/EliCDavis/vector/vector4"
 )
 
-func degreeForDim(dim int) int {
-	if dim < 3 {
-		return 0
-	}
-
-	if dim < 8 {
-		return 1
-	}
-
-	if dim < 15 {
-		return 2
-	}
-
-	return 3
-}
-
 // https://github.com/aras-p/UnityGaussianSplatting/blob/main/package/Shaders/GaussianSplatting.hlsl#L139
 /*
 half3 ShadeSH(SplatSHData splat, half3 dir, int shOrder, bool onlySH)
@@ -202,7 +185,7 @@ func Read(inUncompressed io.Reader) (*Cloud, error) {
 	}
 
 	for i, h := range sh {
-		v3Data[fmt.Sprintf("SH_%d", i)] = h
+		v3Data[modeling.SHAttribute(i)] = h
 	}
 
 	/*
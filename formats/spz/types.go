@@ -2,8 +2,11 @@ package spz
 
 import (
 	"bytes"
+	"fmt"
+	"io"
 
 	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/generator/artifact"
 	"github.com/EliCDavis/polyform/modeling"
 	"github.com/EliCDavis/polyform/nodes"
 	"github.com/EliCDavis/polyform/refutil"
@@ -12,6 +15,7 @@ import (
 func init() {
 	factory := &refutil.TypeFactory{}
 	refutil.RegisterType[ReadNode](factory)
+	refutil.RegisterType[ArtifactNode](factory)
 	generator.RegisterTypes(factory)
 }
 
@@ -38,3 +42,53 @@ func (gad ReadNodeData) Process() (modeling.Mesh, error) {
 
 	return cloud.Mesh, nil
 }
+
+type Artifact struct {
+	Mesh    modeling.Mesh
+	Options WriterOptions
+}
+
+func (sa Artifact) Write(w io.Writer) error {
+	return WriteWithOptions(w, sa.Mesh, sa.Options)
+}
+
+func (Artifact) Mime() string {
+	return "application/octet-stream"
+}
+
+type ArtifactNode = nodes.Struct[artifact.Artifact, ArtifactNodeData]
+
+type ArtifactNodeData struct {
+	In             nodes.NodeOutput[modeling.Mesh]
+	FractionalBits nodes.NodeOutput[int]
+	MaxShDegree    nodes.NodeOutput[int]
+	Antialiased    nodes.NodeOutput[bool]
+}
+
+func (pn ArtifactNodeData) Description() string {
+	return "Niantic Scaniverse's compressed SPZ gaussian splat format"
+}
+
+func (pn ArtifactNodeData) Process() (artifact.Artifact, error) {
+	fractionalBits := nodes.TryGetOutputValue(pn.FractionalBits, int(DefaultWriterOptions.FractionalBits))
+	if fractionalBits < 0 || fractionalBits > 23 {
+		return nil, fmt.Errorf("fractional bits must be between 0 and 23, got %d", fractionalBits)
+	}
+
+	return Artifact{
+		Mesh: nodes.TryGetOutputValue(pn.In, modeling.EmptyMesh(modeling.PointTopology)),
+		Options: WriterOptions{
+			FractionalBits: uint8(fractionalBits),
+			MaxShDegree:    nodes.TryGetOutputValue(pn.MaxShDegree, DefaultWriterOptions.MaxShDegree),
+			Antialiased:    nodes.TryGetOutputValue(pn.Antialiased, DefaultWriterOptions.Antialiased),
+		},
+	}, nil
+}
+
+func NewArtifactNode(meshNode nodes.NodeOutput[modeling.Mesh]) nodes.NodeOutput[artifact.Artifact] {
+	return (&ArtifactNode{
+		Data: ArtifactNodeData{
+			In: meshNode,
+		},
+	}).Out()
+}
@@ -1,20 +1,268 @@
 package spz
 
 import (
+	"compress/gzip"
 	"encoding/binary"
+	"fmt"
 	"io"
+	"math"
 
 	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
 )
 
-func Write(cloud modeling.Mesh, out io.Writer) error {
+// FlagAntialiased marks splats trained with antialiasing (mip-splatting)
+const FlagAntialiased uint8 = 0x1
+
+// Bits of precision kept for each SH band, matching Niantic's encoder
+const (
+	sh1Bits    = 5
+	shRestBits = 4
+)
+
+type WriterOptions struct {
+	// Number of bits of the 24-bit fixed point positions spent on the
+	// fractional part. 12 bits gives a precision of ~0.25mm across a range
+	// of ±2048 units
+	FractionalBits uint8
+
+	// Highest SH degree to write. Coefficients found on the mesh past this
+	// degree are dropped
+	MaxShDegree int
+
+	Antialiased bool
+}
+
+var DefaultWriterOptions = WriterOptions{
+	FractionalBits: 12,
+	MaxShDegree:    3,
+}
+
+// Serialize the gaussian splat to the writer using the default options
+func Write(out io.Writer, cloud modeling.Mesh) error {
+	return WriteWithOptions(out, cloud, DefaultWriterOptions)
+}
+
+// Serialize the gaussian splat to the writer. Attributes are interpreted the
+// same way Read produces them, with everything but position being optional.
+func WriteWithOptions(out io.Writer, cloud modeling.Mesh, options WriterOptions) error {
+	// https://github.com/nianticlabs/spz/blob/main/src/cc/load-spz.cc#L154
+	if cloud.Topology() != modeling.PointTopology {
+		return fmt.Errorf("mesh must be point topology, was instead %s", cloud.Topology())
+	}
+
+	if options.FractionalBits > 23 {
+		return fmt.Errorf("fractional bits must be between 0 and 23, got %d", options.FractionalBits)
+	}
+
+	if options.MaxShDegree < 0 || options.MaxShDegree > 3 {
+		return fmt.Errorf("unsupported SH degree: %d", options.MaxShDegree)
+	}
+
+	count := cloud.PrimitiveCount()
+	if count > 0 && !cloud.HasFloat3Attribute(modeling.PositionAttribute) {
+		return fmt.Errorf("required attribute not present on mesh: %s", modeling.PositionAttribute)
+	}
+
 	header := Header{
 		Magic:          magicNum,
 		Version:        2,
-		NumPoints:      uint32(cloud.PrimitiveCount()),
-		ShDegree:       0,
-		FractionalBits: 3,
+		NumPoints:      uint32(count),
+		ShDegree:       uint8(min(modeling.SHDegree(cloud), options.MaxShDegree)),
+		FractionalBits: options.FractionalBits,
+	}
+	if options.Antialiased {
+		header.Flags |= FlagAntialiased
+	}
+
+	if err := header.Validate(); err != nil {
+		return err
+	}
+
+	compressed := gzip.NewWriter(out)
+	if err := binary.Write(compressed, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	if count > 0 {
+		writers := []func(io.Writer, modeling.Mesh) error{
+			header.writePositions,
+			header.writeAlphas,
+			header.writeColors,
+			header.writeScales,
+			header.writeRotations,
+			header.writeSh,
+		}
+
+		for _, write := range writers {
+			if err := write(compressed, cloud); err != nil {
+				return err
+			}
+		}
+	}
+
+	return compressed.Close()
+}
+
+func toUint8(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(255, v))))
+}
+
+func quantizeSH(x float64, bucketSize int) uint8 {
+	q := int(math.Round(x*128)) + 128
+	q = ((q + bucketSize/2) / bucketSize) * bucketSize
+	return uint8(max(0, min(255, q)))
+}
+
+func float3Data(cloud modeling.Mesh, attribute string, fallback vector3.Float64) func(i int) vector3.Float64 {
+	if !cloud.HasFloat3Attribute(attribute) {
+		return func(int) vector3.Float64 { return fallback }
+	}
+	data := cloud.Float3Attribute(attribute)
+	return data.At
+}
+
+func (pgh Header) writePositions(out io.Writer, cloud modeling.Mesh) error {
+	// Encode 24-bit fixed point coordinates
+	const maxFixed = 1<<23 - 1
+	const minFixed = -(1 << 23)
+	scale := float64(int(1) << pgh.FractionalBits)
+
+	positions := cloud.Float3Attribute(modeling.PositionAttribute)
+	positionData := make([]byte, pgh.NumPoints*9)
+	for i := 0; i < int(pgh.NumPoints); i++ {
+		p := positions.At(i)
+		for c := 0; c < 3; c++ {
+			fixed := math.Round(p.Component(c) * scale)
+			if fixed > maxFixed || fixed < minFixed || math.IsNaN(fixed) {
+				return fmt.Errorf("position %d (%g, %g, %g) can not be represented with %d fractional bits", i, p.X(), p.Y(), p.Z(), pgh.FractionalBits)
+			}
+
+			fixed32 := int32(fixed)
+			i9 := i*9 + c*3
+			positionData[i9+0] = byte(fixed32)
+			positionData[i9+1] = byte(fixed32 >> 8)
+			positionData[i9+2] = byte(fixed32 >> 16)
+		}
+	}
+
+	_, err := out.Write(positionData)
+	return err
+}
+
+func (pgh Header) writeAlphas(out io.Writer, cloud modeling.Mesh) error {
+	alphaData := make([]byte, pgh.NumPoints)
+	if !cloud.HasFloat1Attribute(modeling.OpacityAttribute) {
+		for i := range alphaData {
+			alphaData[i] = 255
+		}
+	} else {
+		alphas := cloud.Float1Attribute(modeling.OpacityAttribute)
+		for i := range alphaData {
+			// Stored post-sigmoid
+			alphaData[i] = toUint8(255 / (1 + math.Exp(-alphas.At(i))))
+		}
+	}
+
+	_, err := out.Write(alphaData)
+	return err
+}
+
+func (pgh Header) writeColors(out io.Writer, cloud modeling.Mesh) error {
+	colors := float3Data(cloud, modeling.FDCAttribute, vector3.Zero[float64]())
+
+	colorData := make([]byte, pgh.NumPoints*3)
+	for i := 0; i < int(pgh.NumPoints); i++ {
+		c := colors(i).Scale(0.15).Add(vector3.Fill(0.5)).Scale(255)
+		i3 := i * 3
+		colorData[i3+0] = toUint8(c.X())
+		colorData[i3+1] = toUint8(c.Y())
+		colorData[i3+2] = toUint8(c.Z())
+	}
+
+	_, err := out.Write(colorData)
+	return err
+}
+
+func (pgh Header) writeScales(out io.Writer, cloud modeling.Mesh) error {
+	scales := float3Data(cloud, modeling.ScaleAttribute, vector3.Zero[float64]())
+
+	scaleData := make([]byte, pgh.NumPoints*3)
+	for i := 0; i < int(pgh.NumPoints); i++ {
+		s := scales(i).Add(vector3.Fill(10.)).Scale(16)
+		i3 := i * 3
+		scaleData[i3+0] = toUint8(s.X())
+		scaleData[i3+1] = toUint8(s.Y())
+		scaleData[i3+2] = toUint8(s.Z())
+	}
+
+	_, err := out.Write(scaleData)
+	return err
+}
+
+func (pgh Header) writeRotations(out io.Writer, cloud modeling.Mesh) error {
+	// Rotations are (w, x, y, z), with the real component stored in X
+	identity := vector4.New(1., 0., 0., 0.)
+	rotations := func(int) vector4.Float64 { return identity }
+	if cloud.HasFloat4Attribute(modeling.RotationAttribute) {
+		rotations = cloud.Float4Attribute(modeling.RotationAttribute).At
+	}
+
+	rotationData := make([]byte, pgh.NumPoints*3)
+	for i := 0; i < int(pgh.NumPoints); i++ {
+		r := rotations(i)
+		if r.Length() == 0 {
+			r = identity
+		}
+
+		// Only xyz is stored, with the reader assuming w is non-negative
+		r = r.Normalized()
+		if r.X() < 0 {
+			r = r.Scale(-1)
+		}
+
+		i3 := i * 3
+		rotationData[i3+0] = toUint8((r.Y() + 1) * 127.5)
+		rotationData[i3+1] = toUint8((r.Z() + 1) * 127.5)
+		rotationData[i3+2] = toUint8((r.W() + 1) * 127.5)
+	}
+
+	_, err := out.Write(rotationData)
+	return err
+}
+
+func (pgh Header) writeSh(out io.Writer, cloud modeling.Mesh) error {
+	shDim, err := pgh.ShDimensions()
+	if err != nil {
+		return err
+	}
+
+	if shDim == 0 {
+		return nil
+	}
+
+	sh := make([]func(int) vector3.Float64, shDim)
+	for d := range sh {
+		sh[d] = cloud.Float3Attribute(modeling.SHAttribute(d)).At
+	}
+
+	shData := make([]byte, pgh.NumPoints*3*uint32(shDim))
+	for i := 0; i < int(pgh.NumPoints); i++ {
+		for d := 0; d < shDim; d++ {
+			bucketSize := 1 << (8 - shRestBits)
+			if d < 3 {
+				bucketSize = 1 << (8 - sh1Bits)
+			}
+
+			v := sh[d](i)
+			i3 := d*3 + (i * 3 * shDim)
+			shData[i3+0] = quantizeSH(v.X(), bucketSize)
+			shData[i3+1] = quantizeSH(v.Y(), bucketSize)
+			shData[i3+2] = quantizeSH(v.Z(), bucketSize)
+		}
 	}
 
-	return binary.Write(out, binary.LittleEndian, header)
+	_, err = out.Write(shData)
+	return err
 }
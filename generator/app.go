@@ -2,20 +2,29 @@ package generator
 
 import (
 	"archive/zip"
+	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"log"
 	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
 	"text/template"
 	"time"
 
 	"github.com/EliCDavis/jbtf"
 	"github.com/EliCDavis/polyform/generator/artifact"
 	"github.com/EliCDavis/polyform/generator/cli"
+	"github.com/EliCDavis/polyform/generator/diff"
 	"github.com/EliCDavis/polyform/generator/graph"
 	"github.com/EliCDavis/polyform/generator/room"
 	"github.com/EliCDavis/polyform/generator/schema"
@@ -62,6 +71,18 @@ func (a *App) ApplySchema(jsonPayload []byte) error {
 	return a.graphInstance.ApplyAppSchema(jsonPayload)
 }
 
+// warnOnMigration logs the nodes that couldn't be migrated applying a graph
+// instead of treating it as a failure, as the rest of the graph was still
+// applied
+func warnOnMigration(err error) error {
+	var migrationErr *graph.MigrationError
+	if errors.As(err, &migrationErr) {
+		log.Printf("warning: %s\n", migrationErr.Error())
+		return nil
+	}
+	return err
+}
+
 func (a *App) Schema() []byte {
 	a.initGraphInstance()
 	g := schema.App{
@@ -83,7 +104,7 @@ func (a *App) Schema() []byte {
 	return data
 }
 
-func writeProducersToZip(path string, graph *graph.Instance, zw *zip.Writer) error {
+func writeProducersToZip(ctx context.Context, path string, graph *graph.Instance, zw *zip.Writer) error {
 	if graph == nil {
 		panic("can't zip nil graph")
 	}
@@ -92,13 +113,23 @@ func writeProducersToZip(path string, graph *graph.Instance, zw *zip.Writer) err
 		panic("can't write to nil zip writer")
 	}
 
+	// Evaluate everything up front so producers sharing nodes can make use of
+	// all workers
+	if err := graph.Evaluate(ctx); err != nil {
+		return err
+	}
+
 	for _, file := range graph.ProducerNames() {
+		artifact, err := graph.Artifact(ctx, file)
+		if err != nil {
+			return err
+		}
+
 		filePath := path + file
 		f, err := zw.Create(filePath)
 		if err != nil {
 			return err
 		}
-		artifact := graph.Artifact(file)
 		err = artifact.Write(f)
 		if err != nil {
 			return err
@@ -113,10 +144,10 @@ func (a App) initialize(set *flag.FlagSet) {
 	a.graphInstance.InitializeParameters(set)
 }
 
-func (a App) WriteZip(out io.Writer) error {
+func (a App) WriteZip(ctx context.Context, out io.Writer) error {
 	z := zip.NewWriter(out)
 
-	err := writeProducersToZip("", a.graphInstance, z)
+	err := writeProducersToZip(ctx, "", a.graphInstance, z)
 	if err != nil {
 		return err
 	}
@@ -124,6 +155,28 @@ func (a App) WriteZip(out io.Writer) error {
 	return z.Close()
 }
 
+// writeTimings lists how long each node took to process, slowest first
+func (a App) writeTimings(out io.Writer) error {
+	timings := a.graphInstance.Timings()
+	ids := make([]string, 0, len(timings))
+	for id := range timings {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return timings[ids[i]] > timings[ids[j]]
+	})
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, id := range ids {
+		name := id
+		if named, ok := a.graphInstance.Node(id).(nodes.Named); ok {
+			name = fmt.Sprintf("%s (%s)", id, named.Name())
+		}
+		fmt.Fprintf(w, "%s\t%s\n", name, timings[id])
+	}
+	return w.Flush()
+}
+
 //go:embed cli.tmpl
 var cliTemplate string
 
@@ -135,13 +188,26 @@ type appCLI struct {
 	Commands    []*cli.Command
 }
 
-func (a App) Generate(outputPath string) error {
-	for _, name := range a.graphInstance.ProducerNames() {
+func (a App) Generate(ctx context.Context, outputPath string) error {
+	return writeProducersToFolder(ctx, outputPath, a.graphInstance)
+}
+
+func writeProducersToFolder(ctx context.Context, outputPath string, graph *graph.Instance) error {
+	if err := graph.Evaluate(ctx); err != nil {
+		return err
+	}
+
+	for _, name := range graph.ProducerNames() {
+		arifact, err := graph.Artifact(ctx, name)
+		if err != nil {
+			return err
+		}
+
 		fp := path.Join(outputPath, name)
 
 		// Producer names are paths which can contain subfolders, so be sure
 		// the subfolders exist before creating the file
-		err := os.MkdirAll(filepath.Dir(fp), os.ModeDir)
+		err = os.MkdirAll(filepath.Dir(fp), os.ModeDir)
 		if err != nil {
 			return err
 		}
@@ -154,7 +220,6 @@ func (a App) Generate(outputPath string) error {
 		defer f.Close()
 
 		// Write data to file
-		arifact := a.graphInstance.Artifact(name)
 		err = arifact.Write(f)
 		if err != nil {
 			return err
@@ -245,10 +310,34 @@ func (a *App) Run(args []string) error {
 				generateCmd := flag.NewFlagSet("generate", flag.ExitOnError)
 				a.initialize(generateCmd)
 				folderFlag := generateCmd.String("folder", ".", "folder to save generated contents to")
+				timingsFlag := generateCmd.Bool("timings", false, "Whether or not to print how long each node took to process")
+				thumbnailsFlag := generateCmd.Int("thumbnails", 0, "Size in pixels of the PNG previews written next to each producer that can be previewed, 0 to skip")
+				cacheFlags := newCacheFlags(generateCmd)
 				if err := generateCmd.Parse(appState.Args); err != nil {
 					return err
 				}
-				return a.Generate(*folderFlag)
+
+				if err := cacheFlags.apply(a.graphInstance); err != nil {
+					return err
+				}
+
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer stop()
+
+				if err := a.Generate(ctx, *folderFlag); err != nil {
+					return err
+				}
+
+				if *thumbnailsFlag > 0 {
+					if err := writeThumbnailsToFolder(ctx, *folderFlag, a.graphInstance, *thumbnailsFlag); err != nil {
+						return err
+					}
+				}
+
+				if *timingsFlag {
+					return a.writeTimings(os.Stderr)
+				}
+				return nil
 			},
 		},
 		{
@@ -262,6 +351,8 @@ func (a *App) Run(args []string) error {
 				portFlag := editCmd.String("port", "8080", "port to serve over")
 
 				autoSave := editCmd.Bool("autosave", false, "Whether or not to save changes back to the graph loaded")
+				snapshotIntervalFlag := editCmd.Duration("snapshot.interval", 0, "How often to snapshot the graph loaded, if it's changed, to a folder next to it. 0 disables snapshots")
+				snapshotMaxFlag := editCmd.Int("snapshot.max", 20, "Maximum number of snapshots to keep, 0 for unlimited")
 				launchWebBrowser := editCmd.Bool("launch-browser", true, "Whether or not to open the web page in the web browser")
 
 				sslFlag := editCmd.Bool("ssl", false, "Whether or not to use SSL")
@@ -293,10 +384,26 @@ func (a *App) Run(args []string) error {
 					"Time allowed to write a message to the peer over a websocketed connection.",
 				)
 
+				cacheFlags := newCacheFlags(editCmd)
+
 				if err := editCmd.Parse(appState.Args); err != nil {
 					return err
 				}
 
+				if err := cacheFlags.apply(a.graphInstance); err != nil {
+					return err
+				}
+
+				var snapshotter *GraphSnapshotter
+				if *snapshotIntervalFlag > 0 {
+					if configFile == "" {
+						return errors.New("snapshots require a graph file to be loaded")
+					}
+
+					snapshotter = NewGraphSnapshotter(configFile, *snapshotIntervalFlag, *snapshotMaxFlag)
+					go snapshotter.Run(context.Background())
+				}
+
 				server := AppServer{
 					app:              a,
 					host:             *hostFlag,
@@ -304,8 +411,9 @@ func (a *App) Run(args []string) error {
 					webscene:         a.WebScene,
 					launchWebbrowser: *launchWebBrowser,
 
-					autosave:   *autoSave,
-					configPath: configFile,
+					autosave:    *autoSave,
+					configPath:  configFile,
+					snapshotter: snapshotter,
 
 					tls:      *sslFlag,
 					certPath: *certFlag,
@@ -321,6 +429,164 @@ func (a *App) Run(args []string) error {
 				return server.Serve()
 			},
 		},
+		{
+			Name:        "Serve",
+			Description: "Starts an http server exposing each producer, evaluated with the parameters provided per request",
+			Aliases:     []string{"serve"},
+			Run: func(appState *cli.RunState) error {
+				serveCmd := flag.NewFlagSet("serve", flag.ExitOnError)
+				a.initialize(serveCmd)
+				hostFlag := serveCmd.String("host", "localhost", "interface to bind to")
+				portFlag := serveCmd.String("port", "8080", "port to serve over")
+				cacheFlags := newCacheFlags(serveCmd)
+
+				if err := serveCmd.Parse(appState.Args); err != nil {
+					return err
+				}
+
+				c, err := cacheFlags.build()
+				if err != nil {
+					return err
+				}
+
+				return NewHeadlessServer(a, c).Serve(*hostFlag, *portFlag)
+			},
+		},
+		{
+			Name:        "Sweep",
+			Description: "Runs all producers once per variant of parameters, taken from a table and/or ranges of values, saving each variant to its own folder or zip",
+			Aliases:     []string{"sweep"},
+			Run: func(appState *cli.RunState) error {
+				sweepCmd := flag.NewFlagSet("sweep", flag.ContinueOnError)
+				a.initialize(sweepCmd)
+				tableFlag := sweepCmd.String("table", "", "Optional CSV or JSON file listing a variant per row, keyed by parameter name")
+				var ranges sweepRangesFlag
+				sweepCmd.Var(&ranges, "range", "Values to sweep a parameter across, as Name=a,b,c or Name=start:end:step. Can be repeated, running every combination")
+				outFlag := sweepCmd.String("out", "sweep", "folder to save each variant and the manifest to")
+				zipFlag := sweepCmd.Bool("zip", false, "Whether or not to write each variant to a zip instead of a folder")
+				workersFlag := sweepCmd.Int("workers", runtime.NumCPU(), "Number of variants to run at once")
+				cacheFlags := newCacheFlags(sweepCmd)
+
+				if err := sweepCmd.Parse(appState.Args); err != nil {
+					return err
+				}
+
+				var table []map[string]parameterOverride
+				if *tableFlag != "" {
+					var err error
+					table, err = readSweepTable(*tableFlag)
+					if err != nil {
+						return err
+					}
+				}
+
+				if len(table) == 0 && len(ranges) == 0 {
+					return errors.New("nothing to sweep, provide a table and/or ranges of values")
+				}
+
+				c, err := cacheFlags.build()
+				if err != nil {
+					return err
+				}
+
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer stop()
+
+				s := sweep{
+					builder: newInstanceBuilder(a, c),
+					out:     *outFlag,
+					zip:     *zipFlag,
+					workers: *workersFlag,
+				}
+				manifest, err := s.run(ctx, sweepVariants(table, ranges))
+				if err != nil {
+					return err
+				}
+				return writeSweepSummary(appState.Out, manifest)
+			},
+		},
+		{
+			Name:        "Diff",
+			Description: "Lists the parameters, connections, producers and everything else that changed between two graph files",
+			Aliases:     []string{"diff"},
+			Run: func(appState *cli.RunState) error {
+				diffCmd := flag.NewFlagSet("diff", flag.ExitOnError)
+				jsonFlag := diffCmd.Bool("json", false, "Whether or not to write the changes as JSON")
+
+				if err := diffCmd.Parse(appState.Args); err != nil {
+					return err
+				}
+
+				if diffCmd.NArg() != 2 {
+					return errors.New("diff requires the graph before and after, in that order")
+				}
+
+				return writeDiff(appState.Out, diffCmd.Arg(0), diffCmd.Arg(1), *jsonFlag)
+			},
+		},
+		{
+			Name:        "Merge",
+			Description: "Three way merges two graph files changed from a common base. Usable as a git merge driver with: merge -out %A %O %A %B",
+			Aliases:     []string{"merge"},
+			Run: func(appState *cli.RunState) error {
+				mergeCmd := flag.NewFlagSet("merge", flag.ExitOnError)
+				outFlag := mergeCmd.String("out", "", "Optional path to file to write the merged graph to")
+				favorFlag := mergeCmd.String("favor", "ours", "Side to take when both changed the same thing, either ours or theirs")
+
+				if err := mergeCmd.Parse(appState.Args); err != nil {
+					return err
+				}
+
+				if mergeCmd.NArg() != 3 {
+					return errors.New("merge requires the base graph, our graph, and their graph, in that order")
+				}
+
+				var favor diff.Side
+				switch *favorFlag {
+				case "ours":
+					favor = diff.Ours
+				case "theirs":
+					favor = diff.Theirs
+				default:
+					return fmt.Errorf("unrecognized side to favor %q, expected ours or theirs", *favorFlag)
+				}
+
+				data, conflicts, err := mergeGraphs(mergeCmd.Arg(0), mergeCmd.Arg(1), mergeCmd.Arg(2), favor)
+				if err != nil {
+					return err
+				}
+
+				if *outFlag != "" {
+					err = os.WriteFile(*outFlag, data, 0666)
+				} else {
+					_, err = appState.Out.Write(data)
+				}
+				if err != nil {
+					return err
+				}
+
+				if len(conflicts) == 0 {
+					return nil
+				}
+
+				summary := fmt.Sprintf("%d conflict(s), resolved in favor of %s", len(conflicts), favor)
+
+				// Without an out file the conflicts would end up mixed into the
+				// merged graph, so they're reported with the error instead
+				if *outFlag == "" {
+					lines := make([]string, len(conflicts))
+					for i, conflict := range conflicts {
+						lines[i] = "conflict " + conflict.String()
+					}
+					return fmt.Errorf("%s\n%s", summary, strings.Join(lines, "\n"))
+				}
+
+				for _, conflict := range conflicts {
+					fmt.Fprintf(appState.Out, "conflict %s\n", conflict.String())
+				}
+				return errors.New(summary)
+			},
+		},
 		{
 			Name:        "Outline",
 			Description: "Enumerates all parameters and producers in a heirarchial fashion formatted in JSON",
@@ -363,11 +629,17 @@ func (a *App) Run(args []string) error {
 				zipCmd := flag.NewFlagSet("zip", flag.ExitOnError)
 				a.initialize(zipCmd)
 				fileFlag := zipCmd.String("out", "", "file to write the contents of the zip too")
+				timingsFlag := zipCmd.Bool("timings", false, "Whether or not to print how long each node took to process")
+				cacheFlags := newCacheFlags(zipCmd)
 
 				if err := zipCmd.Parse(appState.Args); err != nil {
 					return err
 				}
 
+				if err := cacheFlags.apply(a.graphInstance); err != nil {
+					return err
+				}
+
 				var out io.Writer = appState.Out
 
 				if fileFlag != nil && *fileFlag != "" {
@@ -379,7 +651,17 @@ func (a *App) Run(args []string) error {
 					out = f
 				}
 
-				return a.WriteZip(out)
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+				defer stop()
+
+				if err := a.WriteZip(ctx, out); err != nil {
+					return err
+				}
+
+				if *timingsFlag {
+					return a.writeTimings(os.Stderr)
+				}
+				return nil
 			},
 		},
 		{
@@ -471,7 +753,7 @@ func (a *App) Run(args []string) error {
 				return err
 			}
 
-			err = a.ApplySchema(fileData)
+			err = warnOnMigration(a.ApplySchema(fileData))
 			if err != nil {
 				return err
 			}
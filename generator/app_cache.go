@@ -0,0 +1,44 @@
+package generator
+
+import (
+	"flag"
+
+	"github.com/EliCDavis/polyform/generator/cache"
+	"github.com/EliCDavis/polyform/generator/graph"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+type cacheFlags struct {
+	dir  *string
+	size *int64
+}
+
+func newCacheFlags(set *flag.FlagSet) cacheFlags {
+	return cacheFlags{
+		dir:  set.String("cache", "", "Optional directory to cache node outputs in, reusing them across runs"),
+		size: set.Int64("cache.size", 1024, "Maximum size of the cache in megabytes, 0 for unlimited"),
+	}
+}
+
+// build creates the cache requested, returning nil if none was
+func (cf cacheFlags) build() (nodes.OutputCache, error) {
+	if *cf.dir == "" {
+		return nil, nil
+	}
+
+	c, err := cache.New(*cf.dir, *cf.size*1024*1024)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// apply sets up the graph to make use of the cache, if one was requested
+func (cf cacheFlags) apply(instance *graph.Instance) error {
+	c, err := cf.build()
+	if err != nil || c == nil {
+		return err
+	}
+	instance.SetCache(c)
+	return nil
+}
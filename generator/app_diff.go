@@ -0,0 +1,82 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/EliCDavis/polyform/generator/diff"
+)
+
+func loadDocument(path string) (*diff.Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := diff.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+func loadDocuments(paths ...string) ([]*diff.Document, error) {
+	docs := make([]*diff.Document, len(paths))
+	for i, path := range paths {
+		doc, err := loadDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = doc
+	}
+	return docs, nil
+}
+
+// writeDiff lists the changes between the two graphs, a line per change or
+// as a JSON array
+func writeDiff(out io.Writer, beforePath, afterPath string, asJSON bool) error {
+	docs, err := loadDocuments(beforePath, afterPath)
+	if err != nil {
+		return err
+	}
+
+	changes, err := diff.Diff(docs[0], docs[1])
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(changes)
+	}
+
+	for _, change := range changes {
+		if _, err := fmt.Fprintln(out, change.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeGraphs three way merges the graphs, returning the merged graph
+// file along with any conflicts found
+func mergeGraphs(basePath, oursPath, theirsPath string, favor diff.Side) ([]byte, []diff.Conflict, error) {
+	docs, err := loadDocuments(basePath, oursPath, theirsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	merged, conflicts, err := diff.Merge(docs[0], docs[1], docs[2], favor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := merged.Encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, conflicts, nil
+}
@@ -0,0 +1,78 @@
+package generator_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/generator/artifact"
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/EliCDavis/polyform/generator/parameter"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTextGraph(t *testing.T, folder, name, text string) string {
+	t.Helper()
+	app := &generator.App{
+		Files: map[string]nodes.NodeOutput[artifact.Artifact]{
+			"test.txt": basics.NewTextNode(&parameter.String{
+				Name:         "Text",
+				DefaultValue: text,
+			}),
+		},
+	}
+
+	path := filepath.Join(folder, name)
+	require.NoError(t, os.WriteFile(path, app.Schema(), 0666))
+	return path
+}
+
+func TestAppCommand_DiffAndMerge(t *testing.T) {
+	folder := t.TempDir()
+	base := writeTextGraph(t, folder, "base.json", "base")
+	ours := writeTextGraph(t, folder, "ours.json", "ours")
+	theirs := writeTextGraph(t, folder, "theirs.json", "theirs")
+
+	out := &bytes.Buffer{}
+	app := &generator.App{Out: out}
+	require.NoError(t, app.Run([]string{"polyform", "diff", base, ours}))
+	assert.Equal(t, `~ /nodes/Node-0/data/currentValue: "base" -> "ours"
+~ /nodes/Node-0/data/defaultValue: "base" -> "ours"
+`, out.String())
+
+	// Nothing changed on their side, so ours is taken as is
+	merged := filepath.Join(folder, "merged.json")
+	app = &generator.App{Out: &bytes.Buffer{}}
+	require.NoError(t, app.Run([]string{"polyform", "merge", "-out", merged, base, ours, base}))
+
+	out.Reset()
+	app = &generator.App{Out: out}
+	require.NoError(t, app.Run([]string{"polyform", "diff", ours, merged}))
+	assert.Empty(t, out.String())
+
+	// Both changed the same value
+	out.Reset()
+	app = &generator.App{Out: out}
+	err := app.Run([]string{"polyform", "merge", "-out", merged, "-favor", "theirs", base, ours, theirs})
+	assert.EqualError(t, err, "2 conflict(s), resolved in favor of theirs")
+	assert.Equal(t, `conflict /nodes/Node-0/data/currentValue: base "base", ours "ours", theirs "theirs"
+conflict /nodes/Node-0/data/defaultValue: base "base", ours "ours", theirs "theirs"
+`, out.String())
+
+	// With the merged graph written to the output, the conflicts come with
+	// the error instead
+	app = &generator.App{Out: &bytes.Buffer{}}
+	err = app.Run([]string{"polyform", "merge", base, ours, theirs})
+	assert.EqualError(t, err, `2 conflict(s), resolved in favor of ours
+conflict /nodes/Node-0/data/currentValue: base "base", ours "ours", theirs "theirs"
+conflict /nodes/Node-0/data/defaultValue: base "base", ours "ours", theirs "theirs"`)
+
+	out.Reset()
+	app = &generator.App{Out: out}
+	require.NoError(t, app.Run([]string{"polyform", "diff", theirs, merged}))
+	assert.Empty(t, out.String())
+}
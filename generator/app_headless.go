@@ -0,0 +1,264 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+
+	"github.com/EliCDavis/polyform/formats/swagger"
+	"github.com/EliCDavis/polyform/generator/graph"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+const producerValuePath = "/producer/value/"
+
+// errInvalidOverride marks errors caused by the overrides of a request rather
+// than the graph itself
+var errInvalidOverride = errors.New("invalid parameter override")
+
+// instanceBuilder builds graphs independent of one another from a snapshot
+// of the app's schema
+type instanceBuilder struct {
+	schema []byte
+	cache  nodes.OutputCache
+}
+
+// newInstanceBuilder snapshots the app's graph as it currently stands. The
+// optional cache is shared across every graph built, letting them reuse one
+// another's work.
+func newInstanceBuilder(app *App, cache nodes.OutputCache) *instanceBuilder {
+	return &instanceBuilder{
+		schema: app.Schema(),
+		cache:  cache,
+	}
+}
+
+func (ib *instanceBuilder) build() (*graph.Instance, error) {
+	instance := graph.New(types)
+	if err := instance.ApplyAppSchema(ib.schema); err != nil {
+		return nil, err
+	}
+
+	if ib.cache != nil {
+		instance.SetCache(ib.cache)
+	}
+	return instance, nil
+}
+
+// HeadlessServer exposes each of the app's producers over HTTP without the
+// editor. Every request is evaluated against a graph of its own built from
+// the app's schema, so parameter overrides never leak into other requests or
+// the app itself.
+type HeadlessServer struct {
+	app     *App
+	builder *instanceBuilder
+}
+
+// NewHeadlessServer snapshots the app's graph as it currently stands. The
+// optional cache is shared across requests, letting requests with the same
+// parameters reuse one another's work.
+func NewHeadlessServer(app *App, cache nodes.OutputCache) *HeadlessServer {
+	return &HeadlessServer{
+		app:     app,
+		builder: newInstanceBuilder(app, cache),
+	}
+}
+
+func (hs *HeadlessServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(producerValuePath, hs.ProducerEndpoint)
+	mux.HandleFunc("/swagger", hs.SwaggerEndpoint)
+	return mux
+}
+
+func (hs *HeadlessServer) Serve(host, port string) error {
+	connection := fmt.Sprintf("%s:%s", host, port)
+	fmt.Printf("Serving over: http://%s\n", connection)
+	return http.ListenAndServe(connection, hs.Handler())
+}
+
+func (hs *HeadlessServer) SwaggerEndpoint(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	err := hs.app.WriteSwagger(w)
+	if err != nil {
+		log.Println(err.Error())
+	}
+}
+
+// ProducerEndpoint evaluates the producer the path points to, applying the
+// parameter overrides found within the query string and JSON body, with
+// the body taking precedence
+func (hs *HeadlessServer) ProducerEndpoint(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Cache-Control", "no-cache")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		writeJSONError(w, fmt.Errorf("unsupported method %s", r.Method))
+		return
+	}
+
+	status, err := hs.writeProducer(w, r, strings.TrimPrefix(r.URL.Path, producerValuePath))
+	if err != nil {
+		log.Print(err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		writeJSONError(w, err)
+	}
+}
+
+func (hs *HeadlessServer) writeProducer(w http.ResponseWriter, r *http.Request, producerName string) (status int, err error) {
+	defer func() {
+		if recErr := recover(); recErr != nil {
+			fmt.Println("stacktrace from panic: \n" + string(debug.Stack()))
+			status = http.StatusInternalServerError
+			err = fmt.Errorf("panic recover: %v", recErr)
+		}
+	}()
+
+	instance, err := hs.builder.build()
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	producer := instance.Producer(producerName)
+	if producer == nil {
+		return http.StatusNotFound, fmt.Errorf("no producer named %q", producerName)
+	}
+
+	overrides, err := readOverrides(r)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	if _, err := applyOverrides([]nodes.Node{producer.Node()}, overrides); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	artifact, err := instance.Artifact(r.Context(), producerName)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	// Write to memory first so failures can still be reported as such
+	buf := &bytes.Buffer{}
+	if err := artifact.Write(buf); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	w.Header().Set("Content-Type", artifact.Mime())
+	_, err = io.Copy(w, buf)
+	if err != nil {
+		log.Print(err)
+	}
+	return http.StatusOK, nil
+}
+
+// parameterOverride is a requested value for a parameter, either as JSON or
+// the raw text of a query string
+type parameterOverride struct {
+	value []byte
+	raw   bool
+}
+
+func readOverrides(r *http.Request) (map[string]parameterOverride, error) {
+	overrides := make(map[string]parameterOverride)
+
+	query, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidOverride, err)
+	}
+
+	for key, values := range query {
+		overrides[key] = parameterOverride{
+			value: []byte(values[len(values)-1]),
+			raw:   true,
+		}
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return overrides, nil
+	}
+
+	var bodyOverrides map[string]json.RawMessage
+	if err := json.Unmarshal(body, &bodyOverrides); err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidOverride, err)
+	}
+
+	for key, value := range bodyOverrides {
+		overrides[key] = parameterOverride{value: value}
+	}
+
+	return overrides, nil
+}
+
+// overrideMessage converts the override into the message the parameter
+// expects. Query string values are taken as JSON, save for parameters
+// documented as strings which are taken as is.
+func overrideMessage(param graph.Parameter, override parameterOverride) ([]byte, error) {
+	if !override.raw {
+		return override.value, nil
+	}
+
+	if swaggerParam, ok := param.(graph.SwaggerParameter); ok {
+		if swaggerParam.SwaggerProperty().Type == swagger.StringPropertyType {
+			return json.Marshal(string(override.value))
+		}
+	}
+	return override.value, nil
+}
+
+// applyOverrides sets the values of the parameters the nodes depend on,
+// matching them up by their name without spaces, the same as they're
+// documented in the swagger spec. The messages applied are returned by
+// parameter name.
+func applyOverrides(roots []nodes.Node, overrides map[string]parameterOverride) (map[string]json.RawMessage, error) {
+	applied := make(map[string]json.RawMessage)
+	if len(overrides) == 0 {
+		return applied, nil
+	}
+
+	seen := make(map[graph.Parameter]struct{})
+	params := make(map[string][]graph.Parameter)
+	for _, root := range roots {
+		for _, param := range graph.RecurseDependenciesType[graph.Parameter](root) {
+			name := strings.Replace(param.DisplayName(), " ", "", -1)
+			if _, ok := seen[param]; ok || name == "" {
+				continue
+			}
+			seen[param] = struct{}{}
+			params[name] = append(params[name], param)
+		}
+	}
+
+	for name, override := range overrides {
+		matches, ok := params[name]
+		if !ok {
+			return applied, fmt.Errorf("%w: no parameter named %q", errInvalidOverride, name)
+		}
+
+		for _, param := range matches {
+			msg, err := overrideMessage(param, override)
+			if err != nil {
+				return applied, fmt.Errorf("%w: %s: %w", errInvalidOverride, name, err)
+			}
+
+			if _, err := param.ApplyMessage(msg); err != nil {
+				return applied, fmt.Errorf("%w: %s: %w", errInvalidOverride, name, err)
+			}
+			applied[name] = msg
+		}
+	}
+	return applied, nil
+}
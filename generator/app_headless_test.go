@@ -0,0 +1,103 @@
+package generator_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/generator/artifact"
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/EliCDavis/polyform/generator/parameter"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func headlessTestApp() *generator.App {
+	return &generator.App{
+		Name: "Headless",
+		Files: map[string]nodes.NodeOutput[artifact.Artifact]{
+			"text/test.txt": basics.NewTextNode(&parameter.String{
+				Name:         "Some Text",
+				DefaultValue: "yee",
+			}),
+		},
+	}
+}
+
+func headlessRequest(t *testing.T, handler http.Handler, method, url, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, url, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	resp := w.Result()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(data)
+}
+
+func TestHeadlessServer_Overrides(t *testing.T) {
+	app := headlessTestApp()
+	handler := generator.NewHeadlessServer(app, nil).Handler()
+
+	tests := map[string]struct {
+		method string
+		url    string
+		body   string
+		status int
+		want   string
+	}{
+		"default":           {http.MethodGet, "/producer/value/text/test.txt", "", 200, "yee"},
+		"query":             {http.MethodGet, "/producer/value/text/test.txt?SomeText=haw", "", 200, "haw"},
+		"body":              {http.MethodPost, "/producer/value/text/test.txt", `{"SomeText": "haw"}`, 200, "haw"},
+		"body over query":   {http.MethodPost, "/producer/value/text/test.txt?SomeText=a", `{"SomeText": "b"}`, 200, "b"},
+		"unknown producer":  {http.MethodGet, "/producer/value/nope.txt", "", 404, `{"error":"no producer named \"nope.txt\""}`},
+		"unknown parameter": {http.MethodGet, "/producer/value/text/test.txt?Nope=1", "", 400, `{"error":"invalid parameter override: no parameter named \"Nope\""}`},
+		"invalid body":      {http.MethodPost, "/producer/value/text/test.txt", `{"SomeText": 1}`, 400, ""},
+		"bad method":        {http.MethodDelete, "/producer/value/text/test.txt", "", 405, ""},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, body := headlessRequest(t, handler, tc.method, tc.url, tc.body)
+			assert.Equal(t, tc.status, status)
+			if tc.want != "" {
+				assert.Equal(t, tc.want, body)
+			}
+		})
+	}
+
+	// None of the overrides made it back to the app
+	status, body := headlessRequest(t, handler, http.MethodGet, "/producer/value/text/test.txt", "")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "yee", body)
+}
+
+func TestHeadlessServer_ConcurrentRequestsAreIsolated(t *testing.T) {
+	handler := generator.NewHeadlessServer(headlessTestApp(), nil).Handler()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			status, body := headlessRequest(t, handler, http.MethodPost, "/producer/value/text/test.txt", fmt.Sprintf(`{"SomeText": "%d"}`, i))
+			assert.Equal(t, 200, status)
+			assert.Equal(t, fmt.Sprint(i), body)
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestHeadlessServer_Swagger(t *testing.T) {
+	handler := generator.NewHeadlessServer(headlessTestApp(), nil).Handler()
+
+	status, body := headlessRequest(t, handler, http.MethodGet, "/swagger", "")
+	assert.Equal(t, 200, status)
+	assert.Contains(t, body, `"/producer/value/text/test.txt"`)
+}
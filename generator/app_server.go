@@ -2,6 +2,7 @@ package generator
 
 import (
 	"bufio"
+	"context"
 	"embed"
 	"encoding/json"
 	"fmt"
@@ -75,8 +76,9 @@ type AppServer struct {
 	keyPath          string
 	launchWebbrowser bool
 
-	autosave   bool
-	configPath string
+	autosave    bool
+	configPath  string
+	snapshotter *GraphSnapshotter
 
 	webscene *schema.WebScene
 
@@ -133,10 +135,12 @@ func (as *AppServer) Handler(indexFile string) (*http.ServeMux, error) {
 	mux.Handle("/js/", fs)
 
 	var graphSaver *GraphSaver
-	if as.autosave {
+	if as.autosave || as.snapshotter != nil {
 		graphSaver = &GraphSaver{
-			app:      as.app,
-			savePath: as.configPath,
+			app:         as.app,
+			autosave:    as.autosave,
+			savePath:    as.configPath,
+			snapshotter: as.snapshotter,
 		}
 	}
 
@@ -161,6 +165,14 @@ func (as *AppServer) Handler(indexFile string) (*http.ServeMux, error) {
 	mux.Handle("/load-example", exampleGraphEndpoint(as.app))
 	mux.Handle("/graph", graphEndpoint(as.app))
 	mux.Handle("/graph/metadata/", graphMetadataEndpoint(as.app.graphInstance, graphSaver))
+	mux.Handle("/subgraph", subgraphEndpoint(as.app.graphInstance, graphSaver))
+	mux.Handle("/subgraph/definition/", subgraphDefinitionEndpoint(as.app.graphInstance))
+	mux.Handle("/subgraph/selection", subgraphSelectionEndpoint(as.app.graphInstance, graphSaver))
+	mux.Handle("/subgraph/edit", subgraphEditEndpoint(as.app.graphInstance))
+	mux.Handle("/subgraph/edit/finish", subgraphEditFinishEndpoint(as.app.graphInstance, graphSaver))
+	mux.Handle("/history", historyEndpoint(as.app.graphInstance))
+	mux.Handle("/history/undo", historyStepEndpoint(as.app.graphInstance, graphSaver, as.app.graphInstance.Undo))
+	mux.Handle("/history/redo", historyStepEndpoint(as.app.graphInstance, graphSaver, as.app.graphInstance.Redo))
 	mux.HandleFunc("/started", as.StartedEndpoint)
 	mux.HandleFunc("/mermaid", as.MermaidEndpoint)
 	mux.HandleFunc("/swagger", as.SwaggerEndpoint)
@@ -199,7 +211,7 @@ func (as *AppServer) ProducerEndpoint(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Cross-Origin-Embedder-Policy", "require-corp")
 
 	// params, _ := url.ParseQuery(r.URL.RawQuery)
-	err := as.writeProducerDataToRequest(path.Base(r.URL.Path), w)
+	err := as.writeProducerDataToRequest(r.Context(), path.Base(r.URL.Path), w)
 	if err != nil {
 		log.Print(err)
 		w.WriteHeader(http.StatusInternalServerError)
@@ -207,14 +219,17 @@ func (as *AppServer) ProducerEndpoint(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (as *AppServer) writeProducerDataToRequest(producerToLoad string, w http.ResponseWriter) (err error) {
+func (as *AppServer) writeProducerDataToRequest(ctx context.Context, producerToLoad string, w http.ResponseWriter) (err error) {
 	defer func() {
 		if recErr := recover(); recErr != nil {
 			fmt.Println("stacktrace from panic: \n" + string(debug.Stack()))
 			err = fmt.Errorf("panic recover: %v", recErr)
 		}
 	}()
-	artifact := as.app.graphInstance.Artifact(producerToLoad)
+	artifact, err := as.app.graphInstance.Artifact(ctx, producerToLoad)
+	if err != nil {
+		return
+	}
 
 	w.Header().Set("Content-Type", artifact.Mime())
 
@@ -247,7 +262,7 @@ func (as *AppServer) SwaggerEndpoint(w http.ResponseWriter, r *http.Request) {
 }
 
 func (as *AppServer) ZipEndpoint(w http.ResponseWriter, r *http.Request) {
-	err := as.app.WriteZip(w)
+	err := as.app.WriteZip(r.Context(), w)
 	w.Header().Add("Content-Type", "application/zip")
 	if err != nil {
 		panic(err)
@@ -14,7 +14,7 @@ func exampleGraphEndpoint(app *App) endpoint.Handler {
 			http.MethodPost: endpoint.BodyMethod[string]{
 				Request: endpoint.TextRequestReader{},
 				Handler: func(request endpoint.Request[string]) error {
-					err := app.ApplySchema(loadExample(request.Body))
+					err := warnOnMigration(app.ApplySchema(loadExample(request.Body)))
 					if err != nil {
 						return err
 					}
@@ -74,7 +74,7 @@ func graphEndpoint(app *App) endpoint.Handler {
 			http.MethodPost: endpoint.BodyMethod[[]byte]{
 				Request: endpoint.BinaryRequestReader{},
 				Handler: func(request endpoint.Request[[]byte]) error {
-					err := app.ApplySchema(request.Body)
+					err := warnOnMigration(app.ApplySchema(request.Body))
 					if err != nil {
 						return err
 					}
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"net/http"
+
+	"github.com/EliCDavis/polyform/generator/endpoint"
+	"github.com/EliCDavis/polyform/generator/graph"
+)
+
+func historyEndpoint(graphInstance *graph.Instance) endpoint.Handler {
+	return endpoint.Handler{
+		Methods: map[string]endpoint.Method{
+			http.MethodGet: endpoint.ResponseMethod[graph.History]{
+				ResponseWriter: endpoint.JsonResponseWriter[graph.History]{},
+				Handler: func(r *http.Request) (graph.History, error) {
+					return graphInstance.History(), nil
+				},
+			},
+		},
+	}
+}
+
+// historyStepEndpoint undoes or redoes a single operation, depending on the
+// step provided
+func historyStepEndpoint(graphInstance *graph.Instance, saver *GraphSaver, step func() bool) endpoint.Handler {
+	type EmptyRequest struct{}
+
+	type StepResponse struct {
+		Changed bool `json:"changed"`
+	}
+
+	return endpoint.Handler{
+		Methods: map[string]endpoint.Method{
+			http.MethodPost: endpoint.JsonMethod(
+				func(request endpoint.Request[EmptyRequest]) (StepResponse, error) {
+					changed := step()
+					if changed {
+						saver.Save()
+					}
+					return StepResponse{Changed: changed}, nil
+				},
+			),
+		},
+	}
+}
@@ -26,13 +26,16 @@ func nodeConnectionEndpoint(graphInstance *graph.Instance, saver *GraphSaver) en
 		Methods: map[string]endpoint.Method{
 			http.MethodPost: endpoint.JsonMethod(
 				func(request endpoint.Request[CreateRequest]) (EmptyResponse, error) {
-					graphInstance.
+					err := graphInstance.
 						ConnectNodes(
 							request.Body.NodeOutId,
 							request.Body.OutPortName,
 							request.Body.NodeInId,
 							request.Body.InPortName,
 						)
+					if err != nil {
+						return EmptyResponse{}, err
+					}
 					saver.Save()
 					return EmptyResponse{}, nil
 				},
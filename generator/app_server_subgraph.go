@@ -0,0 +1,129 @@
+package generator
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/EliCDavis/polyform/generator/endpoint"
+	"github.com/EliCDavis/polyform/generator/graph"
+)
+
+func subgraphEndpoint(graphInstance *graph.Instance, saver *GraphSaver) endpoint.Handler {
+	return endpoint.Handler{
+		Methods: map[string]endpoint.Method{
+			http.MethodGet: endpoint.ResponseMethod[[]string]{
+				ResponseWriter: endpoint.JsonResponseWriter[[]string]{},
+				Handler: func(r *http.Request) ([]string, error) {
+					return graphInstance.SubgraphNames(), nil
+				},
+			},
+
+			http.MethodPost: endpoint.BodyMethod[[]byte]{
+				Request: endpoint.BinaryRequestReader{},
+				Handler: func(request endpoint.Request[[]byte]) error {
+					if _, err := graphInstance.LoadSubgraph(request.Body); err != nil {
+						return err
+					}
+					saver.Save()
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func subgraphDefinitionEndpoint(graphInstance *graph.Instance) endpoint.Handler {
+	return endpoint.Handler{
+		Methods: map[string]endpoint.Method{
+			http.MethodGet: endpoint.ResponseMethod[[]byte]{
+				ResponseWriter: endpoint.BinaryResponseWriter{},
+				Handler: func(r *http.Request) ([]byte, error) {
+					return graphInstance.EncodeSubgraph(path.Base(r.URL.Path))
+				},
+			},
+		},
+	}
+}
+
+func subgraphSelectionEndpoint(graphInstance *graph.Instance, saver *GraphSaver) endpoint.Handler {
+	type CreateRequest struct {
+		Name  string   `json:"name"`
+		Nodes []string `json:"nodes"`
+	}
+
+	type EmptyResponse struct{}
+
+	return endpoint.Handler{
+		Methods: map[string]endpoint.Method{
+			http.MethodPost: endpoint.JsonMethod(
+				func(request endpoint.Request[CreateRequest]) (EmptyResponse, error) {
+					err := graphInstance.CreateSubgraph(request.Body.Name, request.Body.Nodes)
+					if err != nil {
+						return EmptyResponse{}, err
+					}
+					saver.Save()
+					return EmptyResponse{}, nil
+				},
+			),
+		},
+	}
+}
+
+// subgraphEditEndpoint opens subgraphs for editing, reports which one is open,
+// and discards whatever was done to the one that's open
+func subgraphEditEndpoint(graphInstance *graph.Instance) endpoint.Handler {
+	type EditRequest struct {
+		Name string `json:"name"`
+	}
+
+	type EditResponse struct {
+		Name string `json:"name,omitempty"`
+	}
+
+	return endpoint.Handler{
+		Methods: map[string]endpoint.Method{
+			http.MethodGet: endpoint.ResponseMethod[EditResponse]{
+				ResponseWriter: endpoint.JsonResponseWriter[EditResponse]{},
+				Handler: func(r *http.Request) (EditResponse, error) {
+					name, _ := graphInstance.EditingSubgraph()
+					return EditResponse{Name: name}, nil
+				},
+			},
+
+			http.MethodPost: endpoint.JsonMethod(
+				func(request endpoint.Request[EditRequest]) (EditResponse, error) {
+					if err := graphInstance.EditSubgraph(request.Body.Name); err != nil {
+						return EditResponse{}, err
+					}
+					return EditResponse{Name: request.Body.Name}, nil
+				},
+			),
+
+			http.MethodDelete: endpoint.Func(func(r *http.Request) error {
+				return graphInstance.DiscardSubgraphEdits()
+			}),
+		},
+	}
+}
+
+// subgraphEditFinishEndpoint saves the edits made to the subgraph that's
+// open back into its definition
+func subgraphEditFinishEndpoint(graphInstance *graph.Instance, saver *GraphSaver) endpoint.Handler {
+	type EmptyRequest struct{}
+
+	type EmptyResponse struct{}
+
+	return endpoint.Handler{
+		Methods: map[string]endpoint.Method{
+			http.MethodPost: endpoint.JsonMethod(
+				func(request endpoint.Request[EmptyRequest]) (EmptyResponse, error) {
+					if err := graphInstance.FinishEditingSubgraph(); err != nil {
+						return EmptyResponse{}, err
+					}
+					saver.Save()
+					return EmptyResponse{}, nil
+				},
+			),
+		},
+	}
+}
@@ -3,6 +3,7 @@ package generator
 import (
 	"encoding/json"
 	"io"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -49,6 +50,32 @@ func buildSwaggerDefinitionForProducer(producer nodes.NodeOutput[artifact.Artifa
 	}
 }
 
+// swaggerQueryParameters lists the properties of the definition that can be
+// provided through a query string
+func swaggerQueryParameters(definition swagger.Definition) []swagger.Parameter {
+	names := make([]string, 0, len(definition.Properties))
+	for name, prop := range definition.Properties {
+		switch prop.Type {
+		case swagger.StringPropertyType, swagger.NumberPropertyType, swagger.IntegerPropertyType, swagger.BooleanPropertyType:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	params := make([]swagger.Parameter, 0, len(names))
+	for _, name := range names {
+		prop := definition.Properties[name]
+		description, _ := prop.Description.(string)
+		params = append(params, swagger.Parameter{
+			In:          swagger.QueryParameterLocation,
+			Name:        name,
+			Description: description,
+			Type:        string(prop.Type),
+		})
+	}
+	return params
+}
+
 func (a App) WriteSwagger(out io.Writer) error {
 	jsonData, err := json.MarshalIndent(a.SwaggerSpec(), "", "    ")
 	if err != nil {
@@ -92,8 +119,26 @@ func (a *App) SwaggerSpec() swagger.Spec {
 
 	for _, path := range a.graphInstance.ProducerNames() {
 		definitionName := swaggerDefinitionNameFromProducerPath(path)
+		producer := a.graphInstance.Producer(path)
+		definition := buildSwaggerDefinitionForProducer(producer)
+		definitions[definitionName] = definition
+
+		responses := map[int]swagger.Response{
+			200: {
+				Description: "Producer Payload",
+			},
+		}
+
+		paths[producerValuePath+path] = swagger.Path{
+			// Parameters that can be written out as text can be overridden
+			// through the query string
+			swagger.GetRequestMethod: swagger.RequestDefinition{
+				Produces:   []string{},
+				Consumes:   []string{},
+				Parameters: swaggerQueryParameters(definition),
+				Responses:  responses,
+			},
 
-		paths["/producer/value/"+path] = swagger.Path{
 			// Post required for bodys per HTTP spec.
 			swagger.PostRequestMethod: swagger.RequestDefinition{
 				// Summary:     "Test",
@@ -112,16 +157,9 @@ func (a *App) SwaggerSpec() swagger.Spec {
 						},
 					},
 				},
-				Responses: map[int]swagger.Response{
-					200: {
-						Description: "Producer Payload",
-					},
-				},
+				Responses: responses,
 			},
 		}
-
-		producer := a.graphInstance.Producer(path)
-		definitions[definitionName] = buildSwaggerDefinitionForProducer(producer)
 	}
 
 	for _, def := range definitions {
@@ -48,8 +48,17 @@ func TestSwaggerFromGraph_SingleParameterSingleProducer(t *testing.T) {
 	assert.Contains(t, spec.Paths, "/producer/value/test.txt")
 
 	path := spec.Paths["/producer/value/test.txt"]
-	assert.Len(t, path, 1)
+	assert.Len(t, path, 2)
 	assert.Contains(t, path, swagger.PostRequestMethod)
+	assert.Contains(t, path, swagger.GetRequestMethod)
+
+	// Query string overrides
+	if assert.Len(t, path[swagger.GetRequestMethod].Parameters, 1) {
+		queryParam := path[swagger.GetRequestMethod].Parameters[0]
+		assert.Equal(t, swagger.QueryParameterLocation, queryParam.In)
+		assert.Equal(t, "Welp", queryParam.Name)
+		assert.Equal(t, "string", queryParam.Type)
+	}
 
 	request := path[swagger.PostRequestMethod]
 	if assert.Len(t, request.Consumes, 1) {
@@ -144,8 +153,11 @@ func TestSwaggerFromGraph_MultipleParametersSingleProducer(t *testing.T) {
 	assert.Contains(t, spec.Paths, "/producer/value/example.glb")
 
 	path := spec.Paths["/producer/value/example.glb"]
-	assert.Len(t, path, 1)
+	assert.Len(t, path, 2)
 	assert.Contains(t, path, swagger.PostRequestMethod)
+
+	// Objects can't be provided through a query string
+	assert.Len(t, path[swagger.GetRequestMethod].Parameters, 0)
 	request := path[swagger.PostRequestMethod]
 
 	// Parameter
@@ -0,0 +1,355 @@
+package generator
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+const sweepManifestName = "manifest.json"
+
+// The most values a single numeric range is allowed to expand into
+const maxSweepRangeValues = 10000
+
+// sweepRange is the set of values to sweep a single parameter across, kept as
+// the raw text they were written as
+type sweepRange struct {
+	parameter string
+	values    []string
+}
+
+// parseSweepRange reads a range written as either a list of values,
+// Name=a,b,c, or as an inclusive numeric range with an optional step,
+// Name=start:end:step. Only a single value made up entirely of numbers is
+// taken as a numeric range, so values that just happen to contain a colon,
+// like URLs, are left as they were written.
+func parseSweepRange(s string) (sweepRange, error) {
+	name, values, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return sweepRange{}, fmt.Errorf("range %q is not of the form Name=values", s)
+	}
+
+	numbers, ok := parseNumericRange(values)
+	if !ok {
+		return sweepRange{parameter: name, values: strings.Split(values, ",")}, nil
+	}
+
+	start, end, step := numbers[0], numbers[1], 1.
+	if len(numbers) == 3 {
+		step = numbers[2]
+	}
+
+	for _, n := range numbers {
+		if math.IsInf(n, 0) || math.IsNaN(n) {
+			return sweepRange{}, fmt.Errorf("range %q: bounds and step must be finite", s)
+		}
+	}
+
+	if step == 0 || (end-start)/step < 0 {
+		return sweepRange{}, fmt.Errorf("range %q: step %g never reaches %g from %g", s, step, end, start)
+	}
+
+	// Small tolerance so floating point error doesn't drop the end of the
+	// range
+	steps := (end-start)/step + 1e-9
+	if math.IsInf(steps, 0) || steps >= maxSweepRangeValues {
+		return sweepRange{}, fmt.Errorf("range %q: produces more than %d values", s, maxSweepRangeValues)
+	}
+	count := int(steps) + 1
+	r := sweepRange{parameter: name, values: make([]string, count)}
+	for i := 0; i < count; i++ {
+		r.values[i] = strconv.FormatFloat(start+float64(i)*step, 'f', -1, 64)
+	}
+	return r, nil
+}
+
+// parseNumericRange splits the bounds and optional step out of a range,
+// returning false if it isn't entirely numbers
+func parseNumericRange(values string) ([]float64, bool) {
+	if strings.Contains(values, ",") {
+		return nil, false
+	}
+
+	bounds := strings.Split(values, ":")
+	if len(bounds) < 2 || len(bounds) > 3 {
+		return nil, false
+	}
+
+	numbers := make([]float64, len(bounds))
+	for i, bound := range bounds {
+		number, err := strconv.ParseFloat(strings.TrimSpace(bound), 64)
+		if err != nil {
+			return nil, false
+		}
+		numbers[i] = number
+	}
+	return numbers, true
+}
+
+type sweepRangesFlag []sweepRange
+
+func (f *sweepRangesFlag) String() string {
+	ranges := make([]string, len(*f))
+	for i, r := range *f {
+		ranges[i] = fmt.Sprintf("%s=%s", r.parameter, strings.Join(r.values, ","))
+	}
+	return strings.Join(ranges, " ")
+}
+
+func (f *sweepRangesFlag) Set(value string) error {
+	r, err := parseSweepRange(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, r)
+	return nil
+}
+
+// readSweepTable reads the variants listed within either a CSV file, with a
+// header row of parameter names and a row per variant, or a JSON file
+// containing an array of objects keyed by parameter name. Empty CSV cells
+// leave the parameter as is.
+func readSweepTable(path string) ([]map[string]parameterOverride, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var rows []map[string]json.RawMessage
+		if err := json.NewDecoder(f).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("unable to parse sweep table %s: %w", path, err)
+		}
+
+		table := make([]map[string]parameterOverride, len(rows))
+		for i, row := range rows {
+			table[i] = make(map[string]parameterOverride)
+			for name, value := range row {
+				table[i][name] = parameterOverride{value: value}
+			}
+		}
+		return table, nil
+	}
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse sweep table %s: %w", path, err)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("sweep table %s is missing a header row", path)
+	}
+
+	header := records[0]
+	table := make([]map[string]parameterOverride, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]parameterOverride)
+		for i, cell := range record {
+			if cell == "" {
+				continue
+			}
+			row[strings.TrimSpace(header[i])] = parameterOverride{value: []byte(cell), raw: true}
+		}
+		table = append(table, row)
+	}
+	return table, nil
+}
+
+// sweepVariants pairs every row of the table with every combination of the
+// ranges' values. Ranges take precedence over the table when both set the
+// same parameter.
+func sweepVariants(table []map[string]parameterOverride, ranges []sweepRange) []map[string]parameterOverride {
+	variants := table
+	if len(variants) == 0 {
+		variants = []map[string]parameterOverride{{}}
+	}
+
+	for _, r := range ranges {
+		product := make([]map[string]parameterOverride, 0, len(variants)*len(r.values))
+		for _, variant := range variants {
+			for _, value := range r.values {
+				combined := make(map[string]parameterOverride, len(variant)+1)
+				for name, override := range variant {
+					combined[name] = override
+				}
+				combined[r.parameter] = parameterOverride{value: []byte(value), raw: true}
+				product = append(product, combined)
+			}
+		}
+		variants = product
+	}
+	return variants
+}
+
+// SweepVariant records what went into and came out of a single run of a
+// sweep
+type SweepVariant struct {
+	Name       string                     `json:"name"`
+	Parameters map[string]json.RawMessage `json:"parameters"`
+	Path       string                     `json:"path,omitempty"`    // Folder or zip the outputs were written to, relative to the manifest
+	Outputs    []string                   `json:"outputs,omitempty"` // Producers written, relative to the path
+	Error      string                     `json:"error,omitempty"`
+}
+
+// SweepManifest lists every variant a sweep ran
+type SweepManifest struct {
+	Variants []SweepVariant `json:"variants"`
+}
+
+// Failed returns the number of variants that failed to run
+func (sm SweepManifest) Failed() int {
+	failed := 0
+	for _, variant := range sm.Variants {
+		if variant.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+type sweep struct {
+	builder *instanceBuilder
+	out     string
+	zip     bool
+	workers int
+}
+
+// run evaluates the graph once per variant, spread across the sweep's
+// workers, writing each variant's outputs along with the manifest of
+// everything ran
+func (s *sweep) run(ctx context.Context, variants []map[string]parameterOverride) (SweepManifest, error) {
+	if err := os.MkdirAll(s.out, os.ModePerm); err != nil {
+		return SweepManifest{}, err
+	}
+
+	manifest := SweepManifest{Variants: make([]SweepVariant, len(variants))}
+	width := len(strconv.Itoa(len(variants) - 1))
+
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < max(s.workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				name := fmt.Sprintf("variant-%0*d", width, i)
+				manifest.Variants[i] = s.runVariant(ctx, name, variants[i])
+			}
+		}()
+	}
+
+	for i := range variants {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	f, err := os.Create(filepath.Join(s.out, sweepManifestName))
+	if err != nil {
+		return manifest, err
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "\t")
+	return manifest, encoder.Encode(manifest)
+}
+
+func (s *sweep) runVariant(ctx context.Context, name string, overrides map[string]parameterOverride) (variant SweepVariant) {
+	variant.Name = name
+
+	defer func() {
+		if recErr := recover(); recErr != nil {
+			fmt.Println("stacktrace from panic: \n" + string(debug.Stack()))
+			variant.Error = fmt.Sprintf("panic recover: %v", recErr)
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		variant.Error = err.Error()
+		return
+	}
+
+	instance, err := s.builder.build()
+	if err != nil {
+		variant.Error = err.Error()
+		return
+	}
+
+	producerNames := instance.ProducerNames()
+	producers := make([]nodes.Node, len(producerNames))
+	for i, producerName := range producerNames {
+		producers[i] = instance.Producer(producerName).Node()
+	}
+
+	variant.Parameters, err = applyOverrides(producers, overrides)
+	if err != nil {
+		variant.Error = err.Error()
+		return
+	}
+
+	if s.zip {
+		variant.Path = name + ".zip"
+		err = writeZipFile(filepath.Join(s.out, variant.Path), func(zw *zip.Writer) error {
+			return writeProducersToZip(ctx, "", instance, zw)
+		})
+	} else {
+		variant.Path = name
+		folder := filepath.Join(s.out, variant.Path)
+		if err = os.MkdirAll(folder, os.ModePerm); err == nil {
+			err = writeProducersToFolder(ctx, folder, instance)
+		}
+	}
+
+	if err != nil {
+		variant.Error = err.Error()
+		return
+	}
+
+	variant.Outputs = producerNames
+	return
+}
+
+func writeZipFile(path string, write func(zw *zip.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	if err := write(zw); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeSweepSummary prints a line per variant that failed
+func writeSweepSummary(out io.Writer, manifest SweepManifest) error {
+	failed := manifest.Failed()
+	if failed == 0 {
+		fmt.Fprintf(out, "wrote %d variants\n", len(manifest.Variants))
+		return nil
+	}
+
+	for _, variant := range manifest.Variants {
+		if variant.Error != "" {
+			fmt.Fprintf(out, "%s: %s\n", variant.Name, variant.Error)
+		}
+	}
+	return fmt.Errorf("%d of %d variants failed", failed, len(manifest.Variants))
+}
@@ -0,0 +1,161 @@
+package generator_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readSweepManifest(t *testing.T, folder string) generator.SweepManifest {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(folder, "manifest.json"))
+	require.NoError(t, err)
+
+	manifest := generator.SweepManifest{}
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	return manifest
+}
+
+func TestAppCommand_Sweep_Ranges(t *testing.T) {
+	out := t.TempDir()
+	app := headlessTestApp()
+	app.Out = &bytes.Buffer{}
+
+	err := app.Run([]string{
+		"polyform", "sweep",
+		"-range", "SomeText=1:5:2",
+		"-out", out,
+	})
+	require.NoError(t, err)
+
+	for variant, expected := range map[string]string{"variant-0": "1", "variant-1": "3", "variant-2": "5"} {
+		contents, err := os.ReadFile(filepath.Join(out, variant, "text", "test.txt"))
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(contents))
+	}
+
+	manifest := readSweepManifest(t, out)
+	require.Len(t, manifest.Variants, 3)
+	assert.Equal(t, generator.SweepVariant{
+		Name:       "variant-1",
+		Parameters: map[string]json.RawMessage{"SomeText": json.RawMessage(`"3"`)},
+		Path:       "variant-1",
+		Outputs:    []string{"text/test.txt"},
+	}, manifest.Variants[1])
+}
+
+func TestAppCommand_Sweep_LiteralsWithColons(t *testing.T) {
+	for name, tc := range map[string]struct {
+		value    string
+		expected []string
+	}{
+		"url":   {value: "http://localhost:8080", expected: []string{"http://localhost:8080"}},
+		"times": {value: "12:30,18:45", expected: []string{"12:30", "18:45"}},
+		"words": {value: "a:b", expected: []string{"a:b"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out := t.TempDir()
+			app := headlessTestApp()
+			app.Out = &bytes.Buffer{}
+
+			err := app.Run([]string{
+				"polyform", "sweep",
+				"-range", "SomeText=" + tc.value,
+				"-out", out,
+			})
+			require.NoError(t, err)
+
+			manifest := readSweepManifest(t, out)
+			require.Len(t, manifest.Variants, len(tc.expected))
+			for i, expected := range tc.expected {
+				contents, err := os.ReadFile(filepath.Join(out, manifest.Variants[i].Path, "text", "test.txt"))
+				require.NoError(t, err)
+				assert.Equal(t, expected, string(contents))
+			}
+		})
+	}
+}
+
+func TestAppCommand_Sweep_TableToZips(t *testing.T) {
+	out := t.TempDir()
+	table := filepath.Join(t.TempDir(), "table.csv")
+	require.NoError(t, os.WriteFile(table, []byte("SomeText\nfirst\n\nsecond\n"), 0666))
+
+	app := headlessTestApp()
+	app.Out = &bytes.Buffer{}
+
+	err := app.Run([]string{
+		"polyform", "sweep",
+		"-table", table,
+		"-zip",
+		"-workers", "1",
+		"-out", out,
+	})
+	require.NoError(t, err)
+
+	for variant, expected := range map[string]string{"variant-0.zip": "first", "variant-1.zip": "second"} {
+		zr, err := zip.OpenReader(filepath.Join(out, variant))
+		require.NoError(t, err)
+		require.Len(t, zr.File, 1)
+		assert.Equal(t, "text/test.txt", zr.File[0].Name)
+
+		f, err := zr.File[0].Open()
+		require.NoError(t, err)
+		contents, err := io.ReadAll(f)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(contents))
+		require.NoError(t, zr.Close())
+	}
+
+	manifest := readSweepManifest(t, out)
+	require.Len(t, manifest.Variants, 2)
+	assert.Equal(t, "variant-1.zip", manifest.Variants[1].Path)
+}
+
+func TestAppCommand_Sweep_Failures(t *testing.T) {
+	app := headlessTestApp()
+	app.Out = &bytes.Buffer{}
+
+	err := app.Run([]string{"polyform", "sweep", "-out", t.TempDir()})
+	assert.EqualError(t, err, "nothing to sweep, provide a table and/or ranges of values")
+
+	out := t.TempDir()
+	app = headlessTestApp()
+	app.Out = &bytes.Buffer{}
+	err = app.Run([]string{"polyform", "sweep", "-range", "Missing=a,b", "-out", out})
+	assert.EqualError(t, err, "2 of 2 variants failed")
+
+	manifest := readSweepManifest(t, out)
+	require.Len(t, manifest.Variants, 2)
+	assert.Equal(t, `invalid parameter override: no parameter named "Missing"`, manifest.Variants[0].Error)
+	assert.Empty(t, manifest.Variants[0].Outputs)
+}
+
+func TestAppCommand_Sweep_InvalidNumericRanges(t *testing.T) {
+	for name, tc := range map[string]struct {
+		value string
+		err   string
+	}{
+		"infinite end":  {value: "A=0:Inf", err: `range "A=0:Inf": bounds and step must be finite`},
+		"nan end":       {value: "A=0:NaN", err: `range "A=0:NaN": bounds and step must be finite`},
+		"infinite step": {value: "A=0:1:-Inf", err: `range "A=0:1:-Inf": bounds and step must be finite`},
+		"tiny step":     {value: "A=0:1:1e-300", err: `range "A=0:1:1e-300": produces more than 10000 values`},
+		"too many":      {value: "A=0:10000", err: `range "A=0:10000": produces more than 10000 values`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			app := headlessTestApp()
+			app.Out = &bytes.Buffer{}
+			err := app.Run([]string{"polyform", "sweep", "-range", tc.value, "-out", t.TempDir()})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+}
@@ -236,6 +236,25 @@ func TestAppCommand_Swagger(t *testing.T) {
     },
     "paths": {
         "/producer/value/test.txt": {
+            "get": {
+                "summary": "",
+                "description": "",
+                "produces": [],
+                "consumes": [],
+                "responses": {
+                    "200": {
+                        "description": "Producer Payload"
+                    }
+                },
+                "parameters": [
+                    {
+                        "in": "query",
+                        "name": "Welp",
+                        "description": "I'm a description",
+                        "type": "string"
+                    }
+                ]
+            },
             "post": {
                 "summary": "",
                 "description": "",
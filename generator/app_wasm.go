@@ -4,6 +4,7 @@ package generator
 
 import (
 	"bytes"
+	"context"
 	"log"
 	"syscall/js"
 
@@ -19,7 +20,7 @@ func wasmZip(this js.Value, cb []js.Value) interface{} { //
 	}
 
 	b := bytes.Buffer{}
-	err := globalApp.WriteZip(&b)
+	err := globalApp.WriteZip(context.Background(), &b)
 	if err != nil {
 		log.Printf("error zipping: %s", err.Error())
 	}
@@ -0,0 +1,315 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/polyform/refutil"
+)
+
+// Bumped whenever the key or encoding of cached values changes, so entries
+// written by older builds are simply never found again
+const formatVersion = 1
+
+// keyVersion prefixes every key, tying it to the build that computed it. What
+// a node type outputs can change between versions of polyform, or of the
+// program and dependencies built with it, and nothing about the node itself
+// reflects that. The build info covers the module versions, Go version, and
+// VCS revision the binary was built from.
+var keyVersion = sync.OnceValue(func() string {
+	version := fmt.Sprintf("polyform-cache-v%d", formatVersion)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		version += "\n" + info.String()
+	}
+	return version
+})
+
+// messaged nodes are the leaves of a graph, like parameters, whose output is
+// entirely described by the message they serialize to
+type messaged interface {
+	ToMessage() []byte
+}
+
+type leafKey struct {
+	version int
+	key     string
+}
+
+// Cache is a content addressed store of node outputs on disk. Each node's
+// output is keyed by the node's type, the values of the parameters it depends
+// on, and the keys of everything upstream of it. Because nothing about the
+// key is specific to a single run, outputs are reused across runs of the same
+// or similar graphs.
+//
+// Only outputs with a codec registered through RegisterCodec are stored,
+// with images and byte slices supported out of the box.
+// Once the files within the cache's directory exceed the maximum size, the
+// least recently used are removed.
+type Cache struct {
+	dir     string
+	maxSize int64
+
+	mutex  sync.Mutex
+	leaves map[nodes.Node]leafKey
+}
+
+// New creates a cache writing to the directory provided, creating it if it
+// doesn't already exist. A max size less than or equal to 0 lets the cache
+// grow without bound.
+func New(dir string, maxSize int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create cache directory: %w", err)
+	}
+
+	return &Cache{
+		dir:     dir,
+		maxSize: maxSize,
+		leaves:  make(map[nodes.Node]leafKey),
+	}, nil
+}
+
+// Key computes the content address of the node's output. Returns false if
+// anything the node depends on can't be described by its contents, making
+// it uncacheable.
+func (c *Cache) Key(node nodes.Node) (string, bool) {
+	if leaf, ok := node.(messaged); ok {
+		return c.leafKey(node, leaf), true
+	}
+
+	h := sha256.New()
+	writeString(h, keyVersion())
+	writeString(h, refutil.GetTypeWithPackage(node))
+
+	// Composites of the same type can be made up of entirely different
+	// nodes, so they're addressed by the nodes their outputs come from
+	if composite, ok := node.(nodes.Composite); ok {
+		for _, output := range node.Outputs() {
+			writeString(h, output.NodeOutput.Port())
+		}
+
+		for _, root := range composite.Roots() {
+			key, ok := c.Key(root)
+			if !ok {
+				return "", false
+			}
+			writeString(h, key)
+		}
+		return hex.EncodeToString(h.Sum(nil)), true
+	}
+
+	// Only nodes that come to their value by processing their dependencies
+	// can be addressed by them
+	if _, ok := node.(nodes.Errored); !ok {
+		return "", false
+	}
+
+	dependencies := append([]nodes.NodeDependency(nil), node.Dependencies()...)
+	sort.SliceStable(dependencies, func(i, j int) bool {
+		return dependencies[i].Name() < dependencies[j].Name()
+	})
+	for _, dep := range dependencies {
+		key, ok := c.Key(dep.Dependency())
+		if !ok {
+			return "", false
+		}
+		writeString(h, dep.Name())
+		writeString(h, dep.DependencyPort())
+		writeString(h, key)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), true
+}
+
+// leafKey hashes the message of the leaf, only recomputing it once the leaf
+// has changed, as messages like file contents can be large
+func (c *Cache) leafKey(node nodes.Node, leaf messaged) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cached, ok := c.leaves[node]; ok && cached.version == node.Version() {
+		return cached.key
+	}
+
+	h := sha256.New()
+	writeString(h, keyVersion())
+	writeString(h, refutil.GetTypeWithPackage(node))
+	writeBytes(h, leaf.ToMessage())
+	key := hex.EncodeToString(h.Sum(nil))
+
+	c.leaves[node] = leafKey{version: node.Version(), key: key}
+	return key
+}
+
+func writeString(h hash.Hash, s string) {
+	writeBytes(h, []byte(s))
+}
+
+// writeBytes prefixes the data with it's length so neighbouring values can't
+// run together and collide
+func writeBytes(h hash.Hash, data []byte) {
+	binary.Write(h, binary.LittleEndian, uint64(len(data)))
+	h.Write(data)
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
+
+// Load reads the node's output from the cache, if it's been stored
+func (c *Cache) Load(node nodes.Node) (any, bool) {
+	key, ok := c.Key(node)
+	if !ok {
+		return nil, false
+	}
+
+	path := c.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+
+	value, err := decode(f)
+	if err != nil {
+		log.Printf("unable to read cache entry %s: %s", key, err.Error())
+		return nil, false
+	}
+
+	// Mark the entry as recently used so it's evicted last
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return value, true
+}
+
+// Store writes the node's output to the cache, evicting the least recently
+// used entries if the cache has grown past its max size. Outputs the cache
+// doesn't know how to encode are skipped.
+func (c *Cache) Store(node nodes.Node, value any) {
+	// Leaves are cheap to compute, and their key is already their content
+	if _, ok := node.(messaged); ok {
+		return
+	}
+
+	if !Supported(value) {
+		return
+	}
+
+	key, ok := c.Key(node)
+	if !ok {
+		return
+	}
+
+	if err := c.write(key, value); err != nil {
+		log.Printf("unable to write cache entry %s: %s", key, err.Error())
+		return
+	}
+
+	if err := c.evict(); err != nil {
+		log.Printf("unable to evict cache entries: %s", err.Error())
+	}
+}
+
+// write encodes to a temporary file before moving it into place, so
+// concurrent readers never see a partially written entry
+func (c *Cache) write(key string, value any) error {
+	f, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := encode(f, value); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), c.path(key))
+}
+
+func (c *Cache) evict() error {
+	if c.maxSize <= 0 {
+		return nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	files := make([]os.FileInfo, 0, len(entries))
+	var size int64
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) == ".tmp" {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// Removed out from under us
+			continue
+		}
+		files = append(files, info)
+		size += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, file := range files {
+		if size <= c.maxSize {
+			break
+		}
+
+		err := os.Remove(c.path(file.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= file.Size()
+	}
+
+	return nil
+}
+
+// Clear removes every entry from the cache
+func (c *Cache) Clear() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := os.Remove(c.path(entry.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ nodes.OutputCache = (*Cache)(nil)
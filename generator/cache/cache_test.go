@@ -0,0 +1,141 @@
+package cache_test
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"os"
+	"testing"
+
+	"github.com/EliCDavis/polyform/generator/cache"
+	"github.com/EliCDavis/polyform/generator/parameter"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CountingMeshNode = nodes.Struct[modeling.Mesh, CountingMeshData]
+
+// CountingMeshData builds a triangle, counting how many times it's processed
+type CountingMeshData struct {
+	Size      nodes.NodeOutput[float64]
+	Processed *int
+}
+
+func (cmd CountingMeshData) Process() (modeling.Mesh, error) {
+	*cmd.Processed++
+	size := cmd.Size.Value()
+	mat := modeling.DefaultColorMaterial(color.RGBA{R: 255, A: 255})
+	return modeling.NewTriangleMesh([]int{0, 1, 2}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(size, 0., 0.),
+			vector3.New(0., size, 0.),
+		}).
+		SetFloat1Attribute("weight", []float64{1, 2, 3}).
+		SetMaterial(mat), nil
+}
+
+func newCountingGraph(size float64, processed *int) (*parameter.Value[float64], *CountingMeshNode) {
+	param := &parameter.Value[float64]{Name: "Size", DefaultValue: size}
+	node := &CountingMeshNode{
+		Data: CountingMeshData{
+			Size:      parameter.ParameterNodeOutput[float64]{Val: param},
+			Processed: processed,
+		},
+	}
+	return param, node
+}
+
+func TestCache_ReusedAcrossGraphs(t *testing.T) {
+	c, err := cache.New(t.TempDir(), 0)
+	require.NoError(t, err)
+	evaluator := nodes.Evaluator{Cache: c}
+
+	processed := 0
+	_, first := newCountingGraph(2, &processed)
+	_, err = evaluator.Evaluate(context.Background(), first)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+
+	// A new graph with the same contents is restored from the cache
+	_, second := newCountingGraph(2, &processed)
+	_, err = evaluator.Evaluate(context.Background(), second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, processed)
+	assert.Equal(t, nodes.Processed, second.State())
+
+	want := first.Out().Value()
+	got := second.Out().Value()
+	assert.Equal(t, want.Topology(), got.Topology())
+	assert.Equal(t, want.Float3Attribute(modeling.PositionAttribute).At(1), got.Float3Attribute(modeling.PositionAttribute).At(1))
+	assert.Equal(t, 2., got.Float1Attribute("weight").At(1))
+	require.Len(t, got.Materials(), 1)
+	r, _, _, _ := got.Materials()[0].Material.DiffuseColor.RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+
+	// Changing a parameter changes the key
+	_, third := newCountingGraph(3, &processed)
+	_, err = evaluator.Evaluate(context.Background(), third)
+	require.NoError(t, err)
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, 3., third.Out().Value().Float3Attribute(modeling.PositionAttribute).At(1).X())
+}
+
+func TestCache_ParameterChanges(t *testing.T) {
+	c, err := cache.New(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	processed := 0
+	param, node := newCountingGraph(2, &processed)
+	first, ok := c.Key(node)
+	require.True(t, ok)
+
+	_, err = param.ApplyMessage([]byte("5"))
+	require.NoError(t, err)
+	second, ok := c.Key(node)
+	require.True(t, ok)
+	assert.NotEqual(t, first, second)
+
+	// Nodes depending on values that can't be addressed by their contents
+	// aren't cacheable
+	_, ok = c.Key(&CountingMeshNode{Data: CountingMeshData{Size: nodes.Value(1.)}})
+	assert.False(t, ok)
+}
+
+func TestCache_Evicts(t *testing.T) {
+	dir := t.TempDir()
+	c, err := cache.New(dir, 1500)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		param, _ := newCountingGraph(float64(i), nil)
+		node := &CountingMeshNode{Data: CountingMeshData{Size: parameter.ParameterNodeOutput[float64]{Val: param}}}
+		c.Store(node, make([]byte, 1000))
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestCache_Image(t *testing.T) {
+	c, err := cache.New(t.TempDir(), 0)
+	require.NoError(t, err)
+
+	_, node := newCountingGraph(1, nil)
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(1, 1, color.RGBA{G: 255, A: 255})
+	c.Store(node, img)
+
+	loaded, ok := c.Load(node)
+	require.True(t, ok)
+	require.Implements(t, (*image.Image)(nil), loaded)
+	_, g, _, _ := loaded.(image.Image).At(1, 1).RGBA()
+	assert.Equal(t, uint32(0xffff), g)
+
+	// Values without a codec are skipped
+	assert.False(t, cache.Supported(struct{}{}))
+}
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"sync"
+)
+
+type codec struct {
+	name    string
+	matches func(value any) bool
+	encode  func(out io.Writer, value any) error
+	decode  func(in io.Reader) (any, error)
+}
+
+var (
+	codecs     []codec
+	codecMutex sync.RWMutex
+)
+
+func init() {
+	RegisterCodec("image", func(out io.Writer, img image.Image) error {
+		return png.Encode(out, img)
+	}, png.Decode)
+
+	RegisterCodec("bytes", func(out io.Writer, data []byte) error {
+		_, err := out.Write(data)
+		return err
+	}, io.ReadAll)
+}
+
+// RegisterCodec allows the cache to store values of type T. The name is
+// written alongside each value, identifying how to decode it, so it must be
+// unique and never change once entries have been written with it.
+func RegisterCodec[T any](name string, encode func(out io.Writer, value T) error, decode func(in io.Reader) (T, error)) {
+	codecMutex.Lock()
+	defer codecMutex.Unlock()
+
+	for _, c := range codecs {
+		if c.name == name {
+			panic(fmt.Errorf("cache codec %q already registered", name))
+		}
+	}
+
+	codecs = append(codecs, codec{
+		name: name,
+		matches: func(value any) bool {
+			_, ok := value.(T)
+			return ok
+		},
+		encode: func(out io.Writer, value any) error {
+			return encode(out, value.(T))
+		},
+		decode: func(in io.Reader) (any, error) {
+			return decode(in)
+		},
+	})
+}
+
+func codecForValue(value any) (codec, bool) {
+	codecMutex.RLock()
+	defer codecMutex.RUnlock()
+	for _, c := range codecs {
+		if c.matches(value) {
+			return c, true
+		}
+	}
+	return codec{}, false
+}
+
+func codecByName(name string) (codec, bool) {
+	codecMutex.RLock()
+	defer codecMutex.RUnlock()
+	for _, c := range codecs {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return codec{}, false
+}
+
+// Supported reports whether or not a codec has been registered for the
+// value's type
+func Supported(value any) bool {
+	_, ok := codecForValue(value)
+	return ok
+}
+
+func encode(out io.Writer, value any) error {
+	c, ok := codecForValue(value)
+	if !ok {
+		return fmt.Errorf("no cache codec registered for %T", value)
+	}
+
+	w := bufio.NewWriter(out)
+	if err := binary.Write(w, binary.LittleEndian, uint8(len(c.name))); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(c.name); err != nil {
+		return err
+	}
+	if err := c.encode(w, value); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func decode(in io.Reader) (any, error) {
+	r := bufio.NewReader(in)
+
+	var nameLen uint8
+	if err := binary.Read(r, binary.LittleEndian, &nameLen); err != nil {
+		return nil, err
+	}
+
+	name := make([]byte, nameLen)
+	if _, err := io.ReadFull(r, name); err != nil {
+		return nil, err
+	}
+
+	c, ok := codecByName(string(name))
+	if !ok {
+		return nil, fmt.Errorf("no cache codec registered by the name %q", name)
+	}
+	return c.decode(r)
+}
@@ -0,0 +1,355 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/EliCDavis/polyform/generator/schema"
+)
+
+// Everything within a graph is tracked by its path, a JSON pointer (RFC 6901),
+// down to the granularity changes are tracked at:
+//
+//	/formatVersion, /name, /version, /description, /authors, /webScene
+//	/nodes/{id}                          the node's type
+//	/nodes/{id}/dependencies/{input}     what's connected to the node's input
+//	/nodes/{id}/data/{field}             a top level field of the node's data
+//	/producers/{name}
+//	/subgraphs/{name}
+//	/metadata/{key}/{key}
+type flatGraph map[string]json.RawMessage
+
+// How many levels of metadata are tracked individually, enough for things
+// like the positions of each node
+const metadataDepth = 2
+
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Change is a single difference between two graphs
+type Change struct {
+	Kind   ChangeKind      `json:"kind"`
+	Path   string          `json:"path"`
+	Before json.RawMessage `json:"before,omitempty"`
+	After  json.RawMessage `json:"after,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, abbreviate(c.After))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, abbreviate(c.Before))
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, abbreviate(c.Before), abbreviate(c.After))
+	}
+}
+
+func abbreviate(value json.RawMessage) string {
+	const maxLength = 120
+	if len(value) > maxLength {
+		return string(value[:maxLength-3]) + "..."
+	}
+	return string(value)
+}
+
+// Diff lists everything that changed from one graph to another, ordered by
+// path. Nodes are matched up by their ID, with a node whose type changed
+// showing up as a change to its type along with everything within it.
+func Diff(before, after *Document) ([]Change, error) {
+	beforeFlat, err := flatten(before.App)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFlat, err := flatten(after.App)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]Change, 0)
+	for _, path := range unionPaths(beforeFlat, afterFlat) {
+		beforeValue, inBefore := beforeFlat[path]
+		afterValue, inAfter := afterFlat[path]
+
+		switch {
+		case !inBefore:
+			changes = append(changes, Change{Kind: Added, Path: path, After: afterValue})
+
+		case !inAfter:
+			changes = append(changes, Change{Kind: Removed, Path: path, Before: beforeValue})
+
+		case !bytes.Equal(beforeValue, afterValue):
+			changes = append(changes, Change{Kind: Modified, Path: path, Before: beforeValue, After: afterValue})
+		}
+	}
+	return changes, nil
+}
+
+func unionPaths(graphs ...flatGraph) []string {
+	union := make(map[string]struct{})
+	for _, graph := range graphs {
+		for path := range graph {
+			union[path] = struct{}{}
+		}
+	}
+	return sortedKeys(union)
+}
+
+// pointer builds a JSON pointer from the unescaped segments
+func pointer(segments ...string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	sb := strings.Builder{}
+	for _, segment := range segments {
+		sb.WriteString("/")
+		sb.WriteString(escaper.Replace(segment))
+	}
+	return sb.String()
+}
+
+// splitPointer breaks a JSON pointer back into its unescaped segments
+func splitPointer(p string) []string {
+	unescaper := strings.NewReplacer("~1", "/", "~0", "~")
+	segments := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = unescaper.Replace(segment)
+	}
+	return segments
+}
+
+// connection is what a node's input is connected to
+type connection struct {
+	DependencyID   string `json:"dependencyID"`
+	DependencyPort string `json:"dependencyPort"`
+}
+
+func flatten(app schema.App) (flatGraph, error) {
+	flat := make(flatGraph)
+	set := func(value any, segments ...string) error {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", pointer(segments...), err)
+		}
+		flat[pointer(segments...)] = data
+		return nil
+	}
+
+	var setNested func(value any, depth int, segments ...string) error
+	setNested = func(value any, depth int, segments ...string) error {
+		object, ok := value.(map[string]any)
+		if !ok || depth == 0 || len(object) == 0 {
+			return set(value, segments...)
+		}
+
+		for key, child := range object {
+			if err := setNested(child, depth-1, append(segments, key)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	fields := map[string]any{}
+	if app.FormatVersion != 0 {
+		fields["formatVersion"] = app.FormatVersion
+	}
+	if app.Name != "" {
+		fields["name"] = app.Name
+	}
+	if app.Version != "" {
+		fields["version"] = app.Version
+	}
+	if app.Description != "" {
+		fields["description"] = app.Description
+	}
+	if len(app.Authors) > 0 {
+		fields["authors"] = app.Authors
+	}
+	if app.WebScene != nil {
+		fields["webScene"] = app.WebScene
+	}
+	for field, value := range fields {
+		if err := set(value, field); err != nil {
+			return nil, err
+		}
+	}
+
+	for id, node := range app.Nodes {
+		if err := set(node.Type, "nodes", id); err != nil {
+			return nil, err
+		}
+
+		for _, dependency := range node.Dependencies {
+			c := connection{DependencyID: dependency.DependencyID, DependencyPort: dependency.DependencyPort}
+			if err := set(c, "nodes", id, "dependencies", dependency.Name); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(node.Data) == 0 {
+			continue
+		}
+
+		data, err := decodeJSON(node.Data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", pointer("nodes", id, "data"), err)
+		}
+
+		if err := setNested(data, 1, "nodes", id, "data"); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, producer := range app.Producers {
+		if err := set(producer, "producers", name); err != nil {
+			return nil, err
+		}
+	}
+
+	for name, subgraph := range app.Subgraphs {
+		if err := set(subgraph, "subgraphs", name); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, value := range app.Metadata {
+		if err := setNested(value, metadataDepth-1, "metadata", key); err != nil {
+			return nil, err
+		}
+	}
+
+	return flat, nil
+}
+
+// unflatten rebuilds the graph from its paths. Anything belonging to a node
+// that no longer has a type is dropped along with the node.
+func unflatten(flat flatGraph) (schema.App, error) {
+	app := schema.App{
+		Producers: make(map[string]schema.Producer),
+		Nodes:     make(map[string]schema.AppNodeInstance),
+	}
+
+	fields := map[string]any{
+		"formatVersion": &app.FormatVersion,
+		"name":          &app.Name,
+		"version":       &app.Version,
+		"description":   &app.Description,
+		"authors":       &app.Authors,
+		"webScene":      &app.WebScene,
+	}
+
+	dependencies := make(map[string][]schema.NodeDependency)
+	data := make(map[string]map[string]json.RawMessage)
+	wholeData := make(map[string]json.RawMessage)
+
+	for _, path := range sortedKeys(flat) {
+		value := flat[path]
+		segments := splitPointer(path)
+
+		var err error
+		switch {
+		case len(segments) == 1 && fields[segments[0]] != nil:
+			err = json.Unmarshal(value, fields[segments[0]])
+
+		case segments[0] == "nodes" && len(segments) == 2:
+			node := app.Nodes[segments[1]]
+			err = json.Unmarshal(value, &node.Type)
+			app.Nodes[segments[1]] = node
+
+		case segments[0] == "nodes" && len(segments) == 4 && segments[2] == "dependencies":
+			c := connection{}
+			err = json.Unmarshal(value, &c)
+			dependencies[segments[1]] = append(dependencies[segments[1]], schema.NodeDependency{
+				DependencyID:   c.DependencyID,
+				DependencyPort: c.DependencyPort,
+				Name:           segments[3],
+			})
+
+		case segments[0] == "nodes" && len(segments) == 3 && segments[2] == "data":
+			wholeData[segments[1]] = value
+
+		case segments[0] == "nodes" && len(segments) == 4 && segments[2] == "data":
+			if data[segments[1]] == nil {
+				data[segments[1]] = make(map[string]json.RawMessage)
+			}
+			data[segments[1]][segments[3]] = value
+
+		case segments[0] == "producers" && len(segments) == 2:
+			producer := schema.Producer{}
+			err = json.Unmarshal(value, &producer)
+			app.Producers[segments[1]] = producer
+
+		case segments[0] == "subgraphs" && len(segments) == 2:
+			subgraph := schema.Subgraph{}
+			err = json.Unmarshal(value, &subgraph)
+			if app.Subgraphs == nil {
+				app.Subgraphs = make(map[string]schema.Subgraph)
+			}
+			app.Subgraphs[segments[1]] = subgraph
+
+		case segments[0] == "metadata" && len(segments) > 1:
+			var decoded any
+			err = json.Unmarshal(value, &decoded)
+			if app.Metadata == nil {
+				app.Metadata = make(map[string]any)
+			}
+			setMetadata(app.Metadata, decoded, segments[1:])
+
+		default:
+			err = errors.New("unrecognized path")
+		}
+
+		if err != nil {
+			return app, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	for id, node := range app.Nodes {
+		node.Dependencies = dependencies[id]
+		schema.SortDependencies(node.Dependencies)
+
+		if whole, ok := wholeData[id]; ok {
+			node.Data = whole
+		} else if fields, ok := data[id]; ok {
+			encoded, err := json.Marshal(fields)
+			if err != nil {
+				return app, fmt.Errorf("%s: %w", pointer("nodes", id, "data"), err)
+			}
+			node.Data = encoded
+		}
+		app.Nodes[id] = node
+	}
+
+	return app, nil
+}
+
+func setMetadata(metadata map[string]any, value any, segments []string) {
+	key := segments[0]
+	if len(segments) == 1 {
+		existing, existingIsObject := metadata[key].(map[string]any)
+		object, isObject := value.(map[string]any)
+		if existingIsObject && isObject {
+			for k, v := range object {
+				existing[k] = v
+			}
+			return
+		}
+		metadata[key] = value
+		return
+	}
+
+	child, ok := metadata[key].(map[string]any)
+	if !ok {
+		child = make(map[string]any)
+		metadata[key] = child
+	}
+	setMetadata(child, value, segments[1:])
+}
@@ -0,0 +1,271 @@
+package diff_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/EliCDavis/jbtf"
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/EliCDavis/polyform/generator/diff"
+	"github.com/EliCDavis/polyform/generator/graph"
+	"github.com/EliCDavis/polyform/generator/parameter"
+	"github.com/EliCDavis/polyform/generator/schema"
+	"github.com/EliCDavis/polyform/refutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTypeFactory() *refutil.TypeFactory {
+	factory := &refutil.TypeFactory{}
+	refutil.RegisterType[basics.TextNode](factory)
+	refutil.RegisterType[parameter.String](factory)
+	refutil.RegisterType[parameter.Image](factory)
+	return factory
+}
+
+func pngOf(t *testing.T, c color.RGBA) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, c)
+	buf := &bytes.Buffer{}
+	require.NoError(t, png.Encode(buf, img))
+	return buf.Bytes()
+}
+
+func encodeInstance(t *testing.T, instance *graph.Instance) []byte {
+	t.Helper()
+	appSchema := &schema.App{}
+	encoder := &jbtf.Encoder{}
+	instance.EncodeToAppSchema(appSchema, encoder)
+	data, err := encoder.ToPgtf(appSchema)
+	require.NoError(t, err)
+	return data
+}
+
+func loadInstance(t *testing.T, payload []byte) *graph.Instance {
+	t.Helper()
+	instance := graph.New(testTypeFactory())
+	require.NoError(t, instance.ApplyAppSchema(payload))
+	return instance
+}
+
+// baseGraph is a string parameter (Node-0) feeding a text producer (Node-1),
+// alongside an image parameter (Node-2)
+func baseGraph(t *testing.T) []byte {
+	t.Helper()
+	instance := graph.New(testTypeFactory())
+
+	_, paramID, err := instance.CreateNode(refutil.GetTypeWithPackage(new(parameter.String)))
+	require.NoError(t, err)
+	_, textID, err := instance.CreateNode(refutil.GetTypeWithPackage(new(basics.TextNode)))
+	require.NoError(t, err)
+	_, imageID, err := instance.CreateNode(refutil.GetTypeWithPackage(new(parameter.Image)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"Node-0", "Node-1", "Node-2"}, []string{paramID, textID, imageID})
+
+	require.NoError(t, instance.ConnectNodes(paramID, "Out", textID, "In"))
+	instance.SetNodeAsProducer(textID, "text/test.txt")
+
+	_, err = instance.UpdateParameter(paramID, []byte(`"hello"`))
+	require.NoError(t, err)
+	_, err = instance.UpdateParameter(imageID, pngOf(t, color.RGBA{R: 255, A: 255}))
+	require.NoError(t, err)
+
+	return encodeInstance(t, instance)
+}
+
+// edit applies the changes to a copy of the graph
+func edit(t *testing.T, payload []byte, change func(instance *graph.Instance)) []byte {
+	t.Helper()
+	instance := loadInstance(t, payload)
+	change(instance)
+	return encodeInstance(t, instance)
+}
+
+func load(t *testing.T, payload []byte) *diff.Document {
+	t.Helper()
+	doc, err := diff.Load(payload)
+	require.NoError(t, err)
+	return doc
+}
+
+func readText(t *testing.T, instance *graph.Instance) string {
+	t.Helper()
+	artifact, err := instance.Artifact(context.Background(), "text/test.txt")
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	require.NoError(t, artifact.Write(buf))
+	return buf.String()
+}
+
+func imageRed(t *testing.T, instance *graph.Instance) uint32 {
+	t.Helper()
+	img, ok := instance.Parameter("Node-2").(*parameter.Image)
+	require.True(t, ok)
+	r, _, _, _ := img.Value().At(0, 0).RGBA()
+	return r >> 8
+}
+
+func TestDocument_RoundTrip(t *testing.T) {
+	base := baseGraph(t)
+	doc := load(t, base)
+	require.Len(t, doc.Blobs, 1)
+
+	encoded, err := doc.Encode()
+	require.NoError(t, err)
+
+	instance := loadInstance(t, encoded)
+	assert.Equal(t, "hello", readText(t, instance))
+	assert.Equal(t, uint32(255), imageRed(t, instance))
+
+	changes, err := diff.Diff(doc, load(t, encoded))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	// Encoding is stable
+	again, err := load(t, encoded).Encode()
+	require.NoError(t, err)
+	assert.Equal(t, string(encoded), string(again))
+}
+
+func TestDiff(t *testing.T) {
+	base := baseGraph(t)
+	after := edit(t, base, func(instance *graph.Instance) {
+		_, err := instance.UpdateParameter("Node-0", []byte(`"goodbye"`))
+		require.NoError(t, err)
+		instance.DeleteNodeInputConnection("Node-1", "In")
+		instance.DeleteNode("Node-2")
+		instance.SetNodeAsProducer("Node-1", "other.txt")
+	})
+
+	changes, err := diff.Diff(load(t, base), load(t, after))
+	require.NoError(t, err)
+
+	lines := make([]string, len(changes))
+	for i, change := range changes {
+		lines[i] = change.String()
+	}
+
+	assert.Equal(t, []string{
+		`~ /nodes/Node-0/data/currentValue: "hello" -> "goodbye"`,
+		`- /nodes/Node-1/dependencies/In: {"dependencyID":"Node-0","dependencyPort":"Out"}`,
+		`- /nodes/Node-2: "github.com/EliCDavis/polyform/generator/parameter.Image"`,
+	}, lines[:3])
+
+	paths := make([]string, len(changes))
+	for i, change := range changes {
+		paths[i] = change.Path
+	}
+	assert.Contains(t, paths, "/nodes/Node-2/data/$CurrentValue")
+	assert.Contains(t, paths, "/producers/other.txt")
+}
+
+func TestMerge(t *testing.T) {
+	base := baseGraph(t)
+	ours := edit(t, base, func(instance *graph.Instance) {
+		_, err := instance.UpdateParameter("Node-0", []byte(`"ours"`))
+		require.NoError(t, err)
+
+		_, id, err := instance.CreateNode(refutil.GetTypeWithPackage(new(parameter.String)))
+		require.NoError(t, err)
+		assert.Equal(t, "Node-3", id)
+	})
+	theirs := edit(t, base, func(instance *graph.Instance) {
+		_, err := instance.UpdateParameter("Node-2", pngOf(t, color.RGBA{R: 7, A: 255}))
+		require.NoError(t, err)
+
+		_, id, err := instance.CreateNode(refutil.GetTypeWithPackage(new(basics.TextNode)))
+		require.NoError(t, err)
+		assert.Equal(t, "Node-3", id)
+		require.NoError(t, instance.ConnectNodes("Node-0", "Out", id, "In"))
+		instance.SetNodeAsProducer(id, "theirs.txt")
+	})
+
+	merged, conflicts, err := diff.Merge(load(t, base), load(t, ours), load(t, theirs), diff.Ours)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	encoded, err := merged.Encode()
+	require.NoError(t, err)
+	instance := loadInstance(t, encoded)
+
+	assert.Equal(t, "ours", readText(t, instance))
+	assert.Equal(t, uint32(7), imageRed(t, instance))
+	assert.Equal(t, []string{"text/test.txt", "theirs.txt"}, instance.ProducerNames())
+
+	// Both added a Node-3, so theirs was given an ID of its own
+	assert.IsType(t, &parameter.String{}, instance.Node("Node-3"))
+	assert.Equal(t, "Node-4", merged.App.Producers["theirs.txt"].NodeID)
+}
+
+func TestMerge_Conflicts(t *testing.T) {
+	base := baseGraph(t)
+	ours := edit(t, base, func(instance *graph.Instance) {
+		_, err := instance.UpdateParameter("Node-0", []byte(`"ours"`))
+		require.NoError(t, err)
+		instance.DeleteNode("Node-2")
+	})
+	theirs := edit(t, base, func(instance *graph.Instance) {
+		_, err := instance.UpdateParameter("Node-0", []byte(`"theirs"`))
+		require.NoError(t, err)
+		_, err = instance.UpdateParameter("Node-2", pngOf(t, color.RGBA{R: 7, A: 255}))
+		require.NoError(t, err)
+	})
+
+	for _, favor := range []diff.Side{diff.Ours, diff.Theirs} {
+		merged, conflicts, err := diff.Merge(load(t, base), load(t, ours), load(t, theirs), favor)
+		require.NoError(t, err)
+
+		paths := make([]string, len(conflicts))
+		for i, conflict := range conflicts {
+			paths[i] = conflict.Path
+		}
+		assert.Equal(t, []string{
+			"/nodes/Node-0/data/currentValue",
+			"/nodes/Node-2/data/$CurrentValue",
+		}, paths)
+		assert.Equal(t, `"ours"`, string(conflicts[0].Ours))
+		assert.Equal(t, `"theirs"`, string(conflicts[0].Theirs))
+		assert.Nil(t, conflicts[1].Ours)
+
+		encoded, err := merged.Encode()
+		require.NoError(t, err)
+		instance := loadInstance(t, encoded)
+
+		if favor == diff.Ours {
+			assert.Equal(t, "ours", readText(t, instance))
+		} else {
+			assert.Equal(t, "theirs", readText(t, instance))
+		}
+	}
+}
+
+func TestMerge_DanglingConnection(t *testing.T) {
+	base := baseGraph(t)
+	ours := edit(t, base, func(instance *graph.Instance) {
+		instance.DeleteNodeInputConnection("Node-1", "In")
+		instance.DeleteNode("Node-0")
+	})
+	theirs := edit(t, base, func(instance *graph.Instance) {
+		_, id, err := instance.CreateNode(refutil.GetTypeWithPackage(new(basics.TextNode)))
+		require.NoError(t, err)
+		require.NoError(t, instance.ConnectNodes("Node-0", "Out", id, "In"))
+	})
+
+	merged, conflicts, err := diff.Merge(load(t, base), load(t, ours), load(t, theirs), diff.Ours)
+	require.NoError(t, err)
+
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, "/nodes/Node-3/dependencies/In", conflicts[0].Path)
+	assert.Nil(t, conflicts[0].Base)
+	assert.Nil(t, conflicts[0].Ours)
+	assert.Equal(t, `{"dependencyID":"Node-0","dependencyPort":"Out"}`, string(conflicts[0].Theirs))
+
+	assert.NotContains(t, merged.App.Nodes, "Node-0")
+	assert.Empty(t, merged.App.Nodes["Node-1"].Dependencies)
+	assert.Empty(t, merged.App.Nodes["Node-3"].Dependencies)
+}
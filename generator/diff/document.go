@@ -0,0 +1,249 @@
+package diff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/EliCDavis/jbtf"
+	"github.com/EliCDavis/polyform/generator/schema"
+)
+
+const (
+	dataURIPrefix = "data:application/octet-stream;base64,"
+	blobPrefix    = "sha256:"
+)
+
+// Document is a graph file with the binary data its nodes embed pulled out of
+// the file's buffers. Nodes refer to their binary data by a hash of its
+// contents instead of by buffer view, so graphs can be compared regardless of
+// how their buffers happened to be laid out.
+type Document struct {
+	App   schema.App
+	Blobs map[string][]byte
+}
+
+// Load reads a graph file as written by the generator
+func Load(payload []byte) (*Document, error) {
+	file := jbtf.Schema[schema.App]{}
+	if err := json.Unmarshal(payload, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse graph as a jbtf: %w", err)
+	}
+
+	buffers := make([][]byte, len(file.Buffers))
+	for i, buf := range file.Buffers {
+		encoded, ok := strings.CutPrefix(buf.URI, dataURIPrefix)
+		if !ok {
+			return nil, fmt.Errorf("buffer %d: only buffers embedded as data URIs are supported", i)
+		}
+
+		data, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("buffer %d: %w", i, err)
+		}
+		buffers[i] = data
+	}
+
+	doc := &Document{
+		App:   file.Data,
+		Blobs: make(map[string][]byte),
+	}
+
+	resolve := func(reference any) (any, error) {
+		number, ok := reference.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("invalid buffer view %v", reference)
+		}
+
+		index, err := number.Int64()
+		if err != nil || index < 0 || int(index) >= len(file.BufferViews) {
+			return nil, fmt.Errorf("invalid buffer view %v", reference)
+		}
+
+		view := file.BufferViews[index]
+		if view.Buffer < 0 || view.Buffer >= len(buffers) || view.ByteOffset+view.ByteLength > len(buffers[view.Buffer]) {
+			return nil, fmt.Errorf("buffer view %d is out of bounds", index)
+		}
+
+		blob := buffers[view.Buffer][view.ByteOffset : view.ByteOffset+view.ByteLength]
+		sum := sha256.Sum256(blob)
+		key := blobPrefix + hex.EncodeToString(sum[:])
+		doc.Blobs[key] = blob
+		return key, nil
+	}
+
+	var err error
+	doc.App.Nodes, err = rewriteNodes(doc.App.Nodes, resolve)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, subgraph := range doc.App.Subgraphs {
+		subgraph.Nodes, err = rewriteNodes(subgraph.Nodes, resolve)
+		if err != nil {
+			return nil, fmt.Errorf("subgraph %s: %w", name, err)
+		}
+		doc.App.Subgraphs[name] = subgraph
+	}
+
+	return doc, nil
+}
+
+// Encode writes the document back out as a graph file, laying out the binary
+// data in order of the nodes referring to it
+func (d *Document) Encode() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	views := make([]jbtf.BufferView, 0)
+	viewIndices := make(map[string]int)
+
+	store := func(reference any) (any, error) {
+		key, ok := reference.(string)
+		if !ok || !strings.HasPrefix(key, blobPrefix) {
+			return nil, fmt.Errorf("unresolved binary reference %v", reference)
+		}
+
+		if index, ok := viewIndices[key]; ok {
+			return index, nil
+		}
+
+		blob, ok := d.Blobs[key]
+		if !ok {
+			return nil, fmt.Errorf("missing binary data for %s", key)
+		}
+
+		views = append(views, jbtf.BufferView{
+			Buffer:     0,
+			ByteOffset: buf.Len(),
+			ByteLength: len(blob),
+		})
+		buf.Write(blob)
+		viewIndices[key] = len(views) - 1
+		return len(views) - 1, nil
+	}
+
+	app := d.App
+
+	var err error
+	app.Nodes, err = rewriteNodes(d.App.Nodes, store)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.App.Subgraphs != nil {
+		app.Subgraphs = make(map[string]schema.Subgraph, len(d.App.Subgraphs))
+		for _, name := range sortedKeys(d.App.Subgraphs) {
+			subgraph := d.App.Subgraphs[name]
+			subgraph.Nodes, err = rewriteNodes(subgraph.Nodes, store)
+			if err != nil {
+				return nil, fmt.Errorf("subgraph %s: %w", name, err)
+			}
+			app.Subgraphs[name] = subgraph
+		}
+	}
+
+	return json.MarshalIndent(jbtf.Schema[schema.App]{
+		Buffers: []jbtf.Buffer{{
+			ByteLength: buf.Len(),
+			URI:        dataURIPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()),
+		}},
+		BufferViews: views,
+		Data:        app,
+	}, "", "\t")
+}
+
+// rewriteNodes returns a copy of the nodes with every binary reference within
+// their data rewritten, visiting nodes in order of their IDs
+func rewriteNodes(nodes map[string]schema.AppNodeInstance, rewrite func(reference any) (any, error)) (map[string]schema.AppNodeInstance, error) {
+	if nodes == nil {
+		return nil, nil
+	}
+
+	rewritten := make(map[string]schema.AppNodeInstance, len(nodes))
+	for _, id := range sortedKeys(nodes) {
+		node := nodes[id]
+		data, err := rewriteBinaryReferences(node.Data, rewrite)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", id, err)
+		}
+		node.Data = data
+		rewritten[id] = node
+	}
+	return rewritten, nil
+}
+
+// rewriteBinaryReferences replaces the value of every field jbtf uses to
+// refer to binary data, those keyed by the field's name prefixed with a $
+func rewriteBinaryReferences(data json.RawMessage, rewrite func(reference any) (any, error)) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	value, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var walk func(v any) error
+	walk = func(v any) error {
+		switch t := v.(type) {
+		case map[string]any:
+			// Visited in order so references are rewritten in the same order
+			// every time
+			for _, key := range sortedKeys(t) {
+				child := t[key]
+				if strings.HasPrefix(key, "$") {
+					rewritten, err := rewrite(child)
+					if err != nil {
+						return fmt.Errorf("%s: %w", key, err)
+					}
+					t[key] = rewritten
+					continue
+				}
+
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+
+		case []any:
+			for _, child := range t {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := walk(value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// decodeJSON decodes the data generically, keeping numbers exactly as they
+// were written
+func decodeJSON(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,239 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/EliCDavis/polyform/generator/schema"
+)
+
+// Side is one of the two graphs being merged
+type Side int
+
+const (
+	Ours Side = iota
+	Theirs
+)
+
+func (s Side) String() string {
+	if s == Theirs {
+		return "theirs"
+	}
+	return "ours"
+}
+
+// Conflict is something both sides changed in different ways, or a
+// connection or producer left referring to a node removed by the other side
+type Conflict struct {
+	Path   string          `json:"path"`
+	Base   json.RawMessage `json:"base,omitempty"`
+	Ours   json.RawMessage `json:"ours,omitempty"`
+	Theirs json.RawMessage `json:"theirs,omitempty"`
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%s: base %s, ours %s, theirs %s", c.Path, describe(c.Base), describe(c.Ours), describe(c.Theirs))
+}
+
+func describe(value json.RawMessage) string {
+	if value == nil {
+		return "(absent)"
+	}
+	return abbreviate(value)
+}
+
+// Merge performs a three way merge of the changes both sides made to the
+// base graph. Everything only one side changed is taken from that side, and
+// everything both sides changed in different ways is reported as a conflict
+// and resolved in favor of the side provided. Nodes both sides added under
+// the same ID are kept apart, with theirs moved to an ID of its own.
+func Merge(base, ours, theirs *Document, favor Side) (*Document, []Conflict, error) {
+	flats := make([]flatGraph, 3)
+	for i, app := range []schema.App{base.App, ours.App, separateAddedNodes(base.App, ours.App, theirs.App)} {
+		flat, err := flatten(app)
+		if err != nil {
+			return nil, nil, err
+		}
+		flats[i] = flat
+	}
+	baseFlat, oursFlat, theirsFlat := flats[0], flats[1], flats[2]
+
+	conflicts := make(map[string]Conflict)
+	conflictAt := func(path string) {
+		conflicts[path] = Conflict{
+			Path:   path,
+			Base:   baseFlat[path],
+			Ours:   oursFlat[path],
+			Theirs: theirsFlat[path],
+		}
+	}
+
+	merged := make(flatGraph)
+	for _, path := range unionPaths(baseFlat, oursFlat, theirsFlat) {
+		value, ok, conflicted := mergeValue(baseFlat, oursFlat, theirsFlat, path, favor)
+		if conflicted {
+			conflictAt(path)
+		}
+		if ok {
+			merged[path] = value
+		}
+	}
+
+	app, err := unflatten(merged)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Drop whatever was left referring to nodes the other side removed
+	for id, node := range app.Nodes {
+		dependencies := make([]schema.NodeDependency, 0, len(node.Dependencies))
+		for _, dependency := range node.Dependencies {
+			if _, ok := app.Nodes[dependency.DependencyID]; ok {
+				dependencies = append(dependencies, dependency)
+				continue
+			}
+			conflictAt(pointer("nodes", id, "dependencies", dependency.Name))
+		}
+		node.Dependencies = dependencies
+		app.Nodes[id] = node
+	}
+
+	for name, producer := range app.Producers {
+		if _, ok := app.Nodes[producer.NodeID]; !ok {
+			conflictAt(pointer("producers", name))
+			delete(app.Producers, name)
+		}
+	}
+
+	blobs := make(map[string][]byte)
+	for _, doc := range []*Document{base, ours, theirs} {
+		for key, blob := range doc.Blobs {
+			blobs[key] = blob
+		}
+	}
+
+	sortedConflicts := make([]Conflict, 0, len(conflicts))
+	for _, path := range sortedKeys(conflicts) {
+		sortedConflicts = append(sortedConflicts, conflicts[path])
+	}
+
+	return &Document{App: app, Blobs: blobs}, sortedConflicts, nil
+}
+
+// mergeValue picks the value at the path, returning whether or not the path
+// should be present at all and whether or not the two sides conflict
+func mergeValue(base, ours, theirs flatGraph, path string, favor Side) (value json.RawMessage, ok bool, conflicted bool) {
+	baseValue, inBase := base[path]
+	oursValue, inOurs := ours[path]
+	theirsValue, inTheirs := theirs[path]
+
+	same := func(aValue json.RawMessage, aOk bool, bValue json.RawMessage, bOk bool) bool {
+		return aOk == bOk && bytes.Equal(aValue, bValue)
+	}
+
+	switch {
+	case same(oursValue, inOurs, theirsValue, inTheirs):
+		return oursValue, inOurs, false
+
+	case same(oursValue, inOurs, baseValue, inBase):
+		return theirsValue, inTheirs, false
+
+	case same(theirsValue, inTheirs, baseValue, inBase):
+		return oursValue, inOurs, false
+	}
+
+	if favor == Theirs {
+		return theirsValue, inTheirs, true
+	}
+	return oursValue, inOurs, true
+}
+
+// separateAddedNodes moves nodes both sides added under the same ID that
+// aren't identical to IDs of their own within theirs, returning the graph
+// with everything referring to the nodes updated
+func separateAddedNodes(base, ours, theirs schema.App) schema.App {
+	taken := make(map[string]struct{})
+	for _, app := range []schema.App{base, ours, theirs} {
+		for id := range app.Nodes {
+			taken[id] = struct{}{}
+		}
+	}
+
+	renames := make(map[string]string)
+	for _, id := range sortedKeys(theirs.Nodes) {
+		if _, ok := base.Nodes[id]; ok {
+			continue
+		}
+
+		oursNode, ok := ours.Nodes[id]
+		if !ok || sameNode(oursNode, theirs.Nodes[id]) {
+			continue
+		}
+
+		renames[id] = nextNodeID(taken)
+	}
+
+	if len(renames) == 0 {
+		return theirs
+	}
+
+	rename := func(id string) string {
+		if renamed, ok := renames[id]; ok {
+			return renamed
+		}
+		return id
+	}
+
+	renamed := theirs
+	renamed.Nodes = make(map[string]schema.AppNodeInstance, len(theirs.Nodes))
+	for id, node := range theirs.Nodes {
+		dependencies := make([]schema.NodeDependency, len(node.Dependencies))
+		for i, dependency := range node.Dependencies {
+			dependency.DependencyID = rename(dependency.DependencyID)
+			dependencies[i] = dependency
+		}
+		node.Dependencies = dependencies
+		renamed.Nodes[rename(id)] = node
+	}
+
+	renamed.Producers = make(map[string]schema.Producer, len(theirs.Producers))
+	for name, producer := range theirs.Producers {
+		producer.NodeID = rename(producer.NodeID)
+		renamed.Producers[name] = producer
+	}
+
+	// Editor state such as node positions is kept by node ID
+	if nodeMetadata, ok := theirs.Metadata["nodes"].(map[string]any); ok {
+		renamed.Metadata = make(map[string]any, len(theirs.Metadata))
+		for key, value := range theirs.Metadata {
+			renamed.Metadata[key] = value
+		}
+
+		renamedNodeMetadata := make(map[string]any, len(nodeMetadata))
+		for id, value := range nodeMetadata {
+			renamedNodeMetadata[rename(id)] = value
+		}
+		renamed.Metadata["nodes"] = renamedNodeMetadata
+	}
+
+	return renamed
+}
+
+func sameNode(a, b schema.AppNodeInstance) bool {
+	aData, aErr := json.Marshal(a)
+	bData, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aData, bData)
+}
+
+// nextNodeID finds the first ID not taken, following the same naming the
+// graph uses
+func nextNodeID(taken map[string]struct{}) string {
+	for i := len(taken); ; i++ {
+		id := fmt.Sprintf("Node-%d", i)
+		if _, ok := taken[id]; !ok {
+			taken[id] = struct{}{}
+			return id
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EliCDavis/polyform/generator/artifact"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+// Consecutive updates to the same parameter within this window are recorded
+// as a single operation, so dragging a slider doesn't take just as many
+// undos to take back
+const parameterCoalesceWindow = time.Second
+
+type OperationKind string
+
+const (
+	CreateNodeOperation OperationKind = "create-node"
+	DeleteNodeOperation OperationKind = "delete-node"
+	ConnectOperation    OperationKind = "connect"
+	DisconnectOperation OperationKind = "disconnect"
+	ParameterOperation  OperationKind = "parameter"
+)
+
+// Operation is a single change made to the graph that can be undone, and once
+// undone, redone
+type Operation struct {
+	Kind        OperationKind `json:"kind"`
+	NodeID      string        `json:"nodeID"`
+	Description string        `json:"description"`
+	Time        time.Time     `json:"time"`
+
+	undo func()
+	redo func()
+}
+
+// History is the log of operations made to the graph
+type History struct {
+	Applied []Operation `json:"applied"` // Oldest first
+	Undone  []Operation `json:"undone"`  // Next to be redone first
+}
+
+// record logs the operation as the latest applied, discarding everything
+// that's been undone
+func (i *Instance) record(op Operation) {
+	i.historyLock.Lock()
+	defer i.historyLock.Unlock()
+
+	op.Time = time.Now()
+	i.undone = nil
+
+	if op.Kind == ParameterOperation && len(i.history) > 0 {
+		last := i.history[len(i.history)-1]
+		if last.Kind == ParameterOperation && last.NodeID == op.NodeID && op.Time.Sub(last.Time) < parameterCoalesceWindow {
+			op.undo = last.undo
+			i.history[len(i.history)-1] = op
+			return
+		}
+	}
+
+	i.history = append(i.history, op)
+}
+
+// ClearHistory forgets every operation applied and undone
+func (i *Instance) ClearHistory() {
+	i.historyLock.Lock()
+	defer i.historyLock.Unlock()
+	i.history = nil
+	i.undone = nil
+}
+
+// History returns the operations applied to the graph along with the ones
+// undone that can still be redone
+func (i *Instance) History() History {
+	i.historyLock.Lock()
+	defer i.historyLock.Unlock()
+
+	undone := make([]Operation, len(i.undone))
+	for index, op := range i.undone {
+		undone[len(undone)-1-index] = op
+	}
+
+	return History{
+		Applied: append([]Operation{}, i.history...),
+		Undone:  undone,
+	}
+}
+
+// Undo takes back the latest operation applied to the graph, returning
+// whether or not there was anything to undo
+func (i *Instance) Undo() bool {
+	i.historyLock.Lock()
+	defer i.historyLock.Unlock()
+
+	if len(i.history) == 0 {
+		return false
+	}
+
+	op := i.history[len(i.history)-1]
+	i.history = i.history[:len(i.history)-1]
+	op.undo()
+	i.undone = append(i.undone, op)
+	i.incModelVersion()
+	return true
+}
+
+// Redo re-applies the latest operation undone, returning whether or not there
+// was anything to redo
+func (i *Instance) Redo() bool {
+	i.historyLock.Lock()
+	defer i.historyLock.Unlock()
+
+	if len(i.undone) == 0 {
+		return false
+	}
+
+	op := i.undone[len(i.undone)-1]
+	i.undone = i.undone[:len(i.undone)-1]
+	op.redo()
+	i.history = append(i.history, op)
+	i.incModelVersion()
+	return true
+}
+
+func describeNode(node nodes.Node, id string) string {
+	if named, ok := node.(nodes.Named); ok {
+		return fmt.Sprintf("%s (%s)", id, named.Name())
+	}
+	return id
+}
+
+// removeNode takes the node out of the graph, returning the producers that
+// were removed along with it
+func (i *Instance) removeNode(node nodes.Node) map[string]nodes.NodeOutput[artifact.Artifact] {
+	nodeID := i.nodeIDs[node]
+
+	removed := make(map[string]nodes.NodeOutput[artifact.Artifact])
+	for filename, producer := range i.producers {
+		if producer.Node() == node {
+			removed[filename] = producer
+			delete(i.producers, filename)
+		}
+	}
+
+	delete(i.nodeIDs, node)
+
+	i.evaluationLock.Lock()
+	delete(i.timings, nodeID)
+	i.evaluationLock.Unlock()
+
+	return removed
+}
+
+// restoreNode puts a node previously removed back under the ID it had
+func (i *Instance) restoreNode(node nodes.Node, nodeID string, producers map[string]nodes.NodeOutput[artifact.Artifact]) {
+	i.nodeIDs[node] = nodeID
+	for filename, producer := range producers {
+		i.producers[filename] = producer
+	}
+}
+
+// connection finds what's currently feeding the node's input
+func connection(node nodes.Node, input string) nodes.Output {
+	for _, dep := range node.Dependencies() {
+		if dep.Name() != input {
+			continue
+		}
+
+		output, err := nodes.GetOutput(dep.Dependency(), dep.DependencyPort())
+		if err == nil {
+			return output
+		}
+	}
+	return nodes.Output{}
+}
+
+// arrayConnections finds what's currently feeding each element of the node's
+// array input, in order
+func arrayConnections(node nodes.Node, input string) []nodes.Output {
+	connections := make([]nodes.Output, 0)
+	for {
+		output := connection(node, fmt.Sprintf("%s.%d", input, len(connections)))
+		if output.NodeOutput == nil {
+			return connections
+		}
+		connections = append(connections, output)
+	}
+}
+
+// restoreConnection feeds the output back into the node's input. Setting an
+// element of an array input always appends, so to put one back at the index
+// it was taken from, everything after it is taken off and re-appended.
+func restoreConnection(node nodes.Node, input string, output nodes.Output) {
+	name, element, isElement := strings.Cut(input, ".")
+	index, err := strconv.Atoi(element)
+	if !isElement || err != nil || output.NodeOutput == nil {
+		node.SetInput(input, output)
+		return
+	}
+
+	connections := arrayConnections(node, name)
+	after := connections[min(index, len(connections)):]
+	for range after {
+		node.SetInput(input, nodes.Output{})
+	}
+
+	node.SetInput(input, output)
+	for _, connection := range after {
+		node.SetInput(input, connection)
+	}
+}
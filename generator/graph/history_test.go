@@ -0,0 +1,157 @@
+package graph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/EliCDavis/polyform/generator/graph"
+	"github.com/EliCDavis/polyform/generator/parameter"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/polyform/refutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func historyKinds(ops []graph.Operation) []graph.OperationKind {
+	kinds := make([]graph.OperationKind, 0, len(ops))
+	for _, op := range ops {
+		kinds = append(kinds, op.Kind)
+	}
+	return kinds
+}
+
+type JoinNode = nodes.Struct[string, JoinNodeData]
+
+type JoinNodeData struct {
+	In []nodes.NodeOutput[string]
+}
+
+func (jn JoinNodeData) Process() (string, error) {
+	values := make([]string, 0, len(jn.In))
+	for _, in := range jn.In {
+		values = append(values, in.Value())
+	}
+	return strings.Join(values, ""), nil
+}
+
+func TestInstance_UndoRedo(t *testing.T) {
+	instance := graph.New(subgraphTypeFactory())
+
+	paramNode, paramID, err := instance.CreateNode(refutil.GetTypeWithPackage(new(parameter.String)))
+	require.NoError(t, err)
+	_, textID, err := instance.CreateNode(refutil.GetTypeWithPackage(new(basics.TextNode)))
+	require.NoError(t, err)
+	require.NoError(t, instance.ConnectNodes(paramID, "Out", textID, "In"))
+	instance.SetNodeAsProducer(textID, "test.txt")
+
+	_, err = instance.UpdateParameter(paramID, []byte(`"first"`))
+	require.NoError(t, err)
+	assert.Equal(t, "first", readArtifact(t, instance, "test.txt"))
+
+	assert.Equal(t, []graph.OperationKind{
+		graph.CreateNodeOperation,
+		graph.CreateNodeOperation,
+		graph.ConnectOperation,
+		graph.ParameterOperation,
+	}, historyKinds(instance.History().Applied))
+
+	// Parameter
+	assert.True(t, instance.Undo())
+	assert.Equal(t, "", readArtifact(t, instance, "test.txt"))
+	assert.True(t, instance.Redo())
+	assert.Equal(t, "first", readArtifact(t, instance, "test.txt"))
+	assert.False(t, instance.Redo())
+
+	// Disconnecting
+	instance.DeleteNodeInputConnection(textID, "In")
+	assert.Empty(t, instance.Node(textID).Dependencies())
+	assert.True(t, instance.Undo())
+	require.Len(t, instance.Node(textID).Dependencies(), 1)
+	assert.Equal(t, paramNode, instance.Node(textID).Dependencies()[0].Dependency())
+
+	// Deleting the producer's node takes the producer along with it
+	instance.DeleteNode(textID)
+	assert.Empty(t, instance.ProducerNames())
+	assert.True(t, instance.Undo())
+	assert.Equal(t, []string{"test.txt"}, instance.ProducerNames())
+	assert.Equal(t, "first", readArtifact(t, instance, "test.txt"))
+
+	// Deleting discarded the disconnect undone before it
+	history := instance.History()
+	assert.Len(t, history.Applied, 4)
+	assert.Equal(t, []graph.OperationKind{graph.DeleteNodeOperation}, historyKinds(history.Undone))
+
+	// Making a change discards everything undone
+	_, err = instance.UpdateParameter(paramID, []byte(`"second"`))
+	require.NoError(t, err)
+	assert.Empty(t, instance.History().Undone)
+}
+
+func TestInstance_UndoCreateNode(t *testing.T) {
+	instance := graph.New(subgraphTypeFactory())
+
+	node, id, err := instance.CreateNode(refutil.GetTypeWithPackage(new(parameter.String)))
+	require.NoError(t, err)
+
+	assert.True(t, instance.Undo())
+	assert.Panics(t, func() { instance.Node(id) })
+	assert.False(t, instance.Undo())
+
+	// Comes back under the same ID
+	assert.True(t, instance.Redo())
+	assert.Equal(t, node, instance.Node(id))
+}
+
+func TestInstance_ParameterUpdatesCoalesce(t *testing.T) {
+	param := &parameter.String{Name: "Message", DefaultValue: "hello"}
+	instance := graph.New(subgraphTypeFactory())
+	instance.AddProducer("test.txt", basics.NewTextNode(param))
+	id := instance.NodeId(param)
+
+	for _, value := range []string{`"a"`, `"ab"`, `"abc"`} {
+		_, err := instance.UpdateParameter(id, []byte(value))
+		require.NoError(t, err)
+	}
+
+	history := instance.History()
+	require.Len(t, history.Applied, 1)
+	assert.Equal(t, graph.ParameterOperation, history.Applied[0].Kind)
+	assert.Equal(t, id, history.Applied[0].NodeID)
+
+	assert.True(t, instance.Undo())
+	assert.Equal(t, "hello", param.Value())
+	assert.True(t, instance.Redo())
+	assert.Equal(t, "abc", param.Value())
+}
+
+func TestInstance_UndoRedoArrayInput(t *testing.T) {
+	a := &parameter.String{Name: "A", DefaultValue: "a"}
+	b := &parameter.String{Name: "B", DefaultValue: "b"}
+	c := &parameter.String{Name: "C", DefaultValue: "c"}
+	join := &JoinNode{Data: JoinNodeData{In: []nodes.NodeOutput[string]{a, b, c}}}
+
+	instance := graph.New(subgraphTypeFactory())
+	instance.AddProducer("test.txt", basics.NewTextNode(join.Out()))
+	joinID := instance.NodeId(join)
+	assert.Equal(t, "abc", readArtifact(t, instance, "test.txt"))
+
+	// Disconnecting an element puts it back where it was
+	instance.DeleteNodeInputConnection(joinID, "In.1")
+	assert.Equal(t, "ac", readArtifact(t, instance, "test.txt"))
+	assert.True(t, instance.Undo())
+	assert.Equal(t, "abc", readArtifact(t, instance, "test.txt"))
+	assert.True(t, instance.Redo())
+	assert.Equal(t, "ac", readArtifact(t, instance, "test.txt"))
+	assert.True(t, instance.Undo())
+	assert.Equal(t, "abc", readArtifact(t, instance, "test.txt"))
+
+	// Connecting appends, regardless of the index asked for, and undoing
+	// takes that element back off
+	require.NoError(t, instance.ConnectNodes(instance.NodeId(b), "Out", joinID, "In.0"))
+	assert.Equal(t, "abcb", readArtifact(t, instance, "test.txt"))
+	assert.True(t, instance.Undo())
+	assert.Equal(t, "abc", readArtifact(t, instance, "test.txt"))
+	assert.True(t, instance.Redo())
+	assert.Equal(t, "abcb", readArtifact(t, instance, "test.txt"))
+}
@@ -1,10 +1,13 @@
 package graph
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/EliCDavis/jbtf"
 	"github.com/EliCDavis/polyform/generator/artifact"
@@ -24,15 +27,32 @@ type Instance struct {
 	producers    map[string]nodes.NodeOutput[artifact.Artifact]
 	metadata     *sync.NestedSyncMap
 	producerLock gsync.Mutex
+
+	evaluator        nodes.Evaluator
+	evaluationLock   gsync.Mutex
+	cancelEvaluation context.CancelFunc
+	timings          map[string]time.Duration
+
+	subgraphs map[string]*subgraphDefinition
+	editing   *subgraphEdit
+
+	history     []Operation
+	undone      []Operation
+	historyLock gsync.Mutex
 }
 
+// New creates an empty graph able to build any of the types registered with
+// the factory. The instance works off of a copy of the factory, so subgraphs
+// registered with one graph are never available to another.
 func New(typeFactory *refutil.TypeFactory) *Instance {
 	return &Instance{
-		typeFactory: typeFactory,
+		typeFactory: typeFactory.Combine(),
 
 		nodeIDs:      make(map[nodes.Node]string),
 		metadata:     sync.NewNestedSyncMap(),
 		producers:    make(map[string]nodes.NodeOutput[artifact.Artifact]),
+		timings:      make(map[string]time.Duration),
+		subgraphs:    make(map[string]*subgraphDefinition),
 		movelVersion: 0,
 	}
 }
@@ -57,12 +77,16 @@ func (i *Instance) NodeInstanceSchema(node nodes.Node) schema.NodeInstance {
 
 	nodeInstance := schema.NodeInstance{
 		Name:         "Unamed",
-		Type:         refutil.GetTypeWithPackage(node),
+		Type:         nodeType(node),
 		Dependencies: make([]schema.NodeDependency, 0),
 		Version:      node.Version(),
 		Metadata:     metadata,
 	}
 
+	if err := i.NodeError(node); err != nil {
+		nodeInstance.Error = err.Error()
+	}
+
 	for _, subDependency := range node.Dependencies() {
 		nodeInstance.Dependencies = append(nodeInstance.Dependencies, schema.NodeDependency{
 			DependencyID:   i.nodeIDs[subDependency.Dependency()],
@@ -84,7 +108,20 @@ func (i *Instance) NodeInstanceSchema(node nodes.Node) schema.NodeInstance {
 	return nodeInstance
 }
 
+// nodeType is the key the node's type is registered under
+func nodeType(node nodes.Node) string {
+	if subgraph, ok := node.(*Subgraph); ok {
+		return SubgraphType(subgraph.Name())
+	}
+	return refutil.GetTypeWithPackage(node)
+}
+
 func (i *Instance) addType(v any) {
+	// Subgraphs are registered alongside their definition
+	if _, ok := v.(*Subgraph); ok {
+		return
+	}
+
 	if !i.typeFactory.TypeRegistered(v) {
 		i.typeFactory.RegisterType(v)
 	}
@@ -129,77 +166,71 @@ func (i *Instance) Reset() {
 	i.nodeIDs = make(map[nodes.Node]string)
 	i.metadata = sync.NewNestedSyncMap()
 	i.producers = make(map[string]nodes.NodeOutput[artifact.Artifact])
+
+	i.evaluationLock.Lock()
+	i.timings = make(map[string]time.Duration)
+	i.evaluationLock.Unlock()
+
+	i.clearSubgraphs()
+	i.editing = nil
+	i.ClearHistory()
 }
 
+// ApplyAppSchema replaces the graph with the one described, upgrading graphs
+// written with older versions of the format along the way. Nodes that can't
+// be upgraded are left out of the graph and reported through a
+// *MigrationError, with the rest of the graph still applied.
 func (i *Instance) ApplyAppSchema(jsonPayload []byte) error {
 	appSchema, err := jbtf.Unmarshal[schema.App](jsonPayload)
 	if err != nil {
 		return fmt.Errorf("unable to parse graph as a jbtf: %w", err)
 	}
 
+	migrationErr, err := i.migrateAppSchema(&appSchema)
+	if err != nil {
+		return err
+	}
+
 	decoder, err := jbtf.NewDecoder(jsonPayload)
 	if err != nil {
 		return fmt.Errorf("unable to build a jbtf decoder: %w", err)
 	}
 
 	i.Reset()
+	if err := i.registerSubgraphs(appSchema.Subgraphs, decoder); err != nil {
+		return err
+	}
 	i.metadata.OverwriteData(appSchema.Metadata)
 
-	createdNodes := make(map[string]nodes.Node)
-
-	// Create the Nodes
-	for nodeID, instanceDetails := range appSchema.Nodes {
-		if nodeID == "" {
-			panic("attempting to create a node without an ID")
-		}
-		newNode := i.typeFactory.New(instanceDetails.Type)
-		casted, ok := newNode.(nodes.Node)
-		if !ok {
-			panic(fmt.Errorf("graph definition contained type that instantiated a non node: %s", instanceDetails.Type))
-		}
-		createdNodes[nodeID] = casted
-		i.nodeIDs[casted] = nodeID
+	createdNodes, err := buildNodes(i.typeFactory, appSchema.Nodes, decoder)
+	if err != nil {
+		return err
 	}
 
-	// Connect the nodes we just created
-	for nodeID, instanceDetails := range appSchema.Nodes {
-		node := createdNodes[nodeID]
-		for _, dependency := range instanceDetails.Dependencies {
-
-			outNode := createdNodes[dependency.DependencyID]
-			outPortVals := refutil.CallFuncValuesOfType(outNode, dependency.DependencyPort)
-			ref := outPortVals[0].(nodes.NodeOutputReference)
-
-			node.SetInput(dependency.Name, nodes.Output{
-				NodeOutput: ref,
-			})
-		}
+	for nodeID, node := range createdNodes {
+		i.nodeIDs[node] = nodeID
 	}
 
 	// Set the Producers
 	for fileName, producerDetails := range appSchema.Producers {
 		producerNode := createdNodes[producerDetails.NodeID]
-		outPortVals := refutil.CallFuncValuesOfType(producerNode, producerDetails.Port)
-		ref := outPortVals[0].(nodes.NodeOutput[artifact.Artifact])
-		if ref == nil {
-			panic(fmt.Errorf("REF IS NIL FOR FILE %s (node id: %s) and port %s", fileName, producerDetails.NodeID, producerDetails.Port))
+		output, err := nodes.GetOutput(producerNode, producerDetails.Port)
+		if err != nil {
+			return fmt.Errorf("producer %s (node id: %s): %w", fileName, producerDetails.NodeID, err)
 		}
-		i.producers[fileName] = ref
-	}
 
-	// Set Parameters
-	for nodeID, instanceDetails := range appSchema.Nodes {
-		nodeI := createdNodes[nodeID]
-		if p, ok := nodeI.(CustomGraphSerialization); ok {
-			err := p.FromJSON(decoder, instanceDetails.Data)
-			if err != nil {
-				return err
-			}
+		ref, ok := output.NodeOutput.(nodes.NodeOutput[artifact.Artifact])
+		if !ok {
+			return fmt.Errorf("producer %s (node id: %s) port %s does not produce an artifact", fileName, producerDetails.NodeID, producerDetails.Port)
 		}
+		i.producers[fileName] = ref
 	}
 
 	i.incModelVersion()
 
+	if migrationErr != nil {
+		return migrationErr
+	}
 	return nil
 }
 
@@ -268,18 +299,37 @@ func (i *Instance) Schema() schema.GraphInstance {
 }
 
 func (i *Instance) EncodeToAppSchema(appSchema *schema.App, encoder *jbtf.Encoder) {
-	nodeInstances := make(map[string]schema.AppNodeInstance)
-	for node := range i.nodeIDs {
-		id, ok := i.nodeIDs[node]
-		if !ok {
-			panic(fmt.Errorf("node %v has not had an ID generated for it", node))
-		}
+	// A subgraph being edited isn't a part of the graph until editing
+	// finishes, so what's written out is the graph it was opened from
+	if i.editing != nil {
+		i.editing.graph.EncodeToAppSchema(appSchema, encoder)
+		return
+	}
+
+	appSchema.FormatVersion = schema.CurrentFormatVersion
 
+	// Nodes are encoded in order of their IDs so binary data lands in the
+	// same place within the buffers every time the graph is saved
+	nodeInstances := make(map[string]schema.AppNodeInstance)
+	for _, node := range sortedByID(i.nodeIDs) {
+		id := i.nodeIDs[node]
 		if _, ok := nodeInstances[id]; ok {
 			panic(fmt.Errorf("we've arrived to a invalid state. two nodes refer to the same ID. There's a bug somewhere"))
 		}
 
-		nodeInstances[id] = i.buildNodeGraphInstanceSchema(node, encoder)
+		nodeInstances[id] = encodeNode(node, i.nodeIDs, encoder)
+	}
+
+	if len(i.subgraphs) > 0 {
+		appSchema.Subgraphs = make(map[string]schema.Subgraph)
+		for _, name := range i.SubgraphNames() {
+			definition := i.subgraphs[name]
+			subgraph, err := encodeSubgraph(definition, encoder)
+			if err != nil {
+				panic(fmt.Errorf("unable to encode subgraph %s: %w", name, err))
+			}
+			appSchema.Subgraphs[name] = subgraph
+		}
 	}
 
 	if appSchema.Producers == nil {
@@ -302,24 +352,24 @@ func (i *Instance) EncodeToAppSchema(appSchema *schema.App, encoder *jbtf.Encode
 	appSchema.Metadata = i.metadata.Data()
 }
 
-func (i *Instance) buildNodeGraphInstanceSchema(node nodes.Node, encoder *jbtf.Encoder) schema.AppNodeInstance {
+// encodeNode builds the schema of the node, referring to its dependencies by
+// the IDs provided
+func encodeNode(node nodes.Node, ids map[nodes.Node]string, encoder *jbtf.Encoder) schema.AppNodeInstance {
 
 	nodeInstance := schema.AppNodeInstance{
-		Type:         refutil.GetTypeWithPackage(node),
+		Type:         nodeType(node),
 		Dependencies: make([]schema.NodeDependency, 0),
 	}
 
 	for _, subDependency := range node.Dependencies() {
 		nodeInstance.Dependencies = append(nodeInstance.Dependencies, schema.NodeDependency{
-			DependencyID:   i.nodeIDs[subDependency.Dependency()],
+			DependencyID:   ids[subDependency.Dependency()],
 			DependencyPort: subDependency.DependencyPort(),
 			Name:           subDependency.Name(),
 		})
 	}
 
-	sort.Slice(nodeInstance.Dependencies, func(i, j int) bool {
-		return strings.ToLower(nodeInstance.Dependencies[i].Name) < strings.ToLower(nodeInstance.Dependencies[j].Name)
-	})
+	schema.SortDependencies(nodeInstance.Dependencies)
 
 	if param, ok := node.(CustomGraphSerialization); ok {
 		data, err := param.ToJSON(encoder)
@@ -339,12 +389,20 @@ func (i *Instance) NodeId(node nodes.Node) string {
 }
 
 func (i *Instance) Node(nodeId string) nodes.Node {
+	node, ok := i.nodeByID(nodeId)
+	if !ok {
+		panic(fmt.Errorf("no node exists with id %q", nodeId))
+	}
+	return node
+}
+
+func (i *Instance) nodeByID(nodeId string) (nodes.Node, bool) {
 	for n, id := range i.nodeIDs {
 		if id == nodeId {
-			return n
+			return n, true
 		}
 	}
-	panic(fmt.Errorf("no node exists with id %q", nodeId))
+	return nil, false
 }
 
 func (i *Instance) CreateNode(nodeType string) (nodes.Node, string, error) {
@@ -358,26 +416,562 @@ func (i *Instance) CreateNode(nodeType string) (nodes.Node, string, error) {
 		panic(fmt.Errorf("Regiestered type did not create a node. How'd ya manage that: %s", nodeType))
 	}
 	i.buildIDsForNode(casted)
+	nodeID := i.nodeIDs[casted]
+
+	i.record(Operation{
+		Kind:        CreateNodeOperation,
+		NodeID:      nodeID,
+		Description: fmt.Sprintf("Create %s", describeNode(casted, nodeID)),
+		undo:        func() { i.removeNode(casted) },
+		redo:        func() { i.restoreNode(casted, nodeID, nil) },
+	})
 
-	return casted, i.nodeIDs[casted], nil
+	return casted, nodeID, nil
 }
 
 func (i *Instance) DeleteNode(nodeId string) {
-	var nodeToDelete nodes.Node
+	nodeToDelete, ok := i.nodeByID(nodeId)
+	if !ok {
+		return
+	}
 
-	for n, id := range i.nodeIDs {
-		if id == nodeId {
-			nodeToDelete = n
+	producers := i.removeNode(nodeToDelete)
+
+	i.record(Operation{
+		Kind:        DeleteNodeOperation,
+		NodeID:      nodeId,
+		Description: fmt.Sprintf("Delete %s", describeNode(nodeToDelete, nodeId)),
+		undo:        func() { i.restoreNode(nodeToDelete, nodeId, producers) },
+		redo:        func() { i.removeNode(nodeToDelete) },
+	})
+}
+
+// SUBGRAPHS ==================================================================
+
+// RegisterSubgraph makes the subgraph available as a node type, replacing any
+// subgraph previously registered by the same name. The decoder is what the
+// data of the subgraph's nodes was encoded with.
+func (i *Instance) RegisterSubgraph(subgraph schema.Subgraph, decoder jbtf.Decoder) error {
+	definition, err := i.newSubgraphDefinition(subgraph, decoder)
+	if err != nil {
+		return err
+	}
+
+	i.subgraphs[subgraph.Name] = definition
+	i.typeFactory.RegisterBuilder(SubgraphType(subgraph.Name), func() any {
+		built, err := definition.build()
+		if err != nil {
+			panic(fmt.Errorf("unable to build subgraph %s: %w", subgraph.Name, err))
 		}
+		return built
+	})
+	i.incModelVersion()
+	return nil
+}
+
+// newSubgraphDefinition validates the subgraph, building it once up front so
+// problems surface here rather than every time it's instantiated
+func (i *Instance) newSubgraphDefinition(subgraph schema.Subgraph, decoder jbtf.Decoder) (*subgraphDefinition, error) {
+	if strings.TrimSpace(subgraph.Name) == "" {
+		return nil, errors.New("subgraph requires a name")
 	}
 
-	for filename, producer := range i.producers {
-		if i.nodeIDs[producer.Node()] == nodeId {
-			delete(i.producers, filename)
+	definition := &subgraphDefinition{
+		schema:      subgraph,
+		decoder:     decoder,
+		typeFactory: i.typeFactory,
+	}
+
+	if i.subgraphContains(definition, subgraph.Name, make(map[string]struct{})) {
+		return nil, fmt.Errorf("subgraph %s can not contain itself", subgraph.Name)
+	}
+
+	if _, err := definition.build(); err != nil {
+		return nil, fmt.Errorf("invalid subgraph %s: %w", subgraph.Name, err)
+	}
+
+	return definition, nil
+}
+
+// clearSubgraphs unregisters every subgraph, leaving the instance with only
+// the types it was created with
+func (i *Instance) clearSubgraphs() {
+	for name := range i.subgraphs {
+		i.typeFactory.Unregister(SubgraphType(name))
+	}
+	i.subgraphs = make(map[string]*subgraphDefinition)
+}
+
+// subgraphContains determines whether or not the definition, or any of the
+// subgraphs used within it, makes use of the subgraph with the name provided
+func (i *Instance) subgraphContains(definition *subgraphDefinition, name string, visited map[string]struct{}) bool {
+	for _, used := range definition.subgraphTypes() {
+		if used == name {
+			return true
+		}
+
+		if _, ok := visited[used]; ok {
+			continue
 		}
+		visited[used] = struct{}{}
+
+		if usedDefinition, ok := i.subgraphs[used]; ok && i.subgraphContains(usedDefinition, name, visited) {
+			return true
+		}
+	}
+	return false
+}
+
+// registerSubgraphs registers all subgraphs provided, taking care to register
+// subgraphs used within others first
+func (i *Instance) registerSubgraphs(subgraphs map[string]schema.Subgraph, decoder jbtf.Decoder) error {
+	remaining := make(map[string]schema.Subgraph)
+	for name, subgraph := range subgraphs {
+		subgraph.Name = name
+		remaining[name] = subgraph
+	}
+
+	for len(remaining) > 0 {
+		ready := make([]string, 0)
+		for name, subgraph := range remaining {
+			usesRemaining := false
+			for _, used := range (subgraphDefinition{schema: subgraph}).subgraphTypes() {
+				if _, ok := remaining[used]; ok {
+					usesRemaining = true
+					break
+				}
+			}
+
+			if !usesRemaining {
+				ready = append(ready, name)
+			}
+		}
+
+		if len(ready) == 0 {
+			return errors.New("subgraphs can not contain one another")
+		}
+
+		sort.Strings(ready)
+		for _, name := range ready {
+			if err := i.RegisterSubgraph(remaining[name], decoder); err != nil {
+				return err
+			}
+			delete(remaining, name)
+		}
+	}
+
+	return nil
+}
+
+// LoadSubgraph registers the subgraph found within the jbtf payload,
+// returning it's name
+func (i *Instance) LoadSubgraph(payload []byte) (string, error) {
+	subgraph, decoder, err := decodeSubgraph(payload)
+	if err != nil {
+		return "", err
+	}
+	return subgraph.Name, i.RegisterSubgraph(subgraph, decoder)
+}
+
+func decodeSubgraph(payload []byte) (schema.Subgraph, jbtf.Decoder, error) {
+	subgraph, err := jbtf.Unmarshal[schema.Subgraph](payload)
+	if err != nil {
+		return schema.Subgraph{}, jbtf.Decoder{}, fmt.Errorf("unable to parse subgraph as a jbtf: %w", err)
+	}
+
+	decoder, err := jbtf.NewDecoder(payload)
+	if err != nil {
+		return schema.Subgraph{}, jbtf.Decoder{}, fmt.Errorf("unable to build a jbtf decoder: %w", err)
+	}
+	return subgraph, decoder, nil
+}
+
+// EncodeSubgraph writes the definition of the subgraph out as a jbtf file of
+// its own, which can be loaded with LoadSubgraph
+func (i *Instance) EncodeSubgraph(name string) ([]byte, error) {
+	definition, ok := i.subgraphs[name]
+	if !ok {
+		return nil, fmt.Errorf("no subgraph registered with the name %q", name)
+	}
+
+	encoder := &jbtf.Encoder{}
+	subgraph, err := encodeSubgraph(definition, encoder)
+	if err != nil {
+		return nil, err
+	}
+	return encoder.ToPgtf(subgraph)
+}
+
+// encodeSubgraph re-encodes the data of the definition's nodes with the
+// encoder provided, as the data may refer to buffers of whatever it was
+// originally decoded from
+func encodeSubgraph(definition *subgraphDefinition, encoder *jbtf.Encoder) (schema.Subgraph, error) {
+	built, err := definition.build()
+	if err != nil {
+		return schema.Subgraph{}, err
+	}
+
+	ids := make(map[nodes.Node]string, len(built.nodes))
+	for id, node := range built.nodes {
+		ids[node] = id
+	}
+
+	subgraph := definition.schema
+	subgraph.Nodes = make(map[string]schema.AppNodeInstance, len(built.nodes))
+	for _, node := range sortedByID(ids) {
+		subgraph.Nodes[ids[node]] = encodeNode(node, ids, encoder)
 	}
+	return subgraph, nil
+}
+
+// sortedByID orders the nodes by their IDs
+func sortedByID(ids map[nodes.Node]string) []nodes.Node {
+	sorted := make([]nodes.Node, 0, len(ids))
+	for node := range ids {
+		sorted = append(sorted, node)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return ids[sorted[i]] < ids[sorted[j]]
+	})
+	return sorted
+}
 
-	delete(i.nodeIDs, nodeToDelete)
+// SubgraphNames lists the names of all registered subgraphs
+func (i *Instance) SubgraphNames() []string {
+	names := make([]string, 0, len(i.subgraphs))
+	for name := range i.subgraphs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreateSubgraph registers a subgraph built from copies of the nodes provided,
+// leaving the nodes themselves untouched. Parameters the nodes depend on
+// become the subgraph's inputs, and outputs of the nodes used by anything
+// else, or by nothing at all, become the subgraph's outputs.
+func (i *Instance) CreateSubgraph(name string, nodeIDs []string) error {
+	selected := make(map[nodes.Node]struct{})
+	for _, id := range nodeIDs {
+		node, ok := i.nodeByID(id)
+		if !ok {
+			return fmt.Errorf("no node exists with id %q", id)
+		}
+		selected[node] = struct{}{}
+	}
+
+	if len(selected) == 0 {
+		return errors.New("subgraph requires at least one node")
+	}
+
+	sortedIDs := append([]string(nil), nodeIDs...)
+	sort.Strings(sortedIDs)
+
+	encoder := &jbtf.Encoder{}
+	subgraph := schema.Subgraph{
+		Name:    name,
+		Inputs:  make(map[string]schema.SubgraphPort),
+		Outputs: make(map[string]schema.SubgraphPort),
+		Nodes:   make(map[string]schema.AppNodeInstance),
+	}
+
+	uniqueName := func(ports map[string]schema.SubgraphPort, names ...string) string {
+		for _, n := range names {
+			if _, taken := ports[n]; !taken {
+				return n
+			}
+		}
+		for count := 2; ; count++ {
+			n := fmt.Sprintf("%s %d", names[len(names)-1], count)
+			if _, taken := ports[n]; !taken {
+				return n
+			}
+		}
+	}
+
+	// Outputs, by node ID and port, consumed within the selection
+	consumedWithin := make(map[string]bool)
+
+	for _, id := range sortedIDs {
+		node := i.Node(id)
+		subgraph.Nodes[id] = encodeNode(node, i.nodeIDs, encoder)
+
+		for _, dep := range node.Dependencies() {
+			depNode := dep.Dependency()
+			if _, ok := selected[depNode]; ok {
+				consumedWithin[i.nodeIDs[depNode]+"/"+dep.DependencyPort()] = true
+				continue
+			}
+
+			param, ok := depNode.(Parameter)
+			if !ok {
+				return fmt.Errorf("%s depends on %s, which is neither a part of the subgraph nor a parameter", id, i.nodeIDs[depNode])
+			}
+
+			depID := i.nodeIDs[depNode]
+			if _, ok := subgraph.Nodes[depID]; ok {
+				continue
+			}
+
+			subgraph.Nodes[depID] = encodeNode(depNode, i.nodeIDs, encoder)
+			subgraph.Inputs[uniqueName(subgraph.Inputs, param.DisplayName())] = schema.SubgraphPort{
+				NodeID: depID,
+				Port:   dep.DependencyPort(),
+			}
+		}
+	}
+
+	// Outputs consumed by anything outside of the selection
+	consumedOutside := make(map[string]bool)
+	for node := range i.nodeIDs {
+		if _, ok := selected[node]; ok {
+			continue
+		}
+
+		for _, dep := range node.Dependencies() {
+			if _, ok := selected[dep.Dependency()]; ok {
+				consumedOutside[i.nodeIDs[dep.Dependency()]+"/"+dep.DependencyPort()] = true
+			}
+		}
+	}
+	for _, producer := range i.producers {
+		consumedOutside[i.nodeIDs[producer.Node()]+"/"+producer.Port()] = true
+	}
+
+	for _, id := range sortedIDs {
+		node := i.Node(id)
+		for _, output := range node.Outputs() {
+			port := output.NodeOutput.Port()
+			if consumedWithin[id+"/"+port] && !consumedOutside[id+"/"+port] {
+				continue
+			}
+
+			label := id
+			if named, ok := node.(nodes.Named); ok {
+				label = named.Name()
+			}
+
+			subgraph.Outputs[uniqueName(subgraph.Outputs, port, label+" "+port)] = schema.SubgraphPort{
+				NodeID: id,
+				Port:   port,
+			}
+		}
+	}
+
+	// Carry over where the nodes sit in the editor for whenever the
+	// subgraph is opened for editing
+	placements := make(map[string]any)
+	for id := range subgraph.Nodes {
+		path := "nodes." + id
+		if !i.metadata.PathExists(path) {
+			continue
+		}
+		if data := i.metadata.Get(path); data != nil {
+			placements[id] = data
+		}
+	}
+	if len(placements) > 0 {
+		subgraph.Metadata = map[string]any{"nodes": placements}
+	}
+
+	// Round trip through the encoder so the subgraph's data is read back the
+	// same way it would be from a file
+	data, err := encoder.ToPgtf(subgraph)
+	if err != nil {
+		return err
+	}
+
+	_, err = i.LoadSubgraph(data)
+	return err
+}
+
+// subgraphEdit is the subgraph opened for editing, along with the graph it
+// was opened from
+type subgraphEdit struct {
+	name  string
+	graph *Instance
+}
+
+// EditingSubgraph is the name of the subgraph currently opened for editing,
+// if any
+func (i *Instance) EditingSubgraph() (string, bool) {
+	if i.editing == nil {
+		return "", false
+	}
+	return i.editing.name, true
+}
+
+// EditSubgraph swaps the graph out for the nodes that make up the subgraph,
+// so they can be edited like any other graph. The graph the subgraph was
+// opened from is set aside, and is what gets encoded until editing is either
+// finished or discarded.
+func (i *Instance) EditSubgraph(name string) error {
+	if i.editing != nil {
+		return fmt.Errorf("subgraph %s is already being edited", i.editing.name)
+	}
+
+	if _, ok := i.subgraphs[name]; !ok {
+		return fmt.Errorf("no subgraph registered with the name %q", name)
+	}
+
+	graph, err := i.copyGraph()
+	if err != nil {
+		return err
+	}
+
+	encoder := &jbtf.Encoder{}
+	appSchema := schema.App{
+		FormatVersion: schema.CurrentFormatVersion,
+		Producers:     make(map[string]schema.Producer),
+		Subgraphs:     make(map[string]schema.Subgraph),
+	}
+	for _, subgraphName := range i.SubgraphNames() {
+		subgraph, err := encodeSubgraph(i.subgraphs[subgraphName], encoder)
+		if err != nil {
+			return fmt.Errorf("unable to encode subgraph %s: %w", subgraphName, err)
+		}
+		appSchema.Subgraphs[subgraphName] = subgraph
+	}
+	appSchema.Nodes = appSchema.Subgraphs[name].Nodes
+	appSchema.Metadata = appSchema.Subgraphs[name].Metadata
+
+	data, err := encoder.ToPgtf(appSchema)
+	if err != nil {
+		return err
+	}
+
+	if err := i.ApplyAppSchema(data); err != nil {
+		return errors.Join(err, i.restore(graph))
+	}
+
+	i.editing = &subgraphEdit{name: name, graph: graph}
+	return nil
+}
+
+// FinishEditingSubgraph replaces the definition of the subgraph being edited
+// with the graph as it currently stands, and goes back to the graph the
+// subgraph was opened from. The subgraph's inputs and outputs are left as
+// they were, so the nodes they're tied to need to stick around and keep
+// their types.
+func (i *Instance) FinishEditingSubgraph() error {
+	if i.editing == nil {
+		return errors.New("no subgraph is being edited")
+	}
+
+	original := i.subgraphs[i.editing.name]
+
+	encoder := &jbtf.Encoder{}
+	subgraph := original.schema
+	subgraph.Nodes = make(map[string]schema.AppNodeInstance, len(i.nodeIDs))
+	for _, node := range sortedByID(i.nodeIDs) {
+		subgraph.Nodes[i.nodeIDs[node]] = encodeNode(node, i.nodeIDs, encoder)
+	}
+	subgraph.Metadata = i.metadata.Data()
+
+	// Round trip through the encoder so the subgraph's data is read back the
+	// same way it would be from a file
+	data, err := encoder.ToPgtf(subgraph)
+	if err != nil {
+		return err
+	}
+
+	edited, decoder, err := decodeSubgraph(data)
+	if err != nil {
+		return err
+	}
+
+	definition, err := i.newSubgraphDefinition(edited, decoder)
+	if err != nil {
+		return err
+	}
+
+	if err := samePorts(original, definition); err != nil {
+		return fmt.Errorf("subgraph %s: %w", edited.Name, err)
+	}
+
+	if err := i.restore(i.editing.graph); err != nil {
+		return err
+	}
+
+	if err := i.RegisterSubgraph(edited, decoder); err != nil {
+		return err
+	}
+
+	// Rebuild the graph so everywhere the subgraph is used picks up the
+	// changes
+	graph, err := i.copyGraph()
+	if err != nil {
+		return err
+	}
+	return i.restore(graph)
+}
+
+// DiscardSubgraphEdits goes back to the graph the subgraph being edited was
+// opened from, leaving the subgraph as it was
+func (i *Instance) DiscardSubgraphEdits() error {
+	if i.editing == nil {
+		return errors.New("no subgraph is being edited")
+	}
+	return i.restore(i.editing.graph)
+}
+
+// samePorts ensures the edited definition of a subgraph can stand in for the
+// original everywhere it's used
+func samePorts(original, edited *subgraphDefinition) error {
+	before, err := original.build()
+	if err != nil {
+		return err
+	}
+
+	after, err := edited.build()
+	if err != nil {
+		return err
+	}
+
+	for index, input := range before.Inputs() {
+		if changed := after.Inputs()[index]; changed.Type != input.Type {
+			return fmt.Errorf("input %s changed from %s to %s", input.Name, input.Type, changed.Type)
+		}
+	}
+
+	for index, output := range before.Outputs() {
+		if changed := after.Outputs()[index]; changed.Type != output.Type {
+			return fmt.Errorf("output %s changed from %s to %s", output.NodeOutput.Port(), output.Type, changed.Type)
+		}
+	}
+	return nil
+}
+
+// copyGraph builds an instance of its own from the graph as it currently
+// stands
+func (i *Instance) copyGraph() (*Instance, error) {
+	appSchema := schema.App{}
+	encoder := &jbtf.Encoder{}
+	i.EncodeToAppSchema(&appSchema, encoder)
+
+	data, err := encoder.ToPgtf(appSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := New(i.typeFactory)
+	if err := graph.ApplyAppSchema(data); err != nil {
+		return nil, err
+	}
+	return graph, nil
+}
+
+// restore replaces the graph with a copy of the one provided
+func (i *Instance) restore(graph *Instance) error {
+	appSchema := schema.App{}
+	encoder := &jbtf.Encoder{}
+	graph.EncodeToAppSchema(&appSchema, encoder)
+
+	data, err := encoder.ToPgtf(appSchema)
+	if err != nil {
+		return err
+	}
+	return i.ApplyAppSchema(data)
 }
 
 // PARAMETER ==================================================================
@@ -421,10 +1015,32 @@ func (i *Instance) Parameter(nodeId string) Parameter {
 }
 
 func (i *Instance) UpdateParameter(nodeId string, data []byte) (bool, error) {
+	param := i.Parameter(nodeId)
+	previous := i.ParameterData(nodeId)
+
+	r, err := i.applyParameterMessage(param, data)
+	if err != nil {
+		return r, err
+	}
+
+	i.record(Operation{
+		Kind:        ParameterOperation,
+		NodeID:      nodeId,
+		Description: fmt.Sprintf("Set %s", describeNode(param.(nodes.Node), nodeId)),
+		undo:        func() { i.applyParameterMessage(param, previous) },
+		redo:        func() { i.applyParameterMessage(param, data) },
+	})
+	return r, nil
+}
+
+func (i *Instance) applyParameterMessage(param Parameter, data []byte) (bool, error) {
+	// Whatever's being evaluated is about to be out of date
+	i.cancelRunningEvaluation()
+
 	i.producerLock.Lock()
 	defer i.producerLock.Unlock()
 
-	r, err := i.Parameter(nodeId).ApplyMessage(data)
+	r, err := param.ApplyMessage(data)
 	i.incModelVersion()
 	return r, err
 }
@@ -448,28 +1064,48 @@ func (i *Instance) DeleteMetadata(key string) {
 // CONNECTIONS ================================================================
 
 func (i *Instance) DeleteNodeInputConnection(nodeId, portName string) {
-	i.Node(nodeId).SetInput(
-		portName,
-		nodes.Output{
-			NodeOutput: nil,
-		},
-	)
+	node := i.Node(nodeId)
+	previous := connection(node, portName)
+	disconnected := nodes.Output{NodeOutput: nil}
+
+	node.SetInput(portName, disconnected)
 	i.incModelVersion()
+
+	i.record(Operation{
+		Kind:        DisconnectOperation,
+		NodeID:      nodeId,
+		Description: fmt.Sprintf("Disconnect %s's %s", describeNode(node, nodeId), portName),
+		undo:        func() { restoreConnection(node, portName, previous) },
+		redo:        func() { node.SetInput(portName, disconnected) },
+	})
 }
 
-func (i *Instance) ConnectNodes(nodeOutId, outPortName, nodeInId, inPortName string) {
+func (i *Instance) ConnectNodes(nodeOutId, outPortName, nodeInId, inPortName string) error {
 	inNode := i.Node(nodeInId)
 	outNode := i.Node(nodeOutId)
-	outPortVals := refutil.CallFuncValuesOfType(outNode, outPortName)
-
-	ref := outPortVals[0].(nodes.NodeOutputReference)
-	inNode.SetInput(
-		inPortName,
-		nodes.Output{
-			NodeOutput: ref,
-		},
-	)
+	output, err := nodes.GetOutput(outNode, outPortName)
+	if err != nil {
+		return fmt.Errorf("unable to connect %s to %s: %w", nodeOutId, nodeInId, err)
+	}
+
+	// Elements connected to an array input are appended, so record where it
+	// actually lands for undoing to take the right one back off
+	if name, _, isElement := strings.Cut(inPortName, "."); isElement {
+		inPortName = fmt.Sprintf("%s.%d", name, len(arrayConnections(inNode, name)))
+	}
+
+	previous := connection(inNode, inPortName)
+	inNode.SetInput(inPortName, output)
 	i.incModelVersion()
+
+	i.record(Operation{
+		Kind:        ConnectOperation,
+		NodeID:      nodeInId,
+		Description: fmt.Sprintf("Connect %s's %s to %s's %s", describeNode(outNode, nodeOutId), outPortName, describeNode(inNode, nodeInId), inPortName),
+		undo:        func() { inNode.SetInput(inPortName, previous) },
+		redo:        func() { inNode.SetInput(inPortName, output) },
+	})
+	return nil
 }
 
 // PRODUCERS ==================================================================
@@ -481,24 +1117,23 @@ func (i *Instance) SetNodeAsProducer(nodeId, producerName string) {
 		panic(fmt.Errorf("no node exists with id %q", nodeId))
 	}
 
-	// TODO: We need to allow users to specify which output port
-	// that is the actuall artifact. can't rely on "Out"
-	outPortVals := refutil.CallFuncValuesOfType(producerNode, "Out")
-	ref := outPortVals[0].(nodes.NodeOutput[artifact.Artifact])
+	// TODO: We need to allow users to specify which output port is the
+	// artifact. For now we take the first one we come across
+	var ref nodes.NodeOutput[artifact.Artifact]
+	for _, output := range producerNode.Outputs() {
+		if artifactOutput, ok := output.NodeOutput.(nodes.NodeOutput[artifact.Artifact]); ok {
+			ref = artifactOutput
+			break
+		}
+	}
+
 	if ref == nil {
-		panic(fmt.Errorf("Couldn't find Out port on Node: %s", nodeId))
+		panic(fmt.Errorf("no output of node %s produces an artifact", nodeId))
 	}
 
 	// We need to check and remove previous references...
 	for filename, producer := range i.producers {
-
-		if i.NodeId(producer.Node()) != nodeId {
-			continue
-		}
-
-		// TODO: This changes once we allow multiple output
-		// port artifact. Need to specify port instead of "Out"
-		if producer.Port() != "Out" {
+		if i.NodeId(producer.Node()) != nodeId || producer.Port() != ref.Port() {
 			continue
 		}
 
@@ -516,7 +1151,81 @@ func (i *Instance) recursivelyRegisterNodeTypes(node nodes.Node) {
 	}
 }
 
-func (i *Instance) Artifact(producerName string) artifact.Artifact {
+// Evaluate processes every out of date node the producers depend on, in
+// parallel where possible. All producers are evaluated when none are named.
+// Evaluation is cancelled along with the context, or when a parameter is
+// updated mid evaluation.
+func (i *Instance) Evaluate(ctx context.Context, producerNames ...string) error {
+	if len(producerNames) == 0 {
+		producerNames = i.ProducerNames()
+	}
+
+	roots := make([]nodes.Node, len(producerNames))
+	for index, name := range producerNames {
+		producer, ok := i.producers[name]
+		if !ok {
+			panic(fmt.Errorf("no producer registered for: %s", name))
+		}
+		roots[index] = producer.Node()
+	}
+
+	i.producerLock.Lock()
+	defer i.producerLock.Unlock()
+	return i.evaluate(ctx, roots...)
+}
+
+func (i *Instance) evaluate(ctx context.Context, roots ...nodes.Node) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	i.evaluationLock.Lock()
+	i.cancelEvaluation = cancel
+	i.evaluationLock.Unlock()
+
+	timings, err := i.evaluator.Evaluate(ctx, roots...)
+
+	i.evaluationLock.Lock()
+	defer i.evaluationLock.Unlock()
+	i.cancelEvaluation = nil
+	for node, duration := range timings {
+		i.timings[i.nodeIDs[node]] = duration
+	}
+	return err
+}
+
+func (i *Instance) cancelRunningEvaluation() {
+	i.evaluationLock.Lock()
+	defer i.evaluationLock.Unlock()
+	if i.cancelEvaluation != nil {
+		i.cancelEvaluation()
+	}
+}
+
+// SetCache has nodes restored from the cache provided in place of being
+// processed whenever possible. A nil cache disables caching.
+func (i *Instance) SetCache(cache nodes.OutputCache) {
+	i.producerLock.Lock()
+	defer i.producerLock.Unlock()
+	i.evaluator.Cache = cache
+}
+
+// Timings returns how long each node, by ID, took the last time it was
+// processed
+func (i *Instance) Timings() map[string]time.Duration {
+	i.evaluationLock.Lock()
+	defer i.evaluationLock.Unlock()
+
+	timings := make(map[string]time.Duration, len(i.timings))
+	for id, duration := range i.timings {
+		timings[id] = duration
+	}
+	return timings
+}
+
+// Artifact evaluates the producer's graph, returning an error describing the
+// path from the producer to the node that failed if any node along the way
+// errors
+func (i *Instance) Artifact(ctx context.Context, producerName string) (artifact.Artifact, error) {
 	producer, ok := i.producers[producerName]
 	if !ok {
 		panic(fmt.Errorf("no producer registered for: %s", producerName))
@@ -525,7 +1234,70 @@ func (i *Instance) Artifact(producerName string) artifact.Artifact {
 	i.producerLock.Lock()
 	defer i.producerLock.Unlock()
 
-	return producer.Value()
+	if err := i.evaluate(ctx, producer.Node()); err != nil {
+		return nil, fmt.Errorf("%s: %w", producerName, err)
+	}
+
+	value := producer.Value()
+
+	errored, ok := producer.Node().(nodes.Errored)
+	if !ok {
+		return value, nil
+	}
+
+	err := errored.Err()
+	if err == nil {
+		return value, nil
+	}
+
+	var nodeErr *nodes.NodeError
+	if !errors.As(err, &nodeErr) {
+		return nil, fmt.Errorf("%s: %w", producerName, err)
+	}
+
+	path := i.dependencyPath(producer.Node(), nodeErr.Node)
+	if path == nil {
+		path = []string{i.nodeIDs[nodeErr.Node]}
+	}
+	return nil, fmt.Errorf("%s: %s: %w", producerName, strings.Join(path, " -> "), nodeErr.Err)
+}
+
+// NodeError returns the error the node encountered the last time it was
+// processed. Nodes out of date with their dependencies report no error, as
+// there's no telling whether or not they'll fail until they're processed
+// again.
+func (i *Instance) NodeError(node nodes.Node) error {
+	if node.State() != nodes.Error {
+		return nil
+	}
+
+	errored, ok := node.(nodes.Errored)
+	if !ok {
+		return nil
+	}
+	return errored.Err()
+}
+
+// dependencyPath lists the IDs and names of the nodes walked through the
+// start node's dependencies to reach the end node
+func (i *Instance) dependencyPath(start, end nodes.Node) []string {
+	label := func(node nodes.Node) string {
+		if named, ok := node.(nodes.Named); ok {
+			return fmt.Sprintf("%s (%s)", i.nodeIDs[node], named.Name())
+		}
+		return i.nodeIDs[node]
+	}
+
+	if start == end {
+		return []string{label(start)}
+	}
+
+	for _, dep := range start.Dependencies() {
+		if path := i.dependencyPath(dep.Dependency(), end); path != nil {
+			return append([]string{label(start)}, path...)
+		}
+	}
+	return nil
 }
 
 func (i *Instance) AddProducer(producerName string, producer nodes.NodeOutput[artifact.Artifact]) {
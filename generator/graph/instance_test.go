@@ -2,11 +2,19 @@ package graph_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
 	"testing"
 
 	"github.com/EliCDavis/jbtf"
+	"github.com/EliCDavis/polyform/generator/artifact"
 	"github.com/EliCDavis/polyform/generator/artifact/basics"
 	"github.com/EliCDavis/polyform/generator/graph"
 	"github.com/EliCDavis/polyform/generator/parameter"
@@ -14,6 +22,7 @@ import (
 	"github.com/EliCDavis/polyform/nodes"
 	"github.com/EliCDavis/polyform/refutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type TestNode = nodes.Struct[float64, TestNodeData]
@@ -27,6 +36,38 @@ func (bn TestNodeData) Process() (float64, error) {
 	return 0, nil
 }
 
+type FailingNode = nodes.Struct[string, FailingNodeData]
+
+type FailingNodeData struct {
+	In nodes.NodeOutput[string]
+}
+
+func (fn FailingNodeData) Process() (string, error) {
+	if fn.In.Value() == "fail" {
+		return "", errors.New("bad input")
+	}
+	return fn.In.Value(), nil
+}
+
+type SplitNode = nodes.Struct[SplitNodeOutputs, SplitNodeData]
+
+type SplitNodeData struct {
+	In nodes.NodeOutput[string]
+}
+
+type SplitNodeOutputs struct {
+	First  nodes.Port[string]
+	Second nodes.Port[string]
+}
+
+func (sn SplitNodeData) Process() (SplitNodeOutputs, error) {
+	first, second, _ := strings.Cut(sn.In.Value(), ",")
+	return SplitNodeOutputs{
+		First:  nodes.NewPort(first),
+		Second: nodes.NewPort(second),
+	}, nil
+}
+
 func TestBuildNodeTypeSchema(t *testing.T) {
 	schema := graph.BuildNodeTypeSchema(&TestNode{})
 
@@ -63,7 +104,8 @@ func TestInstance_AddProducer_InitializeParameters_Artifacts(t *testing.T) {
 	producerNames := instance.ProducerNames()
 	instance.InitializeParameters(flags)
 	assert.NoError(t, flags.Parse([]string{"-yeet", contentToSetViaFlag}))
-	textArtifact := instance.Artifact("test.txt")
+	textArtifact, err := instance.Artifact(context.Background(), "test.txt")
+	assert.NoError(t, err)
 	buf := &bytes.Buffer{}
 	assert.NoError(t, textArtifact.Write(buf))
 
@@ -165,6 +207,7 @@ func TestInstance_AddProducer_InitializeParameters_Artifacts(t *testing.T) {
 		}
 	],
 	"data": {
+		"formatVersion": 1,
 		"nodes": {
 			"Node-0": {
 				"type": "github.com/EliCDavis/polyform/generator/parameter.Value[string]",
@@ -199,3 +242,135 @@ func TestInstance_AddProducer_InitializeParameters_Artifacts(t *testing.T) {
 	}
 }`, string(appSchemaData))
 }
+
+func TestInstance_ArtifactError(t *testing.T) {
+	in := nodes.Value("fail")
+	failing := &FailingNode{Data: FailingNodeData{In: in.Out()}}
+
+	instance := graph.New(&refutil.TypeFactory{})
+	instance.AddProducer("test.txt", basics.NewTextNode(failing.Out()))
+
+	artifact, err := instance.Artifact(context.Background(), "test.txt")
+	assert.Nil(t, artifact)
+	assert.EqualError(t, err, "test.txt: Node-2 (TextNodeData) -> Node-1 (FailingNodeData): bad input")
+
+	failingID := instance.NodeId(failing)
+	producerID := instance.NodeId(instance.Producer("test.txt").Node())
+	assert.Equal(t, nodes.Error, failing.State())
+	assert.Equal(t, "FailingNodeData: bad input", instance.Schema().Nodes[failingID].Error)
+	assert.Equal(t, "FailingNodeData: bad input", instance.Schema().Nodes[producerID].Error)
+
+	// Fixing the input clears the error from the whole graph
+	in.Set("pass")
+	assert.Empty(t, instance.Schema().Nodes[failingID].Error)
+
+	artifact, err = instance.Artifact(context.Background(), "test.txt")
+	assert.NoError(t, err)
+	buf := &bytes.Buffer{}
+	assert.NoError(t, artifact.Write(buf))
+	assert.Equal(t, "pass", buf.String())
+	assert.Equal(t, nodes.Processed, failing.State())
+	assert.Empty(t, instance.Schema().Nodes[producerID].Error)
+}
+
+func TestInstance_Evaluate(t *testing.T) {
+	failing := &FailingNode{Data: FailingNodeData{In: nodes.Value("pass")}}
+
+	instance := graph.New(&refutil.TypeFactory{})
+	instance.AddProducer("test.txt", basics.NewTextNode(failing.Out()))
+	assert.Empty(t, instance.Timings())
+
+	assert.NoError(t, instance.Evaluate(context.Background()))
+	assert.Equal(t, nodes.Processed, failing.State())
+
+	timings := instance.Timings()
+	assert.Contains(t, timings, instance.NodeId(failing))
+	assert.Contains(t, timings, instance.NodeId(instance.Producer("test.txt").Node()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	failing.SetInput("In", nodes.Output{NodeOutput: nodes.Value("other")})
+	assert.ErrorIs(t, instance.Evaluate(ctx, "test.txt"), context.Canceled)
+	assert.Equal(t, nodes.Stale, failing.State())
+}
+
+func TestInstance_MultipleOutputs(t *testing.T) {
+	factory := &refutil.TypeFactory{}
+	refutil.RegisterType[SplitNode](factory)
+	refutil.RegisterType[nodes.Struct[artifact.Artifact, basics.TextNodeData]](factory)
+	refutil.RegisterType[parameter.String](factory)
+
+	split := &SplitNode{Data: SplitNodeData{In: &parameter.String{Name: "In", DefaultValue: "a,b"}}}
+	instance := graph.New(factory)
+	instance.AddProducer("first.txt", basics.NewTextNode(nodes.GetNodeOutputPort[string](split, "First")))
+	instance.AddProducer("second.txt", basics.NewTextNode(nodes.GetNodeOutputPort[string](split, "Second")))
+
+	splitSchema := graph.BuildNodeTypeSchema(split)
+	assert.Equal(t, []schema.NodeOutput{
+		{Name: "First", Type: "string"},
+		{Name: "Second", Type: "string"},
+	}, splitSchema.Outputs)
+
+	appSchema := &schema.App{}
+	encoder := &jbtf.Encoder{}
+	instance.EncodeToAppSchema(appSchema, encoder)
+	data, err := encoder.ToPgtf(appSchema)
+	require.NoError(t, err)
+
+	loaded := graph.New(factory)
+	require.NoError(t, loaded.ApplyAppSchema(data))
+
+	read := func(producer string) string {
+		artifact, err := loaded.Artifact(context.Background(), producer)
+		require.NoError(t, err)
+		buf := &bytes.Buffer{}
+		require.NoError(t, artifact.Write(buf))
+		return buf.String()
+	}
+	assert.Equal(t, "a", read("first.txt"))
+	assert.Equal(t, "b", read("second.txt"))
+
+	// Swap which output the first producer reads from
+	firstID := loaded.NodeId(loaded.Producer("first.txt").Node())
+	splitID := loaded.NodeId(loaded.Producer("first.txt").Node().Dependencies()[0].Dependency())
+	require.NoError(t, loaded.ConnectNodes(splitID, "Second", firstID, "In"))
+	assert.Equal(t, "b", read("first.txt"))
+
+	assert.EqualError(
+		t,
+		loaded.ConnectNodes(splitID, "Third", firstID, "In"),
+		fmt.Sprintf(`unable to connect %s to %s: node has no output named "Third"`, splitID, firstID),
+	)
+}
+
+func TestInstance_EncodeToAppSchema_Deterministic(t *testing.T) {
+	factory := &refutil.TypeFactory{}
+	refutil.RegisterType[parameter.Image](factory)
+	instance := graph.New(factory)
+
+	for i := 0; i < 8; i++ {
+		_, id, err := instance.CreateNode(refutil.GetTypeWithPackage(new(parameter.Image)))
+		require.NoError(t, err)
+
+		img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		img.Set(0, 0, color.RGBA{R: uint8(i), A: 255})
+		buf := &bytes.Buffer{}
+		require.NoError(t, png.Encode(buf, img))
+		_, err = instance.UpdateParameter(id, buf.Bytes())
+		require.NoError(t, err)
+	}
+
+	encode := func() []byte {
+		appSchema := &schema.App{}
+		encoder := &jbtf.Encoder{}
+		instance.EncodeToAppSchema(appSchema, encoder)
+		data, err := encoder.ToPgtf(appSchema)
+		require.NoError(t, err)
+		return data
+	}
+
+	first := encode()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, string(first), string(encode()))
+	}
+}
@@ -0,0 +1,234 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/EliCDavis/polyform/generator/schema"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/polyform/refutil"
+)
+
+// Migration upgrades the schema of a node written with an older version of
+// the graph format. Migrations are free to rename the node's inputs, rewrite
+// its data, or change its type entirely.
+type Migration func(node schema.AppNodeInstance) (schema.AppNodeInstance, error)
+
+var (
+	migrations     = make(map[string]map[int]Migration)
+	migrationsLock sync.Mutex
+)
+
+// RegisterMigration registers the migration bringing nodes of the type up to
+// the format version provided from the version prior. Types that have been
+// moved or renamed are expected to be registered under their new name, with
+// an alias from the old one.
+func RegisterMigration(nodeType string, version int, migration Migration) {
+	if version < 1 || version > schema.CurrentFormatVersion {
+		panic(fmt.Errorf("migration for %s targets format version %d, outside of the supported 1 to %d", nodeType, version, schema.CurrentFormatVersion))
+	}
+
+	migrationsLock.Lock()
+	defer migrationsLock.Unlock()
+
+	if migrations[nodeType] == nil {
+		migrations[nodeType] = make(map[int]Migration)
+	}
+
+	if _, ok := migrations[nodeType][version]; ok {
+		panic(fmt.Errorf("migration for %s to format version %d already registered", nodeType, version))
+	}
+	migrations[nodeType][version] = migration
+}
+
+func registeredMigration(nodeType string, version int) (Migration, bool) {
+	migrationsLock.Lock()
+	defer migrationsLock.Unlock()
+	migration, ok := migrations[nodeType][version]
+	return migration, ok
+}
+
+// MigrationError reports the nodes of a graph that could not be brought up
+// to date along with why. Everything else within the graph was still
+// applied, with the nodes listed either left out entirely or missing the
+// connections that no longer fit.
+type MigrationError struct {
+	Nodes map[string]error
+}
+
+func (me *MigrationError) add(nodeID string, err error) {
+	if me.Nodes == nil {
+		me.Nodes = make(map[string]error)
+	}
+	me.Nodes[nodeID] = errors.Join(me.Nodes[nodeID], err)
+}
+
+func (me *MigrationError) Error() string {
+	ids := make([]string, 0, len(me.Nodes))
+	for id := range me.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	problems := make([]string, 0, len(ids))
+	for _, id := range ids {
+		problems = append(problems, fmt.Sprintf("%s: %s", id, me.Nodes[id].Error()))
+	}
+	return fmt.Sprintf("unable to migrate %d node(s): %s", len(ids), strings.Join(problems, "; "))
+}
+
+// migrateNode runs every migration registered for the node from the format
+// version the node was written with up to the current one, following the
+// type's aliases along the way
+func migrateNode(typeFactory *refutil.TypeFactory, node schema.AppNodeInstance, from int) (schema.AppNodeInstance, error) {
+	for version := from + 1; version <= schema.CurrentFormatVersion; version++ {
+		node.Type = typeFactory.Resolve(node.Type)
+		migration, ok := registeredMigration(node.Type, version)
+		if !ok {
+			continue
+		}
+
+		migrated, err := migration(node)
+		if err != nil {
+			return node, fmt.Errorf("migrating to format version %d: %w", version, err)
+		}
+		node = migrated
+	}
+	node.Type = typeFactory.Resolve(node.Type)
+	return node, nil
+}
+
+// migrateNodes brings every node up to date from the format version
+// provided. Nodes that can't be migrated or whose type no longer exists are
+// removed, along with any connection that no longer lines up with the inputs
+// and outputs of the nodes at either end. Problems are reported under the
+// node's ID with the prefix provided.
+func migrateNodes(
+	typeFactory *refutil.TypeFactory,
+	nodeSchemas map[string]schema.AppNodeInstance,
+	from int,
+	typeKnown func(nodeType string) bool,
+	report *MigrationError,
+	prefix string,
+) map[string]schema.AppNodeInstance {
+	migrated := make(map[string]schema.AppNodeInstance)
+	for id, node := range nodeSchemas {
+		node, err := migrateNode(typeFactory, node, from)
+		if err != nil {
+			report.add(prefix+id, err)
+			continue
+		}
+
+		if !typeKnown(node.Type) {
+			report.add(prefix+id, fmt.Errorf("no type registered with the name %q", node.Type))
+			continue
+		}
+		migrated[id] = node
+	}
+
+	// Instances to check connections against. Subgraphs may not have been
+	// registered yet, so their connections are left as is.
+	instances := make(map[string]nodes.Node)
+	for id, node := range migrated {
+		if strings.HasPrefix(node.Type, subgraphTypePrefix) {
+			continue
+		}
+
+		if instance, ok := typeFactory.New(node.Type).(nodes.Node); ok {
+			instances[id] = instance
+		}
+	}
+
+	for id, node := range migrated {
+		var inputs map[string]struct{}
+		if instance, ok := instances[id]; ok {
+			inputs = make(map[string]struct{})
+			for _, input := range instance.Inputs() {
+				inputs[input.Name] = struct{}{}
+			}
+		}
+
+		dependencies := make([]schema.NodeDependency, 0, len(node.Dependencies))
+		for _, dependency := range node.Dependencies {
+			// Removed nodes are already reported
+			if _, ok := migrated[dependency.DependencyID]; !ok {
+				continue
+			}
+
+			inputName, _, _ := strings.Cut(dependency.Name, ".")
+			if _, ok := inputs[inputName]; inputs != nil && !ok {
+				report.add(prefix+id, fmt.Errorf("input %q no longer exists", inputName))
+				continue
+			}
+
+			if dependencyInstance, ok := instances[dependency.DependencyID]; ok {
+				if _, err := nodes.GetOutput(dependencyInstance, dependency.DependencyPort); err != nil {
+					report.add(prefix+id, fmt.Errorf("input %q: %w", dependency.Name, err))
+					continue
+				}
+			}
+
+			dependencies = append(dependencies, dependency)
+		}
+		node.Dependencies = dependencies
+		migrated[id] = node
+	}
+
+	return migrated
+}
+
+// migrateAppSchema brings the nodes of the graph, along with those of its
+// subgraphs, up to the current format version. Anything referring to nodes
+// that had to be removed is removed along with them.
+func (i *Instance) migrateAppSchema(appSchema *schema.App) (*MigrationError, error) {
+	if appSchema.FormatVersion > schema.CurrentFormatVersion {
+		return nil, fmt.Errorf("graph format version %d is newer than the latest supported, %d", appSchema.FormatVersion, schema.CurrentFormatVersion)
+	}
+
+	// Subgraphs registered for whatever graph came before this one don't
+	// count, only those the graph brings along with it
+	typeKnown := func(nodeType string) bool {
+		if strings.HasPrefix(nodeType, subgraphTypePrefix) {
+			_, ok := appSchema.Subgraphs[strings.TrimPrefix(nodeType, subgraphTypePrefix)]
+			return ok
+		}
+		return i.typeFactory.KeyRegistered(nodeType)
+	}
+
+	report := &MigrationError{}
+
+	for name, subgraph := range appSchema.Subgraphs {
+		subgraph.Nodes = migrateNodes(i.typeFactory, subgraph.Nodes, appSchema.FormatVersion, typeKnown, report, name+"/")
+
+		for portName, port := range subgraph.Inputs {
+			if _, ok := subgraph.Nodes[port.NodeID]; !ok {
+				delete(subgraph.Inputs, portName)
+			}
+		}
+
+		for portName, port := range subgraph.Outputs {
+			if _, ok := subgraph.Nodes[port.NodeID]; !ok {
+				delete(subgraph.Outputs, portName)
+			}
+		}
+		appSchema.Subgraphs[name] = subgraph
+	}
+
+	appSchema.Nodes = migrateNodes(i.typeFactory, appSchema.Nodes, appSchema.FormatVersion, typeKnown, report, "")
+
+	for name, producer := range appSchema.Producers {
+		if _, ok := appSchema.Nodes[producer.NodeID]; !ok {
+			delete(appSchema.Producers, name)
+		}
+	}
+
+	appSchema.FormatVersion = schema.CurrentFormatVersion
+
+	if len(report.Nodes) == 0 {
+		return nil, nil
+	}
+	return report, nil
+}
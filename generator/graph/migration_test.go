@@ -0,0 +1,150 @@
+package graph_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/EliCDavis/jbtf"
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/EliCDavis/polyform/generator/graph"
+	"github.com/EliCDavis/polyform/generator/parameter"
+	"github.com/EliCDavis/polyform/generator/schema"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/polyform/refutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type LegacyNode = nodes.Struct[string, LegacyNodeData]
+
+// LegacyNodeData's Text input used to be named Message, back when the node
+// lived in another package
+type LegacyNodeData struct {
+	Text nodes.NodeOutput[string]
+}
+
+func (ln LegacyNodeData) Process() (string, error) {
+	return ln.Text.Value(), nil
+}
+
+const legacyNodeOldType = "github.com/EliCDavis/polyform/old.LegacyNode"
+
+func init() {
+	graph.RegisterMigration(refutil.GetTypeWithPackage(new(LegacyNode)), 1, func(node schema.AppNodeInstance) (schema.AppNodeInstance, error) {
+		for i, dependency := range node.Dependencies {
+			if dependency.Name == "Message" {
+				node.Dependencies[i].Name = "Text"
+			}
+		}
+		return node, nil
+	})
+}
+
+func migrationTypeFactory() *refutil.TypeFactory {
+	factory := subgraphTypeFactory()
+	refutil.RegisterType[LegacyNode](factory)
+	factory.RegisterAlias(legacyNodeOldType, refutil.GetTypeWithPackage(new(LegacyNode)))
+	return factory
+}
+
+// legacyAppSchema builds a graph as it would have been written before the
+// legacy node was moved and had its input renamed
+func legacyAppSchema(t *testing.T) (*schema.App, *jbtf.Encoder, string) {
+	param := &parameter.String{Name: "Message", DefaultValue: "hello"}
+	legacy := &LegacyNode{Data: LegacyNodeData{Text: param}}
+
+	instance := graph.New(migrationTypeFactory())
+	instance.AddProducer("test.txt", basics.NewTextNode(legacy.Out()))
+	legacyID := instance.NodeId(legacy)
+
+	appSchema := &schema.App{}
+	encoder := &jbtf.Encoder{}
+	instance.EncodeToAppSchema(appSchema, encoder)
+	assert.Equal(t, schema.CurrentFormatVersion, appSchema.FormatVersion)
+
+	node := appSchema.Nodes[legacyID]
+	node.Type = legacyNodeOldType
+	require.Len(t, node.Dependencies, 1)
+	node.Dependencies[0].Name = "Message"
+	appSchema.Nodes[legacyID] = node
+	appSchema.FormatVersion = 0
+
+	return appSchema, encoder, legacyID
+}
+
+func TestInstance_ApplyAppSchema_Migrates(t *testing.T) {
+	appSchema, encoder, legacyID := legacyAppSchema(t)
+	data, err := encoder.ToPgtf(appSchema)
+	require.NoError(t, err)
+
+	instance := graph.New(migrationTypeFactory())
+	require.NoError(t, instance.ApplyAppSchema(data))
+	assert.Equal(t, "hello", readArtifact(t, instance, "test.txt"))
+
+	// Written back out under the new type and version
+	upgraded := &schema.App{}
+	instance.EncodeToAppSchema(upgraded, &jbtf.Encoder{})
+	assert.Equal(t, schema.CurrentFormatVersion, upgraded.FormatVersion)
+	assert.Equal(t, refutil.GetTypeWithPackage(new(LegacyNode)), upgraded.Nodes[legacyID].Type)
+	assert.Equal(t, "Text", upgraded.Nodes[legacyID].Dependencies[0].Name)
+}
+
+func TestInstance_ApplyAppSchema_ReportsUnmigratableNodes(t *testing.T) {
+	appSchema, encoder, legacyID := legacyAppSchema(t)
+	paramID := appSchema.Nodes[legacyID].Dependencies[0].DependencyID
+	failingType := refutil.GetTypeWithPackage(new(FailingNode))
+
+	appSchema.Nodes["Ghost"] = schema.AppNodeInstance{Type: "missing.Node"}
+	appSchema.Nodes["Haunted"] = schema.AppNodeInstance{
+		Type:         failingType,
+		Dependencies: []schema.NodeDependency{{Name: "In", DependencyID: "Ghost", DependencyPort: "Out"}},
+	}
+	appSchema.Nodes["Stale"] = schema.AppNodeInstance{
+		Type:         failingType,
+		Dependencies: []schema.NodeDependency{{Name: "Gone", DependencyID: paramID, DependencyPort: "Out"}},
+	}
+
+	data, err := encoder.ToPgtf(appSchema)
+	require.NoError(t, err)
+
+	instance := graph.New(migrationTypeFactory())
+	err = instance.ApplyAppSchema(data)
+
+	var migrationErr *graph.MigrationError
+	require.True(t, errors.As(err, &migrationErr))
+	assert.EqualError(t, err, `unable to migrate 2 node(s): Ghost: no type registered with the name "missing.Node"; Stale: input "Gone" no longer exists`)
+
+	// Everything else made it in
+	assert.Equal(t, "hello", readArtifact(t, instance, "test.txt"))
+	assert.Empty(t, instance.Node("Haunted").Dependencies())
+	assert.Empty(t, instance.Node("Stale").Dependencies())
+}
+
+func TestInstance_ApplyAppSchema_FormatVersion(t *testing.T) {
+	appSchema, encoder, _ := legacyAppSchema(t)
+
+	// Graphs already on the current version aren't migrated
+	appSchema.FormatVersion = schema.CurrentFormatVersion
+	data, err := encoder.ToPgtf(appSchema)
+	require.NoError(t, err)
+	err = graph.New(migrationTypeFactory()).ApplyAppSchema(data)
+	assert.ErrorContains(t, err, `input "Message" no longer exists`)
+
+	appSchema.FormatVersion = schema.CurrentFormatVersion + 1
+	data, err = encoder.ToPgtf(appSchema)
+	require.NoError(t, err)
+	err = graph.New(migrationTypeFactory()).ApplyAppSchema(data)
+	assert.EqualError(t, err, "graph format version 2 is newer than the latest supported, 1")
+}
+
+func TestRegisterMigration_Invalid(t *testing.T) {
+	noop := func(node schema.AppNodeInstance) (schema.AppNodeInstance, error) { return node, nil }
+
+	assert.Panics(t, func() {
+		graph.RegisterMigration("some.Node", schema.CurrentFormatVersion+1, noop)
+	})
+
+	assert.Panics(t, func() {
+		graph.RegisterMigration(refutil.GetTypeWithPackage(new(LegacyNode)), 1, noop)
+	})
+}
@@ -0,0 +1,358 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/EliCDavis/jbtf"
+	"github.com/EliCDavis/polyform/generator/schema"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/polyform/refutil"
+)
+
+const subgraphTypePrefix = "subgraph/"
+
+// SubgraphType is the key the node type of the subgraph with the name
+// provided is registered under
+func SubgraphType(name string) string {
+	return subgraphTypePrefix + name
+}
+
+// buildNodes instantiates the nodes described, connecting them to one another
+// and populating them with their data
+func buildNodes(typeFactory *refutil.TypeFactory, nodeSchemas map[string]schema.AppNodeInstance, decoder jbtf.Decoder) (map[string]nodes.Node, error) {
+	createdNodes := make(map[string]nodes.Node)
+
+	// Create the Nodes
+	for nodeID, instanceDetails := range nodeSchemas {
+		if nodeID == "" {
+			return nil, errors.New("attempting to create a node without an ID")
+		}
+
+		if !typeFactory.KeyRegistered(instanceDetails.Type) {
+			return nil, fmt.Errorf("node %s: no type registered with the name %q", nodeID, instanceDetails.Type)
+		}
+
+		newNode := typeFactory.New(instanceDetails.Type)
+		casted, ok := newNode.(nodes.Node)
+		if !ok {
+			return nil, fmt.Errorf("graph definition contained type that instantiated a non node: %s", instanceDetails.Type)
+		}
+		createdNodes[nodeID] = casted
+	}
+
+	// Connect the nodes we just created
+	for nodeID, instanceDetails := range nodeSchemas {
+		node := createdNodes[nodeID]
+		for _, dependency := range instanceDetails.Dependencies {
+			outNode, ok := createdNodes[dependency.DependencyID]
+			if !ok {
+				return nil, fmt.Errorf("node %s depends on %s, which does not exist", nodeID, dependency.DependencyID)
+			}
+
+			output, err := nodes.GetOutput(outNode, dependency.DependencyPort)
+			if err != nil {
+				return nil, fmt.Errorf("unable to connect %s to %s: %w", dependency.DependencyID, nodeID, err)
+			}
+
+			node.SetInput(dependency.Name, output)
+		}
+	}
+
+	// Set Parameters
+	for nodeID, instanceDetails := range nodeSchemas {
+		if p, ok := createdNodes[nodeID].(CustomGraphSerialization); ok {
+			if err := p.FromJSON(decoder, instanceDetails.Data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return createdNodes, nil
+}
+
+// subgraphDefinition is everything needed to build instances of a subgraph
+type subgraphDefinition struct {
+	schema      schema.Subgraph
+	decoder     jbtf.Decoder // What the data of the subgraph's nodes was encoded with
+	typeFactory *refutil.TypeFactory
+}
+
+// subgraphTypes lists the types of the subgraphs used directly within the
+// definition
+func (sd subgraphDefinition) subgraphTypes() []string {
+	var types []string
+	for _, node := range sd.schema.Nodes {
+		if strings.HasPrefix(node.Type, subgraphTypePrefix) {
+			types = append(types, strings.TrimPrefix(node.Type, subgraphTypePrefix))
+		}
+	}
+	return types
+}
+
+func (sd *subgraphDefinition) build() (*Subgraph, error) {
+	built, err := buildNodes(sd.typeFactory, sd.schema.Nodes, sd.decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	subgraph := &Subgraph{
+		definition: sd,
+		nodes:      built,
+		inputs:     make(map[string]nodes.Output),
+		targets:    make(map[string][]subgraphTarget),
+	}
+
+	if len(sd.schema.Outputs) == 0 {
+		return nil, errors.New("subgraph has no outputs")
+	}
+
+	for name, port := range sd.schema.Outputs {
+		output, err := subgraph.portOutput(port)
+		if err != nil {
+			return nil, fmt.Errorf("output %s: %w", name, err)
+		}
+
+		if _, ok := output.NodeOutput.(nodes.Proxyable); !ok {
+			return nil, fmt.Errorf("output %s: %s can't be handed out by a subgraph", name, output.Type)
+		}
+	}
+
+	for name, port := range sd.schema.Inputs {
+		if _, err := subgraph.portOutput(port); err != nil {
+			return nil, fmt.Errorf("input %s: %w", name, err)
+		}
+
+		// Keep track of everything the input feeds, as connecting to the
+		// input will leave the nodes depending on something else entirely
+		for nodeID, node := range sd.schema.Nodes {
+			for _, dependency := range node.Dependencies {
+				if dependency.DependencyID != port.NodeID || dependency.DependencyPort != port.Port {
+					continue
+				}
+
+				if strings.Contains(dependency.Name, ".") {
+					return nil, fmt.Errorf("input %s: feeding elements of array inputs like %s's %s is not supported", name, nodeID, dependency.Name)
+				}
+
+				subgraph.targets[name] = append(subgraph.targets[name], subgraphTarget{
+					node:  built[nodeID],
+					input: dependency.Name,
+				})
+			}
+		}
+	}
+
+	return subgraph, nil
+}
+
+type subgraphTarget struct {
+	node  nodes.Node
+	input string
+}
+
+type subgraphDependency struct {
+	name string
+	node nodes.Node
+	port string
+}
+
+func (sd subgraphDependency) Name() string {
+	return sd.name
+}
+
+func (sd subgraphDependency) Dependency() nodes.Node {
+	return sd.node
+}
+
+func (sd subgraphDependency) DependencyPort() string {
+	return sd.port
+}
+
+// Subgraph is a node made up of a graph of nodes of its own, built from a
+// subgraph definition. Outputs of the nodes within are exposed as the
+// subgraph's outputs, and whatever's connected to the subgraph's inputs is
+// fed to the nodes within in place of the input's default.
+type Subgraph struct {
+	definition *subgraphDefinition
+	nodes      map[string]nodes.Node
+	inputs     map[string]nodes.Output
+	targets    map[string][]subgraphTarget
+	version    int
+}
+
+func (s *Subgraph) portOutput(port schema.SubgraphPort) (nodes.Output, error) {
+	node, ok := s.nodes[port.NodeID]
+	if !ok {
+		return nodes.Output{}, fmt.Errorf("no node exists with id %q", port.NodeID)
+	}
+	return nodes.GetOutput(node, port.Port)
+}
+
+func sortedPortNames(ports map[string]schema.SubgraphPort) []string {
+	names := make([]string, 0, len(ports))
+	for name := range ports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *Subgraph) Name() string {
+	return s.definition.schema.Name
+}
+
+func (s *Subgraph) Type() string {
+	return s.definition.schema.Name
+}
+
+func (s *Subgraph) Description() string {
+	return s.definition.schema.Description
+}
+
+func (s *Subgraph) Path() string {
+	return "subgraphs"
+}
+
+func (s *Subgraph) Inputs() []nodes.Input {
+	inputs := make([]nodes.Input, 0, len(s.definition.schema.Inputs))
+	for _, name := range sortedPortNames(s.definition.schema.Inputs) {
+		output, _ := s.portOutput(s.definition.schema.Inputs[name])
+		inputs = append(inputs, nodes.Input{Name: name, Type: output.Type})
+	}
+	return inputs
+}
+
+func (s *Subgraph) Outputs() []nodes.Output {
+	outputs := make([]nodes.Output, 0, len(s.definition.schema.Outputs))
+	for _, name := range sortedPortNames(s.definition.schema.Outputs) {
+		output, _ := s.portOutput(s.definition.schema.Outputs[name])
+		outputs = append(outputs, nodes.Output{
+			Type:       output.Type,
+			NodeOutput: output.NodeOutput.(nodes.Proxyable).Proxy(s, name),
+		})
+	}
+	return outputs
+}
+
+// SetInput feeds the output to every node within the subgraph the input is
+// tied to. Disconnecting the input goes back to feeding them the input's
+// default.
+func (s *Subgraph) SetInput(input string, output nodes.Output) {
+	port, ok := s.definition.schema.Inputs[input]
+	if !ok {
+		panic(fmt.Errorf("subgraph %s has no input %q", s.Name(), input))
+	}
+
+	if output.NodeOutput == nil {
+		delete(s.inputs, input)
+		output, _ = s.portOutput(port)
+	} else {
+		s.inputs[input] = output
+	}
+
+	for _, target := range s.targets[input] {
+		target.node.SetInput(target.input, output)
+	}
+	s.version++
+}
+
+func (s *Subgraph) Dependencies() []nodes.NodeDependency {
+	dependencies := make([]nodes.NodeDependency, 0, len(s.inputs))
+	for _, name := range sortedPortNames(s.definition.schema.Inputs) {
+		output, ok := s.inputs[name]
+		if !ok {
+			continue
+		}
+
+		dependencies = append(dependencies, subgraphDependency{
+			name: name,
+			node: output.NodeOutput.Node(),
+			port: output.NodeOutput.Port(),
+		})
+	}
+	return dependencies
+}
+
+// Roots are the nodes within the subgraph its outputs come from
+func (s *Subgraph) Roots() []nodes.Node {
+	seen := make(map[nodes.Node]struct{})
+	roots := make([]nodes.Node, 0, len(s.definition.schema.Outputs))
+	for _, name := range sortedPortNames(s.definition.schema.Outputs) {
+		node := s.nodes[s.definition.schema.Outputs[name].NodeID]
+		if _, ok := seen[node]; ok {
+			continue
+		}
+		seen[node] = struct{}{}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+func (s *Subgraph) Version() int {
+	version := s.version
+	for _, root := range s.Roots() {
+		version += root.Version()
+	}
+	return version
+}
+
+func (s *Subgraph) State() nodes.NodeState {
+	state := nodes.Processed
+	for _, root := range s.Roots() {
+		switch root.State() {
+		case nodes.Stale:
+			return nodes.Stale
+
+		case nodes.Error:
+			state = nodes.Error
+		}
+	}
+	return state
+}
+
+// Err returns the first error encountered by the nodes the subgraph's outputs
+// come from. Errors of nodes within the subgraph are reported as errors of
+// the subgraph itself, as the nodes within aren't a part of the graph the
+// subgraph is used in.
+func (s *Subgraph) Err() error {
+	for _, root := range s.Roots() {
+		errored, ok := root.(nodes.Errored)
+		if !ok {
+			continue
+		}
+
+		err := errored.Err()
+		if err == nil {
+			continue
+		}
+
+		var nodeErr *nodes.NodeError
+		if !errors.As(err, &nodeErr) {
+			return &nodes.NodeError{Node: s, Err: err}
+		}
+
+		id, ok := s.nodeID(nodeErr.Node)
+		if !ok {
+			// Failed outside of the subgraph, before it made it to us
+			return err
+		}
+
+		if named, ok := nodeErr.Node.(nodes.Named); ok {
+			id = fmt.Sprintf("%s (%s)", id, named.Name())
+		}
+		return &nodes.NodeError{Node: s, Err: fmt.Errorf("%s: %w", id, nodeErr.Err)}
+	}
+	return nil
+}
+
+func (s *Subgraph) nodeID(node nodes.Node) (string, bool) {
+	for id, n := range s.nodes {
+		if n == node {
+			return id, true
+		}
+	}
+	return "", false
+}
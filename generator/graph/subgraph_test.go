@@ -0,0 +1,265 @@
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/EliCDavis/jbtf"
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/EliCDavis/polyform/generator/graph"
+	"github.com/EliCDavis/polyform/generator/parameter"
+	"github.com/EliCDavis/polyform/generator/schema"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/polyform/refutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func subgraphTypeFactory() *refutil.TypeFactory {
+	factory := &refutil.TypeFactory{}
+	refutil.RegisterType[FailingNode](factory)
+	refutil.RegisterType[basics.TextNode](factory)
+	refutil.RegisterType[parameter.String](factory)
+	return factory
+}
+
+func readArtifact(t *testing.T, instance *graph.Instance, producer string) string {
+	t.Helper()
+	artifact, err := instance.Artifact(context.Background(), producer)
+	require.NoError(t, err)
+	buf := &bytes.Buffer{}
+	require.NoError(t, artifact.Write(buf))
+	return buf.String()
+}
+
+func TestInstance_Subgraph(t *testing.T) {
+	param := &parameter.String{Name: "Message", DefaultValue: "hello"}
+	failing := &FailingNode{Data: FailingNodeData{In: param}}
+
+	instance := graph.New(subgraphTypeFactory())
+	instance.AddProducer("test.txt", basics.NewTextNode(failing.Out()))
+	require.NoError(t, instance.CreateSubgraph("Check", []string{instance.NodeId(failing)}))
+	assert.Equal(t, []string{"Check"}, instance.SubgraphNames())
+
+	// Use the subgraph, feeding it a parameter of its own
+	subgraph, subgraphID, err := instance.CreateNode(graph.SubgraphType("Check"))
+	require.NoError(t, err)
+	assert.Equal(t, []nodes.Input{{Name: "Message", Type: "string"}}, subgraph.Inputs())
+	require.Len(t, subgraph.Outputs(), 1)
+	assert.Equal(t, "Out", subgraph.Outputs()[0].NodeOutput.Port())
+
+	_, textID, err := instance.CreateNode(refutil.GetTypeWithPackage(new(basics.TextNode)))
+	require.NoError(t, err)
+	require.NoError(t, instance.ConnectNodes(subgraphID, "Out", textID, "In"))
+	instance.SetNodeAsProducer(textID, "subgraph.txt")
+
+	// Unconnected inputs fall back to the subgraph's copy of the parameter
+	assert.Equal(t, "hello", readArtifact(t, instance, "subgraph.txt"))
+
+	_, otherID, err := instance.CreateNode(refutil.GetTypeWithPackage(new(parameter.String)))
+	require.NoError(t, err)
+	_, err = instance.UpdateParameter(otherID, []byte(`"world"`))
+	require.NoError(t, err)
+	require.NoError(t, instance.ConnectNodes(otherID, "Out", subgraphID, "Message"))
+	assert.Equal(t, "world", readArtifact(t, instance, "subgraph.txt"))
+	assert.Equal(t, "hello", readArtifact(t, instance, "test.txt"))
+
+	// Errors within the subgraph are reported through the subgraph node
+	_, err = instance.UpdateParameter(otherID, []byte(`"fail"`))
+	require.NoError(t, err)
+	_, err = instance.Artifact(context.Background(), "subgraph.txt")
+	assert.EqualError(t, err, fmt.Sprintf(
+		"subgraph.txt: %s (TextNodeData) -> %s (Check): %s (FailingNodeData): bad input",
+		textID, subgraphID, instance.NodeId(failing),
+	))
+	assert.Equal(t, nodes.Error, subgraph.State())
+
+	_, err = instance.UpdateParameter(otherID, []byte(`"again"`))
+	require.NoError(t, err)
+
+	// Round trip through the app schema, with a factory that has never seen
+	// the subgraph before
+	appSchema := &schema.App{}
+	encoder := &jbtf.Encoder{}
+	instance.EncodeToAppSchema(appSchema, encoder)
+	require.Contains(t, appSchema.Subgraphs, "Check")
+	assert.Equal(t, graph.SubgraphType("Check"), appSchema.Nodes[subgraphID].Type)
+
+	data, err := encoder.ToPgtf(appSchema)
+	require.NoError(t, err)
+
+	loaded := graph.New(subgraphTypeFactory())
+	require.NoError(t, loaded.ApplyAppSchema(data))
+	assert.Equal(t, "again", readArtifact(t, loaded, "subgraph.txt"))
+	assert.Equal(t, "hello", readArtifact(t, loaded, "test.txt"))
+
+	// Disconnecting the input goes back to the default
+	loaded.DeleteNodeInputConnection(subgraphID, "Message")
+	assert.Equal(t, "hello", readArtifact(t, loaded, "subgraph.txt"))
+}
+
+func TestInstance_LoadSubgraph(t *testing.T) {
+	param := &parameter.String{Name: "Message", DefaultValue: "hello"}
+	failing := &FailingNode{Data: FailingNodeData{In: param}}
+
+	instance := graph.New(subgraphTypeFactory())
+	instance.AddProducer("test.txt", basics.NewTextNode(failing.Out()))
+	require.NoError(t, instance.CreateSubgraph("Check", []string{instance.NodeId(failing)}))
+
+	data, err := instance.EncodeSubgraph("Check")
+	require.NoError(t, err)
+
+	other := graph.New(subgraphTypeFactory())
+	name, err := other.LoadSubgraph(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Check", name)
+
+	subgraph, _, err := other.CreateNode(graph.SubgraphType("Check"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", nodes.GetNodeOutputPort[string](subgraph, "Out").Value())
+
+	_, err = other.EncodeSubgraph("Missing")
+	assert.EqualError(t, err, `no subgraph registered with the name "Missing"`)
+}
+
+func TestInstance_RegisterSubgraph_Invalid(t *testing.T) {
+	instance := graph.New(subgraphTypeFactory())
+	failingType := refutil.GetTypeWithPackage(new(FailingNode))
+
+	tests := map[string]struct {
+		subgraph schema.Subgraph
+		err      string
+	}{
+		"no name": {
+			subgraph: schema.Subgraph{},
+			err:      "subgraph requires a name",
+		},
+		"contains itself": {
+			subgraph: schema.Subgraph{
+				Name: "Loop",
+				Nodes: map[string]schema.AppNodeInstance{
+					"A": {Type: graph.SubgraphType("Loop")},
+				},
+			},
+			err: "subgraph Loop can not contain itself",
+		},
+		"no outputs": {
+			subgraph: schema.Subgraph{
+				Name: "Empty",
+				Nodes: map[string]schema.AppNodeInstance{
+					"A": {Type: failingType},
+				},
+			},
+			err: "invalid subgraph Empty: subgraph has no outputs",
+		},
+		"missing output node": {
+			subgraph: schema.Subgraph{
+				Name:    "Missing",
+				Outputs: map[string]schema.SubgraphPort{"Out": {NodeID: "B", Port: "Out"}},
+				Nodes: map[string]schema.AppNodeInstance{
+					"A": {Type: failingType},
+				},
+			},
+			err: `invalid subgraph Missing: output Out: no node exists with id "B"`,
+		},
+		"unknown type": {
+			subgraph: schema.Subgraph{
+				Name:    "Unknown",
+				Outputs: map[string]schema.SubgraphPort{"Out": {NodeID: "A", Port: "Out"}},
+				Nodes: map[string]schema.AppNodeInstance{
+					"A": {Type: "missing.Node"},
+				},
+			},
+			err: `invalid subgraph Unknown: node A: no type registered with the name "missing.Node"`,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.EqualError(t, instance.RegisterSubgraph(tc.subgraph, jbtf.Decoder{}), tc.err)
+		})
+	}
+	assert.Empty(t, instance.SubgraphNames())
+}
+
+func TestInstance_EditSubgraph(t *testing.T) {
+	param := &parameter.String{Name: "Message", DefaultValue: "hello"}
+	failing := &FailingNode{Data: FailingNodeData{In: param}}
+
+	instance := graph.New(subgraphTypeFactory())
+	instance.AddProducer("test.txt", basics.NewTextNode(failing.Out()))
+	paramID, failingID := instance.NodeId(param), instance.NodeId(failing)
+	require.NoError(t, instance.CreateSubgraph("Check", []string{failingID}))
+
+	_, subgraphID, err := instance.CreateNode(graph.SubgraphType("Check"))
+	require.NoError(t, err)
+	_, textID, err := instance.CreateNode(refutil.GetTypeWithPackage(new(basics.TextNode)))
+	require.NoError(t, err)
+	require.NoError(t, instance.ConnectNodes(subgraphID, "Out", textID, "In"))
+	instance.SetNodeAsProducer(textID, "subgraph.txt")
+
+	// Opening the subgraph swaps the graph out for its internals
+	require.NoError(t, instance.EditSubgraph("Check"))
+	name, editing := instance.EditingSubgraph()
+	assert.True(t, editing)
+	assert.Equal(t, "Check", name)
+	assert.Empty(t, instance.ProducerNames())
+	assert.Len(t, instance.Schema().Nodes, 2)
+	assert.EqualError(t, instance.EditSubgraph("Check"), "subgraph Check is already being edited")
+
+	// The graph written out in the meantime is the one it was opened from
+	appSchema := &schema.App{}
+	instance.EncodeToAppSchema(appSchema, &jbtf.Encoder{})
+	assert.Contains(t, appSchema.Producers, "subgraph.txt")
+
+	_, err = instance.UpdateParameter(paramID, []byte(`"edited"`))
+	require.NoError(t, err)
+	require.NoError(t, instance.FinishEditingSubgraph())
+
+	_, editing = instance.EditingSubgraph()
+	assert.False(t, editing)
+	assert.Equal(t, "edited", readArtifact(t, instance, "subgraph.txt"))
+	assert.Equal(t, "hello", readArtifact(t, instance, "test.txt"))
+
+	// Removing a node the subgraph's outputs come from keeps it open
+	require.NoError(t, instance.EditSubgraph("Check"))
+	instance.DeleteNode(failingID)
+	err = instance.FinishEditingSubgraph()
+	assert.ErrorContains(t, err, fmt.Sprintf(`no node exists with id %q`, failingID))
+	_, editing = instance.EditingSubgraph()
+	assert.True(t, editing)
+
+	require.NoError(t, instance.DiscardSubgraphEdits())
+	_, editing = instance.EditingSubgraph()
+	assert.False(t, editing)
+	assert.Equal(t, "edited", readArtifact(t, instance, "subgraph.txt"))
+	assert.EqualError(t, instance.FinishEditingSubgraph(), "no subgraph is being edited")
+}
+
+func TestInstance_SubgraphsScopedToGraph(t *testing.T) {
+	factory := subgraphTypeFactory()
+	param := &parameter.String{Name: "Message", DefaultValue: "hello"}
+	failing := &FailingNode{Data: FailingNodeData{In: param}}
+
+	instance := graph.New(factory)
+	instance.AddProducer("test.txt", basics.NewTextNode(failing.Out()))
+	require.NoError(t, instance.CreateSubgraph("Check", []string{instance.NodeId(failing)}))
+
+	// Other graphs built from the same factory never see the subgraph
+	_, _, err := graph.New(factory).CreateNode(graph.SubgraphType("Check"))
+	assert.Error(t, err)
+
+	// Nor does the graph itself once a different graph is loaded in
+	appSchema := &schema.App{}
+	encoder := &jbtf.Encoder{}
+	graph.New(subgraphTypeFactory()).EncodeToAppSchema(appSchema, encoder)
+	data, err := encoder.ToPgtf(appSchema)
+	require.NoError(t, err)
+
+	require.NoError(t, instance.ApplyAppSchema(data))
+	assert.Empty(t, instance.SubgraphNames())
+	_, _, err = instance.CreateNode(graph.SubgraphType("Check"))
+	assert.Error(t, err)
+}
@@ -9,7 +9,9 @@ import (
 type GraphSaver struct {
 	app          *App
 	autsaveMutex sync.Mutex
+	autosave     bool
 	savePath     string
+	snapshotter  *GraphSnapshotter
 }
 
 func (gs *GraphSaver) Save() {
@@ -19,7 +21,13 @@ func (gs *GraphSaver) Save() {
 
 	gs.autsaveMutex.Lock()
 	defer gs.autsaveMutex.Unlock()
-	err := os.WriteFile(gs.savePath, gs.app.Schema(), 0666)
+	data := gs.app.Schema()
+	gs.snapshotter.Record(data)
+	if !gs.autosave {
+		return
+	}
+
+	err := os.WriteFile(gs.savePath, data, 0666)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,122 @@
+package generator
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const snapshotTimeFormat = "2006-01-02T15-04-05.000"
+
+// GraphSnapshotter periodically writes a copy of the graph to a folder next
+// to the graph file it was loaded from, keeping only the latest snapshots.
+// The graph is never read while snapshotting, as it may be mid edit. Instead
+// whatever edits it records the schema it leaves behind.
+type GraphSnapshotter struct {
+	savePath string
+	interval time.Duration
+	max      int // 0 keeps every snapshot
+
+	pendingLock sync.Mutex
+	pending     []byte // Schema recorded since the last snapshot
+}
+
+func NewGraphSnapshotter(savePath string, interval time.Duration, max int) *GraphSnapshotter {
+	return &GraphSnapshotter{
+		savePath: savePath,
+		interval: interval,
+		max:      max,
+	}
+}
+
+// Dir is the folder the snapshots of the graph file are written to
+func (gs *GraphSnapshotter) Dir() string {
+	ext := filepath.Ext(gs.savePath)
+	return strings.TrimSuffix(gs.savePath, ext) + ".snapshots"
+}
+
+// Record queues the schema of the graph after an edit to be written out come
+// the next snapshot
+func (gs *GraphSnapshotter) Record(schema []byte) {
+	if gs == nil {
+		return
+	}
+
+	gs.pendingLock.Lock()
+	defer gs.pendingLock.Unlock()
+	gs.pending = schema
+}
+
+// Run snapshots the graph every interval it's been changed until the
+// context is cancelled
+func (gs *GraphSnapshotter) Run(ctx context.Context) {
+	ticker := time.NewTicker(gs.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			gs.pendingLock.Lock()
+			schema := gs.pending
+			gs.pending = nil
+			gs.pendingLock.Unlock()
+
+			if schema == nil {
+				continue
+			}
+
+			if err := gs.Snapshot(schema, time.Now()); err != nil {
+				log.Printf("unable to snapshot graph: %s", err.Error())
+			}
+		}
+	}
+}
+
+// Snapshot writes the graph's schema, removing the oldest snapshots past the
+// maximum kept
+func (gs *GraphSnapshotter) Snapshot(schema []byte, now time.Time) error {
+	dir := gs.Dir()
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	snapshotPath := filepath.Join(dir, now.Format(snapshotTimeFormat)+filepath.Ext(gs.savePath))
+	if err := os.WriteFile(snapshotPath, schema, 0666); err != nil {
+		return err
+	}
+	log.Printf("Graph snapshot written %s\n", snapshotPath)
+
+	if gs.max <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	// Timestamps sort chronologically
+	snapshots := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			snapshots = append(snapshots, entry.Name())
+		}
+	}
+	sort.Strings(snapshots)
+
+	for len(snapshots) > gs.max {
+		if err := os.Remove(filepath.Join(dir, snapshots[0])); err != nil {
+			return err
+		}
+		snapshots = snapshots[1:]
+	}
+	return nil
+}
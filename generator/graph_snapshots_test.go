@@ -0,0 +1,66 @@
+package generator_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphSnapshotter_Snapshot(t *testing.T) {
+	dir := t.TempDir()
+	app := headlessTestApp()
+	snapshotter := generator.NewGraphSnapshotter(filepath.Join(dir, "graph.json"), time.Minute, 2)
+	assert.Equal(t, filepath.Join(dir, "graph.snapshots"), snapshotter.Dir())
+
+	schema := app.Schema()
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, snapshotter.Snapshot(schema, start.Add(time.Duration(i)*time.Second)))
+	}
+
+	// Only the latest are kept
+	entries, err := os.ReadDir(snapshotter.Dir())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "2024-01-02T03-04-06.000.json", entries[0].Name())
+	assert.Equal(t, "2024-01-02T03-04-07.000.json", entries[1].Name())
+
+	data, err := os.ReadFile(filepath.Join(snapshotter.Dir(), entries[1].Name()))
+	require.NoError(t, err)
+	assert.Equal(t, string(schema), string(data))
+}
+
+func TestGraphSnapshotter_RunWritesRecordedSchema(t *testing.T) {
+	dir := t.TempDir()
+	snapshotter := generator.NewGraphSnapshotter(filepath.Join(dir, "graph.json"), time.Millisecond, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		snapshotter.Run(ctx)
+		close(done)
+	}()
+
+	snapshotter.Record([]byte("edited"))
+	assert.Eventually(t, func() bool {
+		entries, err := os.ReadDir(snapshotter.Dir())
+		return err == nil && len(entries) == 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+
+	// Nothing new was recorded, so nothing new was written
+	entries, err := os.ReadDir(snapshotter.Dir())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	data, err := os.ReadFile(filepath.Join(snapshotter.Dir(), entries[0].Name()))
+	require.NoError(t, err)
+	assert.Equal(t, "edited", string(data))
+}
@@ -249,6 +249,7 @@ class SchemaRefreshManager {
                 this.RemoveLoading();
                 console.error("unable to load text", producerURL, error);
                 ErrorManager.ShowError(producerURL, JSON.parse(error).error);
+                schemaManager.refreshNodes();
             }
         );
     }
@@ -265,6 +266,7 @@ class SchemaRefreshManager {
                 this.RemoveLoading();
                 console.error("unable to load image", producerURL, error);
                 ErrorManager.ShowError(producerURL, JSON.parse(error).error);
+                schemaManager.refreshNodes();
             }
         );
     }
@@ -343,6 +345,7 @@ class SchemaRefreshManager {
                 this.RemoveLoading();
                 error.response.json().then(x => {
                     ErrorManager.ShowError(key, x.error);
+                    schemaManager.refreshNodes();
                 })
             });
     }
@@ -405,6 +408,7 @@ class SchemaRefreshManager {
             console.error(x)
             this.RemoveLoading();
             ErrorManager.ShowError(key, x.error);
+            schemaManager.refreshNodes();
         })
     }
 
@@ -542,6 +546,171 @@ fileSettingsFolder.add(fileControls, "newGraph").name("New")
 fileSettingsFolder.add(fileControls, "saveGraph").name("Save")
 fileSettingsFolder.add(fileControls, "loadProfile").name("Load")
 
+const subgraphControls = {
+    createFromSelection: () => {
+        const selected = nodeFlowGraph.getNodes()
+            .filter((node) => node.selected() && node.nodeInstanceID)
+            .map((node) => node.nodeInstanceID);
+
+        if (selected.length === 0) {
+            alert("Select the nodes to build the subgraph from");
+            return;
+        }
+
+        const name = prompt("Subgraph Name");
+        if (!name) {
+            return;
+        }
+
+        requestManager.createSubgraphFromNodes(name, selected, () => {
+            location.reload();
+        })
+    },
+    load: () => {
+        const input = document.createElement('input');
+        input.type = 'file';
+
+        input.onchange = e => {
+            const reader = new FileReader();
+            reader.readAsArrayBuffer(e.target.files[0]);
+            reader.onload = readerEvent => {
+                requestManager.addSubgraph(readerEvent.target.result, () => {
+                    location.reload();
+                })
+            }
+        }
+
+        input.click();
+    },
+    edit: () => {
+        requestManager.getSubgraphs((names) => {
+            if (names.length === 0) {
+                alert("Graph has no subgraphs");
+                return;
+            }
+
+            const name = prompt("Subgraph to edit (" + names.join(", ") + ")", names[0]);
+            if (!name) {
+                return;
+            }
+
+            requestManager.editSubgraph(name, () => {
+                location.reload();
+            }, (err) => alert(err))
+        })
+    },
+    finishEditing: () => {
+        requestManager.finishEditingSubgraph(() => {
+            location.reload();
+        }, (err) => alert(err))
+    },
+    discardEdits: () => {
+        if (!confirm("Discard the changes made to the subgraph?")) {
+            return;
+        }
+        requestManager.discardSubgraphEdits(() => {
+            location.reload();
+        })
+    },
+    save: () => {
+        requestManager.getSubgraphs((names) => {
+            if (names.length === 0) {
+                alert("Graph has no subgraphs");
+                return;
+            }
+
+            const name = prompt("Subgraph to save (" + names.join(", ") + ")", names[0]);
+            if (!name) {
+                return;
+            }
+
+            requestManager.getSubgraph(name, (data) => {
+                const a = document.createElement('a');
+                a.download = name + '.json';
+                const url = window.URL.createObjectURL(data);
+                a.href = url;
+                a.click();
+                window.URL.revokeObjectURL(url);
+            })
+        })
+    }
+}
+
+const subgraphSettingsFolder = panel.addFolder("Subgraphs");
+subgraphSettingsFolder.add(subgraphControls, "createFromSelection").name("Create From Selection")
+subgraphSettingsFolder.add(subgraphControls, "edit").name("Edit")
+subgraphSettingsFolder.add(subgraphControls, "save").name("Save")
+subgraphSettingsFolder.add(subgraphControls, "load").name("Load")
+subgraphSettingsFolder.close();
+
+// While a subgraph is open, the graph shown is the subgraph's internals, and
+// editing has to be finished or discarded to get back to the graph itself
+requestManager.getEditingSubgraph((resp) => {
+    if (!resp.name) {
+        return;
+    }
+
+    subgraphSettingsFolder.title("Subgraphs (Editing " + resp.name + ")");
+    subgraphSettingsFolder.add(subgraphControls, "finishEditing").name("Finish Editing " + resp.name)
+    subgraphSettingsFolder.add(subgraphControls, "discardEdits").name("Discard Edits")
+    subgraphSettingsFolder.open();
+})
+
+// The editor doesn't yet know how to take nodes and connections back out of
+// the graph, so reload to pick up whatever the step changed
+const historyStepped = (resp) => {
+    if (resp.changed) {
+        location.reload();
+    }
+}
+
+const historyControls = {
+    undo: () => requestManager.undo(historyStepped),
+    redo: () => requestManager.redo(historyStepped),
+}
+
+const historySettingsFolder = panel.addFolder("History");
+historySettingsFolder.add(historyControls, "undo").name("Undo")
+historySettingsFolder.add(historyControls, "redo").name("Redo")
+const historyLogFolder = historySettingsFolder.addFolder("Log");
+historySettingsFolder.close();
+
+// Most recent operations shown in the log
+const historyLogLength = 15;
+
+const refreshHistoryLog = () => {
+    requestManager.getHistory((history) => {
+        historyLogFolder.controllers.slice().forEach((c) => c.destroy());
+
+        const entries = [];
+        history.undone.slice().reverse().forEach((op) => entries.push("(undone) " + op.description));
+        history.applied.slice().reverse().forEach((op) => entries.push(op.description));
+
+        entries.slice(0, historyLogLength).forEach((entry, i) => {
+            const row = {};
+            row["op" + i] = () => { };
+            historyLogFolder.add(row, "op" + i).name(entry).disable();
+        });
+    })
+}
+schemaManager.subscribe(refreshHistoryLog);
+
+document.addEventListener("keydown", (event) => {
+    const target = event.target;
+    if (target.tagName === "INPUT" || target.tagName === "TEXTAREA" || !(event.ctrlKey || event.metaKey)) {
+        return;
+    }
+
+    const key = event.key.toLowerCase();
+    if (key === "z" && !event.shiftKey) {
+        event.preventDefault();
+        historyControls.undo();
+    } else if (key === "y" || (key === "z" && event.shiftKey)) {
+        event.preventDefault();
+        historyControls.redo();
+    }
+});
+
 const exportSettingsFolder = panel.addFolder("Export");
 exportSettingsFolder.add(fileControls, "saveModel").name("Model")
 exportSettingsFolder.add(fileControls, "viewProgram").name("Mermaid")
@@ -91,10 +91,21 @@ export class NodeManager {
                     continue;
                 }
 
+                let sourceOutput = -1;
+                for (let sourceOutputIndex = 0; sourceOutputIndex < outNode.flowNode.outputs(); sourceOutputIndex++) {
+                    if (outNode.flowNode.outputPort(sourceOutputIndex).getDisplayName() === dep.dependencyPort) {
+                        sourceOutput = sourceOutputIndex;
+                    }
+                }
+
+                if (sourceOutput === -1) {
+                    console.error("failed to find output port for ", dep)
+                    continue;
+                }
+
                 // connectNodes(nodeOut: FlowNode, outPort: number, nodeIn: FlowNode, inPort): Connection | undefined {
-                // TODO: This only works for nodes with one output
                 this.app.NodeFlowGraph.connectNodes(
-                    outNode.flowNode, 0,
+                    outNode.flowNode, sourceOutput,
                     inNode.flowNode, sourceInput,
                 )
             }
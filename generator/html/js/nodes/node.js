@@ -99,6 +99,7 @@ export class PolyNodeController {
         this.dependencies = [];
 
         this.parameter = null;
+        this.errorWidget = null;
 
         if (nodeData.metadata) {
             if (nodeData.metadata.position) {
@@ -247,6 +248,29 @@ export class PolyNodeController {
         if (nodeData.parameter) {
             this.parameter.update(nodeData.parameter)
         }
+
+        this.setError(nodeData.error);
+    }
+
+    /**
+     * Displays the error the node encountered while processing, clearing it
+     * when there is none
+     * 
+     * @param {string|undefined} message 
+     */
+    setError(message) {
+        if (!message) {
+            if (this.errorWidget) {
+                this.errorWidget.Set("");
+            }
+            return;
+        }
+
+        if (!this.errorWidget) {
+            this.errorWidget = GlobalWidgetFactory.create(this.flowNode, "text", {});
+            this.flowNode.addWidget(this.errorWidget);
+        }
+        this.errorWidget.Set(message);
     }
 
     updateConnections() {
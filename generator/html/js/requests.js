@@ -100,11 +100,18 @@ class RequestManager {
         this.postBinaryJsonResponse(theUrl, JSON.stringify(body), callback)
     }
 
-    postJsonBodyEmptyResponse(theUrl, body, callback) {
+    postJsonBodyEmptyResponse(theUrl, body, callback, errorCallback) {
         const xmlHttp = new XMLHttpRequest();
         xmlHttp.onreadystatechange = () => {
-            if (xmlHttp.readyState == 4 && xmlHttp.status == 200 && callback) {
-                callback();
+            if (xmlHttp.readyState != 4) {
+                return;
+            }
+            if (xmlHttp.status == 200) {
+                if (callback) {
+                    callback();
+                }
+            } else if (errorCallback) {
+                errorCallback(xmlHttp.responseText);
             }
         }
         xmlHttp.open("POST", theUrl, true); // true for asynchronous 
@@ -242,6 +249,55 @@ class RequestManager {
         this.fetchJSON("./swagger", callback)
     }
 
+    getSubgraphs(callback) {
+        this.fetchJSON("./subgraph", callback)
+    }
+
+    getSubgraph(name, callback) {
+        this.fetchRaw("./subgraph/definition/" + encodeURIComponent(name), callback)
+    }
+
+    addSubgraph(definition, callback) {
+        this.postBinaryEmptyResponse("./subgraph", definition, callback)
+    }
+
+    createSubgraphFromNodes(name, nodeIDs, callback) {
+        this.postJsonBodyEmptyResponse("./subgraph/selection", {
+            "name": name,
+            "nodes": nodeIDs,
+        }, callback)
+    }
+
+    getEditingSubgraph(callback) {
+        this.fetchJSON("./subgraph/edit", callback)
+    }
+
+    editSubgraph(name, callback, errorCallback) {
+        this.postJsonBodyEmptyResponse("./subgraph/edit", {
+            "name": name,
+        }, callback, errorCallback)
+    }
+
+    finishEditingSubgraph(callback, errorCallback) {
+        this.postJsonBodyEmptyResponse("./subgraph/edit/finish", {}, callback, errorCallback)
+    }
+
+    discardSubgraphEdits(callback) {
+        this.deleteEmptyBodyEmptyResponse("./subgraph/edit", callback)
+    }
+
+    getHistory(callback) {
+        this.fetchJSON("./history", callback)
+    }
+
+    undo(callback) {
+        this.postJsonBodyJsonResponse("./history/undo", {}, callback)
+    }
+
+    redo(callback) {
+        this.postJsonBodyJsonResponse("./history/redo", {}, callback)
+    }
+
     setGraph(newGraph, callback) {
         this.postJsonBodyJsonResponse("./graph", newGraph, callback)
     }
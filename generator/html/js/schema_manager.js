@@ -51,4 +51,15 @@ class SchemaManager {
             this.noteManager.schemaUpdate(this.schema);
         }).bind(this))
     }
+
+    /**
+     * Updates the nodes with the latest schema without notifying subscribers,
+     * picking up any errors encountered evaluating producers
+     */
+    refreshNodes() {
+        this.requestManager.getSchema(((newSchema) => {
+            this.schema = newSchema;
+            this.nodeManager.updateNodes(this.schema)
+        }).bind(this))
+    }
 }
\ No newline at end of file
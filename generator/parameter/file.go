@@ -108,6 +108,10 @@ func (sno FileNodeOutput) Port() string {
 	return "Out"
 }
 
+func (sno FileNodeOutput) Proxy(node nodes.Node, port string) nodes.NodeOutputReference {
+	return nodes.NewProxyOutput[[]byte](node, port, sno)
+}
+
 func (tn *File) Outputs() []nodes.Output {
 	return []nodes.Output{
 		{
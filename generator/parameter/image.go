@@ -138,6 +138,10 @@ func (sno ImageNodeOutput) Port() string {
 	return "Out"
 }
 
+func (sno ImageNodeOutput) Proxy(node nodes.Node, port string) nodes.NodeOutputReference {
+	return nodes.NewProxyOutput[image.Image](node, port, sno)
+}
+
 func (tn *Image) Outputs() []nodes.Output {
 	return []nodes.Output{
 		{
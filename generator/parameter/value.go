@@ -35,6 +35,10 @@ func (sno ParameterNodeOutput[T]) Port() string {
 	return "Out"
 }
 
+func (sno ParameterNodeOutput[T]) Proxy(node nodes.Node, port string) nodes.NodeOutputReference {
+	return nodes.NewProxyOutput[T](node, port, sno)
+}
+
 // ============================================================================
 
 type ValueSchema[T any] struct {
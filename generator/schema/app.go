@@ -2,17 +2,27 @@ package schema
 
 import (
 	"encoding/json"
+	"sort"
+	"strings"
 )
 
+// CurrentFormatVersion is the version of the graph format written out. It
+// needs to be incremented alongside registering migrations for changes made
+// to nodes, as graphs are only migrated up from the version they were
+// written with.
+const CurrentFormatVersion = 1
+
 type App struct {
-	Name        string                     `json:"name,omitempty"`
-	Version     string                     `json:"version,omitempty"`
-	Description string                     `json:"description,omitempty"`
-	Authors     []Author                   `json:"authors,omitempty"`
-	WebScene    *WebScene                  `json:"webScene,omitempty"`
-	Producers   map[string]Producer        `json:"producers"`
-	Nodes       map[string]AppNodeInstance `json:"nodes"`
-	Metadata    map[string]any             `json:"metadata,omitempty"`
+	FormatVersion int                        `json:"formatVersion,omitempty"` // Graphs predating versioning are 0
+	Name          string                     `json:"name,omitempty"`
+	Version       string                     `json:"version,omitempty"`
+	Description   string                     `json:"description,omitempty"`
+	Authors       []Author                   `json:"authors,omitempty"`
+	WebScene      *WebScene                  `json:"webScene,omitempty"`
+	Producers     map[string]Producer        `json:"producers"`
+	Nodes         map[string]AppNodeInstance `json:"nodes"`
+	Subgraphs     map[string]Subgraph        `json:"subgraphs,omitempty"`
+	Metadata      map[string]any             `json:"metadata,omitempty"`
 }
 
 type AppNodeInstance struct {
@@ -20,3 +30,15 @@ type AppNodeInstance struct {
 	Dependencies []NodeDependency `json:"dependencies,omitempty"`
 	Data         json.RawMessage  `json:"data,omitempty"`
 }
+
+// SortDependencies puts the dependencies into the order they're written out
+// in, alphabetically by input name regardless of case
+func SortDependencies(dependencies []NodeDependency) {
+	sort.Slice(dependencies, func(i, j int) bool {
+		a, b := strings.ToLower(dependencies[i].Name), strings.ToLower(dependencies[j].Name)
+		if a != b {
+			return a < b
+		}
+		return dependencies[i].Name < dependencies[j].Name
+	})
+}
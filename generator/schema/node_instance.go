@@ -6,6 +6,7 @@ type NodeInstance struct {
 	Version      int              `json:"version"`
 	Dependencies []NodeDependency `json:"dependencies"`
 	Parameter    Parameter        `json:"parameter,omitempty"`
+	Error        string           `json:"error,omitempty"`
 
 	Metadata map[string]any `json:"metadata,omitempty"`
 }
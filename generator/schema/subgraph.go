@@ -0,0 +1,24 @@
+package schema
+
+// Subgraph is a reusable collection of nodes, instantiated within other
+// graphs as a single node
+type Subgraph struct {
+	Name        string                     `json:"name"`
+	Description string                     `json:"description,omitempty"`
+	Inputs      map[string]SubgraphPort    `json:"inputs,omitempty"`
+	Outputs     map[string]SubgraphPort    `json:"outputs"`
+	Nodes       map[string]AppNodeInstance `json:"nodes"`
+
+	// Whatever the editor stored while the subgraph was last edited, like
+	// where each of its nodes sit
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// SubgraphPort ties one of a subgraph's inputs or outputs to the output port
+// of a node within it. Connecting to a subgraph input replaces the output of
+// the node it's tied to everywhere within the subgraph, leaving the node as
+// the input's default.
+type SubgraphPort struct {
+	NodeID string `json:"nodeID"`
+	Port   string `json:"port"`
+}
@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path"
+	"sync"
+
+	"github.com/EliCDavis/polyform/generator/artifact"
+	"github.com/EliCDavis/polyform/generator/graph"
+)
+
+// Thumbnailer renders a preview of the artifact, reporting false for
+// artifacts it doesn't know how to preview
+type Thumbnailer func(a artifact.Artifact, width, height int) (image.Image, bool, error)
+
+var thumbnailers []Thumbnailer
+
+var thumbnailerMutex sync.Mutex
+
+// RegisterThumbnailer adds a way of previewing artifacts, used for writing
+// thumbnails next to the producers that can be previewed
+func RegisterThumbnailer(thumbnailer Thumbnailer) {
+	thumbnailerMutex.Lock()
+	defer thumbnailerMutex.Unlock()
+	thumbnailers = append(thumbnailers, thumbnailer)
+}
+
+// Thumbnail previews the artifact with the first registered thumbnailer that
+// knows how to, reporting false if none do
+func Thumbnail(a artifact.Artifact, width, height int) (image.Image, bool, error) {
+	thumbnailerMutex.Lock()
+	registered := thumbnailers
+	thumbnailerMutex.Unlock()
+
+	for _, thumbnailer := range registered {
+		img, ok, err := thumbnailer(a, width, height)
+		if ok || err != nil {
+			return img, ok, err
+		}
+	}
+	return nil, false, nil
+}
+
+// thumbnailPath is where the thumbnail of the producer is written
+func thumbnailPath(outputPath, producer string) string {
+	return path.Join(outputPath, producer+".thumb.png")
+}
+
+// writeThumbnailsToFolder writes a square PNG preview next to every producer
+// that can be previewed
+func writeThumbnailsToFolder(ctx context.Context, outputPath string, graph *graph.Instance, size int) error {
+	for _, name := range graph.ProducerNames() {
+		a, err := graph.Artifact(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		img, ok, err := Thumbnail(a, size, size)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			continue
+		}
+
+		f, err := os.Create(thumbnailPath(outputPath, name))
+		if err != nil {
+			return err
+		}
+
+		err = png.Encode(f, img)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
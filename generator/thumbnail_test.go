@@ -0,0 +1,64 @@
+package generator_test
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/generator/artifact"
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	generator.RegisterThumbnailer(func(a artifact.Artifact, width, height int) (image.Image, bool, error) {
+		if _, ok := a.(basics.Text); !ok {
+			return nil, false, nil
+		}
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		img.Set(0, 0, color.RGBA{R: 255, A: 255})
+		return img, true, nil
+	})
+}
+
+func TestThumbnail(t *testing.T) {
+	img, ok, err := generator.Thumbnail(basics.Text{Data: "yee"}, 3, 2)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, image.Rect(0, 0, 3, 2), img.Bounds())
+
+	img, ok, err = generator.Thumbnail(basics.Binary{}, 3, 2)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, img)
+}
+
+func TestAppCommand_Generate_Thumbnails(t *testing.T) {
+	out := t.TempDir()
+	app := headlessTestApp()
+
+	require.NoError(t, app.Run([]string{"polyform", "generate", "-folder", out, "-thumbnails", "8"}))
+
+	f, err := os.Open(filepath.Join(out, "text", "test.txt.thumb.png"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	img, err := png.Decode(f)
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 8, 8), img.Bounds())
+}
+
+func TestAppCommand_Generate_NoThumbnails(t *testing.T) {
+	out := t.TempDir()
+	app := headlessTestApp()
+
+	require.NoError(t, app.Run([]string{"polyform", "generate", "-folder", out}))
+
+	_, err := os.Stat(filepath.Join(out, "text", "test.txt.thumb.png"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -7,6 +7,16 @@ type Plane struct {
 	distance float64
 }
 
+// NewPlane creates a plane passing through the point, facing the direction
+// of the normal provided
+func NewPlane(point, normal vector3.Float64) Plane {
+	normal = normal.Normalized()
+	return Plane{
+		normal:   normal,
+		distance: normal.Dot(point),
+	}
+}
+
 func NewPlaneFromPoints(a, b, c vector3.Float64) Plane {
 	normal := b.Sub(a).Cross(c.Sub(a)).Normalized()
 	return Plane{
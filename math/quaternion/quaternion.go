@@ -115,6 +115,36 @@ func (q Quaternion) Multiply(other Quaternion) Quaternion {
 	}
 }
 
+// Slerp spherically interpolates between the two rotations, taking the
+// shortest path between them. t of 0 returns a, and t of 1 returns b.
+//
+// https://github.com/toji/gl-matrix/blob/f0583ef53e94bc7e78b78c8a24f09ed5e2f7a20c/src/gl-matrix/quat.js#L296
+func Slerp(a, b Quaternion, t float64) Quaternion {
+	av := a.Vector4()
+	bv := b.Vector4()
+
+	cosom := av.Dot(bv)
+	if cosom < 0 {
+		cosom = -cosom
+		bv = bv.Scale(-1)
+	}
+
+	scale0 := 1 - t
+	scale1 := t
+
+	// Fall back to linear interpolation when the rotations are close enough
+	// to divide by zero
+	if 1-cosom > 0.000001 {
+		omega := math.Acos(cosom)
+		sinom := math.Sin(omega)
+		scale0 = math.Sin((1-t)*omega) / sinom
+		scale1 = math.Sin(t*omega) / sinom
+	}
+
+	r := av.Scale(scale0).Add(bv.Scale(scale1))
+	return New(vector3.New(r.X(), r.Y(), r.Z()), r.W())
+}
+
 // https://github.com/toji/gl-matrix/blob/f0583ef53e94bc7e78b78c8a24f09ed5e2f7a20c/src/gl-matrix/quat.js#L54
 func RotationTo(from, to vector3.Float64) Quaternion {
 	dot := from.Dot(to)
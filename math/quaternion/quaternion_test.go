@@ -47,3 +47,22 @@ func TestToEulerAngles(t *testing.T) {
 		})
 	}
 }
+
+func TestSlerp(t *testing.T) {
+	a := quaternion.Identity()
+	b := quaternion.FromTheta(math.Pi/2, vector3.New(0., 1., 0.))
+
+	assert.Equal(t, a, quaternion.Slerp(a, b, 0))
+	assert.InDelta(t, b.W(), quaternion.Slerp(a, b, 1).W(), 1e-12)
+
+	half := quaternion.Slerp(a, b, 0.5)
+	expected := quaternion.FromTheta(math.Pi/4, vector3.New(0., 1., 0.))
+	assert.InDelta(t, expected.W(), half.W(), 1e-12)
+	assert.InDelta(t, expected.Dir().Y(), half.Dir().Y(), 1e-12)
+
+	// Takes the shortest path when the rotations are in opposite hemispheres
+	negated := quaternion.New(b.Dir().Scale(-1), -b.W())
+	shortest := quaternion.Slerp(a, negated, 0.5)
+	assert.InDelta(t, expected.W(), shortest.W(), 1e-12)
+	assert.InDelta(t, expected.Dir().Y(), shortest.Dir().Y(), 1e-12)
+}
@@ -0,0 +1,185 @@
+package animation
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/EliCDavis/polyform/math/quaternion"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// Interpolation is how values are computed between a sequence's keyframes
+type Interpolation int
+
+const (
+	// Values are linearly interpolated between keyframes, with rotations
+	// using spherical linear interpolation
+	LinearInterpolation Interpolation = iota
+
+	// Values are held constant until the next keyframe
+	StepInterpolation
+
+	// Values follow a cubic Hermite spline using each keyframe's in and out
+	// tangents
+	CubicSplineInterpolation
+)
+
+func (i Interpolation) String() string {
+	switch i {
+	case LinearInterpolation:
+		return "LINEAR"
+	case StepInterpolation:
+		return "STEP"
+	case CubicSplineInterpolation:
+		return "CUBICSPLINE"
+	}
+	return fmt.Sprintf("Interpolation(%d)", int(i))
+}
+
+// span finds the keyframe at or before the time, along with how far along
+// (0 to 1) the time is towards the following keyframe and the duration
+// between the two. Times outside of the frames are clamped to the first or
+// last keyframe.
+func span[T any](frames []Frame[T], time float64) (int, float64, float64) {
+	last := len(frames) - 1
+	if time <= frames[0].time {
+		return 0, 0, 0
+	}
+
+	if time >= frames[last].time {
+		return last, 0, 0
+	}
+
+	next := sort.Search(len(frames), func(i int) bool {
+		return frames[i].time > time
+	})
+	start := next - 1
+	duration := frames[next].time - frames[start].time
+	return start, (time - frames[start].time) / duration, duration
+}
+
+// hermite returns the weights of the start value, start out tangent, end
+// value, and end in tangent of the cubic spline at t, following the glTF
+// specification where tangents are scaled by the duration between keyframes
+func hermite(t, duration float64) [4]float64 {
+	t2 := t * t
+	t3 := t2 * t
+	return [4]float64{
+		2*t3 - 3*t2 + 1,
+		(t3 - 2*t2 + t) * duration,
+		-2*t3 + 3*t2,
+		(t3 - t2) * duration,
+	}
+}
+
+func sample[T any](
+	frames []Frame[T],
+	interpolation Interpolation,
+	time float64,
+	lerp func(a, b T, t float64) T,
+	combine func(v0, m0, v1, m1 T, weights [4]float64) T,
+) T {
+	start, t, duration := span(frames, time)
+	if duration == 0 || interpolation == StepInterpolation {
+		return frames[start].val
+	}
+
+	a := frames[start]
+	b := frames[start+1]
+	if interpolation == CubicSplineInterpolation {
+		return combine(a.val, a.outTangent, b.val, b.inTangent, hermite(t, duration))
+	}
+	return lerp(a.val, b.val, t)
+}
+
+// SampleVector3 computes the value of the frames at the time provided.
+// Returns zero when there are no frames.
+func SampleVector3(frames []Frame[vector3.Float64], interpolation Interpolation, time float64) vector3.Float64 {
+	if len(frames) == 0 {
+		return vector3.Zero[float64]()
+	}
+
+	return sample(
+		frames, interpolation, time,
+		func(a, b vector3.Float64, t float64) vector3.Float64 {
+			return a.Scale(1 - t).Add(b.Scale(t))
+		},
+		func(v0, m0, v1, m1 vector3.Float64, w [4]float64) vector3.Float64 {
+			return v0.Scale(w[0]).Add(m0.Scale(w[1])).Add(v1.Scale(w[2])).Add(m1.Scale(w[3]))
+		},
+	)
+}
+
+// SampleRotation computes the rotation of the frames at the time provided.
+// Returns the identity when there are no frames.
+func SampleRotation(frames []Frame[quaternion.Quaternion], interpolation Interpolation, time float64) quaternion.Quaternion {
+	if len(frames) == 0 {
+		return quaternion.Identity()
+	}
+
+	return sample(
+		frames, interpolation, time,
+		quaternion.Slerp,
+		func(v0, m0, v1, m1 quaternion.Quaternion, w [4]float64) quaternion.Quaternion {
+			v := v0.Vector4().Scale(w[0]).
+				Add(m0.Vector4().Scale(w[1])).
+				Add(v1.Vector4().Scale(w[2])).
+				Add(m1.Vector4().Scale(w[3]))
+			return quaternion.New(vector3.New(v.X(), v.Y(), v.Z()), v.W()).Normalize()
+		},
+	)
+}
+
+// SampleWeights computes the morph target weights of the frames at the time
+// provided. Returns nil when there are no frames.
+func SampleWeights(frames []Frame[[]float64], interpolation Interpolation, time float64) []float64 {
+	if len(frames) == 0 {
+		return nil
+	}
+
+	weights := sample(
+		frames, interpolation, time,
+		func(a, b []float64, t float64) []float64 {
+			out := make([]float64, len(a))
+			for i := range out {
+				out[i] = a[i]*(1-t) + b[i]*t
+			}
+			return out
+		},
+		func(v0, m0, v1, m1 []float64, w [4]float64) []float64 {
+			// Frames created without tangents have flat ones
+			tangent := func(m []float64, i int) float64 {
+				if i < len(m) {
+					return m[i]
+				}
+				return 0
+			}
+
+			out := make([]float64, len(v0))
+			for i := range out {
+				out[i] = v0[i]*w[0] + tangent(m0, i)*w[1] + v1[i]*w[2] + tangent(m1, i)*w[3]
+			}
+			return out
+		},
+	)
+
+	// Don't hand out the frame's own slice for callers to modify
+	return append([]float64(nil), weights...)
+}
+
+// Vector3 computes the translation or scale of the sequence at the time
+// provided
+func (s Sequence) Vector3(time float64) vector3.Float64 {
+	return SampleVector3(s.frames, s.interpolation, time)
+}
+
+// Rotation computes the rotation of the sequence at the time provided
+func (s Sequence) Rotation(time float64) quaternion.Quaternion {
+	return SampleRotation(s.rotations, s.interpolation, time)
+}
+
+// Weights computes the morph target weights of the sequence at the time
+// provided
+func (s Sequence) Weights(time float64) []float64 {
+	return SampleWeights(s.weights, s.interpolation, time)
+}
@@ -1,10 +1,18 @@
 package animation
 
-import "github.com/EliCDavis/vector/vector3"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/EliCDavis/polyform/math/quaternion"
+	"github.com/EliCDavis/vector/vector3"
+)
 
 type Frame[T any] struct {
-	time float64
-	val  T
+	time       float64
+	val        T
+	inTangent  T
+	outTangent T
 }
 
 func NewFrame[T any](time float64, val T) Frame[T] {
@@ -14,6 +22,18 @@ func NewFrame[T any](time float64, val T) Frame[T] {
 	}
 }
 
+// NewCubicSplineFrame creates a frame for sequences using cubic spline
+// interpolation, where the tangents describe the rate of change per second
+// arriving at and leaving the value
+func NewCubicSplineFrame[T any](time float64, inTangent, val, outTangent T) Frame[T] {
+	return Frame[T]{
+		time:       time,
+		val:        val,
+		inTangent:  inTangent,
+		outTangent: outTangent,
+	}
+}
+
 func (s Frame[T]) Time() float64 {
 	return s.time
 }
@@ -22,24 +42,188 @@ func (s Frame[T]) Val() T {
 	return s.val
 }
 
-// type Sequence interface{}
+func (s Frame[T]) InTangent() T {
+	return s.inTangent
+}
+
+func (s Frame[T]) OutTangent() T {
+	return s.outTangent
+}
 
+// Property is the part of a joint or model's transform a sequence animates
+type Property int
+
+const (
+	TranslationProperty Property = iota
+	RotationProperty
+	ScaleProperty
+	WeightsProperty // Morph target weights
+)
+
+func (p Property) String() string {
+	switch p {
+	case TranslationProperty:
+		return "translation"
+	case RotationProperty:
+		return "rotation"
+	case ScaleProperty:
+		return "scale"
+	case WeightsProperty:
+		return "weights"
+	}
+	return fmt.Sprintf("Property(%d)", int(p))
+}
+
+// Sequence is a set of keyframes animating a single property of a joint. An
+// empty joint animates the model the sequence belongs to rather than any
+// joint of it's skeleton.
 type Sequence struct {
-	joint  string
-	frames []Frame[vector3.Float64]
+	joint         string
+	property      Property
+	interpolation Interpolation
+
+	frames    []Frame[vector3.Float64] // Translation or scale
+	rotations []Frame[quaternion.Quaternion]
+	weights   []Frame[[]float64]
 }
 
+// Frames returns the keyframes of translation and scale sequences
 func (s Sequence) Frames() []Frame[vector3.Float64] {
 	return s.frames
 }
 
+// RotationFrames returns the keyframes of rotation sequences
+func (s Sequence) RotationFrames() []Frame[quaternion.Quaternion] {
+	return s.rotations
+}
+
+// WeightFrames returns the keyframes of morph target weight sequences, with
+// one weight per morph target in each frame
+func (s Sequence) WeightFrames() []Frame[[]float64] {
+	return s.weights
+}
+
 func (s Sequence) Joint() string {
 	return s.joint
 }
 
+func (s Sequence) Property() Property {
+	return s.property
+}
+
+func (s Sequence) Interpolation() Interpolation {
+	return s.interpolation
+}
+
+// Len is the number of keyframes within the sequence
+func (s Sequence) Len() int {
+	switch s.property {
+	case RotationProperty:
+		return len(s.rotations)
+	case WeightsProperty:
+		return len(s.weights)
+	}
+	return len(s.frames)
+}
+
+// Time returns the time of the keyframe at the index
+func (s Sequence) Time(i int) float64 {
+	switch s.property {
+	case RotationProperty:
+		return s.rotations[i].time
+	case WeightsProperty:
+		return s.weights[i].time
+	}
+	return s.frames[i].time
+}
+
+// Duration is the time of the sequence's last keyframe
+func (s Sequence) Duration() float64 {
+	if s.Len() == 0 {
+		return 0
+	}
+	return s.Time(s.Len() - 1)
+}
+
+// Validate checks the sequence can be played back, requiring at least one
+// keyframe, keyframe times that start at or after 0 and strictly increase,
+// two or more keyframes for cubic splines, and the same number of weights
+// across all weight keyframes and their tangents
+func (s Sequence) Validate() error {
+	if s.Len() == 0 {
+		return errors.New("sequence has no keyframes")
+	}
+
+	if s.interpolation == CubicSplineInterpolation && s.Len() < 2 {
+		return errors.New("cubic spline sequences require at least 2 keyframes")
+	}
+
+	if s.Time(0) < 0 {
+		return fmt.Errorf("keyframe 0 has a negative time %g", s.Time(0))
+	}
+
+	for i := 1; i < s.Len(); i++ {
+		if s.Time(i) <= s.Time(i-1) {
+			return fmt.Errorf("keyframe %d time %g does not come after the previous keyframe's %g", i, s.Time(i), s.Time(i-1))
+		}
+	}
+
+	if s.property == WeightsProperty {
+		for i, frame := range s.weights {
+			if len(frame.val) != len(s.weights[0].val) {
+				return fmt.Errorf("keyframe %d has %d weights, expected %d", i, len(frame.val), len(s.weights[0].val))
+			}
+
+			// Frames created without tangents are treated as flat
+			if (frame.inTangent != nil && len(frame.inTangent) != len(frame.val)) ||
+				(frame.outTangent != nil && len(frame.outTangent) != len(frame.val)) {
+				return fmt.Errorf("keyframe %d tangents don't match it's %d weights", i, len(frame.val))
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewSequence creates a sequence linearly interpolating the joint's
+// translation
 func NewSequence(joint string, frames []Frame[vector3.Float64]) Sequence {
+	return NewTranslationSequence(joint, LinearInterpolation, frames)
+}
+
+func NewTranslationSequence(joint string, interpolation Interpolation, frames []Frame[vector3.Float64]) Sequence {
+	return Sequence{
+		joint:         joint,
+		property:      TranslationProperty,
+		interpolation: interpolation,
+		frames:        frames,
+	}
+}
+
+func NewScaleSequence(joint string, interpolation Interpolation, frames []Frame[vector3.Float64]) Sequence {
+	return Sequence{
+		joint:         joint,
+		property:      ScaleProperty,
+		interpolation: interpolation,
+		frames:        frames,
+	}
+}
+
+func NewRotationSequence(joint string, interpolation Interpolation, frames []Frame[quaternion.Quaternion]) Sequence {
+	return Sequence{
+		joint:         joint,
+		property:      RotationProperty,
+		interpolation: interpolation,
+		rotations:     frames,
+	}
+}
+
+// NewWeightsSequence creates a sequence animating the weights of the morph
+// targets of the model it belongs to
+func NewWeightsSequence(interpolation Interpolation, frames []Frame[[]float64]) Sequence {
 	return Sequence{
-		joint:  joint,
-		frames: frames,
+		property:      WeightsProperty,
+		interpolation: interpolation,
+		weights:       frames,
 	}
 }
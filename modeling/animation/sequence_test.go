@@ -0,0 +1,149 @@
+package animation_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/polyform/math/quaternion"
+	"github.com/EliCDavis/polyform/modeling/animation"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequence_Vector3(t *testing.T) {
+	frames := []animation.Frame[vector3.Float64]{
+		animation.NewFrame(1, vector3.New(0., 0., 0.)),
+		animation.NewFrame(3, vector3.New(2., 4., 0.)),
+	}
+
+	tests := map[string]struct {
+		interpolation animation.Interpolation
+		time          float64
+		want          vector3.Float64
+	}{
+		"linear before first": {animation.LinearInterpolation, 0, vector3.New(0., 0., 0.)},
+		"linear middle":       {animation.LinearInterpolation, 2, vector3.New(1., 2., 0.)},
+		"linear after last":   {animation.LinearInterpolation, 5, vector3.New(2., 4., 0.)},
+		"step middle":         {animation.StepInterpolation, 2.9, vector3.New(0., 0., 0.)},
+		"step on keyframe":    {animation.StepInterpolation, 3, vector3.New(2., 4., 0.)},
+
+		// Flat tangents ease in and out, crossing the midpoint halfway
+		"cubic middle":  {animation.CubicSplineInterpolation, 2, vector3.New(1., 2., 0.)},
+		"cubic quarter": {animation.CubicSplineInterpolation, 1.5, vector3.New(0.3125, 0.625, 0.)},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			sequence := animation.NewTranslationSequence("Joint", tc.interpolation, frames)
+			got := sequence.Vector3(tc.time)
+			assert.InDelta(t, tc.want.X(), got.X(), 1e-9)
+			assert.InDelta(t, tc.want.Y(), got.Y(), 1e-9)
+			assert.InDelta(t, tc.want.Z(), got.Z(), 1e-9)
+		})
+	}
+}
+
+func TestSequence_CubicSplineTangents(t *testing.T) {
+	// Tangents matching the slope of the line reproduce it exactly
+	sequence := animation.NewScaleSequence("", animation.CubicSplineInterpolation, []animation.Frame[vector3.Float64]{
+		animation.NewCubicSplineFrame(0, vector3.New(1., 0., 0.), vector3.New(0., 0., 0.), vector3.New(1., 0., 0.)),
+		animation.NewCubicSplineFrame(2, vector3.New(1., 0., 0.), vector3.New(2., 0., 0.), vector3.New(1., 0., 0.)),
+	})
+
+	assert.Equal(t, animation.ScaleProperty, sequence.Property())
+	assert.InDelta(t, 0.5, sequence.Vector3(0.5).X(), 1e-9)
+	assert.InDelta(t, 1.5, sequence.Vector3(1.5).X(), 1e-9)
+}
+
+func TestSequence_Rotation(t *testing.T) {
+	up := vector3.New(0., 1., 0.)
+	sequence := animation.NewRotationSequence("Joint", animation.LinearInterpolation, []animation.Frame[quaternion.Quaternion]{
+		animation.NewFrame(0, quaternion.Identity()),
+		animation.NewFrame(1, quaternion.FromTheta(math.Pi/2, up)),
+	})
+
+	assert.Equal(t, animation.RotationProperty, sequence.Property())
+	assert.Equal(t, 2, sequence.Len())
+	assert.Equal(t, 1., sequence.Duration())
+
+	got := sequence.Rotation(0.5)
+	want := quaternion.FromTheta(math.Pi/4, up)
+	assert.InDelta(t, want.W(), got.W(), 1e-9)
+	assert.InDelta(t, want.Dir().Y(), got.Dir().Y(), 1e-9)
+
+	// Cubic splines stay normalized
+	cubic := animation.NewRotationSequence("Joint", animation.CubicSplineInterpolation, sequence.RotationFrames())
+	assert.InDelta(t, 1, cubic.Rotation(0.5).Vector4().Length(), 1e-9)
+}
+
+func TestSequence_Weights(t *testing.T) {
+	sequence := animation.NewWeightsSequence(animation.LinearInterpolation, []animation.Frame[[]float64]{
+		animation.NewFrame(0, []float64{0, 1}),
+		animation.NewFrame(1, []float64{1, 0}),
+	})
+
+	assert.Equal(t, "", sequence.Joint())
+	assert.Equal(t, animation.WeightsProperty, sequence.Property())
+	assert.Equal(t, []float64{0.25, 0.75}, sequence.Weights(0.25))
+
+	// Callers can't modify the frames through the sampled weights
+	sampled := sequence.Weights(-1)
+	sampled[0] = 100
+	assert.Equal(t, []float64{0, 1}, sequence.WeightFrames()[0].Val())
+}
+
+func TestSequence_Validate(t *testing.T) {
+	v := vector3.New(0., 0., 0.)
+	tests := map[string]struct {
+		sequence animation.Sequence
+		err      string
+	}{
+		"valid": {
+			sequence: animation.NewSequence("Joint", []animation.Frame[vector3.Float64]{
+				animation.NewFrame(0, v),
+				animation.NewFrame(1, v),
+			}),
+		},
+		"empty": {
+			sequence: animation.NewSequence("Joint", nil),
+			err:      "sequence has no keyframes",
+		},
+		"negative time": {
+			sequence: animation.NewSequence("Joint", []animation.Frame[vector3.Float64]{
+				animation.NewFrame(-1, v),
+			}),
+			err: "keyframe 0 has a negative time -1",
+		},
+		"out of order": {
+			sequence: animation.NewSequence("Joint", []animation.Frame[vector3.Float64]{
+				animation.NewFrame(1, v),
+				animation.NewFrame(1, v),
+			}),
+			err: "keyframe 1 time 1 does not come after the previous keyframe's 1",
+		},
+		"single cubic keyframe": {
+			sequence: animation.NewTranslationSequence("Joint", animation.CubicSplineInterpolation, []animation.Frame[vector3.Float64]{
+				animation.NewFrame(0, v),
+			}),
+			err: "cubic spline sequences require at least 2 keyframes",
+		},
+		"mismatched weights": {
+			sequence: animation.NewWeightsSequence(animation.StepInterpolation, []animation.Frame[[]float64]{
+				animation.NewFrame(0, []float64{1, 2}),
+				animation.NewFrame(1, []float64{1}),
+			}),
+			err: "keyframe 1 has 1 weights, expected 2",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tc.sequence.Validate()
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
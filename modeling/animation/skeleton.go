@@ -35,6 +35,19 @@ func (s Skeleton) Lookup(name string) int {
 	panic(fmt.Errorf("skeleton did not contain a joint with the path: %s", name))
 }
 
+// TryLookup returns the index of the joint with the path provided, and
+// whether or not the skeleton contains it
+func (s Skeleton) TryLookup(name string) (int, bool) {
+	index, ok := s.jointLUT[name]
+	return index, ok
+}
+
+// Path returns the full path of the joint at the index, with each joint's
+// name separated by a "/"
+func (s Skeleton) Path(index int) string {
+	return s.joints[index].path
+}
+
 func (s Skeleton) Children(index int) []int {
 	return s.joints[index].children
 }
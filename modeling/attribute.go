@@ -3,6 +3,7 @@ package modeling
 const (
 	PositionAttribute  = "Position"
 	NormalAttribute    = "Normal"
+	TangentAttribute   = "Tangent"
 	ColorAttribute     = "Color"
 	TexCoordAttribute  = "TexCoord"
 	ClassAttribute     = "Class"
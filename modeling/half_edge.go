@@ -0,0 +1,311 @@
+package modeling
+
+import "sort"
+
+// Edge is a pair of vertex indices. Edges returned from a HalfEdgeMesh are
+// directed, following the winding of the face they came from, unless
+// otherwise documented.
+type Edge struct {
+	A, B int
+}
+
+// HalfEdge is one side of an edge, running from Origin to the origin of the
+// next half edge within the same face
+type HalfEdge struct {
+	Origin int // Vertex the half edge starts from
+	Face   int // Triangle the half edge belongs to
+	Next   int // Next half edge within the face
+	Prev   int // Previous half edge within the face
+
+	// Half edge running in the opposite direction along the same edge. -1 if
+	// the edge is either on the boundary or is non-manifold
+	Twin int
+}
+
+// HalfEdgeMesh is a connectivity view of a triangle mesh, answering
+// adjacency queries about edges, faces, and vertices without needing to
+// rebuild lookup tables for each question.
+//
+// Triangle i is made up of the half edges 3i, 3i+1, and 3i+2. An edge is
+// considered manifold when it's shared by exactly two triangles that wind
+// it in opposite directions. Edges shared by more triangles, or by two
+// triangles that wind it in the same direction, are non-manifold and their
+// half edges are left without twins.
+type HalfEdgeMesh struct {
+	halfEdges   []HalfEdge
+	outgoing    [][]int // Half edges leaving each vertex
+	nonManifold []Edge
+}
+
+// NewHalfEdgeMesh builds the connectivity of the triangle indices provided
+// for a mesh containing vertexCount vertices
+func NewHalfEdgeMesh(triangles []int, vertexCount int) *HalfEdgeMesh {
+	faceCount := len(triangles) / 3
+	for _, index := range triangles {
+		vertexCount = max(vertexCount, index+1)
+	}
+	hem := &HalfEdgeMesh{
+		halfEdges: make([]HalfEdge, faceCount*3),
+		outgoing:  make([][]int, vertexCount),
+	}
+
+	directed := make(map[Edge][]int, faceCount*3)
+	for face := 0; face < faceCount; face++ {
+		for i := 0; i < 3; i++ {
+			he := face*3 + i
+			next := face*3 + (i+1)%3
+			prev := face*3 + (i+2)%3
+			origin := triangles[he]
+
+			hem.halfEdges[he] = HalfEdge{
+				Origin: origin,
+				Face:   face,
+				Next:   next,
+				Prev:   prev,
+				Twin:   -1,
+			}
+			hem.outgoing[origin] = append(hem.outgoing[origin], he)
+
+			edge := Edge{A: origin, B: triangles[next]}
+			directed[edge] = append(directed[edge], he)
+		}
+	}
+
+	for edge := range directed {
+		// Visit each undirected edge once, from it's smaller index
+		if edge.A > edge.B {
+			if _, ok := directed[Edge{A: edge.B, B: edge.A}]; ok {
+				continue
+			}
+		}
+
+		forward := directed[edge]
+		backward := directed[Edge{A: edge.B, B: edge.A}]
+
+		if len(forward) == 1 && len(backward) == 1 {
+			hem.halfEdges[forward[0]].Twin = backward[0]
+			hem.halfEdges[backward[0]].Twin = forward[0]
+			continue
+		}
+
+		if len(forward)+len(backward) == 1 || edge.A == edge.B {
+			continue
+		}
+
+		hem.nonManifold = append(hem.nonManifold, Edge{A: min(edge.A, edge.B), B: max(edge.A, edge.B)})
+	}
+
+	sort.Slice(hem.nonManifold, func(i, j int) bool {
+		if hem.nonManifold[i].A == hem.nonManifold[j].A {
+			return hem.nonManifold[i].B < hem.nonManifold[j].B
+		}
+		return hem.nonManifold[i].A < hem.nonManifold[j].A
+	})
+
+	return hem
+}
+
+// HalfEdgeCount is the total number of half edges, which is always three
+// times the number of faces
+func (hem HalfEdgeMesh) HalfEdgeCount() int {
+	return len(hem.halfEdges)
+}
+
+func (hem HalfEdgeMesh) HalfEdge(i int) HalfEdge {
+	return hem.halfEdges[i]
+}
+
+func (hem HalfEdgeMesh) FaceCount() int {
+	return len(hem.halfEdges) / 3
+}
+
+func (hem HalfEdgeMesh) VertexCount() int {
+	return len(hem.outgoing)
+}
+
+// Destination is the vertex the half edge points to
+func (hem HalfEdgeMesh) Destination(halfEdge int) int {
+	return hem.halfEdges[hem.halfEdges[halfEdge].Next].Origin
+}
+
+// Edge returns the directed edge represented by the half edge
+func (hem HalfEdgeMesh) Edge(halfEdge int) Edge {
+	return Edge{A: hem.halfEdges[halfEdge].Origin, B: hem.Destination(halfEdge)}
+}
+
+// IsBoundary is true when the half edge belongs to the only face that
+// references it's edge
+func (hem HalfEdgeMesh) IsBoundary(halfEdge int) bool {
+	return hem.halfEdges[halfEdge].Twin == -1 && !hem.isNonManifold(hem.Edge(halfEdge))
+}
+
+func (hem HalfEdgeMesh) isNonManifold(e Edge) bool {
+	key := Edge{A: min(e.A, e.B), B: max(e.A, e.B)}
+	i := sort.Search(len(hem.nonManifold), func(i int) bool {
+		nm := hem.nonManifold[i]
+		return nm.A > key.A || (nm.A == key.A && nm.B >= key.B)
+	})
+	return i < len(hem.nonManifold) && hem.nonManifold[i] == key
+}
+
+// BoundaryEdges returns every edge referenced by a single face, directed in
+// the winding order of that face
+func (hem HalfEdgeMesh) BoundaryEdges() []Edge {
+	edges := make([]Edge, 0)
+	for i := range hem.halfEdges {
+		if hem.IsBoundary(i) {
+			edges = append(edges, hem.Edge(i))
+		}
+	}
+	return edges
+}
+
+// NonManifoldEdges returns every edge that is either shared by more than two
+// faces, or shared by two faces with inconsistent winding. The edges are
+// undirected, with A always being the smaller of the two indices.
+func (hem HalfEdgeMesh) NonManifoldEdges() []Edge {
+	return append([]Edge(nil), hem.nonManifold...)
+}
+
+// IsManifold is true when every edge of the mesh is shared by at most two
+// consistently wound faces
+func (hem HalfEdgeMesh) IsManifold() bool {
+	return len(hem.nonManifold) == 0
+}
+
+// IsClosed is true when the mesh is manifold and contains no boundary edges
+func (hem HalfEdgeMesh) IsClosed() bool {
+	if !hem.IsManifold() {
+		return false
+	}
+
+	for _, he := range hem.halfEdges {
+		if he.Twin == -1 {
+			return false
+		}
+	}
+	return true
+}
+
+// FaceNeighbors returns the face found across each edge of the face provided,
+// or -1 if the edge is on the boundary or is non-manifold
+func (hem HalfEdgeMesh) FaceNeighbors(face int) [3]int {
+	neighbors := [3]int{-1, -1, -1}
+	for i := 0; i < 3; i++ {
+		if twin := hem.halfEdges[face*3+i].Twin; twin != -1 {
+			neighbors[i] = hem.halfEdges[twin].Face
+		}
+	}
+	return neighbors
+}
+
+// IsBoundaryVertex is true when the vertex is the start of any boundary edge
+// or the end of one
+func (hem HalfEdgeMesh) IsBoundaryVertex(v int) bool {
+	for _, he := range hem.outgoing[v] {
+		if hem.IsBoundary(he) || hem.IsBoundary(hem.halfEdges[he].Prev) {
+			return true
+		}
+	}
+	return false
+}
+
+// OutgoingHalfEdges returns every half edge that starts from the vertex
+func (hem HalfEdgeMesh) OutgoingHalfEdges(v int) []int {
+	return hem.outgoing[v]
+}
+
+// OneRing returns the vertices connected to v by an edge, in order around
+// the vertex following the winding of the faces. Vertices on a boundary
+// start from the boundary edge so the ring reads as a single fan. Vertices
+// touching multiple fans, like where two cones meet at their tips, have each
+// fan appended one after the other.
+func (hem HalfEdgeMesh) OneRing(v int) []int {
+	ring := make([]int, 0, len(hem.outgoing[v]))
+	visited := make(map[int]struct{}, len(hem.outgoing[v]))
+
+	walk := func(start int) {
+		// Rewind to the start of the fan if we're not already at it
+		he := start
+		for {
+			twin := hem.halfEdges[hem.halfEdges[he].Prev].Twin
+			if twin == -1 || twin == start {
+				break
+			}
+			he = twin
+		}
+		start = he
+
+		// The first vertex of a boundary fan is only reachable from the
+		// previous half edge of the face
+		if hem.halfEdges[hem.halfEdges[start].Prev].Twin == -1 {
+			ring = append(ring, hem.halfEdges[hem.halfEdges[start].Prev].Origin)
+		}
+
+		for {
+			visited[he] = struct{}{}
+			ring = append(ring, hem.Destination(he))
+
+			twin := hem.halfEdges[he].Twin
+			if twin == -1 {
+				return
+			}
+
+			he = hem.halfEdges[twin].Next
+			if he == start {
+				return
+			}
+		}
+	}
+
+	for _, he := range hem.outgoing[v] {
+		if _, ok := visited[he]; !ok {
+			walk(he)
+		}
+	}
+
+	return ring
+}
+
+// ConnectedComponents groups faces into sets where each face can reach every
+// other face in the set by walking across shared vertices
+func (hem HalfEdgeMesh) ConnectedComponents() [][]int {
+	parent := make([]int, len(hem.outgoing))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for face := 0; face < hem.FaceCount(); face++ {
+		root := find(hem.halfEdges[face*3].Origin)
+		for i := 1; i < 3; i++ {
+			other := find(hem.halfEdges[face*3+i].Origin)
+			if other != root {
+				parent[other] = root
+			}
+		}
+	}
+
+	componentLUT := make(map[int]int)
+	components := make([][]int, 0)
+	for face := 0; face < hem.FaceCount(); face++ {
+		root := find(hem.halfEdges[face*3].Origin)
+		component, ok := componentLUT[root]
+		if !ok {
+			component = len(components)
+			componentLUT[root] = component
+			components = append(components, make([]int, 0))
+		}
+		components[component] = append(components[component], face)
+	}
+
+	return components
+}
@@ -0,0 +1,105 @@
+package modeling_test
+
+import (
+	"testing"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/primitives"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHalfEdgeMesh_Quad(t *testing.T) {
+	// 3 ----- 2
+	// |     / |
+	// |   /   |
+	// | /     |
+	// 0 ----- 1
+	hem := modeling.NewHalfEdgeMesh([]int{0, 1, 2, 0, 2, 3}, 4)
+
+	assert.Equal(t, 6, hem.HalfEdgeCount())
+	assert.Equal(t, 2, hem.FaceCount())
+	assert.True(t, hem.IsManifold())
+	assert.False(t, hem.IsClosed())
+
+	assert.ElementsMatch(t, []modeling.Edge{
+		{A: 0, B: 1},
+		{A: 1, B: 2},
+		{A: 2, B: 3},
+		{A: 3, B: 0},
+	}, hem.BoundaryEdges())
+	assert.Empty(t, hem.NonManifoldEdges())
+
+	assert.Equal(t, [3]int{-1, -1, 1}, hem.FaceNeighbors(0))
+	assert.Equal(t, [3]int{0, -1, -1}, hem.FaceNeighbors(1))
+
+	assert.Equal(t, []int{3, 2, 1}, hem.OneRing(0))
+	assert.Equal(t, []int{0, 2}, hem.OneRing(1))
+	assert.Equal(t, []int{1, 0, 3}, hem.OneRing(2))
+	assert.True(t, hem.IsBoundaryVertex(0))
+}
+
+func TestHalfEdgeMesh_ClosedMesh(t *testing.T) {
+	cube := primitives.UnitCube()
+	hem := cube.HalfEdges()
+
+	assert.True(t, hem.IsManifold())
+	assert.True(t, hem.IsClosed())
+	assert.Empty(t, hem.BoundaryEdges())
+
+	lut := cube.VertexNeighborTable()
+	for v := 0; v < hem.VertexCount(); v++ {
+		assert.False(t, hem.IsBoundaryVertex(v))
+
+		ring := hem.OneRing(v)
+		assert.Len(t, ring, lut.Count(v))
+		for _, n := range ring {
+			assert.Contains(t, lut.Lookup(v), n)
+		}
+
+		// Each consecutive pair in the ring should form a face with v
+		for i := range ring {
+			a := ring[i]
+			b := ring[(i+1)%len(ring)]
+			assert.Contains(t, lut.Lookup(a), b)
+		}
+	}
+
+	for f := 0; f < hem.FaceCount(); f++ {
+		for _, n := range hem.FaceNeighbors(f) {
+			assert.NotEqual(t, -1, n)
+		}
+	}
+
+	assert.Len(t, hem.ConnectedComponents(), 1)
+}
+
+func TestHalfEdgeMesh_NonManifold(t *testing.T) {
+	// Three triangles sharing the edge 0-1, and a fourth triangle wound
+	// inconsistently with it's neighbor across edge 2-5
+	hem := modeling.NewHalfEdgeMesh([]int{
+		0, 1, 2,
+		1, 0, 3,
+		0, 1, 4,
+		2, 5, 6,
+		2, 5, 7,
+	}, 8)
+
+	assert.False(t, hem.IsManifold())
+	assert.Equal(t, []modeling.Edge{{A: 0, B: 1}, {A: 2, B: 5}}, hem.NonManifoldEdges())
+	assert.Equal(t, [3]int{-1, -1, -1}, hem.FaceNeighbors(0))
+
+	for _, e := range hem.BoundaryEdges() {
+		assert.NotEqual(t, modeling.Edge{A: 0, B: 1}, e)
+		assert.NotEqual(t, modeling.Edge{A: 1, B: 0}, e)
+	}
+}
+
+func TestHalfEdgeMesh_ConnectedComponents(t *testing.T) {
+	hem := modeling.NewHalfEdgeMesh([]int{
+		0, 1, 2,
+		3, 4, 5,
+		2, 1, 6,
+	}, 7)
+
+	assert.Equal(t, [][]int{{0, 2}, {1}}, hem.ConnectedComponents())
+}
@@ -969,6 +969,13 @@ func (m Mesh) WeldByFloat3Attribute(attribute string, decimalPlace int) Mesh {
 	}
 }
 
+// HalfEdges builds a connectivity view of the mesh for answering adjacency
+// queries about it's edges, faces, and vertices. Requires triangle topology
+func (m Mesh) HalfEdges() *HalfEdgeMesh {
+	m.requireTopology(TriangleTopology)
+	return NewHalfEdgeMesh(m.indices, m.AttributeLength())
+}
+
 func (m Mesh) VertexNeighborTable() VertexLUT {
 	table := VertexLUT{}
 
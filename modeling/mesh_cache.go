@@ -0,0 +1,209 @@
+package modeling
+
+import (
+	"encoding/gob"
+	"image/color"
+	"io"
+
+	"github.com/EliCDavis/polyform/generator/cache"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+func init() {
+	cache.RegisterCodec("mesh", encodeCachedMesh, decodeCachedMesh)
+}
+
+func encodeCachedMesh(out io.Writer, m Mesh) error {
+	return gob.NewEncoder(out).Encode(newCachedMesh(m))
+}
+
+func decodeCachedMesh(in io.Reader) (Mesh, error) {
+	cached := cachedMesh{}
+	if err := gob.NewDecoder(in).Decode(&cached); err != nil {
+		return Mesh{}, err
+	}
+	return cached.Mesh(), nil
+}
+
+// cachedMesh mirrors the contents of a mesh in a form gob can encode, with
+// vector attributes flattened into their components
+type cachedMesh struct {
+	Topology  Topology
+	Indices   []int
+	Float4    map[string][]float64
+	Float3    map[string][]float64
+	Float2    map[string][]float64
+	Float1    map[string][]float64
+	Materials []cachedMeshMaterial
+}
+
+type cachedMeshMaterial struct {
+	PrimitiveCount int
+	Material       *cachedMaterial
+}
+
+type cachedMaterial struct {
+	Name               string
+	AmbientColor       *color.RGBA64
+	DiffuseColor       *color.RGBA64
+	SpecularColor      *color.RGBA64
+	SpecularHighlight  float64
+	OpticalDensity     float64
+	Transparency       float64
+	ColorTextureURI    *string
+	NormalTextureURI   *string
+	SpecularTextureURI *string
+}
+
+func toRGBA64(c color.Color) *color.RGBA64 {
+	if c == nil {
+		return nil
+	}
+	rgba := color.RGBA64Model.Convert(c).(color.RGBA64)
+	return &rgba
+}
+
+func fromRGBA64(c *color.RGBA64) color.Color {
+	if c == nil {
+		return nil
+	}
+	return *c
+}
+
+func flatten[T any](count int, at func(i int) T, components func(v T) []float64) []float64 {
+	data := make([]float64, 0, count)
+	for i := 0; i < count; i++ {
+		data = append(data, components(at(i))...)
+	}
+	return data
+}
+
+func unflatten[T any](data []float64, size int, build func(c []float64) T) []T {
+	out := make([]T, len(data)/size)
+	for i := range out {
+		out[i] = build(data[i*size : (i+1)*size])
+	}
+	return out
+}
+
+func newCachedMesh(m Mesh) cachedMesh {
+	indices := m.Indices()
+	cached := cachedMesh{
+		Topology: m.Topology(),
+		Indices:  make([]int, indices.Len()),
+		Float4:   make(map[string][]float64),
+		Float3:   make(map[string][]float64),
+		Float2:   make(map[string][]float64),
+		Float1:   make(map[string][]float64),
+	}
+
+	for i := range cached.Indices {
+		cached.Indices[i] = indices.At(i)
+	}
+
+	for _, attr := range m.Float4Attributes() {
+		data := m.Float4Attribute(attr)
+		cached.Float4[attr] = flatten(data.Len(), data.At, func(v vector4.Float64) []float64 {
+			return []float64{v.X(), v.Y(), v.Z(), v.W()}
+		})
+	}
+
+	for _, attr := range m.Float3Attributes() {
+		data := m.Float3Attribute(attr)
+		cached.Float3[attr] = flatten(data.Len(), data.At, func(v vector3.Float64) []float64 {
+			return []float64{v.X(), v.Y(), v.Z()}
+		})
+	}
+
+	for _, attr := range m.Float2Attributes() {
+		data := m.Float2Attribute(attr)
+		cached.Float2[attr] = flatten(data.Len(), data.At, func(v vector2.Float64) []float64 {
+			return []float64{v.X(), v.Y()}
+		})
+	}
+
+	for _, attr := range m.Float1Attributes() {
+		data := m.Float1Attribute(attr)
+		cached.Float1[attr] = flatten(data.Len(), data.At, func(v float64) []float64 {
+			return []float64{v}
+		})
+	}
+
+	for _, mat := range m.Materials() {
+		cachedMat := cachedMeshMaterial{PrimitiveCount: mat.PrimitiveCount}
+		if mat.Material != nil {
+			cachedMat.Material = &cachedMaterial{
+				Name:               mat.Material.Name,
+				AmbientColor:       toRGBA64(mat.Material.AmbientColor),
+				DiffuseColor:       toRGBA64(mat.Material.DiffuseColor),
+				SpecularColor:      toRGBA64(mat.Material.SpecularColor),
+				SpecularHighlight:  mat.Material.SpecularHighlight,
+				OpticalDensity:     mat.Material.OpticalDensity,
+				Transparency:       mat.Material.Transparency,
+				ColorTextureURI:    mat.Material.ColorTextureURI,
+				NormalTextureURI:   mat.Material.NormalTextureURI,
+				SpecularTextureURI: mat.Material.SpecularTextureURI,
+			}
+		}
+		cached.Materials = append(cached.Materials, cachedMat)
+	}
+
+	return cached
+}
+
+func (cm cachedMesh) Mesh() Mesh {
+	var materials []MeshMaterial
+	for _, mat := range cm.Materials {
+		meshMat := MeshMaterial{PrimitiveCount: mat.PrimitiveCount}
+		if mat.Material != nil {
+			meshMat.Material = &Material{
+				Name:               mat.Material.Name,
+				AmbientColor:       fromRGBA64(mat.Material.AmbientColor),
+				DiffuseColor:       fromRGBA64(mat.Material.DiffuseColor),
+				SpecularColor:      fromRGBA64(mat.Material.SpecularColor),
+				SpecularHighlight:  mat.Material.SpecularHighlight,
+				OpticalDensity:     mat.Material.OpticalDensity,
+				Transparency:       mat.Material.Transparency,
+				ColorTextureURI:    mat.Material.ColorTextureURI,
+				NormalTextureURI:   mat.Material.NormalTextureURI,
+				SpecularTextureURI: mat.Material.SpecularTextureURI,
+			}
+		}
+		materials = append(materials, meshMat)
+	}
+
+	float4 := make(map[string][]vector4.Float64)
+	for attr, data := range cm.Float4 {
+		float4[attr] = unflatten(data, 4, func(c []float64) vector4.Float64 {
+			return vector4.New(c[0], c[1], c[2], c[3])
+		})
+	}
+
+	float3 := make(map[string][]vector3.Float64)
+	for attr, data := range cm.Float3 {
+		float3[attr] = unflatten(data, 3, func(c []float64) vector3.Float64 {
+			return vector3.New(c[0], c[1], c[2])
+		})
+	}
+
+	float2 := make(map[string][]vector2.Float64)
+	for attr, data := range cm.Float2 {
+		float2[attr] = unflatten(data, 2, func(c []float64) vector2.Float64 {
+			return vector2.New(c[0], c[1])
+		})
+	}
+
+	float1 := make(map[string][]float64)
+	for attr, data := range cm.Float1 {
+		float1[attr] = data
+	}
+
+	return NewMesh(cm.Topology, cm.Indices).
+		SetFloat4Data(float4).
+		SetFloat3Data(float3).
+		SetFloat2Data(float2).
+		SetFloat1Data(float1).
+		SetMaterials(materials)
+}
@@ -0,0 +1,712 @@
+package meshops
+
+import (
+	"math"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+// Constructive solid geometry, implemented with BSP trees in the manner of
+// csg.js (https://github.com/evanw/csg.js). Both meshes are expected to be
+// closed, and are cut against each other's planes rather than resampled, so
+// sharp edges, vertex attributes and materials are all retained.
+
+// Tolerance used when classifying vertices against a plane
+const csgEpsilon = 1e-5
+
+// Union ======================================================================
+
+type UnionTransformer struct {
+	Attribute string
+	Other     modeling.Mesh
+}
+
+func (ut UnionTransformer) attribute() string {
+	return ut.Attribute
+}
+
+func (ut UnionTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	attribute := getAttribute(ut, modeling.PositionAttribute)
+
+	if err = requireCSGInput(m, ut.Other, attribute); err != nil {
+		return
+	}
+
+	return Union(m, ut.Other, attribute), nil
+}
+
+// Union builds a mesh that encloses the volume found within either of the
+// two meshes
+func Union(a, b modeling.Mesh, attribute string) modeling.Mesh {
+	csg := newCSG(a, b, attribute)
+
+	nodeA := csg.tree(csg.a)
+	nodeB := csg.tree(csg.b)
+
+	nodeA.clipTo(csg, nodeB)
+	nodeB.clipTo(csg, nodeA)
+	nodeB.invert()
+	nodeB.clipTo(csg, nodeA)
+	nodeB.invert()
+	nodeA.build(csg, nodeB.allPolygons())
+
+	return csg.mesh(nodeA.allPolygons())
+}
+
+// Difference =================================================================
+
+type DifferenceTransformer struct {
+	Attribute string
+	Other     modeling.Mesh // Mesh to subtract from the one being transformed
+}
+
+func (dt DifferenceTransformer) attribute() string {
+	return dt.Attribute
+}
+
+func (dt DifferenceTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	attribute := getAttribute(dt, modeling.PositionAttribute)
+
+	if err = requireCSGInput(m, dt.Other, attribute); err != nil {
+		return
+	}
+
+	return Difference(m, dt.Other, attribute), nil
+}
+
+// Difference builds a mesh that encloses the volume found within a, but not
+// within b
+func Difference(a, b modeling.Mesh, attribute string) modeling.Mesh {
+	csg := newCSG(a, b, attribute)
+
+	nodeA := csg.tree(csg.a)
+	nodeB := csg.tree(csg.b)
+
+	nodeA.invert()
+	nodeA.clipTo(csg, nodeB)
+	nodeB.clipTo(csg, nodeA)
+	nodeB.invert()
+	nodeB.clipTo(csg, nodeA)
+	nodeB.invert()
+	nodeA.build(csg, nodeB.allPolygons())
+	nodeA.invert()
+
+	return csg.mesh(nodeA.allPolygons())
+}
+
+// Intersection ===============================================================
+
+type IntersectionTransformer struct {
+	Attribute string
+	Other     modeling.Mesh
+}
+
+func (it IntersectionTransformer) attribute() string {
+	return it.Attribute
+}
+
+func (it IntersectionTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	attribute := getAttribute(it, modeling.PositionAttribute)
+
+	if err = requireCSGInput(m, it.Other, attribute); err != nil {
+		return
+	}
+
+	return Intersection(m, it.Other, attribute), nil
+}
+
+// Intersection builds a mesh that encloses the volume found within both of
+// the meshes
+func Intersection(a, b modeling.Mesh, attribute string) modeling.Mesh {
+	csg := newCSG(a, b, attribute)
+
+	nodeA := csg.tree(csg.a)
+	nodeB := csg.tree(csg.b)
+
+	nodeA.invert()
+	nodeB.clipTo(csg, nodeA)
+	nodeB.invert()
+	nodeA.clipTo(csg, nodeB)
+	nodeB.clipTo(csg, nodeA)
+	nodeA.build(csg, nodeB.allPolygons())
+	nodeA.invert()
+
+	return csg.mesh(nodeA.allPolygons())
+}
+
+func requireCSGInput(a, b modeling.Mesh, attribute string) error {
+	for _, m := range []modeling.Mesh{a, b} {
+		if err := RequireTopology(m, modeling.TriangleTopology); err != nil {
+			return err
+		}
+
+		if err := RequireV3Attribute(m, attribute); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Nodes ======================================================================
+
+type UnionNode = nodes.Struct[modeling.Mesh, UnionNodeData]
+
+type UnionNodeData struct {
+	A         nodes.NodeOutput[modeling.Mesh]
+	B         nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+}
+
+func (UnionNodeData) Description() string {
+	return "Boolean union, keeping the volume found within either mesh"
+}
+
+func (und UnionNodeData) Process() (modeling.Mesh, error) {
+	if und.A == nil && und.B == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	if und.A == nil {
+		return und.B.Value(), nil
+	}
+
+	if und.B == nil {
+		return und.A.Value(), nil
+	}
+
+	return UnionTransformer{
+		Attribute: nodes.TryGetOutputValue(und.Attribute, modeling.PositionAttribute),
+		Other:     und.B.Value(),
+	}.Transform(und.A.Value())
+}
+
+type DifferenceNode = nodes.Struct[modeling.Mesh, DifferenceNodeData]
+
+type DifferenceNodeData struct {
+	A         nodes.NodeOutput[modeling.Mesh]
+	B         nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+}
+
+func (DifferenceNodeData) Description() string {
+	return "Boolean difference, removing the volume of B from A"
+}
+
+func (dnd DifferenceNodeData) Process() (modeling.Mesh, error) {
+	if dnd.A == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	if dnd.B == nil {
+		return dnd.A.Value(), nil
+	}
+
+	return DifferenceTransformer{
+		Attribute: nodes.TryGetOutputValue(dnd.Attribute, modeling.PositionAttribute),
+		Other:     dnd.B.Value(),
+	}.Transform(dnd.A.Value())
+}
+
+type IntersectionNode = nodes.Struct[modeling.Mesh, IntersectionNodeData]
+
+type IntersectionNodeData struct {
+	A         nodes.NodeOutput[modeling.Mesh]
+	B         nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+}
+
+func (IntersectionNodeData) Description() string {
+	return "Boolean intersection, keeping only the volume shared by both meshes"
+}
+
+func (ind IntersectionNodeData) Process() (modeling.Mesh, error) {
+	if ind.A == nil || ind.B == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	return IntersectionTransformer{
+		Attribute: nodes.TryGetOutputValue(ind.Attribute, modeling.PositionAttribute),
+		Other:     ind.B.Value(),
+	}.Transform(ind.A.Value())
+}
+
+// BSP ========================================================================
+
+type csgPlane struct {
+	normal vector3.Float64
+	w      float64
+}
+
+func (p csgPlane) flip() csgPlane {
+	return csgPlane{normal: p.normal.Scale(-1), w: -p.w}
+}
+
+type csgPolygon struct {
+	vertices []int
+	plane    csgPlane
+	material int
+
+	// Whether or not the polygon is facing the opposite direction of the
+	// vertex data it references
+	flipped bool
+}
+
+func (p csgPolygon) flip() csgPolygon {
+	vertices := make([]int, len(p.vertices))
+	for i, v := range p.vertices {
+		vertices[len(vertices)-1-i] = v
+	}
+	return csgPolygon{
+		vertices: vertices,
+		plane:    p.plane.flip(),
+		material: p.material,
+		flipped:  !p.flipped,
+	}
+}
+
+type csgNode struct {
+	plane    *csgPlane
+	front    *csgNode
+	back     *csgNode
+	polygons []csgPolygon
+}
+
+func (n *csgNode) invert() {
+	for i, p := range n.polygons {
+		n.polygons[i] = p.flip()
+	}
+
+	if n.plane != nil {
+		flipped := n.plane.flip()
+		n.plane = &flipped
+	}
+
+	if n.front != nil {
+		n.front.invert()
+	}
+
+	if n.back != nil {
+		n.back.invert()
+	}
+
+	n.front, n.back = n.back, n.front
+}
+
+// clipPolygons removes all polygons that are found within this BSP tree
+func (n *csgNode) clipPolygons(csg *csgContext, polygons []csgPolygon) []csgPolygon {
+	if n.plane == nil {
+		return append([]csgPolygon(nil), polygons...)
+	}
+
+	front := make([]csgPolygon, 0)
+	back := make([]csgPolygon, 0)
+	for _, p := range polygons {
+		csg.split(*n.plane, p, &front, &back, &front, &back)
+	}
+
+	if n.front != nil {
+		front = n.front.clipPolygons(csg, front)
+	}
+
+	if n.back != nil {
+		back = n.back.clipPolygons(csg, back)
+	} else {
+		back = nil
+	}
+
+	return append(front, back...)
+}
+
+// clipTo removes all polygons in this tree that are found within the other
+func (n *csgNode) clipTo(csg *csgContext, other *csgNode) {
+	n.polygons = other.clipPolygons(csg, n.polygons)
+
+	if n.front != nil {
+		n.front.clipTo(csg, other)
+	}
+
+	if n.back != nil {
+		n.back.clipTo(csg, other)
+	}
+}
+
+func (n *csgNode) allPolygons() []csgPolygon {
+	polygons := append([]csgPolygon(nil), n.polygons...)
+
+	if n.front != nil {
+		polygons = append(polygons, n.front.allPolygons()...)
+	}
+
+	if n.back != nil {
+		polygons = append(polygons, n.back.allPolygons()...)
+	}
+
+	return polygons
+}
+
+func (n *csgNode) build(csg *csgContext, polygons []csgPolygon) {
+	if len(polygons) == 0 {
+		return
+	}
+
+	if n.plane == nil {
+		plane := polygons[0].plane
+		n.plane = &plane
+	}
+
+	front := make([]csgPolygon, 0)
+	back := make([]csgPolygon, 0)
+	for _, p := range polygons {
+		csg.split(*n.plane, p, &n.polygons, &n.polygons, &front, &back)
+	}
+
+	if len(front) > 0 {
+		if n.front == nil {
+			n.front = &csgNode{}
+		}
+		n.front.build(csg, front)
+	}
+
+	if len(back) > 0 {
+		if n.back == nil {
+			n.back = &csgNode{}
+		}
+		n.back.build(csg, back)
+	}
+}
+
+// Vertex Data ================================================================
+
+type csgSplitKey struct {
+	a, b  int
+	plane csgPlane
+}
+
+type csgContext struct {
+	attribute string
+
+	v4 map[string][]vector4.Float64
+	v3 map[string][]vector3.Float64
+	v2 map[string][]vector2.Float64
+	v1 map[string][]float64
+
+	// Polygons of each mesh
+	a []csgPolygon
+	b []csgPolygon
+
+	// Every material referenced by a polygon. Meshes without materials
+	// contribute a single nil entry
+	materials    []*modeling.Material
+	hasMaterials bool
+
+	// Vertices created by splitting an edge against a plane, cached so
+	// neighboring polygons share the same vertex
+	splits map[csgSplitKey]int
+}
+
+func newCSG(a, b modeling.Mesh, attribute string) *csgContext {
+	csg := &csgContext{
+		attribute:    attribute,
+		v4:           make(map[string][]vector4.Float64),
+		v3:           make(map[string][]vector3.Float64),
+		v2:           make(map[string][]vector2.Float64),
+		v1:           make(map[string][]float64),
+		splits:       make(map[csgSplitKey]int),
+		hasMaterials: len(a.Materials()) > 0 || len(b.Materials()) > 0,
+	}
+
+	csg.a = csg.add(a)
+	csg.b = csg.add(b)
+	return csg
+}
+
+func (csg *csgContext) vertexCount() int {
+	return len(csg.v3[csg.attribute])
+}
+
+func (csg *csgContext) position(i int) vector3.Float64 {
+	return csg.v3[csg.attribute][i]
+}
+
+// add appends the mesh's vertex data, returning polygons for each of it's
+// triangles
+func (csg *csgContext) add(m modeling.Mesh) []csgPolygon {
+	offset := csg.vertexCount()
+	count := m.AttributeLength()
+
+	appendCSGData(csg.v4, readAllFloat4Data(m), offset, count)
+	appendCSGData(csg.v3, readAllFloat3Data(m), offset, count)
+	appendCSGData(csg.v2, readAllFloat2Data(m), offset, count)
+	appendCSGData(csg.v1, readAllFloat1Data(m), offset, count)
+
+	materialOffset := len(csg.materials)
+	materialEnds := make([]int, 0)
+	for _, mat := range m.Materials() {
+		csg.materials = append(csg.materials, mat.Material)
+		end := mat.PrimitiveCount
+		if len(materialEnds) > 0 {
+			end += materialEnds[len(materialEnds)-1]
+		}
+		materialEnds = append(materialEnds, end)
+	}
+	if len(materialEnds) == 0 {
+		csg.materials = append(csg.materials, nil)
+	}
+
+	polygons := make([]csgPolygon, 0, m.PrimitiveCount())
+	material := 0
+	indices := m.Indices()
+	for tri := 0; tri < m.PrimitiveCount(); tri++ {
+		for material < len(materialEnds)-1 && tri >= materialEnds[material] {
+			material++
+		}
+
+		vertices := []int{
+			indices.At(tri*3) + offset,
+			indices.At(tri*3+1) + offset,
+			indices.At(tri*3+2) + offset,
+		}
+
+		p0 := csg.position(vertices[0])
+		normal := csg.position(vertices[1]).Sub(p0).Cross(csg.position(vertices[2]).Sub(p0))
+		length := normal.Length()
+
+		// Degenerate triangles don't enclose any volume
+		if length == 0 || math.IsNaN(length) {
+			continue
+		}
+		normal = normal.DivByConstant(length)
+
+		polygons = append(polygons, csgPolygon{
+			vertices: vertices,
+			plane:    csgPlane{normal: normal, w: normal.Dot(p0)},
+			material: materialOffset + material,
+		})
+	}
+	return polygons
+}
+
+// appendCSGData appends the data to the destination, zero filling any
+// attribute that is only found in one of the two
+func appendCSGData[T any](dst, src map[string][]T, dstLen, srcLen int) {
+	for attr, data := range src {
+		if _, ok := dst[attr]; !ok {
+			dst[attr] = make([]T, dstLen)
+		}
+		dst[attr] = append(dst[attr], data...)
+	}
+
+	for attr, data := range dst {
+		if _, ok := src[attr]; !ok {
+			dst[attr] = append(data, make([]T, srcLen)...)
+		}
+	}
+}
+
+func (csg *csgContext) tree(polygons []csgPolygon) *csgNode {
+	node := &csgNode{}
+	node.build(csg, polygons)
+	return node
+}
+
+// interpolate creates a new vertex between vertex a and b, or returns one
+// previously created for the same edge and plane
+func (csg *csgContext) interpolate(a, b int, plane csgPlane) int {
+	// Always interpolate in the same direction so shared edges produce the
+	// exact same vertex
+	if a > b {
+		a, b = b, a
+	}
+
+	key := csgSplitKey{a: a, b: b, plane: plane}
+	if v, ok := csg.splits[key]; ok {
+		return v
+	}
+
+	pa := csg.position(a)
+	t := (plane.w - plane.normal.Dot(pa)) / plane.normal.Dot(csg.position(b).Sub(pa))
+	t = math.Max(0, math.Min(1, t))
+
+	for attr, data := range csg.v4 {
+		v := data[a].Add(data[b].Sub(data[a]).Scale(t))
+		if attr == modeling.JointAttribute {
+			v = data[a]
+			if t > 0.5 {
+				v = data[b]
+			}
+		}
+		csg.v4[attr] = append(data, v)
+	}
+
+	for attr, data := range csg.v3 {
+		v := data[a].Add(data[b].Sub(data[a]).Scale(t))
+		if attr == modeling.NormalAttribute {
+			v = v.Normalized()
+		}
+		csg.v3[attr] = append(data, v)
+	}
+
+	for attr, data := range csg.v2 {
+		csg.v2[attr] = append(data, data[a].Add(data[b].Sub(data[a]).Scale(t)))
+	}
+
+	for attr, data := range csg.v1 {
+		csg.v1[attr] = append(data, data[a]+(data[b]-data[a])*t)
+	}
+
+	v := csg.vertexCount() - 1
+	csg.splits[key] = v
+	return v
+}
+
+const (
+	csgCoplanar = 0
+	csgFront    = 1
+	csgBack     = 2
+	csgSpanning = 3
+)
+
+// split places the polygon, or the pieces of it after being cut by the plane,
+// into the appropriate list
+func (csg *csgContext) split(plane csgPlane, polygon csgPolygon, coplanarFront, coplanarBack, front, back *[]csgPolygon) {
+	polygonType := 0
+	types := make([]int, len(polygon.vertices))
+	for i, v := range polygon.vertices {
+		t := plane.normal.Dot(csg.position(v)) - plane.w
+		vertexType := csgCoplanar
+		if t < -csgEpsilon {
+			vertexType = csgBack
+		} else if t > csgEpsilon {
+			vertexType = csgFront
+		}
+		polygonType |= vertexType
+		types[i] = vertexType
+	}
+
+	switch polygonType {
+	case csgCoplanar:
+		if plane.normal.Dot(polygon.plane.normal) > 0 {
+			*coplanarFront = append(*coplanarFront, polygon)
+		} else {
+			*coplanarBack = append(*coplanarBack, polygon)
+		}
+
+	case csgFront:
+		*front = append(*front, polygon)
+
+	case csgBack:
+		*back = append(*back, polygon)
+
+	case csgSpanning:
+		f := make([]int, 0, len(polygon.vertices)+1)
+		b := make([]int, 0, len(polygon.vertices)+1)
+		for i, vi := range polygon.vertices {
+			j := (i + 1) % len(polygon.vertices)
+			ti := types[i]
+			tj := types[j]
+
+			if ti != csgBack {
+				f = append(f, vi)
+			}
+
+			if ti != csgFront {
+				b = append(b, vi)
+			}
+
+			if (ti | tj) == csgSpanning {
+				v := csg.interpolate(vi, polygon.vertices[j], plane)
+				f = append(f, v)
+				b = append(b, v)
+			}
+		}
+
+		if len(f) >= 3 {
+			*front = append(*front, csgPolygon{vertices: f, plane: polygon.plane, material: polygon.material, flipped: polygon.flipped})
+		}
+
+		if len(b) >= 3 {
+			*back = append(*back, csgPolygon{vertices: b, plane: polygon.plane, material: polygon.material, flipped: polygon.flipped})
+		}
+	}
+}
+
+// mesh triangulates the polygons, grouping them by material
+func (csg *csgContext) mesh(polygons []csgPolygon) modeling.Mesh {
+	normals, hasNormals := csg.v3[modeling.NormalAttribute]
+
+	// Flipped polygons need their own copy of each vertex with the normal
+	// pointing in the opposite direction
+	flippedVertices := make(map[int]int)
+	flippedVertex := func(v int) int {
+		if !hasNormals {
+			return v
+		}
+
+		if flipped, ok := flippedVertices[v]; ok {
+			return flipped
+		}
+
+		for attr, data := range csg.v4 {
+			csg.v4[attr] = append(data, data[v])
+		}
+		for attr, data := range csg.v3 {
+			csg.v3[attr] = append(data, data[v])
+		}
+		for attr, data := range csg.v2 {
+			csg.v2[attr] = append(data, data[v])
+		}
+		for attr, data := range csg.v1 {
+			csg.v1[attr] = append(data, data[v])
+		}
+
+		flipped := csg.vertexCount() - 1
+		normals = csg.v3[modeling.NormalAttribute]
+		normals[flipped] = normals[v].Scale(-1)
+		flippedVertices[v] = flipped
+		return flipped
+	}
+
+	triangles := make([][]int, len(csg.materials))
+	for _, p := range polygons {
+		vertices := p.vertices
+		if p.flipped {
+			vertices = make([]int, len(p.vertices))
+			for i, v := range p.vertices {
+				vertices[i] = flippedVertex(v)
+			}
+		}
+
+		for i := 2; i < len(vertices); i++ {
+			triangles[p.material] = append(triangles[p.material], vertices[0], vertices[i-1], vertices[i])
+		}
+	}
+
+	indices := make([]int, 0)
+	materials := make([]modeling.MeshMaterial, 0)
+	for i, tris := range triangles {
+		if len(tris) == 0 {
+			continue
+		}
+		indices = append(indices, tris...)
+		materials = append(materials, modeling.MeshMaterial{
+			PrimitiveCount: len(tris) / 3,
+			Material:       csg.materials[i],
+		})
+	}
+
+	mesh := modeling.NewTriangleMesh(indices).
+		SetFloat4Data(csg.v4).
+		SetFloat3Data(csg.v3).
+		SetFloat2Data(csg.v2).
+		SetFloat1Data(csg.v1)
+
+	if csg.hasMaterials {
+		mesh = mesh.SetMaterials(materials)
+	}
+
+	return RemovedUnreferencedVertices(mesh)
+}
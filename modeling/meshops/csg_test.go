@@ -0,0 +1,118 @@
+package meshops_test
+
+import (
+	"testing"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/modeling/primitives"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signedVolume calculates the volume enclosed by a closed triangle mesh
+func signedVolume(m modeling.Mesh) float64 {
+	volume := 0.
+	positions := m.Float3Attribute(modeling.PositionAttribute)
+	indices := m.Indices()
+	for i := 0; i < indices.Len(); i += 3 {
+		a := positions.At(indices.At(i))
+		b := positions.At(indices.At(i + 1))
+		c := positions.At(indices.At(i + 2))
+		volume += a.Dot(b.Cross(c)) / 6.
+	}
+	return volume
+}
+
+func TestCSG(t *testing.T) {
+	a := primitives.UnitCube()
+	b := primitives.UnitCube().Translate(vector3.New(0.5, 0.5, 0.5))
+
+	// Sanity check our winding so that volumes are positive
+	require.InDelta(t, 1., signedVolume(a), 0.000001)
+
+	tests := map[string]struct {
+		transformer modeling.Transformer
+		volume      float64
+	}{
+		"union": {
+			transformer: meshops.UnionTransformer{Other: b},
+			volume:      2 - 0.125,
+		},
+		"difference": {
+			transformer: meshops.DifferenceTransformer{Other: b},
+			volume:      1 - 0.125,
+		},
+		"intersection": {
+			transformer: meshops.IntersectionTransformer{Other: b},
+			volume:      0.125,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out, err := tc.transformer.Transform(a)
+			require.NoError(t, err)
+			assert.Equal(t, modeling.TriangleTopology, out.Topology())
+			assert.InDelta(t, tc.volume, signedVolume(out), 0.000001)
+
+			// Normals should be carried over, and agree with the winding of
+			// each triangle they belong to, even for faces cut from the inside
+			// of B
+			require.True(t, out.HasFloat3Attribute(modeling.NormalAttribute))
+			positions := out.Float3Attribute(modeling.PositionAttribute)
+			normals := out.Float3Attribute(modeling.NormalAttribute)
+			indices := out.Indices()
+			for i := 0; i < indices.Len(); i += 3 {
+				a := positions.At(indices.At(i))
+				b := positions.At(indices.At(i + 1))
+				c := positions.At(indices.At(i + 2))
+				faceNormal := b.Sub(a).Cross(c.Sub(a))
+				for v := 0; v < 3; v++ {
+					assert.Greater(t, faceNormal.Dot(normals.At(indices.At(i+v))), 0.)
+				}
+			}
+		})
+	}
+}
+
+func TestCSG_KeepsAttributesAndMaterials(t *testing.T) {
+	a := primitives.Cube{Width: 1, Height: 1, Depth: 1, UVs: primitives.DefaultCubeUVs()}.
+		Welded().
+		SetMaterial(modeling.Material{Name: "a"})
+
+	b := primitives.UnitCube().
+		Translate(vector3.New(0.5, 0., 0.)).
+		SetMaterial(modeling.Material{Name: "b"})
+
+	out := meshops.Union(a, b, modeling.PositionAttribute)
+	assert.InDelta(t, 1.5, signedVolume(out), 0.000001)
+
+	require.True(t, out.HasFloat2Attribute(modeling.TexCoordAttribute))
+	assert.Equal(t, out.AttributeLength(), out.Float2Attribute(modeling.TexCoordAttribute).Len())
+
+	materials := out.Materials()
+	require.Len(t, materials, 2)
+	assert.Equal(t, "a", materials[0].Material.Name)
+	assert.Equal(t, "b", materials[1].Material.Name)
+	assert.Equal(t, out.PrimitiveCount(), materials[0].PrimitiveCount+materials[1].PrimitiveCount)
+}
+
+func TestCSG_DisjointMeshes(t *testing.T) {
+	a := primitives.UnitCube()
+	b := primitives.UnitCube().Translate(vector3.New(5., 0., 0.))
+
+	assert.InDelta(t, 2., signedVolume(meshops.Union(a, b, modeling.PositionAttribute)), 0.000001)
+	assert.InDelta(t, 1., signedVolume(meshops.Difference(a, b, modeling.PositionAttribute)), 0.000001)
+	assert.Equal(t, 0, meshops.Intersection(a, b, modeling.PositionAttribute).PrimitiveCount())
+}
+
+func TestCSG_RequiresTriangles(t *testing.T) {
+	points := modeling.NewPointCloud(nil, map[string][]vector3.Float64{
+		modeling.PositionAttribute: {vector3.Zero[float64]()},
+	}, nil, nil, nil)
+
+	_, err := meshops.UnionTransformer{Other: points}.Transform(primitives.UnitCube())
+	assert.ErrorIs(t, err, meshops.ErrRequireTriangleTopology)
+}
@@ -0,0 +1,159 @@
+package gausops_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops/gausops"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cleanupSplat is a dense 5x5x5 grid of gaussians, a small line of 3
+// gaussians off to the side, and a single floater
+func cleanupSplat() modeling.Mesh {
+	positions := make([]vector3.Float64, 0)
+	for x := 0; x < 5; x++ {
+		for y := 0; y < 5; y++ {
+			for z := 0; z < 5; z++ {
+				positions = append(positions, vector3.New(float64(x), float64(y), float64(z)).Scale(0.1))
+			}
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		positions = append(positions, vector3.New(5+float64(i)*0.1, 0., 0.))
+	}
+	positions = append(positions, vector3.New(0., 10., 0.))
+
+	scales := make([]vector3.Float64, len(positions))
+	opacities := make([]float64, len(positions))
+	for i := range positions {
+		scales[i] = vector3.Fill(math.Log(0.01))
+		opacities[i] = float64(i)
+	}
+
+	return modeling.NewPointCloud(
+		nil,
+		map[string][]vector3.Float64{
+			modeling.PositionAttribute: positions,
+			modeling.ScaleAttribute:    scales,
+		},
+		nil,
+		map[string][]float64{
+			modeling.OpacityAttribute: opacities,
+		},
+		nil,
+	)
+}
+
+func TestRemoveOutliers(t *testing.T) {
+	m := gausops.RemoveOutliers(cleanupSplat(), 4, 1)
+
+	// Only the grid survives, with attributes still lined up
+	require.Equal(t, 125, m.PrimitiveCount())
+	opacity := m.Float1Attribute(modeling.OpacityAttribute)
+	positions := m.Float3Attribute(modeling.PositionAttribute)
+	for i := 0; i < 125; i++ {
+		assert.Equal(t, float64(i), opacity.At(i))
+	}
+	assert.Equal(t, vector3.New(0.4, 0.4, 0.4), positions.At(124))
+
+	// Not enough gaussians to have neighbors
+	assert.Equal(t, 129, gausops.RemoveOutliers(cleanupSplat(), 200, 1).PrimitiveCount())
+}
+
+func TestCluster(t *testing.T) {
+	labels, clusters := gausops.Cluster(cleanupSplat(), 0.15, 3)
+
+	assert.Equal(t, 2, clusters)
+	require.Len(t, labels, 129)
+	for i := 0; i < 125; i++ {
+		assert.Equal(t, labels[0], labels[i])
+	}
+
+	// The ends of the line aren't dense, but are reachable from the middle
+	assert.NotEqual(t, labels[0], labels[125])
+	assert.Equal(t, labels[125], labels[126])
+	assert.Equal(t, labels[125], labels[127])
+	assert.Equal(t, gausops.Noise, labels[128])
+
+	assert.Equal(t, 125, gausops.LargestCluster(cleanupSplat(), 0.15, 3).PrimitiveCount())
+
+	// Nothing is dense enough to be a cluster
+	labels, clusters = gausops.Cluster(cleanupSplat(), 0.05, 2)
+	assert.Equal(t, 0, clusters)
+	assert.Equal(t, gausops.Noise, labels[0])
+	assert.Equal(t, 0, gausops.LargestCluster(cleanupSplat(), 0.05, 2).PrimitiveCount())
+}
+
+func TestCullOpacity(t *testing.T) {
+	// Opacities are stored as logits: sigmoid(3) ≈ 0.95
+	m := gausops.CullOpacity(cleanupSplat(), 0.95)
+	require.Equal(t, 126, m.PrimitiveCount())
+	assert.Equal(t, 3., m.Float1Attribute(modeling.OpacityAttribute).At(0))
+}
+
+func TestCullProjectedSize(t *testing.T) {
+	splat := cleanupSplat()
+	scales := make([]vector3.Float64, splat.AttributeLength())
+	for i := range scales {
+		scales[i] = splat.Float3Attribute(modeling.ScaleAttribute).At(i)
+	}
+	scales[0] = vector3.Fill(math.Log(1.))
+	splat = splat.SetFloat3Attribute(modeling.ScaleAttribute, scales)
+
+	// With a 90 degree field of view, 3 standard deviations of a 0.01 scale
+	// gaussian at a distance of 10 spans 1.5 pixels of a 1000 pixel image
+	m := gausops.CullProjectedSize(splat, vector3.New(0., 0., 10.), 90, 1000, 1, 50)
+	assert.Equal(t, 128, m.PrimitiveCount())
+	assert.Equal(t, 1., m.Float1Attribute(modeling.OpacityAttribute).At(0))
+
+	m = gausops.CullProjectedSize(splat, vector3.New(0., 0., 10.), 90, 1000, 2, math.Inf(1))
+	assert.Equal(t, 1, m.PrimitiveCount())
+}
+
+func TestCleanupNodes(t *testing.T) {
+	outliers := &gausops.RemoveOutliersNode{
+		Data: gausops.RemoveOutliersNodeData{
+			Splat:              nodes.Value(cleanupSplat()),
+			Neighbors:          nodes.Value(4),
+			StandardDeviations: nodes.Value(1.),
+		},
+	}
+	assert.Equal(t, 125, outliers.Out().Value().PrimitiveCount())
+
+	cluster := &gausops.LargestClusterNode{
+		Data: gausops.LargestClusterNodeData{
+			Splat:  nodes.Value(cleanupSplat()),
+			Radius: nodes.Value(0.15),
+		},
+	}
+	assert.Equal(t, 125, cluster.Out().Value().PrimitiveCount())
+
+	opacity := &gausops.CullOpacityNode{
+		Data: gausops.CullOpacityNodeData{
+			Splat: nodes.Value(cleanupSplat()),
+		},
+	}
+	assert.Equal(t, 129, opacity.Out().Value().PrimitiveCount())
+
+	size := &gausops.CullProjectedSizeNode{
+		Data: gausops.CullProjectedSizeNodeData{
+			Splat:     nodes.Value(cleanupSplat()),
+			Viewpoint: nodes.Value(vector3.New(0., 0., 10.)),
+			MinPixels: nodes.Value(1.),
+		},
+	}
+	assert.Equal(t, 129, size.Out().Value().PrimitiveCount())
+
+	empty := &gausops.RemoveOutliersNode{
+		Data: gausops.RemoveOutliersNodeData{
+			Splat: nodes.Value(modeling.EmptyPointcloud()),
+		},
+	}
+	assert.Equal(t, 0, empty.Out().Value().PrimitiveCount())
+}
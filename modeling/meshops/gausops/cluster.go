@@ -0,0 +1,122 @@
+package gausops
+
+import (
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+// Cluster label given to gaussians that don't belong to any cluster
+const Noise = -1
+
+// Cluster groups the gaussians of the splat using DBSCAN. Gaussians with at
+// least minNeighbors gaussians (including themselves) within the radius are
+// dense, and every gaussian reachable through a chain of dense neighbors
+// belongs to the same cluster. The cluster each gaussian belongs to is
+// returned, or Noise, along with the number of clusters found.
+func Cluster(m modeling.Mesh, radius float64, minNeighbors int) ([]int, int) {
+	check(requirePositions(m))
+	if m.AttributeLength() == 0 {
+		return nil, 0
+	}
+
+	const unvisited = -2
+
+	tree, centers := positionTree(m)
+	labels := make([]int, len(centers))
+	for i := range labels {
+		labels[i] = unvisited
+	}
+
+	clusters := 0
+	for i, center := range centers {
+		if labels[i] != unvisited {
+			continue
+		}
+
+		neighbors := tree.ElementsWithinRange(center, radius)
+		if len(neighbors) < minNeighbors {
+			labels[i] = Noise
+			continue
+		}
+
+		cluster := clusters
+		clusters++
+		labels[i] = cluster
+
+		for len(neighbors) > 0 {
+			n := neighbors[len(neighbors)-1]
+			neighbors = neighbors[:len(neighbors)-1]
+
+			if labels[n] == Noise {
+				// Sits on the edge of the cluster, but isn't dense itself
+				labels[n] = cluster
+			}
+
+			if labels[n] != unvisited {
+				continue
+			}
+			labels[n] = cluster
+
+			reachable := tree.ElementsWithinRange(centers[n], radius)
+			if len(reachable) >= minNeighbors {
+				neighbors = append(neighbors, reachable...)
+			}
+		}
+	}
+
+	return labels, clusters
+}
+
+// LargestCluster keeps only the gaussians belonging to the largest cluster
+// found by Cluster, dropping disconnected debris surrounding the main
+// subject of the splat
+func LargestCluster(m modeling.Mesh, radius float64, minNeighbors int) modeling.Mesh {
+	labels, clusters := Cluster(m, radius, minNeighbors)
+
+	sizes := make([]int, clusters)
+	for _, label := range labels {
+		if label != Noise {
+			sizes[label]++
+		}
+	}
+
+	largest := Noise
+	for cluster, size := range sizes {
+		if largest == Noise || size > sizes[largest] {
+			largest = cluster
+		}
+	}
+
+	return keepGaussians(m, func(i int) bool {
+		return largest != Noise && labels[i] == largest
+	})
+}
+
+type LargestClusterNode = nodes.Struct[modeling.Mesh, LargestClusterNodeData]
+
+type LargestClusterNodeData struct {
+	Splat        nodes.NodeOutput[modeling.Mesh]
+	Radius       nodes.NodeOutput[float64]
+	MinNeighbors nodes.NodeOutput[int]
+}
+
+func (lcnd LargestClusterNodeData) Description() string {
+	return "Keeps only the largest cluster of densely packed gaussians, removing everything disconnected from it"
+}
+
+func (lcnd LargestClusterNodeData) Process() (modeling.Mesh, error) {
+	if lcnd.Splat == nil {
+		return modeling.EmptyPointcloud(), nil
+	}
+
+	m := lcnd.Splat.Value()
+	if lcnd.Radius == nil {
+		return m, nil
+	}
+
+	if err := requirePositions(m); err != nil {
+		return m, err
+	}
+
+	return LargestCluster(m, lcnd.Radius.Value(), nodes.TryGetOutputValue(lcnd.MinNeighbors, 8)), nil
+}
@@ -0,0 +1,122 @@
+package gausops
+
+import (
+	"math"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// CullOpacity removes gaussians more transparent than the minimum opacity
+// provided, ranging from 0 to 1. The splat's opacity is expected to be
+// stored before the sigmoid is applied, as 3D Gaussian Splatting's PLY files
+// do.
+func CullOpacity(m modeling.Mesh, minOpacity float64) modeling.Mesh {
+	if m.AttributeLength() == 0 {
+		return m
+	}
+	check(meshops.RequireV1Attribute(m, modeling.OpacityAttribute))
+
+	opacity := m.Float1Attribute(modeling.OpacityAttribute)
+	return keepGaussians(m, func(i int) bool {
+		return sigmoid(opacity.At(i)) >= minOpacity
+	})
+}
+
+// CullProjectedSize removes gaussians whose size on screen falls outside the
+// range provided, in pixels, when viewed from the viewpoint by a camera with
+// the vertical field of view (in degrees) and resolution given. The size of
+// a gaussian is taken to be three standard deviations along its largest
+// axis, the extent 3D Gaussian Splatting's renderer draws it out to, and the
+// gaussian is assumed to be in front of the camera regardless of where it's
+// facing.
+func CullProjectedSize(m modeling.Mesh, viewpoint vector3.Float64, fieldOfView float64, resolution int, minPixels, maxPixels float64) modeling.Mesh {
+	if m.AttributeLength() == 0 {
+		return m
+	}
+	check(meshops.RequireV3Attribute(m, modeling.PositionAttribute))
+	check(meshops.RequireV3Attribute(m, modeling.ScaleAttribute))
+
+	focal := float64(resolution) / (2 * math.Tan(fieldOfView*math.Pi/360))
+	positions := m.Float3Attribute(modeling.PositionAttribute)
+	scales := m.Float3Attribute(modeling.ScaleAttribute)
+
+	return keepGaussians(m, func(i int) bool {
+		extent := 3 * scales.At(i).Exp().MaxComponent()
+		size := focal * extent / positions.At(i).Distance(viewpoint)
+		return size >= minPixels && size <= maxPixels
+	})
+}
+
+type CullOpacityNode = nodes.Struct[modeling.Mesh, CullOpacityNodeData]
+
+type CullOpacityNodeData struct {
+	Splat      nodes.NodeOutput[modeling.Mesh]
+	MinOpacity nodes.NodeOutput[float64]
+}
+
+func (cond CullOpacityNodeData) Description() string {
+	return "Removes gaussians more transparent than the minimum opacity, ranging from 0 to 1"
+}
+
+func (cond CullOpacityNodeData) Process() (modeling.Mesh, error) {
+	if cond.Splat == nil {
+		return modeling.EmptyPointcloud(), nil
+	}
+
+	m := cond.Splat.Value()
+	if cond.MinOpacity == nil || m.AttributeLength() == 0 {
+		return m, nil
+	}
+
+	if err := meshops.RequireV1Attribute(m, modeling.OpacityAttribute); err != nil {
+		return m, err
+	}
+
+	return CullOpacity(m, cond.MinOpacity.Value()), nil
+}
+
+type CullProjectedSizeNode = nodes.Struct[modeling.Mesh, CullProjectedSizeNodeData]
+
+type CullProjectedSizeNodeData struct {
+	Splat       nodes.NodeOutput[modeling.Mesh]
+	Viewpoint   nodes.NodeOutput[vector3.Float64]
+	FieldOfView nodes.NodeOutput[float64]
+	Resolution  nodes.NodeOutput[int]
+	MinPixels   nodes.NodeOutput[float64]
+	MaxPixels   nodes.NodeOutput[float64]
+}
+
+func (cpsnd CullProjectedSizeNodeData) Description() string {
+	return "Removes gaussians that appear too small or too large on screen when viewed from the viewpoint"
+}
+
+func (cpsnd CullProjectedSizeNodeData) Process() (modeling.Mesh, error) {
+	if cpsnd.Splat == nil {
+		return modeling.EmptyPointcloud(), nil
+	}
+
+	m := cpsnd.Splat.Value()
+	if m.AttributeLength() == 0 {
+		return m, nil
+	}
+
+	if err := meshops.RequireV3Attribute(m, modeling.PositionAttribute); err != nil {
+		return m, err
+	}
+
+	if err := meshops.RequireV3Attribute(m, modeling.ScaleAttribute); err != nil {
+		return m, err
+	}
+
+	return CullProjectedSize(
+		m,
+		nodes.TryGetOutputValue(cpsnd.Viewpoint, vector3.Zero[float64]()),
+		nodes.TryGetOutputValue(cpsnd.FieldOfView, 60.),
+		nodes.TryGetOutputValue(cpsnd.Resolution, 1080),
+		nodes.TryGetOutputValue(cpsnd.MinPixels, 0.),
+		nodes.TryGetOutputValue(cpsnd.MaxPixels, math.Inf(1)),
+	), nil
+}
@@ -0,0 +1,76 @@
+package gausops
+
+import (
+	"math"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+// RemoveOutliers performs statistical outlier removal, getting rid of the
+// floaters left behind by training. The mean distance from each gaussian to
+// its nearest neighbors is computed, and any gaussian whose mean distance
+// lies more than the number of standard deviations provided above the
+// average across the entire splat is removed.
+func RemoveOutliers(m modeling.Mesh, neighbors int, standardDeviations float64) modeling.Mesh {
+	check(requirePositions(m))
+
+	count := m.AttributeLength()
+	if neighbors < 1 || count <= neighbors {
+		return m
+	}
+
+	tree, centers := positionTree(m)
+
+	meanDistances := make([]float64, count)
+	total := 0.
+	for i, center := range centers {
+		// The gaussian itself is always among the closest, at a distance of 0
+		sum := 0.
+		for _, neighbor := range tree.ClosestPoints(center, neighbors+1) {
+			sum += centers[neighbor].Distance(center)
+		}
+		meanDistances[i] = sum / float64(neighbors)
+		total += meanDistances[i]
+	}
+
+	mean := total / float64(count)
+	variance := 0.
+	for _, d := range meanDistances {
+		variance += (d - mean) * (d - mean)
+	}
+	threshold := mean + standardDeviations*math.Sqrt(variance/float64(count))
+
+	return keepGaussians(m, func(i int) bool {
+		return meanDistances[i] <= threshold
+	})
+}
+
+type RemoveOutliersNode = nodes.Struct[modeling.Mesh, RemoveOutliersNodeData]
+
+type RemoveOutliersNodeData struct {
+	Splat              nodes.NodeOutput[modeling.Mesh]
+	Neighbors          nodes.NodeOutput[int]
+	StandardDeviations nodes.NodeOutput[float64]
+}
+
+func (rond RemoveOutliersNodeData) Description() string {
+	return "Removes gaussians unusually far from their nearest neighbors"
+}
+
+func (rond RemoveOutliersNodeData) Process() (modeling.Mesh, error) {
+	if rond.Splat == nil {
+		return modeling.EmptyPointcloud(), nil
+	}
+
+	m := rond.Splat.Value()
+	if err := requirePositions(m); err != nil {
+		return m, err
+	}
+
+	return RemoveOutliers(
+		m,
+		nodes.TryGetOutputValue(rond.Neighbors, 16),
+		nodes.TryGetOutputValue(rond.StandardDeviations, 2.),
+	), nil
+}
@@ -0,0 +1,315 @@
+package gausops
+
+import (
+	"math"
+
+	"github.com/EliCDavis/iter"
+	"github.com/EliCDavis/polyform/formats/splat"
+	"github.com/EliCDavis/polyform/math/quaternion"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// Spherical harmonic basis constants, matching 3D Gaussian Splatting's
+// renderer
+// https://github.com/graphdeco-inria/gaussian-splatting/blob/main/utils/sh_utils.py
+const shC1 = 0.4886025119029199
+
+var shC2 = [5]float64{
+	1.0925484305920792,
+	-1.0925484305920792,
+	0.31539156525252005,
+	-1.0925484305920792,
+	0.5462742152960396,
+}
+
+var shC3 = [7]float64{
+	-0.5900435899266435,
+	2.890611442640554,
+	-0.4570457994644658,
+	0.3731763325901154,
+	-0.4570457994644658,
+	1.445305721320277,
+	-0.5900435899266435,
+}
+
+// shBasis evaluates each basis function past the DC term for the direction
+func shBasis(dir vector3.Float64, degree int) []float64 {
+	basis := make([]float64, modeling.SHCoefficients(degree))
+	if degree < 1 {
+		return basis
+	}
+
+	x, y, z := dir.X(), dir.Y(), dir.Z()
+	basis[0] = -shC1 * y
+	basis[1] = shC1 * z
+	basis[2] = -shC1 * x
+	if degree < 2 {
+		return basis
+	}
+
+	xx, yy, zz := x*x, y*y, z*z
+	xy, yz, xz := x*y, y*z, x*z
+	basis[3] = shC2[0] * xy
+	basis[4] = shC2[1] * yz
+	basis[5] = shC2[2] * (2*zz - xx - yy)
+	basis[6] = shC2[3] * xz
+	basis[7] = shC2[4] * (xx - yy)
+	if degree < 3 {
+		return basis
+	}
+
+	basis[8] = shC3[0] * y * (3*xx - yy)
+	basis[9] = shC3[1] * xy * z
+	basis[10] = shC3[2] * y * (4*zz - xx - yy)
+	basis[11] = shC3[3] * z * (2*zz - 3*xx - 3*yy)
+	basis[12] = shC3[4] * x * (4*zz - xx - yy)
+	basis[13] = shC3[5] * z * (xx - yy)
+	basis[14] = shC3[6] * x * (xx - 3*yy)
+	return basis
+}
+
+// EvaluateSH computes the color of a gaussian seen along the direction
+// provided, pointing from the viewer towards the gaussian. Coefficients past
+// the DC term are used up to the highest degree they fully cover.
+func EvaluateSH(fdc vector3.Float64, coefficients []vector3.Float64, dir vector3.Float64) vector3.Float64 {
+	degree := modeling.SHDegreeForCoefficients(len(coefficients))
+	basis := shBasis(dir.Normalized(), degree)
+
+	color := fdc.Scale(splat.SH_C0)
+	for i, b := range basis {
+		color = color.Add(coefficients[i].Scale(b))
+	}
+	return color.Add(vector3.Fill(0.5)).Clamp(0, 1)
+}
+
+// shCoefficients is the data of each spherical harmonic coefficient the mesh
+// has, up to the highest degree it fully covers
+func shCoefficients(m modeling.Mesh) []*iter.ArrayIterator[vector3.Float64] {
+	coefficients := make([]*iter.ArrayIterator[vector3.Float64], modeling.SHCoefficients(modeling.SHDegree(m)))
+	for i := range coefficients {
+		coefficients[i] = m.Float3Attribute(modeling.SHAttribute(i))
+	}
+	return coefficients
+}
+
+// EvaluateSHColor sets the color attribute of the splat to the color of each
+// gaussian seen along the direction provided
+func EvaluateSHColor(m modeling.Mesh, dir vector3.Float64) modeling.Mesh {
+	check(meshops.RequireV3Attribute(m, modeling.FDCAttribute))
+
+	sh := shCoefficients(m)
+	fdc := m.Float3Attribute(modeling.FDCAttribute)
+
+	colors := make([]vector3.Float64, fdc.Len())
+	coefficients := make([]vector3.Float64, len(sh))
+	for i := 0; i < len(colors); i++ {
+		for c, data := range sh {
+			coefficients[c] = data.At(i)
+		}
+		colors[i] = EvaluateSH(fdc.At(i), coefficients, dir)
+	}
+
+	return m.SetFloat3Attribute(modeling.ColorAttribute, colors)
+}
+
+// TruncateSH drops every spherical harmonic coefficient past the degree
+// provided
+func TruncateSH(m modeling.Mesh, degree int) modeling.Mesh {
+	keep := modeling.SHCoefficients(max(0, min(degree, modeling.MaxSHDegree)))
+	for i := keep; i < modeling.SHCoefficients(modeling.MaxSHDegree); i++ {
+		if m.HasFloat3Attribute(modeling.SHAttribute(i)) {
+			m = m.SetFloat3Attribute(modeling.SHAttribute(i), nil)
+		}
+	}
+	return m
+}
+
+// shRotation builds the matrix rotating the coefficients of a single band.
+// Rather than building the Wigner D-matrix analytically, the band is
+// evaluated at a set of directions both as-is and rotated, and the matrix
+// mapping one to the other is solved for, which is exact since rotations
+// never move a function out of its band.
+func shRotation(band int, inverse quaternion.Quaternion) [][]float64 {
+	start := modeling.SHCoefficients(band - 1)
+	size := 2*band + 1
+
+	// Directions spread across the sphere, more than enough to pin down
+	// even the largest band
+	const samples = 32
+	goldenAngle := math.Pi * (3 - math.Sqrt(5))
+	a := make([][]float64, samples)
+	b := make([][]float64, samples)
+	for i := 0; i < samples; i++ {
+		y := 1 - (2*(float64(i)+0.5))/samples
+		r := math.Sqrt(1 - y*y)
+		theta := goldenAngle * float64(i)
+		dir := vector3.New(r*math.Cos(theta), y, r*math.Sin(theta))
+
+		a[i] = shBasis(dir, band)[start:]
+		b[i] = shBasis(inverse.Rotate(dir), band)[start:]
+	}
+
+	// Least squares: (AᵀA) D = AᵀB
+	ata := make([][]float64, size)
+	atb := make([][]float64, size)
+	for r := 0; r < size; r++ {
+		ata[r] = make([]float64, size)
+		atb[r] = make([]float64, size)
+		for c := 0; c < size; c++ {
+			for s := 0; s < samples; s++ {
+				ata[r][c] += a[s][r] * a[s][c]
+				atb[r][c] += a[s][r] * b[s][c]
+			}
+		}
+	}
+	return solve(ata, atb)
+}
+
+// solve performs gaussian elimination with partial pivoting, returning X
+// such that AX = B
+func solve(a, b [][]float64) [][]float64 {
+	n := len(a)
+	for col := 0; col < n; col++ {
+		pivot := col
+		for r := col + 1; r < n; r++ {
+			if math.Abs(a[r][col]) > math.Abs(a[pivot][col]) {
+				pivot = r
+			}
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		b[col], b[pivot] = b[pivot], b[col]
+
+		for r := 0; r < n; r++ {
+			if r == col {
+				continue
+			}
+			f := a[r][col] / a[col][col]
+			for c := 0; c < n; c++ {
+				a[r][c] -= f * a[col][c]
+				b[r][c] -= f * b[col][c]
+			}
+		}
+	}
+
+	for r := 0; r < n; r++ {
+		for c := range b[r] {
+			b[r][c] /= a[r][r]
+		}
+	}
+	return b
+}
+
+// RotateSH rotates the spherical harmonic coefficients of each gaussian, so
+// the view dependent color follows the gaussian when it's rotated
+func RotateSH(m modeling.Mesh, amount quaternion.Quaternion) modeling.Mesh {
+	degree := modeling.SHDegree(m)
+	if degree == 0 {
+		return m
+	}
+
+	q := amount.Normalize()
+	inverse := quaternion.New(q.Dir().Scale(-1), q.W())
+
+	original := shCoefficients(m)
+	for band := 1; band <= degree; band++ {
+		rotation := shRotation(band, inverse)
+		start := modeling.SHCoefficients(band - 1)
+
+		for out := range rotation {
+			rotated := make([]vector3.Float64, original[start].Len())
+			for v := range rotated {
+				sum := vector3.Zero[float64]()
+				for in, weight := range rotation[out] {
+					sum = sum.Add(original[start+in].At(v).Scale(weight))
+				}
+				rotated[v] = sum
+			}
+			m = m.SetFloat3Attribute(modeling.SHAttribute(start+out), rotated)
+		}
+	}
+	return m
+}
+
+// Rotate rotates the entire splat about the origin, taking the position,
+// orientation and spherical harmonics of each gaussian along with it
+func Rotate(m modeling.Mesh, amount quaternion.Quaternion) modeling.Mesh {
+	if m.HasFloat3Attribute(modeling.PositionAttribute) {
+		m = meshops.RotateAttribute3D(m, modeling.PositionAttribute, amount.Normalize())
+	}
+
+	if m.HasFloat4Attribute(modeling.RotationAttribute) {
+		m = RotateAttribute(m, modeling.RotationAttribute, amount)
+	}
+
+	return RotateSH(m, amount)
+}
+
+type RotateNode = nodes.Struct[modeling.Mesh, RotateNodeData]
+
+type RotateNodeData struct {
+	Splat  nodes.NodeOutput[modeling.Mesh]
+	Amount nodes.NodeOutput[quaternion.Quaternion]
+}
+
+func (rnd RotateNodeData) Description() string {
+	return "Rotates the splat about the origin, including the orientation and spherical harmonics of each gaussian"
+}
+
+func (rnd RotateNodeData) Process() (modeling.Mesh, error) {
+	if rnd.Splat == nil {
+		return modeling.EmptyPointcloud(), nil
+	}
+
+	if rnd.Amount == nil {
+		return rnd.Splat.Value(), nil
+	}
+
+	return Rotate(rnd.Splat.Value(), rnd.Amount.Value()), nil
+}
+
+type TruncateSHNode = nodes.Struct[modeling.Mesh, TruncateSHNodeData]
+
+type TruncateSHNodeData struct {
+	Splat  nodes.NodeOutput[modeling.Mesh]
+	Degree nodes.NodeOutput[int]
+}
+
+func (tnd TruncateSHNodeData) Description() string {
+	return "Drops the spherical harmonic coefficients past the degree provided"
+}
+
+func (tnd TruncateSHNodeData) Process() (modeling.Mesh, error) {
+	if tnd.Splat == nil {
+		return modeling.EmptyPointcloud(), nil
+	}
+
+	return TruncateSH(tnd.Splat.Value(), nodes.TryGetOutputValue(tnd.Degree, 0)), nil
+}
+
+type EvaluateSHNode = nodes.Struct[modeling.Mesh, EvaluateSHNodeData]
+
+type EvaluateSHNodeData struct {
+	Splat     nodes.NodeOutput[modeling.Mesh]
+	Direction nodes.NodeOutput[vector3.Float64]
+}
+
+func (end EvaluateSHNodeData) Description() string {
+	return "Sets the color of each gaussian to its color seen along the direction provided"
+}
+
+func (end EvaluateSHNodeData) Process() (modeling.Mesh, error) {
+	if end.Splat == nil {
+		return modeling.EmptyPointcloud(), nil
+	}
+
+	m := end.Splat.Value()
+	if err := meshops.RequireV3Attribute(m, modeling.FDCAttribute); err != nil {
+		return m, err
+	}
+
+	dir := nodes.TryGetOutputValue(end.Direction, vector3.Forward[float64]())
+	return EvaluateSHColor(m, dir), nil
+}
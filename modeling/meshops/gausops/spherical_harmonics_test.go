@@ -0,0 +1,143 @@
+package gausops_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/polyform/formats/splat"
+	"github.com/EliCDavis/polyform/math/quaternion"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops/gausops"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+	"github.com/stretchr/testify/assert"
+)
+
+func shSplat(degree int) modeling.Mesh {
+	v3Data := map[string][]vector3.Float64{
+		modeling.PositionAttribute: {vector3.New(1., 0., 0.)},
+		modeling.FDCAttribute:      {vector3.New(0.1, -0.2, 0.3)},
+	}
+	for i := 0; i < modeling.SHCoefficients(degree); i++ {
+		// Arbitrary, but small enough to keep colors from clamping
+		v := math.Sin(float64(i+1)) * 0.1
+		v3Data[modeling.SHAttribute(i)] = []vector3.Float64{vector3.New(v, -v, v*0.5)}
+	}
+
+	return modeling.NewPointCloud(
+		map[string][]vector4.Float64{
+			modeling.RotationAttribute: {vector4.New(1., 0., 0., 0.)},
+		},
+		v3Data,
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func coefficients(m modeling.Mesh) []vector3.Float64 {
+	c := make([]vector3.Float64, modeling.SHCoefficients(modeling.SHDegree(m)))
+	for i := range c {
+		c[i] = m.Float3Attribute(modeling.SHAttribute(i)).At(0)
+	}
+	return c
+}
+
+func assertVector3InDelta(t *testing.T, expected, actual vector3.Float64, delta float64) {
+	t.Helper()
+	assert.InDelta(t, expected.X(), actual.X(), delta)
+	assert.InDelta(t, expected.Y(), actual.Y(), delta)
+	assert.InDelta(t, expected.Z(), actual.Z(), delta)
+}
+
+func TestEvaluateSH_DCOnly(t *testing.T) {
+	fdc := vector3.New(0.1, -0.2, 0.3)
+	color := gausops.EvaluateSH(fdc, nil, vector3.Forward[float64]())
+	assertVector3InDelta(t, fdc.Scale(splat.SH_C0).Add(vector3.Fill(0.5)), color, 1e-9)
+}
+
+func TestEvaluateSH_ViewDependent(t *testing.T) {
+	m := shSplat(3)
+	fdc := m.Float3Attribute(modeling.FDCAttribute).At(0)
+	c := coefficients(m)
+
+	forward := gausops.EvaluateSH(fdc, c, vector3.Forward[float64]())
+	up := gausops.EvaluateSH(fdc, c, vector3.Up[float64]())
+	assert.NotEqual(t, forward, up)
+
+	// Only the coefficients up to the highest degree fully covered count
+	assert.Equal(t, gausops.EvaluateSH(fdc, c[:3], up), gausops.EvaluateSH(fdc, c[:7], up))
+}
+
+func TestRotateSH(t *testing.T) {
+	rotations := []quaternion.Quaternion{
+		quaternion.FromTheta(math.Pi/2, vector3.Up[float64]()),
+		quaternion.FromTheta(1.3, vector3.New(1., 2., -0.5).Normalized()),
+		quaternion.FromEulerAngles(vector3.New(0.3, -2.1, 0.8)),
+	}
+
+	directions := []vector3.Float64{
+		vector3.Forward[float64](),
+		vector3.Up[float64](),
+		vector3.New(0.3, -0.4, 0.8).Normalized(),
+	}
+
+	for degree := 1; degree <= modeling.MaxSHDegree; degree++ {
+		m := shSplat(degree)
+		fdc := m.Float3Attribute(modeling.FDCAttribute).At(0)
+
+		for _, q := range rotations {
+			rotated := gausops.RotateSH(m, q)
+			assert.Equal(t, degree, modeling.SHDegree(rotated))
+
+			// Viewing the rotated splat from a rotated direction looks the
+			// same as viewing the original from the original direction
+			for _, dir := range directions {
+				assertVector3InDelta(
+					t,
+					gausops.EvaluateSH(fdc, coefficients(m), dir),
+					gausops.EvaluateSH(fdc, coefficients(rotated), q.Rotate(dir)),
+					1e-9,
+				)
+			}
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	q := quaternion.FromTheta(math.Pi/2, vector3.Up[float64]())
+	m := shSplat(2)
+	rotated := gausops.Rotate(m, q)
+
+	assertVector3InDelta(t, q.Rotate(vector3.New(1., 0., 0.)), rotated.Float3Attribute(modeling.PositionAttribute).At(0), 1e-9)
+
+	rot := rotated.Float4Attribute(modeling.RotationAttribute).At(0)
+	assert.InDelta(t, q.W(), rot.X(), 1e-9)
+	assert.InDelta(t, q.Dir().Y(), rot.Z(), 1e-9)
+
+	assert.Equal(t, coefficients(gausops.RotateSH(m, q)), coefficients(rotated))
+}
+
+func TestTruncateSH(t *testing.T) {
+	m := shSplat(3)
+
+	assert.Equal(t, 1, modeling.SHDegree(gausops.TruncateSH(m, 1)))
+	assert.False(t, gausops.TruncateSH(m, 1).HasFloat3Attribute(modeling.SHAttribute(3)))
+	assert.Equal(t, 0, modeling.SHDegree(gausops.TruncateSH(m, 0)))
+	assert.Equal(t, 3, modeling.SHDegree(gausops.TruncateSH(m, 5)))
+
+	// Everything else is left alone
+	assert.True(t, gausops.TruncateSH(m, 0).HasFloat3Attribute(modeling.FDCAttribute))
+}
+
+func TestEvaluateSHColor(t *testing.T) {
+	m := gausops.EvaluateSHColor(shSplat(1), vector3.Up[float64]())
+	assert.True(t, m.HasFloat3Attribute(modeling.ColorAttribute))
+
+	expected := gausops.EvaluateSH(
+		m.Float3Attribute(modeling.FDCAttribute).At(0),
+		coefficients(m),
+		vector3.Up[float64](),
+	)
+	assert.Equal(t, expected, m.Float3Attribute(modeling.ColorAttribute).At(0))
+}
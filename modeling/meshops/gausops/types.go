@@ -10,6 +10,13 @@ func init() {
 
 	refutil.RegisterType[ColorGradingLutNode](factory)
 	refutil.RegisterType[ScaleNode](factory)
+	refutil.RegisterType[RotateNode](factory)
+	refutil.RegisterType[TruncateSHNode](factory)
+	refutil.RegisterType[EvaluateSHNode](factory)
+	refutil.RegisterType[RemoveOutliersNode](factory)
+	refutil.RegisterType[LargestClusterNode](factory)
+	refutil.RegisterType[CullOpacityNode](factory)
+	refutil.RegisterType[CullProjectedSizeNode](factory)
 
 	generator.RegisterTypes(factory)
 }
@@ -1,6 +1,15 @@
 package gausops
 
-import "strings"
+import (
+	"math"
+	"strings"
+
+	"github.com/EliCDavis/polyform/math/geometry"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/trees"
+	"github.com/EliCDavis/vector/vector3"
+)
 
 func getAttribute(attr string, fallback string) string {
 	if strings.TrimSpace(attr) == "" {
@@ -14,3 +23,49 @@ func check(err error) {
 		panic(err)
 	}
 }
+
+// sigmoid converts opacity as it's stored by 3D Gaussian Splatting into the
+// 0 to 1 range
+func sigmoid(x float64) float64 {
+	return 1 / (1 + math.Exp(-x))
+}
+
+// keepGaussians removes every gaussian the filter rejects
+func keepGaussians(m modeling.Mesh, keep func(i int) bool) modeling.Mesh {
+	count := m.AttributeLength()
+	kept := make([]int, 0, count)
+	for i := 0; i < count; i++ {
+		if keep(i) {
+			kept = append(kept, i)
+		}
+	}
+
+	if len(kept) == count && m.PrimitiveCount() == count {
+		return m
+	}
+
+	return meshops.RemovedUnreferencedVertices(m.SetIndices(kept))
+}
+
+// positionTree spatially organizes the center of each gaussian, with element
+// indices matching the splat's attribute data
+func positionTree(m modeling.Mesh) (*trees.OctTree, []vector3.Float64) {
+	positions := m.Float3Attribute(modeling.PositionAttribute)
+
+	centers := make([]vector3.Float64, positions.Len())
+	elements := make([]trees.Element, positions.Len())
+	for i := range elements {
+		centers[i] = positions.At(i)
+		elements[i] = trees.BoundingBoxElement(geometry.NewAABB(centers[i], vector3.Zero[float64]()))
+	}
+	return trees.NewOctree(elements), centers
+}
+
+// requirePositions validates the splat has positions for its gaussians. Empty
+// splats have nothing to position, so they always pass
+func requirePositions(m modeling.Mesh) error {
+	if m.AttributeLength() == 0 {
+		return nil
+	}
+	return meshops.RequireV3Attribute(m, modeling.PositionAttribute)
+}
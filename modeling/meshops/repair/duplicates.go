@@ -0,0 +1,102 @@
+package repair
+
+import (
+	"sort"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+// duplicateFaces finds every face that occupies the same three positions as
+// a face that came before it, regardless of winding
+func duplicateFaces(m modeling.Mesh, attribute string) []int {
+	ids := weldedIDs(m, attribute)
+	indices := m.Indices()
+
+	seen := make(map[[3]int]struct{}, m.PrimitiveCount())
+	duplicates := make([]int, 0)
+	for face := 0; face < m.PrimitiveCount(); face++ {
+		key := [3]int{
+			ids[indices.At(face*3)],
+			ids[indices.At(face*3+1)],
+			ids[indices.At(face*3+2)],
+		}
+		sort.Ints(key[:])
+
+		if _, ok := seen[key]; ok {
+			duplicates = append(duplicates, face)
+			continue
+		}
+		seen[key] = struct{}{}
+	}
+	return duplicates
+}
+
+type RemoveDuplicateFacesTransformer struct {
+	Attribute string
+}
+
+func (rdft RemoveDuplicateFacesTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	attribute := getAttribute(rdft.Attribute, modeling.PositionAttribute)
+
+	if err = meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return
+	}
+
+	if err = meshops.RequireV3Attribute(m, attribute); err != nil {
+		return
+	}
+
+	return RemoveDuplicateFaces(m, attribute), nil
+}
+
+// RemoveDuplicateFaces removes every face that occupies the same positions as
+// a face that came before it, keeping the first occurrence. Faces are
+// considered duplicates regardless of their winding or whether or not they
+// share vertices.
+func RemoveDuplicateFaces(m modeling.Mesh, attribute string) modeling.Mesh {
+	if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		panic(err)
+	}
+
+	if err := meshops.RequireV3Attribute(m, attribute); err != nil {
+		panic(err)
+	}
+
+	duplicates := duplicateFaces(m, attribute)
+	if len(duplicates) == 0 {
+		return m
+	}
+
+	keep := make([]bool, m.PrimitiveCount())
+	for i := range keep {
+		keep[i] = true
+	}
+	for _, face := range duplicates {
+		keep[face] = false
+	}
+
+	return keepFaces(m, keep)
+}
+
+type RemoveDuplicateFacesNode = nodes.Struct[modeling.Mesh, RemoveDuplicateFacesNodeData]
+
+type RemoveDuplicateFacesNodeData struct {
+	Mesh      nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+}
+
+func (RemoveDuplicateFacesNodeData) Description() string {
+	return "Removes faces that occupy the same positions as another face"
+}
+
+func (rdfnd RemoveDuplicateFacesNodeData) Process() (modeling.Mesh, error) {
+	if rdfnd.Mesh == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	return RemoveDuplicateFacesTransformer{
+		Attribute: nodes.TryGetOutputValue(rdfnd.Attribute, modeling.PositionAttribute),
+	}.Transform(rdfnd.Mesh.Value())
+}
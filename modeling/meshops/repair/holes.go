@@ -0,0 +1,93 @@
+package repair
+
+import (
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+type FillHolesTransformer struct {
+	// Holes bordered by more edges than this are left open. Values less
+	// than 3 fill every hole found.
+	MaxEdges int
+}
+
+func (fht FillHolesTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	if err = meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return
+	}
+
+	return FillHoles(m, fht.MaxEdges), nil
+}
+
+// FillHoles closes each loop of boundary edges within the mesh. Triangular
+// holes are closed with a single face, while larger holes are closed with a
+// fan of faces around a new vertex placed at the average of the loop. New
+// faces take on the material of the faces bordering them.
+func FillHoles(m modeling.Mesh, maxEdges int) modeling.Mesh {
+	if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		panic(err)
+	}
+
+	hem := m.HalfEdges()
+	loops := boundaryLoops(hem)
+	if len(loops) == 0 {
+		return m
+	}
+
+	// Track the face bordering each boundary edge so we can borrow it's
+	// material
+	borderingFace := make(map[modeling.Edge]int)
+	for he := 0; he < hem.HalfEdgeCount(); he++ {
+		if hem.IsBoundary(he) {
+			borderingFace[hem.Edge(he)] = hem.HalfEdge(he).Face
+		}
+	}
+
+	md := newMeshData(m)
+	for _, loop := range loops {
+		if len(loop) < 3 || (maxEdges >= 3 && len(loop) > maxEdges) {
+			continue
+		}
+
+		material := md.materials[borderingFace[modeling.Edge{A: loop[0], B: loop[1]}]]
+
+		// Boundary edges follow the winding of the faces they belong to, so
+		// the faces filling the hole need to run the other way
+		if len(loop) == 3 {
+			md.indices = append(md.indices, loop[0], loop[2], loop[1])
+			md.materials = append(md.materials, material)
+			continue
+		}
+
+		center := md.average(loop)
+		for i, a := range loop {
+			b := loop[(i+1)%len(loop)]
+			md.indices = append(md.indices, b, a, center)
+			md.materials = append(md.materials, material)
+		}
+	}
+
+	return md.mesh()
+}
+
+type FillHolesNode = nodes.Struct[modeling.Mesh, FillHolesNodeData]
+
+type FillHolesNodeData struct {
+	Mesh     nodes.NodeOutput[modeling.Mesh]
+	MaxEdges nodes.NodeOutput[int]
+}
+
+func (FillHolesNodeData) Description() string {
+	return "Closes holes in the mesh bordered by no more than MaxEdges edges, filling all holes when MaxEdges is less than 3"
+}
+
+func (fhnd FillHolesNodeData) Process() (modeling.Mesh, error) {
+	if fhnd.Mesh == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	return FillHolesTransformer{
+		MaxEdges: nodes.TryGetOutputValue(fhnd.MaxEdges, 0),
+	}.Transform(fhnd.Mesh.Value())
+}
@@ -0,0 +1,128 @@
+package repair
+
+import (
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+// vertexFans groups the half edges leaving the vertex by the fan of faces
+// they belong to, where faces within a fan are connected to one another
+// across manifold edges
+func vertexFans(hem *modeling.HalfEdgeMesh, v int) [][]int {
+	outgoing := hem.OutgoingHalfEdges(v)
+	if len(outgoing) < 2 {
+		return [][]int{outgoing}
+	}
+
+	lut := make(map[int]int, len(outgoing))
+	parent := make([]int, len(outgoing))
+	for i, he := range outgoing {
+		lut[he] = i
+		parent[i] = i
+	}
+
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+
+	for i, he := range outgoing {
+		// The previous half edge ends at v, so it's twin leaves v from the
+		// neighboring face of the fan
+		twin := hem.HalfEdge(hem.HalfEdge(he).Prev).Twin
+		if twin == -1 {
+			continue
+		}
+
+		a, b := find(i), find(lut[twin])
+		if a != b {
+			parent[b] = a
+		}
+	}
+
+	fanLUT := make(map[int]int)
+	fans := make([][]int, 0)
+	for i, he := range outgoing {
+		root := find(i)
+		fan, ok := fanLUT[root]
+		if !ok {
+			fan = len(fans)
+			fanLUT[root] = fan
+			fans = append(fans, make([]int, 0))
+		}
+		fans[fan] = append(fans[fan], he)
+	}
+	return fans
+}
+
+func nonManifoldVertices(hem *modeling.HalfEdgeMesh) []int {
+	vertices := make([]int, 0)
+	for v := 0; v < hem.VertexCount(); v++ {
+		if len(vertexFans(hem, v)) > 1 {
+			vertices = append(vertices, v)
+		}
+	}
+	return vertices
+}
+
+type SplitNonManifoldVerticesTransformer struct{}
+
+func (SplitNonManifoldVerticesTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	if err = meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return
+	}
+
+	return SplitNonManifoldVertices(m), nil
+}
+
+// SplitNonManifoldVertices gives each fan of faces meeting at a non-manifold
+// vertex it's own copy of the vertex, like where the tips of two cones touch.
+// Faces with inconsistent winding are treated as separate fans, so winding
+// should be unified before splitting.
+func SplitNonManifoldVertices(m modeling.Mesh) modeling.Mesh {
+	if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		panic(err)
+	}
+
+	hem := m.HalfEdges()
+	vertices := nonManifoldVertices(hem)
+	if len(vertices) == 0 {
+		return m
+	}
+
+	md := newMeshData(m)
+	for _, v := range vertices {
+		fans := vertexFans(hem, v)
+
+		// The first fan keeps the original vertex
+		for _, fan := range fans[1:] {
+			split := md.duplicate(v)
+			for _, he := range fan {
+				md.indices[he] = split
+			}
+		}
+	}
+
+	return md.mesh()
+}
+
+type SplitNonManifoldVerticesNode = nodes.Struct[modeling.Mesh, SplitNonManifoldVerticesNodeData]
+
+type SplitNonManifoldVerticesNodeData struct {
+	Mesh nodes.NodeOutput[modeling.Mesh]
+}
+
+func (SplitNonManifoldVerticesNodeData) Description() string {
+	return "Duplicates vertices shared by otherwise disconnected fans of faces so each fan has it's own"
+}
+
+func (snmvnd SplitNonManifoldVerticesNodeData) Process() (modeling.Mesh, error) {
+	if snmvnd.Mesh == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+	return SplitNonManifoldVerticesTransformer{}.Transform(snmvnd.Mesh.Value())
+}
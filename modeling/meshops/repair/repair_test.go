@@ -0,0 +1,259 @@
+package repair_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/EliCDavis/iter"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops/repair"
+	"github.com/EliCDavis/polyform/modeling/primitives"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signedVolume(m modeling.Mesh) float64 {
+	volume := 0.
+	positions := m.Float3Attribute(modeling.PositionAttribute)
+	indices := m.Indices()
+	for i := 0; i < indices.Len(); i += 3 {
+		a := positions.At(indices.At(i))
+		b := positions.At(indices.At(i + 1))
+		c := positions.At(indices.At(i + 2))
+		volume += a.Dot(b.Cross(c)) / 6.
+	}
+	return volume
+}
+
+func cube() modeling.Mesh {
+	return primitives.Cube{Width: 1, Height: 1, Depth: 1}.Welded()
+}
+
+// removeFaces builds a copy of the mesh without the faces provided
+func removeFaces(m modeling.Mesh, faces ...int) modeling.Mesh {
+	remove := make(map[int]struct{})
+	for _, f := range faces {
+		remove[f] = struct{}{}
+	}
+
+	indices := iter.ReadFull(m.Indices())
+	kept := make([]int, 0, len(indices))
+	for f := 0; f < len(indices)/3; f++ {
+		if _, ok := remove[f]; !ok {
+			kept = append(kept, indices[f*3:f*3+3]...)
+		}
+	}
+	return m.SetIndices(kept)
+}
+
+func TestInspect_Clean(t *testing.T) {
+	m := cube()
+	require.InDelta(t, 1., signedVolume(m), 0.000001)
+
+	report := repair.Inspect(m, modeling.PositionAttribute)
+	assert.True(t, report.Clean())
+	assert.Equal(t, 8, report.VertexCount)
+	assert.Equal(t, 12, report.FaceCount)
+}
+
+func TestFillHoles(t *testing.T) {
+	tests := map[string]struct {
+		removed  []int
+		vertices int
+	}{
+		"triangle": {removed: []int{0}, vertices: 8},
+		"quad":     {removed: []int{0, 1}, vertices: 9},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := removeFaces(cube(), tc.removed...)
+
+			report := repair.Inspect(m, modeling.PositionAttribute)
+			assert.False(t, report.Clean())
+			require.Len(t, report.Holes, 1)
+			assert.Len(t, report.Holes[0], len(tc.removed)+2)
+
+			filled, err := repair.FillHolesTransformer{}.Transform(m)
+			require.NoError(t, err)
+			assert.Equal(t, tc.vertices, filled.AttributeLength())
+			assert.True(t, filled.HalfEdges().IsClosed())
+			assert.True(t, repair.Inspect(filled, modeling.PositionAttribute).Clean())
+			assert.InDelta(t, 1., signedVolume(filled), 0.000001)
+		})
+	}
+}
+
+func TestFillHoles_MaxEdges(t *testing.T) {
+	m := removeFaces(cube(), 0, 1)
+	assert.Equal(t, m.PrimitiveCount(), repair.FillHoles(m, 3).PrimitiveCount())
+	assert.Equal(t, m.PrimitiveCount()+4, repair.FillHoles(m, 4).PrimitiveCount())
+}
+
+func TestUnifyWinding(t *testing.T) {
+	indices := iter.ReadFull(cube().Indices())
+
+	tests := map[string][]int{
+		"some flipped": {0, 5, 7},
+		"all flipped":  {0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+	}
+
+	for name, flipped := range tests {
+		t.Run(name, func(t *testing.T) {
+			flippedIndices := append([]int(nil), indices...)
+			for _, f := range flipped {
+				flippedIndices[f*3+1], flippedIndices[f*3+2] = flippedIndices[f*3+2], flippedIndices[f*3+1]
+			}
+			m := cube().SetIndices(flippedIndices)
+
+			if len(flipped) < 12 {
+				assert.NotEmpty(t, repair.Inspect(m, modeling.PositionAttribute).InconsistentEdges)
+			}
+
+			unified, err := repair.UnifyWindingTransformer{}.Transform(m)
+			require.NoError(t, err)
+			assert.True(t, repair.Inspect(unified, modeling.PositionAttribute).Clean())
+			assert.InDelta(t, 1., signedVolume(unified), 0.000001)
+		})
+	}
+}
+
+func TestRemoveDuplicateFaces(t *testing.T) {
+	m := cube()
+	positions := iter.ReadFull(m.Float3Attribute(modeling.PositionAttribute))
+	indices := iter.ReadFull(m.Indices())
+
+	// Duplicate the first face with vertices of it's own, wound the other way
+	positions = append(positions, positions[indices[0]], positions[indices[1]], positions[indices[2]])
+	indices = append(indices, 8, 10, 9)
+	withDuplicate := modeling.NewTriangleMesh(indices).SetFloat3Attribute(modeling.PositionAttribute, positions)
+
+	report := repair.Inspect(withDuplicate, modeling.PositionAttribute)
+	assert.Equal(t, []int{12}, report.DuplicateFaces)
+
+	out, err := repair.RemoveDuplicateFacesTransformer{}.Transform(withDuplicate)
+	require.NoError(t, err)
+	assert.Equal(t, 12, out.PrimitiveCount())
+	assert.Equal(t, 8, out.AttributeLength())
+	assert.True(t, repair.Inspect(out, modeling.PositionAttribute).Clean())
+}
+
+func TestFixTJunctions(t *testing.T) {
+	// A square on the left, with two rectangles on the right that meet at
+	// the middle of the square's right edge
+	m := modeling.NewTriangleMesh([]int{
+		0, 1, 2,
+		0, 2, 3,
+
+		1, 4, 5,
+		1, 5, 6,
+
+		6, 5, 7,
+		6, 7, 2,
+	}).SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+		vector3.New(0., 0., 0.),
+		vector3.New(1., 0., 0.),
+		vector3.New(1., 1., 0.),
+		vector3.New(0., 1., 0.),
+		vector3.New(2., 0., 0.),
+		vector3.New(2., 0.5, 0.),
+		vector3.New(1., 0.5, 0.),
+		vector3.New(2., 1., 0.),
+	})
+
+	report := repair.Inspect(m, modeling.PositionAttribute)
+	assert.Equal(t, []repair.TJunction{{Vertex: 6, Edge: modeling.Edge{A: 1, B: 2}}}, report.TJunctions)
+
+	out, err := repair.FixTJunctionsTransformer{}.Transform(m)
+	require.NoError(t, err)
+	assert.Equal(t, 7, out.PrimitiveCount())
+
+	fixed := repair.Inspect(out, modeling.PositionAttribute)
+	assert.Empty(t, fixed.TJunctions)
+	assert.Len(t, fixed.BoundaryEdges, 7)
+	assert.Empty(t, fixed.DegenerateFaces)
+}
+
+func TestSplitNonManifoldVertices(t *testing.T) {
+	// Two triangles touching at a single vertex
+	m := modeling.NewTriangleMesh([]int{
+		0, 1, 2,
+		0, 3, 4,
+	}).SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+		vector3.New(0., 0., 0.),
+		vector3.New(1., 0., 0.),
+		vector3.New(1., 1., 0.),
+		vector3.New(-1., 0., 0.),
+		vector3.New(-1., -1., 0.),
+	})
+
+	report := repair.Inspect(m, modeling.PositionAttribute)
+	assert.Equal(t, []int{0}, report.NonManifoldVertices)
+
+	out, err := repair.SplitNonManifoldVerticesTransformer{}.Transform(m)
+	require.NoError(t, err)
+	assert.Equal(t, 6, out.AttributeLength())
+	assert.Empty(t, repair.Inspect(out, modeling.PositionAttribute).NonManifoldVertices)
+	assert.Equal(t,
+		out.Float3Attribute(modeling.PositionAttribute).At(0),
+		out.Float3Attribute(modeling.PositionAttribute).At(5),
+	)
+}
+
+func TestInspect_NonManifoldEdges(t *testing.T) {
+	// Three triangles sharing the same edge
+	m := modeling.NewTriangleMesh([]int{
+		0, 1, 2,
+		1, 0, 3,
+		0, 1, 4,
+	}).SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+		vector3.New(0., 0., 0.),
+		vector3.New(1., 0., 0.),
+		vector3.New(0., 1., 0.),
+		vector3.New(0., -1., 0.),
+		vector3.New(0., 0., 1.),
+	})
+
+	report := repair.Inspect(m, modeling.PositionAttribute)
+	assert.Equal(t, []modeling.Edge{{A: 0, B: 1}}, report.NonManifoldEdges)
+	assert.Empty(t, report.InconsistentEdges)
+}
+
+func TestReportNode(t *testing.T) {
+	node := &repair.ReportNode{
+		Data: repair.ReportNodeData{
+			Mesh: nodes.Value(removeFaces(cube(), 0)),
+		},
+	}
+
+	artifact := node.Out().Value()
+	assert.Equal(t, "application/json", artifact.Mime())
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, artifact.Write(buf))
+
+	report := repair.Report{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+	assert.Len(t, report.Holes, 1)
+	assert.Len(t, report.BoundaryEdges, 3)
+}
+
+func TestNodes(t *testing.T) {
+	m := removeFaces(cube(), 0)
+	out := (&repair.FillHolesNode{
+		Data: repair.FillHolesNodeData{Mesh: nodes.Value(m)},
+	}).Out().Value()
+	assert.Equal(t, 12, out.PrimitiveCount())
+
+	for _, node := range []nodes.NodeOutput[modeling.Mesh]{
+		(&repair.UnifyWindingNode{Data: repair.UnifyWindingNodeData{Mesh: nodes.Value(out)}}).Out(),
+		(&repair.RemoveDuplicateFacesNode{Data: repair.RemoveDuplicateFacesNodeData{Mesh: nodes.Value(out)}}).Out(),
+		(&repair.FixTJunctionsNode{Data: repair.FixTJunctionsNodeData{Mesh: nodes.Value(out)}}).Out(),
+		(&repair.SplitNonManifoldVerticesNode{Data: repair.SplitNonManifoldVerticesNodeData{Mesh: nodes.Value(out)}}).Out(),
+	} {
+		assert.True(t, repair.Inspect(node.Value(), modeling.PositionAttribute).Clean())
+	}
+}
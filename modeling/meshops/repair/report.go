@@ -0,0 +1,347 @@
+package repair
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/EliCDavis/polyform/generator/artifact"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// TJunction is a vertex that sits along a boundary edge it's not a part of,
+// leaving a crack in the surface between the two
+type TJunction struct {
+	Vertex int           `json:"vertex"`
+	Edge   modeling.Edge `json:"edge"`
+}
+
+// Report lists all defects found within a triangle mesh. Connectivity is
+// determined by the mesh's indices, so meshes containing duplicate vertices
+// should be welded before being inspected.
+type Report struct {
+	VertexCount int `json:"vertexCount"`
+	FaceCount   int `json:"faceCount"`
+
+	// Faces with no area, or that reference the same vertex more than once
+	DegenerateFaces []int `json:"degenerateFaces"`
+
+	// Faces that occupy the same positions as a face that came before them
+	DuplicateFaces []int `json:"duplicateFaces"`
+
+	// Edges only referenced by a single face
+	BoundaryEdges []modeling.Edge `json:"boundaryEdges"`
+
+	// Loops of boundary vertices, in the winding order of the faces that
+	// border them
+	Holes [][]int `json:"holes"`
+
+	// Edges referenced by more than two faces
+	NonManifoldEdges []modeling.Edge `json:"nonManifoldEdges"`
+
+	// Vertices shared by multiple fans of faces that are otherwise
+	// disconnected from one another
+	NonManifoldVertices []int `json:"nonManifoldVertices"`
+
+	// Edges shared by two faces that wind it in the same direction
+	InconsistentEdges []modeling.Edge `json:"inconsistentEdges"`
+
+	TJunctions           []TJunction `json:"tJunctions"`
+	UnreferencedVertices []int       `json:"unreferencedVertices"`
+}
+
+// Clean is true when no defects were found
+func (r Report) Clean() bool {
+	return len(r.DegenerateFaces) == 0 &&
+		len(r.DuplicateFaces) == 0 &&
+		len(r.BoundaryEdges) == 0 &&
+		len(r.NonManifoldEdges) == 0 &&
+		len(r.NonManifoldVertices) == 0 &&
+		len(r.InconsistentEdges) == 0 &&
+		len(r.TJunctions) == 0 &&
+		len(r.UnreferencedVertices) == 0
+}
+
+func (r Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(r)
+}
+
+func (Report) Mime() string {
+	return "application/json"
+}
+
+// Inspect searches the triangle mesh for defects, using the attribute
+// provided as the position of each vertex
+func Inspect(m modeling.Mesh, attribute string) Report {
+	if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		panic(err)
+	}
+
+	if err := meshops.RequireV3Attribute(m, attribute); err != nil {
+		panic(err)
+	}
+
+	hem := m.HalfEdges()
+	nonManifold, inconsistent := classifyEdges(m)
+
+	return Report{
+		VertexCount:          m.AttributeLength(),
+		FaceCount:            m.PrimitiveCount(),
+		DegenerateFaces:      degenerateFaces(m, attribute),
+		DuplicateFaces:       duplicateFaces(m, attribute),
+		BoundaryEdges:        hem.BoundaryEdges(),
+		Holes:                boundaryLoops(hem),
+		NonManifoldEdges:     nonManifold,
+		NonManifoldVertices:  nonManifoldVertices(hem),
+		InconsistentEdges:    inconsistent,
+		TJunctions:           tJunctions(m, hem, attribute, defaultTolerance(m, attribute)),
+		UnreferencedVertices: unreferencedVertices(m),
+	}
+}
+
+func degenerateFaces(m modeling.Mesh, attribute string) []int {
+	faces := make([]int, 0)
+	for i := 0; i < m.PrimitiveCount(); i++ {
+		tri := m.Tri(i)
+		if tri.P1() == tri.P2() || tri.P2() == tri.P3() || tri.P1() == tri.P3() {
+			faces = append(faces, i)
+			continue
+		}
+
+		area := tri.Area3D(attribute)
+		if math.IsNaN(area) || area <= 0 {
+			faces = append(faces, i)
+		}
+	}
+	return faces
+}
+
+// classifyEdges finds all edges shared by more than two faces, and all edges
+// shared by two faces that disagree on winding
+func classifyEdges(m modeling.Mesh) (nonManifold, inconsistent []modeling.Edge) {
+	directed := make(map[modeling.Edge]int)
+	indices := m.Indices()
+	for i := 0; i < indices.Len(); i += 3 {
+		for j := 0; j < 3; j++ {
+			a, b := indices.At(i+j), indices.At(i+(j+1)%3)
+			if a != b {
+				directed[modeling.Edge{A: a, B: b}]++
+			}
+		}
+	}
+
+	nonManifold = make([]modeling.Edge, 0)
+	inconsistent = make([]modeling.Edge, 0)
+	for edge, forward := range directed {
+		backward, ok := directed[modeling.Edge{A: edge.B, B: edge.A}]
+		if ok && edge.A > edge.B {
+			// Visit each undirected edge once
+			continue
+		}
+
+		undirected := modeling.Edge{A: min(edge.A, edge.B), B: max(edge.A, edge.B)}
+		switch {
+		case forward+backward > 2:
+			nonManifold = append(nonManifold, undirected)
+		case forward == 2:
+			inconsistent = append(inconsistent, undirected)
+		}
+	}
+
+	sortEdges(nonManifold)
+	sortEdges(inconsistent)
+	return
+}
+
+func sortEdges(edges []modeling.Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].A == edges[j].A {
+			return edges[i].B < edges[j].B
+		}
+		return edges[i].A < edges[j].A
+	})
+}
+
+func unreferencedVertices(m modeling.Mesh) []int {
+	referenced := make([]bool, m.AttributeLength())
+	indices := m.Indices()
+	for i := 0; i < indices.Len(); i++ {
+		referenced[indices.At(i)] = true
+	}
+
+	vertices := make([]int, 0)
+	for v, r := range referenced {
+		if !r {
+			vertices = append(vertices, v)
+		}
+	}
+	return vertices
+}
+
+// boundaryLoops walks the boundary edges of the mesh, returning each closed
+// loop of vertices found
+func boundaryLoops(hem *modeling.HalfEdgeMesh) [][]int {
+	loops := make([][]int, 0)
+	visited := make([]bool, hem.HalfEdgeCount())
+
+	nextBoundary := func(he int) int {
+		for _, out := range hem.OutgoingHalfEdges(hem.Destination(he)) {
+			if !visited[out] && hem.IsBoundary(out) {
+				return out
+			}
+		}
+		return -1
+	}
+
+	for start := 0; start < hem.HalfEdgeCount(); start++ {
+		if visited[start] || !hem.IsBoundary(start) {
+			continue
+		}
+
+		loop := make([]int, 0)
+		he := start
+		for {
+			visited[he] = true
+			loop = append(loop, hem.HalfEdge(he).Origin)
+
+			if hem.Destination(he) == hem.HalfEdge(start).Origin {
+				loops = append(loops, loop)
+				break
+			}
+
+			if he = nextBoundary(he); he == -1 {
+				// Boundary never made it back to where it started
+				break
+			}
+		}
+	}
+
+	return loops
+}
+
+// defaultTolerance is how close a vertex needs to be to an edge to be
+// considered lying on it, scaled to the size of the mesh
+func defaultTolerance(m modeling.Mesh, attribute string) float64 {
+	if m.AttributeLength() == 0 {
+		return 0
+	}
+	return m.BoundingBox(attribute).Size().Length() * 1e-6
+}
+
+// tJunctions finds all boundary vertices that lie along a boundary edge
+// within the tolerance provided
+func tJunctions(m modeling.Mesh, hem *modeling.HalfEdgeMesh, attribute string, tolerance float64) []TJunction {
+	positions := m.Float3Attribute(attribute)
+	edges := hem.BoundaryEdges()
+
+	// Boundary vertices sorted along X so each edge only needs to check the
+	// vertices that fall within it's extents
+	vertices := make([]int, 0)
+	seen := make(map[int]struct{})
+	for _, e := range edges {
+		for _, v := range [2]int{e.A, e.B} {
+			if _, ok := seen[v]; !ok {
+				seen[v] = struct{}{}
+				vertices = append(vertices, v)
+			}
+		}
+	}
+	sort.Slice(vertices, func(i, j int) bool {
+		return positions.At(vertices[i]).X() < positions.At(vertices[j]).X()
+	})
+
+	junctions := make([]TJunction, 0)
+	for _, e := range edges {
+		a := positions.At(e.A)
+		b := positions.At(e.B)
+		minX := math.Min(a.X(), b.X()) - tolerance
+		maxX := math.Max(a.X(), b.X()) + tolerance
+
+		start := sort.Search(len(vertices), func(i int) bool {
+			return positions.At(vertices[i]).X() >= minX
+		})
+
+		for i := start; i < len(vertices); i++ {
+			v := vertices[i]
+			p := positions.At(v)
+			if p.X() > maxX {
+				break
+			}
+
+			if v == e.A || v == e.B {
+				continue
+			}
+
+			if t, ok := onSegment(a, b, p, tolerance); ok && t > 0 && t < 1 {
+				junctions = append(junctions, TJunction{Vertex: v, Edge: e})
+			}
+		}
+	}
+
+	sort.Slice(junctions, func(i, j int) bool {
+		if junctions[i].Vertex == junctions[j].Vertex {
+			if junctions[i].Edge.A == junctions[j].Edge.A {
+				return junctions[i].Edge.B < junctions[j].Edge.B
+			}
+			return junctions[i].Edge.A < junctions[j].Edge.A
+		}
+		return junctions[i].Vertex < junctions[j].Vertex
+	})
+
+	return junctions
+}
+
+// onSegment determines whether or not p lies on the segment ab, returning
+// how far along the segment p is found, with points within the tolerance of
+// either end point excluded
+func onSegment(a, b, p vector3.Float64, tolerance float64) (float64, bool) {
+	dir := b.Sub(a)
+	length := dir.Length()
+	if length <= tolerance*2 {
+		return 0, false
+	}
+
+	t := p.Sub(a).Dot(dir) / (length * length)
+	if t*length <= tolerance || (1-t)*length <= tolerance {
+		return t, false
+	}
+
+	closest := a.Add(dir.Scale(t))
+	return t, closest.Distance(p) <= tolerance
+}
+
+type ReportNode = nodes.Struct[artifact.Artifact, ReportNodeData]
+
+type ReportNodeData struct {
+	Mesh      nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+}
+
+func (ReportNodeData) Description() string {
+	return "JSON report of all defects found within the mesh"
+}
+
+func (rnd ReportNodeData) Process() (artifact.Artifact, error) {
+	if rnd.Mesh == nil {
+		return Report{}, nil
+	}
+
+	m := rnd.Mesh.Value()
+	attribute := nodes.TryGetOutputValue(rnd.Attribute, modeling.PositionAttribute)
+
+	if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return nil, err
+	}
+
+	if err := meshops.RequireV3Attribute(m, attribute); err != nil {
+		return nil, err
+	}
+
+	return Inspect(m, attribute), nil
+}
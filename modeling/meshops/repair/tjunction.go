@@ -0,0 +1,138 @@
+package repair
+
+import (
+	"sort"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+type FixTJunctionsTransformer struct {
+	Attribute string
+
+	// How close a vertex needs to be to an edge to be considered lying on
+	// it. Values of 0 or less fall back to a tolerance scaled to the size of
+	// the mesh
+	Tolerance float64
+}
+
+func (ftjt FixTJunctionsTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	attribute := getAttribute(ftjt.Attribute, modeling.PositionAttribute)
+
+	if err = meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return
+	}
+
+	if err = meshops.RequireV3Attribute(m, attribute); err != nil {
+		return
+	}
+
+	return FixTJunctions(m, attribute, ftjt.Tolerance), nil
+}
+
+// FixTJunctions splits faces whose boundary edges have other boundary
+// vertices lying along them, so the vertex becomes a part of both sides of
+// the crack. Faces are split into a fan around the corner opposite of the
+// edge.
+func FixTJunctions(m modeling.Mesh, attribute string, tolerance float64) modeling.Mesh {
+	if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		panic(err)
+	}
+
+	if err := meshops.RequireV3Attribute(m, attribute); err != nil {
+		panic(err)
+	}
+
+	if tolerance <= 0 {
+		tolerance = defaultTolerance(m, attribute)
+	}
+
+	// Splitting one edge of a face can leave junctions along it's other two
+	// edges, which the next pass will pick up
+	for pass := 0; pass < 3; pass++ {
+		hem := m.HalfEdges()
+		junctions := tJunctions(m, hem, attribute, tolerance)
+		if len(junctions) == 0 {
+			break
+		}
+		m = splitJunctions(m, hem, attribute, junctions)
+	}
+
+	return m
+}
+
+func splitJunctions(m modeling.Mesh, hem *modeling.HalfEdgeMesh, attribute string, junctions []TJunction) modeling.Mesh {
+	positions := m.Float3Attribute(attribute)
+
+	onEdge := make(map[modeling.Edge][]int)
+	for _, j := range junctions {
+		onEdge[j.Edge] = append(onEdge[j.Edge], j.Vertex)
+	}
+
+	md := newMeshData(m)
+	indices := make([]int, 0, len(md.indices))
+	materials := make([]int, 0, len(md.materials))
+
+	for face := 0; face < hem.FaceCount(); face++ {
+		split := false
+		for i := 0; i < 3 && !split; i++ {
+			he := face*3 + i
+			if !hem.IsBoundary(he) {
+				continue
+			}
+
+			edge := hem.Edge(he)
+			vertices, ok := onEdge[edge]
+			if !ok {
+				continue
+			}
+
+			// Order the vertices from the start of the edge to it's end
+			start := positions.At(edge.A)
+			sort.Slice(vertices, func(i, j int) bool {
+				return positions.At(vertices[i]).Distance(start) < positions.At(vertices[j]).Distance(start)
+			})
+
+			opposite := hem.HalfEdge(hem.HalfEdge(he).Prev).Origin
+			chain := append(append([]int{edge.A}, vertices...), edge.B)
+			for c := 0; c < len(chain)-1; c++ {
+				indices = append(indices, chain[c], chain[c+1], opposite)
+				materials = append(materials, md.materials[face])
+			}
+			split = true
+		}
+
+		if !split {
+			indices = append(indices, md.indices[face*3:face*3+3]...)
+			materials = append(materials, md.materials[face])
+		}
+	}
+
+	md.indices = indices
+	md.materials = materials
+	return md.mesh()
+}
+
+type FixTJunctionsNode = nodes.Struct[modeling.Mesh, FixTJunctionsNodeData]
+
+type FixTJunctionsNodeData struct {
+	Mesh      nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+	Tolerance nodes.NodeOutput[float64]
+}
+
+func (FixTJunctionsNodeData) Description() string {
+	return "Splits faces so vertices lying along the edges of neighboring faces become a part of them"
+}
+
+func (ftjnd FixTJunctionsNodeData) Process() (modeling.Mesh, error) {
+	if ftjnd.Mesh == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	return FixTJunctionsTransformer{
+		Attribute: nodes.TryGetOutputValue(ftjnd.Attribute, modeling.PositionAttribute),
+		Tolerance: nodes.TryGetOutputValue(ftjnd.Tolerance, 0.),
+	}.Transform(ftjnd.Mesh.Value())
+}
@@ -0,0 +1,19 @@
+package repair
+
+import (
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/refutil"
+)
+
+func init() {
+	factory := &refutil.TypeFactory{}
+
+	refutil.RegisterType[ReportNode](factory)
+	refutil.RegisterType[FillHolesNode](factory)
+	refutil.RegisterType[UnifyWindingNode](factory)
+	refutil.RegisterType[RemoveDuplicateFacesNode](factory)
+	refutil.RegisterType[FixTJunctionsNode](factory)
+	refutil.RegisterType[SplitNonManifoldVerticesNode](factory)
+
+	generator.RegisterTypes(factory)
+}
@@ -0,0 +1,205 @@
+package repair
+
+import (
+	"strings"
+
+	"github.com/EliCDavis/iter"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+// meshData is a mutable copy of a triangle mesh, used by repairs that need to
+// add vertices or rearrange faces before building the final mesh
+type meshData struct {
+	indices   []int
+	materials []int // Index into original materials for each face
+
+	original    modeling.Mesh
+	vertexCount int
+
+	v4 map[string][]vector4.Float64
+	v3 map[string][]vector3.Float64
+	v2 map[string][]vector2.Float64
+	v1 map[string][]float64
+}
+
+func readAll[T any](attrs []string, reader func(string) *iter.ArrayIterator[T]) map[string][]T {
+	data := make(map[string][]T)
+	for _, attr := range attrs {
+		data[attr] = iter.ReadFull(reader(attr))
+	}
+	return data
+}
+
+func newMeshData(m modeling.Mesh) *meshData {
+	return &meshData{
+		indices:     iter.ReadFull(m.Indices()),
+		materials:   faceMaterials(m),
+		original:    m,
+		vertexCount: m.AttributeLength(),
+		v4:          readAll(m.Float4Attributes(), m.Float4Attribute),
+		v3:          readAll(m.Float3Attributes(), m.Float3Attribute),
+		v2:          readAll(m.Float2Attributes(), m.Float2Attribute),
+		v1:          readAll(m.Float1Attributes(), m.Float1Attribute),
+	}
+}
+
+// duplicate creates a new vertex with the same data as the one provided
+func (md *meshData) duplicate(v int) int {
+	return md.average([]int{v})
+}
+
+// average creates a new vertex whose attributes are the average of all the
+// vertices provided
+func (md *meshData) average(vertices []int) int {
+	scale := 1. / float64(len(vertices))
+
+	for attr, data := range md.v4 {
+		sum := vector4.Zero[float64]()
+		for _, v := range vertices {
+			sum = sum.Add(data[v])
+		}
+		if attr == modeling.JointAttribute {
+			sum = data[vertices[0]]
+		} else {
+			sum = sum.Scale(scale)
+		}
+		md.v4[attr] = append(data, sum)
+	}
+
+	for attr, data := range md.v3 {
+		sum := vector3.Zero[float64]()
+		for _, v := range vertices {
+			sum = sum.Add(data[v])
+		}
+		sum = sum.Scale(scale)
+		if attr == modeling.NormalAttribute && len(vertices) > 1 {
+			sum = sum.Normalized()
+		}
+		md.v3[attr] = append(data, sum)
+	}
+
+	for attr, data := range md.v2 {
+		sum := vector2.Zero[float64]()
+		for _, v := range vertices {
+			sum = sum.Add(data[v])
+		}
+		md.v2[attr] = append(data, sum.Scale(scale))
+	}
+
+	for attr, data := range md.v1 {
+		sum := 0.
+		for _, v := range vertices {
+			sum += data[v]
+		}
+		md.v1[attr] = append(data, sum*scale)
+	}
+
+	md.vertexCount++
+	return md.vertexCount - 1
+}
+
+// mesh builds the final mesh, grouping faces by their material
+func (md *meshData) mesh() modeling.Mesh {
+	originalMaterials := md.original.Materials()
+
+	indices := md.indices
+	var materials []modeling.MeshMaterial
+	if len(originalMaterials) > 0 {
+		groups := make([][]int, len(originalMaterials))
+		for face, mat := range md.materials {
+			groups[mat] = append(groups[mat], md.indices[face*3:face*3+3]...)
+		}
+
+		indices = make([]int, 0, len(md.indices))
+		materials = make([]modeling.MeshMaterial, 0, len(originalMaterials))
+		for i, group := range groups {
+			if len(group) == 0 {
+				continue
+			}
+			indices = append(indices, group...)
+			materials = append(materials, modeling.MeshMaterial{
+				PrimitiveCount: len(group) / 3,
+				Material:       originalMaterials[i].Material,
+			})
+		}
+	}
+
+	return modeling.NewTriangleMesh(indices).
+		SetFloat4Data(md.v4).
+		SetFloat3Data(md.v3).
+		SetFloat2Data(md.v2).
+		SetFloat1Data(md.v1).
+		SetMaterials(materials)
+}
+
+// faceMaterials returns the index of the material used by each face of the
+// mesh
+func faceMaterials(m modeling.Mesh) []int {
+	faces := make([]int, m.PrimitiveCount())
+	materials := m.Materials()
+	if len(materials) == 0 {
+		return faces
+	}
+
+	face := 0
+	for i, mat := range materials {
+		for j := 0; j < mat.PrimitiveCount && face < len(faces); j++ {
+			faces[face] = i
+			face++
+		}
+	}
+
+	// Any faces unaccounted for fall under the last material
+	for ; face < len(faces); face++ {
+		faces[face] = len(materials) - 1
+	}
+
+	return faces
+}
+
+// keepFaces builds a new mesh containing only the faces specified, retaining
+// each face's material
+func keepFaces(m modeling.Mesh, keep []bool) modeling.Mesh {
+	md := newMeshData(m)
+	indices := make([]int, 0, len(md.indices))
+	materials := make([]int, 0, len(md.materials))
+	for face, k := range keep {
+		if !k {
+			continue
+		}
+		indices = append(indices, md.indices[face*3:face*3+3]...)
+		materials = append(materials, md.materials[face])
+	}
+	md.indices = indices
+	md.materials = materials
+	return meshops.RemovedUnreferencedVertices(md.mesh())
+}
+
+// weldedIDs assigns every vertex an ID shared by all other vertices that have
+// the exact same position
+func weldedIDs(m modeling.Mesh, attribute string) []int {
+	positions := m.Float3Attribute(attribute)
+	ids := make([]int, positions.Len())
+	lut := make(map[vector3.Float64]int, positions.Len())
+	for i := 0; i < positions.Len(); i++ {
+		p := positions.At(i)
+		id, ok := lut[p]
+		if !ok {
+			id = i
+			lut[p] = id
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func getAttribute(attr string, fallback string) string {
+	if strings.TrimSpace(attr) == "" {
+		return fallback
+	}
+	return attr
+}
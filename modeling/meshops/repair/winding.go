@@ -0,0 +1,172 @@
+package repair
+
+import (
+	"github.com/EliCDavis/iter"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+)
+
+// Orientation decided for each face while unifying winding
+const (
+	unoriented = iota
+	keepWinding
+	flipWinding
+)
+
+type UnifyWindingTransformer struct {
+	Attribute string
+}
+
+func (uwt UnifyWindingTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	attribute := getAttribute(uwt.Attribute, modeling.PositionAttribute)
+
+	if err = meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return
+	}
+
+	if err = meshops.RequireV3Attribute(m, attribute); err != nil {
+		return
+	}
+
+	return UnifyWinding(m, attribute), nil
+}
+
+// UnifyWinding flips faces so every pair of faces sharing an edge agrees on
+// winding order. Closed pieces of the mesh are then oriented so their faces
+// point outward, using the attribute provided as the position of each vertex.
+func UnifyWinding(m modeling.Mesh, attribute string) modeling.Mesh {
+	if err := meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		panic(err)
+	}
+
+	if err := meshops.RequireV3Attribute(m, attribute); err != nil {
+		panic(err)
+	}
+
+	indices := iter.ReadFull(m.Indices())
+	faceCount := len(indices) / 3
+
+	type edgeUse struct {
+		face    int
+		forward bool // Whether or not the face winds the edge from A to B
+	}
+
+	edges := make(map[modeling.Edge][]edgeUse)
+	for face := 0; face < faceCount; face++ {
+		for i := 0; i < 3; i++ {
+			a, b := indices[face*3+i], indices[face*3+(i+1)%3]
+			if a == b {
+				continue
+			}
+			key := modeling.Edge{A: min(a, b), B: max(a, b)}
+			edges[key] = append(edges[key], edgeUse{face: face, forward: a < b})
+		}
+	}
+
+	orientation := make([]int, faceCount)
+	changed := false
+
+	for seed := 0; seed < faceCount; seed++ {
+		if orientation[seed] != unoriented {
+			continue
+		}
+
+		// Flood fill across manifold edges, making each neighbor agree with
+		// the face we came from
+		orientation[seed] = keepWinding
+		component := []int{seed}
+		closed := true
+		for queue := []int{seed}; len(queue) > 0; {
+			face := queue[0]
+			queue = queue[1:]
+
+			for i := 0; i < 3; i++ {
+				a, b := indices[face*3+i], indices[face*3+(i+1)%3]
+				if a == b {
+					continue
+				}
+
+				uses := edges[modeling.Edge{A: min(a, b), B: max(a, b)}]
+				if len(uses) != 2 {
+					closed = false
+					continue
+				}
+
+				self, other := uses[0], uses[1]
+				if self.face != face {
+					self, other = other, self
+				}
+
+				if orientation[other.face] != unoriented {
+					continue
+				}
+
+				orientation[other.face] = orientation[face]
+				if self.forward == other.forward {
+					orientation[other.face] = keepWinding + flipWinding - orientation[face]
+				}
+				component = append(component, other.face)
+				queue = append(queue, other.face)
+			}
+		}
+
+		if closed && componentVolume(m, attribute, indices, component, orientation) < 0 {
+			for _, face := range component {
+				orientation[face] = keepWinding + flipWinding - orientation[face]
+			}
+		}
+
+		for _, face := range component {
+			if orientation[face] == flipWinding {
+				indices[face*3+1], indices[face*3+2] = indices[face*3+2], indices[face*3+1]
+				changed = true
+			}
+		}
+	}
+
+	if !changed {
+		return m
+	}
+
+	return m.SetIndices(indices)
+}
+
+// componentVolume computes the signed volume enclosed by the faces provided,
+// taking into account whether or not each face is going to be flipped
+func componentVolume(m modeling.Mesh, attribute string, indices, faces, orientation []int) float64 {
+	positions := m.Float3Attribute(attribute)
+	volume := 0.
+	for _, face := range faces {
+		a := positions.At(indices[face*3])
+		b := positions.At(indices[face*3+1])
+		c := positions.At(indices[face*3+2])
+		v := a.Dot(b.Cross(c)) / 6.
+		if orientation[face] == flipWinding {
+			v = -v
+		}
+		volume += v
+	}
+	return volume
+}
+
+type UnifyWindingNode = nodes.Struct[modeling.Mesh, UnifyWindingNodeData]
+
+type UnifyWindingNodeData struct {
+	Mesh      nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+}
+
+func (UnifyWindingNodeData) Description() string {
+	return "Flips faces so neighboring faces agree on winding, and closed surfaces face outward"
+}
+
+func (uwnd UnifyWindingNodeData) Process() (modeling.Mesh, error) {
+	if uwnd.Mesh == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	return UnifyWindingTransformer{
+		Attribute: nodes.TryGetOutputValue(uwnd.Attribute, modeling.PositionAttribute),
+	}.Transform(uwnd.Mesh.Value())
+}
@@ -3,6 +3,8 @@ package meshops
 import (
 	"github.com/EliCDavis/polyform/math/geometry"
 	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector3"
 )
 
 type SliceByPlaneTransformerSide int
@@ -99,3 +101,52 @@ func SliceByPlaneWithAttribute(m modeling.Mesh, plane geometry.Plane, attribute
 
 	return RemovedUnreferencedVertices(above), RemovedUnreferencedVertices(below)
 }
+
+type SliceByPlaneNode = nodes.Struct[SliceByPlaneNodeOutputs, SliceByPlaneNodeData]
+
+type SliceByPlaneNodeData struct {
+	Mesh      nodes.NodeOutput[modeling.Mesh]
+	Attribute nodes.NodeOutput[string]
+	Origin    nodes.NodeOutput[vector3.Float64]
+	Normal    nodes.NodeOutput[vector3.Float64]
+}
+
+type SliceByPlaneNodeOutputs struct {
+	Above nodes.Port[modeling.Mesh]
+	Below nodes.Port[modeling.Mesh]
+}
+
+func (sbpn SliceByPlaneNodeData) Description() string {
+	return "Splits the mesh in two, separating the triangles on either side of the plane"
+}
+
+func (sbpn SliceByPlaneNodeData) Process() (SliceByPlaneNodeOutputs, error) {
+	if sbpn.Mesh == nil {
+		empty := modeling.EmptyMesh(modeling.TriangleTopology)
+		return SliceByPlaneNodeOutputs{
+			Above: nodes.NewPort(empty),
+			Below: nodes.NewPort(empty),
+		}, nil
+	}
+
+	attribute := fallbackAttribute(nodes.TryGetOutputValue(sbpn.Attribute, ""), modeling.PositionAttribute)
+
+	m := sbpn.Mesh.Value()
+	if err := RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return SliceByPlaneNodeOutputs{}, err
+	}
+
+	if err := RequireV3Attribute(m, attribute); err != nil {
+		return SliceByPlaneNodeOutputs{}, err
+	}
+
+	plane := geometry.NewPlane(
+		nodes.TryGetOutputValue(sbpn.Origin, vector3.Zero[float64]()),
+		nodes.TryGetOutputValue(sbpn.Normal, vector3.Up[float64]()),
+	)
+	above, below := SliceByPlaneWithAttribute(m, plane, attribute)
+	return SliceByPlaneNodeOutputs{
+		Above: nodes.NewPort(above),
+		Below: nodes.NewPort(below),
+	}, nil
+}
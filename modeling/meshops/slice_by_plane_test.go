@@ -0,0 +1,43 @@
+package meshops_test
+
+import (
+	"testing"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSliceByPlaneNode(t *testing.T) {
+	// One triangle on either side of the XZ plane
+	m := modeling.NewTriangleMesh([]int{0, 1, 2, 3, 4, 5}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 1., 0.),
+			vector3.New(1., 1., 0.),
+			vector3.New(1., 2., 0.),
+			vector3.New(0., -1., 0.),
+			vector3.New(1., -1., 0.),
+			vector3.New(1., -2., 0.),
+		})
+
+	node := &meshops.SliceByPlaneNode{
+		Data: meshops.SliceByPlaneNodeData{
+			Mesh: nodes.Value(m),
+		},
+	}
+
+	outputs := node.Outputs()
+	require.Len(t, outputs, 2)
+	assert.Equal(t, "Above", outputs[0].NodeOutput.Port())
+	assert.Equal(t, "Below", outputs[1].NodeOutput.Port())
+
+	above := nodes.GetNodeOutputPort[modeling.Mesh](node, "Above").Value()
+	below := nodes.GetNodeOutputPort[modeling.Mesh](node, "Below").Value()
+	require.Equal(t, 3, above.AttributeLength())
+	require.Equal(t, 3, below.AttributeLength())
+	assert.Equal(t, -1., above.Float3Attribute(modeling.PositionAttribute).At(0).Y())
+	assert.Equal(t, 1., below.Float3Attribute(modeling.PositionAttribute).At(0).Y())
+}
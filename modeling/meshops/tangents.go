@@ -0,0 +1,229 @@
+package meshops
+
+import (
+	"math"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+)
+
+type GenerateTangentsTransformer struct{}
+
+func (GenerateTangentsTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	if err = RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return
+	}
+
+	if err = RequireV3Attribute(m, modeling.PositionAttribute); err != nil {
+		return
+	}
+
+	if err = RequireV3Attribute(m, modeling.NormalAttribute); err != nil {
+		return
+	}
+
+	if err = RequireV2Attribute(m, modeling.TexCoordAttribute); err != nil {
+		return
+	}
+
+	return GenerateTangents(m), nil
+}
+
+// tangentGroup identifies corners that share a tangent. Like MikkTSpace,
+// corners are merged when their position, normal, and texture coordinate
+// match and their faces agree on the orientation of texture space.
+type tangentGroup struct {
+	position vector3.Float64
+	normal   vector3.Float64
+	uv       vector2.Float64
+	flipped  bool
+}
+
+// projectOntoPlane removes the component of v that lies along the normal
+func projectOntoPlane(v, normal vector3.Float64) vector3.Float64 {
+	return v.Sub(normal.Scale(normal.Dot(v)))
+}
+
+// GenerateTangents computes MikkTSpace compatible tangents from the mesh's
+// position, normal, and texture coordinates, storing them in the float4
+// Tangent attribute. XYZ holds the tangent, pointing in the direction U
+// increases, and W holds the handedness of the bitangent, computed as
+// cross(normal, tangent) * W.
+//
+// Each face's tangent is projected onto the plane of each corner's normal and
+// accumulated, weighted by the angle of the corner. Vertices shared by faces
+// that disagree on the orientation of texture space, like along a mirrored
+// seam, are split so each side gets it's own tangent.
+func GenerateTangents(m modeling.Mesh) modeling.Mesh {
+	check(RequireTopology(m, modeling.TriangleTopology))
+	check(RequireV3Attribute(m, modeling.PositionAttribute))
+	check(RequireV3Attribute(m, modeling.NormalAttribute))
+	check(RequireV2Attribute(m, modeling.TexCoordAttribute))
+
+	positions := m.Float3Attribute(modeling.PositionAttribute)
+	normals := m.Float3Attribute(modeling.NormalAttribute)
+	uvs := m.Float2Attribute(modeling.TexCoordAttribute)
+	indices := m.Indices()
+
+	cornerGroups := make([]tangentGroup, indices.Len())
+	sums := make(map[tangentGroup]vector3.Float64)
+
+	for face := 0; face < indices.Len()/3; face++ {
+		i0, i1, i2 := indices.At(face*3), indices.At(face*3+1), indices.At(face*3+2)
+		p0, p1, p2 := positions.At(i0), positions.At(i1), positions.At(i2)
+		t0, t1, t2 := uvs.At(i0), uvs.At(i1), uvs.At(i2)
+
+		d1 := p1.Sub(p0)
+		d2 := p2.Sub(p0)
+		st1 := t1.Sub(t0)
+		st2 := t2.Sub(t0)
+
+		signedArea := st1.X()*st2.Y() - st1.Y()*st2.X()
+		flipped := signedArea < 0
+
+		tangent := d1.Scale(st2.Y()).Sub(d2.Scale(st1.Y()))
+		if signedArea != 0 {
+			tangent = tangent.Scale(1. / signedArea)
+		}
+
+		corners := [3]int{i0, i1, i2}
+		for c, v := range corners {
+			n := normals.At(v)
+			group := tangentGroup{
+				position: positions.At(v),
+				normal:   n,
+				uv:       uvs.At(v),
+				flipped:  flipped,
+			}
+			cornerGroups[face*3+c] = group
+
+			projected := projectOntoPlane(tangent, n)
+			if projected.LengthSquared() == 0 || projected.ContainsNaN() {
+				continue
+			}
+
+			// Weight by the angle of the corner within the normal's plane
+			p := positions.At(v)
+			e1 := projectOntoPlane(positions.At(corners[(c+1)%3]).Sub(p), n)
+			e2 := projectOntoPlane(positions.At(corners[(c+2)%3]).Sub(p), n)
+			if e1.LengthSquared() == 0 || e2.LengthSquared() == 0 {
+				continue
+			}
+			angle := math.Acos(math.Max(-1, math.Min(1, e1.Normalized().Dot(e2.Normalized()))))
+
+			sums[group] = sums[group].Add(projected.Normalized().Scale(angle))
+		}
+	}
+
+	tangentOf := func(group tangentGroup) vector4.Float64 {
+		t := sums[group]
+		if t.LengthSquared() == 0 || t.ContainsNaN() {
+			t = group.normal.Perpendicular()
+		}
+		t = t.Normalized()
+
+		w := 1.
+		if group.flipped {
+			w = -1
+		}
+		return vector4.New(t.X(), t.Y(), t.Z(), w)
+	}
+
+	// Assign each vertex the tangent of the first corner that references
+	// it, tracking any corners that disagree
+	tangents := make([]vector4.Float64, m.AttributeLength())
+	assigned := make([]bool, m.AttributeLength())
+	vertexGroup := make([]tangentGroup, m.AttributeLength())
+	split := false
+	for corner, group := range cornerGroups {
+		v := indices.At(corner)
+		if !assigned[v] {
+			assigned[v] = true
+			vertexGroup[v] = group
+			tangents[v] = tangentOf(group)
+			continue
+		}
+
+		if vertexGroup[v].flipped != group.flipped {
+			split = true
+		}
+	}
+
+	if !split {
+		return m.SetFloat4Attribute(modeling.TangentAttribute, tangents)
+	}
+
+	// Duplicate vertices referenced by corners of both orientations
+	type splitKey struct {
+		vertex  int
+		flipped bool
+	}
+	duplicates := make(map[splitKey]int)
+	sources := make([]int, m.AttributeLength())
+	for i := range sources {
+		sources[i] = i
+	}
+
+	newIndices := make([]int, indices.Len())
+	for corner, group := range cornerGroups {
+		v := indices.At(corner)
+		newIndices[corner] = v
+		if vertexGroup[v].flipped == group.flipped {
+			continue
+		}
+
+		key := splitKey{vertex: v, flipped: group.flipped}
+		duplicate, ok := duplicates[key]
+		if !ok {
+			duplicate = len(sources)
+			duplicates[key] = duplicate
+			sources = append(sources, v)
+			tangents = append(tangents, tangentOf(group))
+		}
+		newIndices[corner] = duplicate
+	}
+
+	v4 := gatherData(readAllFloat4Data(m), sources)
+	v4[modeling.TangentAttribute] = tangents
+
+	return modeling.NewTriangleMesh(newIndices).
+		SetFloat4Data(v4).
+		SetFloat3Data(gatherData(readAllFloat3Data(m), sources)).
+		SetFloat2Data(gatherData(readAllFloat2Data(m), sources)).
+		SetFloat1Data(gatherData(readAllFloat1Data(m), sources)).
+		SetMaterials(m.Materials())
+}
+
+// gatherData builds new attribute data where each entry is copied from the
+// source index provided
+func gatherData[T any](data map[string][]T, sources []int) map[string][]T {
+	out := make(map[string][]T, len(data))
+	for attr, values := range data {
+		gathered := make([]T, len(sources))
+		for i, source := range sources {
+			gathered[i] = values[source]
+		}
+		out[attr] = gathered
+	}
+	return out
+}
+
+type GenerateTangentsNode = nodes.Struct[modeling.Mesh, GenerateTangentsNodeData]
+
+type GenerateTangentsNodeData struct {
+	Mesh nodes.NodeOutput[modeling.Mesh]
+}
+
+func (GenerateTangentsNodeData) Description() string {
+	return "Computes MikkTSpace compatible tangents from the mesh's positions, normals, and texture coordinates, required for normal mapping"
+}
+
+func (gtnd GenerateTangentsNodeData) Process() (modeling.Mesh, error) {
+	if gtnd.Mesh == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+	return GenerateTangentsTransformer{}.Transform(gtnd.Mesh.Value())
+}
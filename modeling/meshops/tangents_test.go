@@ -0,0 +1,132 @@
+package meshops_test
+
+import (
+	"testing"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tangentQuad(uvs []vector2.Float64) modeling.Mesh {
+	return modeling.NewTriangleMesh([]int{0, 1, 2, 0, 2, 3}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(1., 0., 0.),
+			vector3.New(1., 1., 0.),
+			vector3.New(0., 1., 0.),
+		}).
+		SetFloat3Attribute(modeling.NormalAttribute, []vector3.Float64{
+			vector3.New(0., 0., 1.),
+			vector3.New(0., 0., 1.),
+			vector3.New(0., 0., 1.),
+			vector3.New(0., 0., 1.),
+		}).
+		SetFloat2Attribute(modeling.TexCoordAttribute, uvs)
+}
+
+func TestGenerateTangents(t *testing.T) {
+	tests := map[string]struct {
+		uvs []vector2.Float64
+		x   float64
+		w   float64
+	}{
+		"matching": {
+			uvs: []vector2.Float64{
+				vector2.New(0., 0.),
+				vector2.New(1., 0.),
+				vector2.New(1., 1.),
+				vector2.New(0., 1.),
+			},
+			x: 1,
+			w: 1,
+		},
+		"mirrored U": {
+			uvs: []vector2.Float64{
+				vector2.New(1., 0.),
+				vector2.New(0., 0.),
+				vector2.New(0., 1.),
+				vector2.New(1., 1.),
+			},
+			x: -1,
+			w: -1,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			out := meshops.GenerateTangents(tangentQuad(tc.uvs))
+
+			require.True(t, out.HasFloat4Attribute(modeling.TangentAttribute))
+			tangents := out.Float4Attribute(modeling.TangentAttribute)
+			require.Equal(t, 4, tangents.Len())
+			for i := 0; i < tangents.Len(); i++ {
+				tangent := tangents.At(i)
+				assert.InDelta(t, tc.x, tangent.X(), 1e-9)
+				assert.InDelta(t, 0, tangent.Y(), 1e-9)
+				assert.InDelta(t, 0, tangent.Z(), 1e-9)
+				assert.Equal(t, tc.w, tangent.W())
+			}
+		})
+	}
+}
+
+func TestGenerateTangents_SplitsMirroredSeam(t *testing.T) {
+	// The second triangle's UVs are mirrored across the shared edge
+	m := tangentQuad([]vector2.Float64{
+		vector2.New(0., 0.),
+		vector2.New(1., 0.),
+		vector2.New(1., 1.),
+		vector2.New(2., 0.),
+	})
+
+	out := meshops.GenerateTangents(m)
+
+	// Vertex 0 and 2 are shared by both orientations
+	assert.Equal(t, 6, out.AttributeLength())
+	assert.Equal(t, 6, out.Indices().Len())
+
+	tangents := out.Float4Attribute(modeling.TangentAttribute)
+	positions := out.Float3Attribute(modeling.PositionAttribute)
+	indices := out.Indices()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, 1., tangents.At(indices.At(i)).W())
+		assert.Equal(t, -1., tangents.At(indices.At(3+i)).W())
+	}
+	assert.Equal(t, positions.At(indices.At(0)), positions.At(indices.At(3)))
+	assert.Equal(t, positions.At(indices.At(2)), positions.At(indices.At(4)))
+}
+
+func TestGenerateTangents_MissingAttributes(t *testing.T) {
+	m := modeling.NewTriangleMesh([]int{0, 1, 2}).
+		SetFloat3Attribute(modeling.PositionAttribute, []vector3.Float64{
+			vector3.New(0., 0., 0.),
+			vector3.New(1., 0., 0.),
+			vector3.New(1., 1., 0.),
+		})
+
+	_, err := meshops.GenerateTangentsTransformer{}.Transform(m)
+	assert.Error(t, err)
+}
+
+func TestGenerateTangentsNode(t *testing.T) {
+	m := tangentQuad([]vector2.Float64{
+		vector2.New(0., 0.),
+		vector2.New(1., 0.),
+		vector2.New(1., 1.),
+		vector2.New(0., 1.),
+	})
+
+	node := &meshops.GenerateTangentsNode{
+		Data: meshops.GenerateTangentsNodeData{
+			Mesh: nodes.Value(m),
+		},
+	}
+
+	out := node.Out().Value()
+	assert.True(t, out.HasFloat4Attribute(modeling.TangentAttribute))
+}
@@ -13,13 +13,19 @@ func init() {
 	refutil.RegisterType[LaplacianSmoothNode](factory)
 
 	refutil.RegisterType[CombineNode](factory)
+	refutil.RegisterType[UnionNode](factory)
+	refutil.RegisterType[DifferenceNode](factory)
+	refutil.RegisterType[IntersectionNode](factory)
 
 	refutil.RegisterType[SmoothNormalsNode](factory)
 	refutil.RegisterType[SmoothNormalsImplicitWeldNode](factory)
 	refutil.RegisterType[FlatNormalsNode](factory)
+	refutil.RegisterType[GenerateTangentsNode](factory)
 
 	refutil.RegisterType[ScaleAttribute3DNode](factory)
 	refutil.RegisterType[ScaleAttributeAlongNormalNode](factory)
 
+	refutil.RegisterType[SliceByPlaneNode](factory)
+
 	generator.RegisterTypes(factory)
 }
@@ -1,11 +1,23 @@
 package simplify
 
 import (
+	"container/heap"
+	"math"
+
+	"github.com/EliCDavis/iter"
 	"github.com/EliCDavis/polyform/math/mat"
 	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector2"
 	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
 )
 
+// How heavily the planes placed perpendicular to open boundaries are weighted
+// when boundaries are allowed to be simplified
+const boundaryPenalty = 100.
+
 func QuadricVector(a mat.Matrix4x4) vector3.Float64 {
 	b := mat.Matrix4x4{
 		X00: a.X00, X01: a.X01, X02: a.X02, X03: a.X03,
@@ -23,6 +35,699 @@ func QuadricErrorForVector(a mat.Matrix4x4, v vector3.Float64) float64 {
 		v.X()*a.X03 + v.Y()*a.X13 + v.Z()*a.X23 + a.X33)
 }
 
-func QuadricDecimation(m modeling.Mesh) modeling.Mesh {
-	return m
+// PlaneQuadric builds the fundamental error quadric Kp = pp^T for the plane
+// ax + by + cz + d = 0
+func PlaneQuadric(a, b, c, d float64) mat.Matrix4x4 {
+	return mat.Matrix4x4{
+		X00: a * a, X01: a * b, X02: a * c, X03: a * d,
+		X10: a * b, X11: b * b, X12: b * c, X13: b * d,
+		X20: a * c, X21: b * c, X22: c * c, X23: c * d,
+		X30: a * d, X31: b * d, X32: c * d, X33: d * d,
+	}
+}
+
+// optimalQuadricVector returns the position minimizing the quadric's error,
+// or false if the quadric's matrix is singular and no unique solution exists
+func optimalQuadricVector(a mat.Matrix4x4) (vector3.Float64, bool) {
+	det := a.X00*(a.X11*a.X22-a.X12*a.X21) -
+		a.X01*(a.X10*a.X22-a.X12*a.X20) +
+		a.X02*(a.X10*a.X21-a.X11*a.X20)
+
+	if math.Abs(det) < 1e-10 {
+		return vector3.Zero[float64](), false
+	}
+
+	v := QuadricVector(a)
+	if math.IsNaN(v.X()) || math.IsNaN(v.Y()) || math.IsNaN(v.Z()) {
+		return vector3.Zero[float64](), false
+	}
+	return v, true
+}
+
+type QuadricDecimationTransformer struct {
+	// Number of triangles to reduce the mesh to. Values less than or equal
+	// to 0 are ignored and the mesh is decimated until the error budget is
+	// spent
+	TargetTriangleCount int
+
+	// Maximum quadric error any single edge collapse is allowed to
+	// introduce. Values less than or equal to 0 are ignored
+	MaxError float64
+
+	// Prevents vertices that lie on open boundaries from moving or being
+	// removed
+	PreserveBoundary bool
+}
+
+func (qdt QuadricDecimationTransformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	if err = meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return
+	}
+
+	if err = meshops.RequireV3Attribute(m, modeling.PositionAttribute); err != nil {
+		return
+	}
+
+	return QuadricDecimation(m, qdt.TargetTriangleCount, qdt.MaxError, qdt.PreserveBoundary), nil
+}
+
+// QuadricDecimation reduces the triangle count of the mesh through a series
+// of edge collapses ordered by the quadric error metric described by Garland
+// and Heckbert in "Surface Simplification Using Quadric Error Metrics".
+//
+// Decimation stops once the mesh has been reduced to targetTriangleCount, or
+// once the cheapest remaining collapse would introduce more than maxError.
+// Passing a value less than or equal to 0 for either disables that stopping
+// criteria. If both are disabled, the mesh is returned unchanged.
+//
+// All float1-4 attributes are interpolated across each collapse, with the
+// exception of joints and weights which are taken from the nearest vertex.
+// Vertices sharing a position and all attribute data are welded together.
+// Positions shared by more than one set of attribute data (UV seams, hard
+// normals) and vertices on non-manifold edges are never removed, which keeps
+// seams intact. Open boundaries are either locked in place or penalized
+// depending on preserveBoundary.
+func QuadricDecimation(m modeling.Mesh, targetTriangleCount int, maxError float64, preserveBoundary bool) modeling.Mesh {
+	check(meshops.RequireTopology(m, modeling.TriangleTopology))
+	check(meshops.RequireV3Attribute(m, modeling.PositionAttribute))
+
+	if targetTriangleCount <= 0 && maxError <= 0 {
+		return m
+	}
+
+	d := newDecimator(m, preserveBoundary)
+	d.run(targetTriangleCount, maxError)
+	return d.mesh()
+}
+
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ============================================================================
+
+type edgeCollapse struct {
+	// Node being removed
+	from int
+
+	// Node that remains after the collapse
+	to int
+
+	// Where the remaining node gets placed
+	position vector3.Float64
+
+	// How much of the remaining node's attribute data is kept, where 1 means
+	// nothing is taken from the node being removed
+	weight float64
+
+	cost        float64
+	fromVersion int
+	toVersion   int
+}
+
+type collapseQueue []edgeCollapse
+
+func (cq collapseQueue) Len() int           { return len(cq) }
+func (cq collapseQueue) Less(i, j int) bool { return cq[i].cost < cq[j].cost }
+func (cq collapseQueue) Swap(i, j int)      { cq[i], cq[j] = cq[j], cq[i] }
+
+func (cq *collapseQueue) Push(x any) {
+	*cq = append(*cq, x.(edgeCollapse))
+}
+
+func (cq *collapseQueue) Pop() any {
+	old := *cq
+	n := len(old)
+	item := old[n-1]
+	*cq = old[:n-1]
+	return item
+}
+
+// decimator operates on "nodes", which are the unique positions found within
+// the mesh. Each node references one or more vertices, where a node with more
+// than one vertex sits on an attribute seam.
+type decimator struct {
+	mesh0 modeling.Mesh
+
+	v4Data map[string][]vector4.Float64
+	v3Data map[string][]vector3.Float64
+	v2Data map[string][]vector2.Float64
+	v1Data map[string][]float64
+
+	tris       [][3]int
+	triRemoved []bool
+	liveTris   int
+
+	vertexNode []int
+
+	nodePositions []vector3.Float64
+	nodeQuadrics  []mat.Matrix4x4
+	nodeVertices  [][]int
+	nodeTris      [][]int
+	nodeLocked    []bool
+	nodeRemoved   []bool
+	nodeVersion   []int
+
+	queue collapseQueue
+}
+
+func readAll[T any](attrs []string, reader func(string) *iter.ArrayIterator[T]) map[string][]T {
+	data := make(map[string][]T)
+	for _, attr := range attrs {
+		data[attr] = iter.ReadFull(reader(attr))
+	}
+	return data
+}
+
+func newDecimator(m modeling.Mesh, preserveBoundary bool) *decimator {
+	d := &decimator{
+		mesh0:  m,
+		v4Data: readAll(m.Float4Attributes(), m.Float4Attribute),
+		v3Data: readAll(m.Float3Attributes(), m.Float3Attribute),
+		v2Data: readAll(m.Float2Attributes(), m.Float2Attribute),
+		v1Data: readAll(m.Float1Attributes(), m.Float1Attribute),
+	}
+
+	positions := d.v3Data[modeling.PositionAttribute]
+
+	// Group vertices by position into nodes. Vertices that share both a
+	// position and all of their attribute data are welded together, so only
+	// nodes that actually sit on an attribute seam end up with more than one
+	// vertex
+	nodeLookup := make(map[vector3.Float64]int)
+	d.vertexNode = make([]int, len(positions))
+	weld := make([]int, len(positions))
+	for i, p := range positions {
+		node, ok := nodeLookup[p]
+		if !ok {
+			node = len(d.nodePositions)
+			nodeLookup[p] = node
+			d.nodePositions = append(d.nodePositions, p)
+			d.nodeVertices = append(d.nodeVertices, nil)
+		}
+		d.vertexNode[i] = node
+
+		weld[i] = i
+		for _, v := range d.nodeVertices[node] {
+			if d.sameAttributes(v, i) {
+				weld[i] = v
+				break
+			}
+		}
+
+		if weld[i] == i {
+			d.nodeVertices[node] = append(d.nodeVertices[node], i)
+		}
+	}
+
+	nodeCount := len(d.nodePositions)
+	d.nodeQuadrics = make([]mat.Matrix4x4, nodeCount)
+	d.nodeTris = make([][]int, nodeCount)
+	d.nodeLocked = make([]bool, nodeCount)
+	d.nodeRemoved = make([]bool, nodeCount)
+	d.nodeVersion = make([]int, nodeCount)
+
+	indices := m.Indices()
+	d.tris = make([][3]int, indices.Len()/3)
+	d.triRemoved = make([]bool, len(d.tris))
+
+	type edge struct{ a, b int }
+	edgeTris := make(map[edge][]int)
+	newEdge := func(a, b int) edge {
+		if a < b {
+			return edge{a, b}
+		}
+		return edge{b, a}
+	}
+
+	for t := range d.tris {
+		tri := [3]int{weld[indices.At(t*3)], weld[indices.At(t*3+1)], weld[indices.At(t*3+2)]}
+		d.tris[t] = tri
+
+		n0, n1, n2 := d.vertexNode[tri[0]], d.vertexNode[tri[1]], d.vertexNode[tri[2]]
+		if n0 == n1 || n1 == n2 || n0 == n2 {
+			d.triRemoved[t] = true
+			continue
+		}
+
+		d.liveTris++
+		d.nodeTris[n0] = append(d.nodeTris[n0], t)
+		d.nodeTris[n1] = append(d.nodeTris[n1], t)
+		d.nodeTris[n2] = append(d.nodeTris[n2], t)
+
+		edgeTris[newEdge(n0, n1)] = append(edgeTris[newEdge(n0, n1)], t)
+		edgeTris[newEdge(n1, n2)] = append(edgeTris[newEdge(n1, n2)], t)
+		edgeTris[newEdge(n2, n0)] = append(edgeTris[newEdge(n2, n0)], t)
+
+		normal, ok := d.triNormal(t)
+		if !ok {
+			continue
+		}
+		p := d.nodePositions[n0]
+		kp := PlaneQuadric(normal.X(), normal.Y(), normal.Z(), -normal.Dot(p))
+		d.nodeQuadrics[n0] = d.nodeQuadrics[n0].Add(kp)
+		d.nodeQuadrics[n1] = d.nodeQuadrics[n1].Add(kp)
+		d.nodeQuadrics[n2] = d.nodeQuadrics[n2].Add(kp)
+	}
+
+	for node, verts := range d.nodeVertices {
+		if len(verts) > 1 {
+			d.nodeLocked[node] = true
+		}
+	}
+
+	for e, tris := range edgeTris {
+		if len(tris) > 2 {
+			d.nodeLocked[e.a] = true
+			d.nodeLocked[e.b] = true
+			continue
+		}
+
+		if len(tris) != 1 {
+			continue
+		}
+
+		if preserveBoundary {
+			d.nodeLocked[e.a] = true
+			d.nodeLocked[e.b] = true
+			continue
+		}
+
+		// Add a plane perpendicular to the boundary edge to keep the
+		// boundary from wandering
+		normal, ok := d.triNormal(tris[0])
+		if !ok {
+			continue
+		}
+		pa := d.nodePositions[e.a]
+		dir := d.nodePositions[e.b].Sub(pa)
+		perp := dir.Cross(normal)
+		if perp.Length() == 0 {
+			continue
+		}
+		perp = perp.Normalized()
+		kp := PlaneQuadric(perp.X(), perp.Y(), perp.Z(), -perp.Dot(pa))
+		kp = scaleQuadric(kp, boundaryPenalty)
+		d.nodeQuadrics[e.a] = d.nodeQuadrics[e.a].Add(kp)
+		d.nodeQuadrics[e.b] = d.nodeQuadrics[e.b].Add(kp)
+	}
+
+	for e := range edgeTris {
+		d.pushCollapse(e.a, e.b)
+	}
+	heap.Init(&d.queue)
+
+	return d
+}
+
+func sameValues[T comparable](data map[string][]T, a, b int) bool {
+	for _, values := range data {
+		if values[a] != values[b] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameAttributes determines whether or not the two vertices carry identical
+// data across every attribute
+func (d *decimator) sameAttributes(a, b int) bool {
+	return sameValues(d.v4Data, a, b) &&
+		sameValues(d.v3Data, a, b) &&
+		sameValues(d.v2Data, a, b) &&
+		sameValues(d.v1Data, a, b)
+}
+
+func scaleQuadric(a mat.Matrix4x4, s float64) mat.Matrix4x4 {
+	return mat.Matrix4x4{
+		X00: a.X00 * s, X01: a.X01 * s, X02: a.X02 * s, X03: a.X03 * s,
+		X10: a.X10 * s, X11: a.X11 * s, X12: a.X12 * s, X13: a.X13 * s,
+		X20: a.X20 * s, X21: a.X21 * s, X22: a.X22 * s, X23: a.X23 * s,
+		X30: a.X30 * s, X31: a.X31 * s, X32: a.X32 * s, X33: a.X33 * s,
+	}
+}
+
+func (d *decimator) triNodes(t int) (int, int, int) {
+	tri := d.tris[t]
+	return d.vertexNode[tri[0]], d.vertexNode[tri[1]], d.vertexNode[tri[2]]
+}
+
+func (d *decimator) triNormal(t int) (vector3.Float64, bool) {
+	n0, n1, n2 := d.triNodes(t)
+	return planeNormal(d.nodePositions[n0], d.nodePositions[n1], d.nodePositions[n2])
+}
+
+func planeNormal(a, b, c vector3.Float64) (vector3.Float64, bool) {
+	cross := b.Sub(a).Cross(c.Sub(a))
+	length := cross.Length()
+	if length == 0 || math.IsNaN(length) {
+		return vector3.Zero[float64](), false
+	}
+	return cross.DivByConstant(length), true
+}
+
+func (d *decimator) pushCollapse(a, b int) {
+	if d.nodeLocked[a] && d.nodeLocked[b] {
+		return
+	}
+
+	q := d.nodeQuadrics[a].Add(d.nodeQuadrics[b])
+	collapse := edgeCollapse{
+		from:   a,
+		to:     b,
+		weight: 1,
+	}
+
+	switch {
+	case d.nodeLocked[a]:
+		collapse.from = b
+		collapse.to = a
+		collapse.position = d.nodePositions[a]
+
+	case d.nodeLocked[b]:
+		collapse.position = d.nodePositions[b]
+
+	default:
+		pa := d.nodePositions[a]
+		pb := d.nodePositions[b]
+		if optimal, ok := optimalQuadricVector(q); ok {
+			collapse.position = optimal
+		} else {
+			collapse.position = pb
+			best := QuadricErrorForVector(q, pb)
+			for _, candidate := range []vector3.Float64{pa, pa.Midpoint(pb)} {
+				if err := QuadricErrorForVector(q, candidate); err < best {
+					best = err
+					collapse.position = candidate
+				}
+			}
+		}
+
+		// Project the new position onto the edge to determine how to blend
+		// the attribute data of the two vertices
+		dir := pb.Sub(pa)
+		lenSqr := dir.Dot(dir)
+		if lenSqr > 0 {
+			collapse.weight = math.Max(0, math.Min(1, collapse.position.Sub(pa).Dot(dir)/lenSqr))
+		}
+	}
+
+	collapse.cost = math.Max(0, QuadricErrorForVector(q, collapse.position))
+	collapse.fromVersion = d.nodeVersion[collapse.from]
+	collapse.toVersion = d.nodeVersion[collapse.to]
+	heap.Push(&d.queue, collapse)
+}
+
+func (d *decimator) run(targetTriangleCount int, maxError float64) {
+	for d.queue.Len() > 0 {
+		if targetTriangleCount > 0 && d.liveTris <= targetTriangleCount {
+			return
+		}
+
+		collapse := heap.Pop(&d.queue).(edgeCollapse)
+		if d.nodeRemoved[collapse.from] || d.nodeRemoved[collapse.to] {
+			continue
+		}
+
+		if d.nodeVersion[collapse.from] != collapse.fromVersion || d.nodeVersion[collapse.to] != collapse.toVersion {
+			continue
+		}
+
+		if maxError > 0 && collapse.cost > maxError {
+			return
+		}
+
+		d.collapse(collapse)
+	}
+}
+
+func (d *decimator) liveNodeTris(node int) []int {
+	live := d.nodeTris[node][:0]
+	for _, t := range d.nodeTris[node] {
+		if !d.triRemoved[t] {
+			live = append(live, t)
+		}
+	}
+	d.nodeTris[node] = live
+	return live
+}
+
+func (d *decimator) neighbors(node int) map[int]struct{} {
+	neighbors := make(map[int]struct{})
+	for _, t := range d.liveNodeTris(node) {
+		n0, n1, n2 := d.triNodes(t)
+		neighbors[n0] = struct{}{}
+		neighbors[n1] = struct{}{}
+		neighbors[n2] = struct{}{}
+	}
+	delete(neighbors, node)
+	return neighbors
+}
+
+func (d *decimator) triContains(t, node int) bool {
+	n0, n1, n2 := d.triNodes(t)
+	return n0 == node || n1 == node || n2 == node
+}
+
+// flips determines whether or not moving the node to the new position causes
+// any of its triangles (ignoring the ones shared with the other node) to flip
+// or degenerate
+func (d *decimator) flips(node, other int, position vector3.Float64) bool {
+	for _, t := range d.nodeTris[node] {
+		if d.triContains(t, other) {
+			continue
+		}
+
+		n0, n1, n2 := d.triNodes(t)
+		before, ok := planeNormal(d.nodePositions[n0], d.nodePositions[n1], d.nodePositions[n2])
+		if !ok {
+			continue
+		}
+
+		p := [3]vector3.Float64{d.nodePositions[n0], d.nodePositions[n1], d.nodePositions[n2]}
+		for i, n := range [3]int{n0, n1, n2} {
+			if n == node {
+				p[i] = position
+			}
+		}
+
+		after, ok := planeNormal(p[0], p[1], p[2])
+		if !ok || before.Dot(after) < 0.2 {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *decimator) collapse(c edgeCollapse) {
+	fromNeighbors := d.neighbors(c.from)
+	toNeighbors := d.neighbors(c.to)
+
+	// Link condition: the only vertices both nodes share as neighbors must be
+	// the ones opposite the edge being collapsed, otherwise we'd pinch the
+	// surface into something non-manifold
+	shared := make([]int, 0, 2)
+	opposite := make(map[int]struct{})
+	for _, t := range d.nodeTris[c.from] {
+		if !d.triContains(t, c.to) {
+			continue
+		}
+		shared = append(shared, t)
+		n0, n1, n2 := d.triNodes(t)
+		for _, n := range [3]int{n0, n1, n2} {
+			if n != c.from && n != c.to {
+				opposite[n] = struct{}{}
+			}
+		}
+	}
+
+	if len(shared) == 0 {
+		return
+	}
+
+	for n := range fromNeighbors {
+		if _, ok := toNeighbors[n]; !ok {
+			continue
+		}
+		if _, ok := opposite[n]; !ok {
+			return
+		}
+	}
+
+	// Collapsing a tetrahedron (or a similarly closed fan) down any further
+	// produces nothing but degenerate geometry
+	if len(fromNeighbors) <= 2 || len(toNeighbors) <= 2 {
+		return
+	}
+
+	// Determine which of the remaining node's vertices the removed node's
+	// triangles should reference. The triangles along the edge must agree.
+	toVertex := -1
+	for _, t := range shared {
+		for _, v := range d.tris[t] {
+			if d.vertexNode[v] != c.to {
+				continue
+			}
+			if toVertex != -1 && toVertex != v {
+				return
+			}
+			toVertex = v
+		}
+	}
+
+	if d.flips(c.from, c.to, c.position) || d.flips(c.to, c.from, c.position) {
+		return
+	}
+
+	fromVertex := d.nodeVertices[c.from][0]
+	d.interpolate(fromVertex, toVertex, c.weight)
+	for _, v := range d.nodeVertices[c.to] {
+		d.v3Data[modeling.PositionAttribute][v] = c.position
+	}
+
+	for _, t := range d.nodeTris[c.from] {
+		if d.triContains(t, c.to) {
+			d.triRemoved[t] = true
+			d.liveTris--
+			continue
+		}
+
+		for i, v := range d.tris[t] {
+			if v == fromVertex {
+				d.tris[t][i] = toVertex
+			}
+		}
+		d.nodeTris[c.to] = append(d.nodeTris[c.to], t)
+	}
+
+	d.nodePositions[c.to] = c.position
+	d.nodeQuadrics[c.to] = d.nodeQuadrics[c.from].Add(d.nodeQuadrics[c.to])
+	d.nodeRemoved[c.from] = true
+	d.nodeTris[c.from] = nil
+	d.vertexNode[fromVertex] = c.to
+	d.nodeVersion[c.from]++
+	d.nodeVersion[c.to]++
+
+	for n := range d.neighbors(c.to) {
+		d.pushCollapse(c.to, n)
+	}
+}
+
+func lerp[T any](data map[string][]T, from, to int, weight float64, f func(a, b T, t float64) T) {
+	for attr, values := range data {
+		switch attr {
+		case modeling.PositionAttribute:
+			continue
+
+		case modeling.JointAttribute, modeling.WeightAttribute:
+			// Interpolating joint indices is meaningless, so we take the
+			// skinning data of whichever vertex we're closest to
+			if weight < 0.5 {
+				values[to] = values[from]
+			}
+			continue
+		}
+		values[to] = f(values[from], values[to], weight)
+	}
+}
+
+func (d *decimator) interpolate(from, to int, weight float64) {
+	if weight >= 1 {
+		return
+	}
+
+	lerp(d.v4Data, from, to, weight, func(a, b vector4.Float64, t float64) vector4.Float64 {
+		return a.Scale(1 - t).Add(b.Scale(t))
+	})
+
+	lerp(d.v3Data, from, to, weight, func(a, b vector3.Float64, t float64) vector3.Float64 {
+		return a.Scale(1 - t).Add(b.Scale(t))
+	})
+
+	lerp(d.v2Data, from, to, weight, func(a, b vector2.Float64, t float64) vector2.Float64 {
+		return a.Scale(1 - t).Add(b.Scale(t))
+	})
+
+	lerp(d.v1Data, from, to, weight, func(a, b float64, t float64) float64 {
+		return a*(1-t) + b*t
+	})
+
+	if normals, ok := d.v3Data[modeling.NormalAttribute]; ok && normals[to].Length() > 0 {
+		normals[to] = normals[to].Normalized()
+	}
+}
+
+func (d *decimator) mesh() modeling.Mesh {
+	indices := make([]int, 0, d.liveTris*3)
+	for t, tri := range d.tris {
+		if d.triRemoved[t] {
+			continue
+		}
+		indices = append(indices, tri[0], tri[1], tri[2])
+	}
+
+	// Triangles are never re-ordered, only removed, so each material keeps
+	// whatever portion of its original range survived
+	originalMaterials := d.mesh0.Materials()
+	materials := make([]modeling.MeshMaterial, len(originalMaterials))
+	start := 0
+	for i, material := range originalMaterials {
+		count := 0
+		for t := start; t < start+material.PrimitiveCount && t < len(d.tris); t++ {
+			if !d.triRemoved[t] {
+				count++
+			}
+		}
+		start += material.PrimitiveCount
+		materials[i] = modeling.MeshMaterial{
+			PrimitiveCount: count,
+			Material:       material.Material,
+		}
+	}
+
+	return meshops.RemovedUnreferencedVertices(
+		modeling.NewTriangleMesh(indices).
+			SetFloat4Data(d.v4Data).
+			SetFloat3Data(d.v3Data).
+			SetFloat2Data(d.v2Data).
+			SetFloat1Data(d.v1Data).
+			SetMaterials(materials),
+	)
+}
+
+// ============================================================================
+
+type QuadricDecimationNode = nodes.Struct[modeling.Mesh, QuadricDecimationNodeData]
+
+type QuadricDecimationNodeData struct {
+	Mesh                nodes.NodeOutput[modeling.Mesh]
+	TargetTriangleCount nodes.NodeOutput[int]
+	MaxError            nodes.NodeOutput[float64]
+	PreserveBoundary    nodes.NodeOutput[bool]
+}
+
+func (qdn QuadricDecimationNodeData) Description() string {
+	return "Reduces the number of triangles in a mesh through a series of edge collapses, choosing the collapses that introduce the least amount of error first. If neither a target triangle count or max error is provided, the mesh is reduced to half of its original triangle count."
+}
+
+func (qdn QuadricDecimationNodeData) Process() (modeling.Mesh, error) {
+	if qdn.Mesh == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	m := qdn.Mesh.Value()
+	target := nodes.TryGetOutputValue(qdn.TargetTriangleCount, 0)
+	maxError := nodes.TryGetOutputValue(qdn.MaxError, 0.)
+
+	if target <= 0 && maxError <= 0 {
+		target = m.PrimitiveCount() / 2
+	}
+
+	return QuadricDecimationTransformer{
+		TargetTriangleCount: target,
+		MaxError:            maxError,
+		PreserveBoundary:    nodes.TryGetOutputValue(qdn.PreserveBoundary, false),
+	}.Transform(m)
 }
@@ -0,0 +1,134 @@
+package simplify_test
+
+import (
+	"testing"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/modeling/primitives"
+	"github.com/EliCDavis/polyform/modeling/simplify"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+)
+
+func grid(size int) modeling.Mesh {
+	positions := make([]vector3.Float64, 0)
+	uvs := make([]vector2.Float64, 0)
+	for z := 0; z <= size; z++ {
+		for x := 0; x <= size; x++ {
+			positions = append(positions, vector3.New(float64(x), 0, float64(z)))
+			uvs = append(uvs, vector2.New(float64(x)/float64(size), float64(z)/float64(size)))
+		}
+	}
+
+	tris := make([]int, 0)
+	row := size + 1
+	for z := 0; z < size; z++ {
+		for x := 0; x < size; x++ {
+			bl := z*row + x
+			br := bl + 1
+			tl := bl + row
+			tr := tl + 1
+			tris = append(tris, bl, tl, tr, bl, tr, br)
+		}
+	}
+
+	return modeling.NewTriangleMesh(tris).
+		SetFloat3Attribute(modeling.PositionAttribute, positions).
+		SetFloat2Attribute(modeling.TexCoordAttribute, uvs)
+}
+
+func TestQuadricVector(t *testing.T) {
+	q := simplify.PlaneQuadric(1, 0, 0, -1).
+		Add(simplify.PlaneQuadric(0, 1, 0, -2)).
+		Add(simplify.PlaneQuadric(0, 0, 1, -3))
+
+	v := simplify.QuadricVector(q)
+	assert.InDelta(t, 1., v.X(), 0.000001)
+	assert.InDelta(t, 2., v.Y(), 0.000001)
+	assert.InDelta(t, 3., v.Z(), 0.000001)
+	assert.InDelta(t, 0., simplify.QuadricErrorForVector(q, v), 0.000001)
+	assert.InDelta(t, 1., simplify.QuadricErrorForVector(q, vector3.New(0., 2., 3.)), 0.000001)
+}
+
+func TestQuadricDecimation_NoCriteriaReturnsInput(t *testing.T) {
+	m := grid(4)
+	out := simplify.QuadricDecimation(m, 0, 0, false)
+	assert.Equal(t, m.PrimitiveCount(), out.PrimitiveCount())
+}
+
+func TestQuadricDecimation_FlatPlanePreservingBoundary(t *testing.T) {
+	m := grid(10)
+	assert.Equal(t, 200, m.PrimitiveCount())
+
+	out := simplify.QuadricDecimation(m, 1, 0, true)
+
+	// 40 boundary vertices locked in place leaves us with a triangulated
+	// polygon at best
+	assert.Less(t, out.PrimitiveCount(), 200)
+	assert.GreaterOrEqual(t, out.PrimitiveCount(), 38)
+
+	positions := out.Float3Attribute(modeling.PositionAttribute)
+	uvs := out.Float2Attribute(modeling.TexCoordAttribute)
+	boundaryCount := 0
+	for i := 0; i < positions.Len(); i++ {
+		p := positions.At(i)
+		assert.InDelta(t, 0., p.Y(), 0.000001)
+
+		// UVs were a linear function of position, so they should remain one
+		assert.InDelta(t, p.X()/10., uvs.At(i).X(), 0.000001)
+		assert.InDelta(t, p.Z()/10., uvs.At(i).Y(), 0.000001)
+
+		if p.X() == 0 || p.X() == 10 || p.Z() == 0 || p.Z() == 10 {
+			boundaryCount++
+		}
+	}
+	assert.Equal(t, 40, boundaryCount)
+}
+
+func TestQuadricDecimation_TargetTriangleCount(t *testing.T) {
+	m := primitives.UVSphere(1, 20, 20)
+	original := m.PrimitiveCount()
+
+	out := simplify.QuadricDecimationTransformer{
+		TargetTriangleCount: original / 4,
+	}
+	decimated := m.Transform(out)
+
+	assert.LessOrEqual(t, decimated.PrimitiveCount(), original/4)
+	assert.Greater(t, decimated.PrimitiveCount(), original/8)
+	assert.True(t, decimated.HasFloat3Attribute(modeling.NormalAttribute))
+	assert.Equal(t, decimated.AttributeLength(), decimated.Float3Attribute(modeling.NormalAttribute).Len())
+
+	// Everything should have stayed reasonably close to the sphere's surface
+	decimated.ScanFloat3Attribute(modeling.PositionAttribute, func(i int, v vector3.Float64) {
+		assert.InDelta(t, 1., v.Length(), 0.2)
+	})
+}
+
+func TestQuadricDecimation_UnweldedInput(t *testing.T) {
+	m := meshops.Unweld(primitives.UVSphere(1, 20, 20))
+	original := m.PrimitiveCount()
+
+	decimated := simplify.QuadricDecimation(m, original/4, 0, false)
+
+	assert.LessOrEqual(t, decimated.PrimitiveCount(), original/4)
+	assert.Greater(t, decimated.PrimitiveCount(), original/8)
+	decimated.ScanFloat3Attribute(modeling.PositionAttribute, func(i int, v vector3.Float64) {
+		assert.InDelta(t, 1., v.Length(), 0.2)
+	})
+}
+
+func TestQuadricDecimation_MaxError(t *testing.T) {
+	m := primitives.UVSphere(1, 20, 20)
+	original := m.PrimitiveCount()
+
+	flat := simplify.QuadricDecimation(grid(10), 0, 0.0000001, false)
+	assert.Less(t, flat.PrimitiveCount(), 200)
+
+	// A budget this small shouldn't allow anything on a curved surface to
+	// collapse
+	curved := simplify.QuadricDecimation(m, 0, 0.0000001, false)
+	assert.Equal(t, original, curved.PrimitiveCount())
+}
@@ -0,0 +1,14 @@
+package simplify
+
+import (
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/refutil"
+)
+
+func init() {
+	factory := &refutil.TypeFactory{}
+
+	refutil.RegisterType[QuadricDecimationNode](factory)
+
+	generator.RegisterTypes(factory)
+}
@@ -0,0 +1,43 @@
+package modeling
+
+import "fmt"
+
+// Gaussian splats store their view dependent color as spherical harmonics.
+// The DC term is kept in FDCAttribute, and every coefficient past it is an RGB
+// Float3 attribute of its own, named by SHAttribute. Degree 1 uses
+// coefficients 0 through 2, degree 2 adds 3 through 7, and degree 3 adds 8
+// through 14, following the same order as 3D Gaussian Splatting's f_rest
+// properties.
+const MaxSHDegree = 3
+
+// SHAttribute is the name of the attribute holding the spherical harmonic
+// coefficient, counting from the first coefficient past the DC term
+func SHAttribute(coefficient int) string {
+	return fmt.Sprintf("SH_%d", coefficient)
+}
+
+// SHCoefficients is the number of coefficients past the DC term a spherical
+// harmonic of the degree provided is made up of
+func SHCoefficients(degree int) int {
+	return (degree+1)*(degree+1) - 1
+}
+
+// SHDegreeForCoefficients is the highest spherical harmonic degree the number
+// of coefficients past the DC term fully covers
+func SHDegreeForCoefficients(coefficients int) int {
+	degree := 0
+	for degree < MaxSHDegree && SHCoefficients(degree+1) <= coefficients {
+		degree++
+	}
+	return degree
+}
+
+// SHDegree is the highest spherical harmonic degree the mesh has every
+// coefficient attribute for
+func SHDegree(m Mesh) int {
+	coefficients := 0
+	for coefficients < SHCoefficients(MaxSHDegree) && m.HasFloat3Attribute(SHAttribute(coefficients)) {
+		coefficients++
+	}
+	return SHDegreeForCoefficients(coefficients)
+}
@@ -0,0 +1,41 @@
+package modeling_test
+
+import (
+	"testing"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSHCoefficients(t *testing.T) {
+	assert.Equal(t, 0, modeling.SHCoefficients(0))
+	assert.Equal(t, 3, modeling.SHCoefficients(1))
+	assert.Equal(t, 8, modeling.SHCoefficients(2))
+	assert.Equal(t, 15, modeling.SHCoefficients(3))
+}
+
+func TestSHDegreeForCoefficients(t *testing.T) {
+	tests := map[int]int{0: 0, 2: 0, 3: 1, 7: 1, 8: 2, 14: 2, 15: 3, 45: 3}
+	for coefficients, degree := range tests {
+		assert.Equal(t, degree, modeling.SHDegreeForCoefficients(coefficients), "coefficients: %d", coefficients)
+	}
+}
+
+func TestSHDegree(t *testing.T) {
+	v3Data := map[string][]vector3.Float64{
+		modeling.PositionAttribute: {vector3.Zero[float64]()},
+	}
+	cloud := modeling.NewPointCloud(nil, v3Data, nil, nil, nil)
+	assert.Equal(t, 0, modeling.SHDegree(cloud))
+
+	for i := 0; i < 5; i++ {
+		cloud = cloud.SetFloat3Attribute(modeling.SHAttribute(i), []vector3.Float64{vector3.Zero[float64]()})
+	}
+	assert.Equal(t, 1, modeling.SHDegree(cloud))
+
+	// Gaps end the coefficients considered
+	cloud = cloud.SetFloat3Attribute(modeling.SHAttribute(6), []vector3.Float64{vector3.Zero[float64]()})
+	cloud = cloud.SetFloat3Attribute(modeling.SHAttribute(7), []vector3.Float64{vector3.Zero[float64]()})
+	assert.Equal(t, 1, modeling.SHDegree(cloud))
+}
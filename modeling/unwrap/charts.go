@@ -0,0 +1,109 @@
+package unwrap
+
+import (
+	"math"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// weldedIDs assigns every vertex an ID shared by all other vertices that have
+// the exact same position, so charts can grow across faces that don't share
+// vertices
+func weldedIDs(positions []vector3.Float64) []int {
+	ids := make([]int, len(positions))
+	lut := make(map[vector3.Float64]int, len(positions))
+	for i, p := range positions {
+		id, ok := lut[p]
+		if !ok {
+			id = i
+			lut[p] = id
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+func faceNormals(positions []vector3.Float64, indices []int) []vector3.Float64 {
+	normals := make([]vector3.Float64, len(indices)/3)
+	for face := range normals {
+		a := positions[indices[face*3]]
+		b := positions[indices[face*3+1]]
+		c := positions[indices[face*3+2]]
+		n := b.Sub(a).Cross(c.Sub(a))
+		if n.LengthSquared() > 0 {
+			n = n.Normalized()
+		}
+		normals[face] = n
+	}
+	return normals
+}
+
+// buildCharts segments the mesh into charts by growing outward from a seed
+// face, taking on neighboring faces until their normals deviate from the
+// seed's by more than the max angle. Edges between charts become the seams
+// of the unwrap.
+func buildCharts(positions []vector3.Float64, indices []int, maxAngle float64) [][]int {
+	ids := weldedIDs(positions)
+	normals := faceNormals(positions, indices)
+	faceCount := len(indices) / 3
+
+	edges := make(map[modeling.Edge][]int)
+	for face := 0; face < faceCount; face++ {
+		for i := 0; i < 3; i++ {
+			a, b := ids[indices[face*3+i]], ids[indices[face*3+(i+1)%3]]
+			if a == b {
+				continue
+			}
+			key := modeling.Edge{A: min(a, b), B: max(a, b)}
+			edges[key] = append(edges[key], face)
+		}
+	}
+
+	minDot := math.Cos(maxAngle)
+	chartOf := make([]int, faceCount)
+	for i := range chartOf {
+		chartOf[i] = -1
+	}
+
+	charts := make([][]int, 0)
+	for seed := 0; seed < faceCount; seed++ {
+		if chartOf[seed] != -1 {
+			continue
+		}
+
+		chart := len(charts)
+		chartOf[seed] = chart
+		faces := []int{seed}
+		seedNormal := normals[seed]
+
+		for queue := []int{seed}; len(queue) > 0; {
+			face := queue[0]
+			queue = queue[1:]
+
+			for i := 0; i < 3; i++ {
+				a, b := ids[indices[face*3+i]], ids[indices[face*3+(i+1)%3]]
+				for _, neighbor := range edges[modeling.Edge{A: min(a, b), B: max(a, b)}] {
+					if chartOf[neighbor] != -1 {
+						continue
+					}
+
+					// Degenerate faces have no normal and join whichever
+					// chart reaches them first
+					n := normals[neighbor]
+					if n.LengthSquared() > 0 && seedNormal.LengthSquared() > 0 && n.Dot(seedNormal) < minDot {
+						continue
+					}
+
+					chartOf[neighbor] = chart
+					faces = append(faces, neighbor)
+					queue = append(queue, neighbor)
+				}
+			}
+		}
+
+		charts = append(charts, faces)
+	}
+
+	return charts
+}
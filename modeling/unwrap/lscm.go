@@ -0,0 +1,232 @@
+package unwrap
+
+import (
+	"math"
+
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// sparseRow is a single row of a sparse matrix
+type sparseRow struct {
+	cols []int
+	vals []float64
+}
+
+// projectToPlane flattens the points onto the plane that best faces the
+// area weighted normal of the triangles provided
+func projectToPlane(positions []vector3.Float64, triangles []int) []vector2.Float64 {
+	normal := vector3.Zero[float64]()
+	for i := 0; i < len(triangles); i += 3 {
+		a := positions[triangles[i]]
+		b := positions[triangles[i+1]]
+		c := positions[triangles[i+2]]
+		normal = normal.Add(b.Sub(a).Cross(c.Sub(a)))
+	}
+
+	if normal.LengthSquared() == 0 {
+		normal = vector3.Forward[float64]()
+	}
+	normal = normal.Normalized()
+
+	uAxis := normal.Perpendicular().Normalized()
+	vAxis := normal.Cross(uAxis)
+
+	projected := make([]vector2.Float64, len(positions))
+	for i, p := range positions {
+		projected[i] = vector2.New(p.Dot(uAxis), p.Dot(vAxis))
+	}
+	return projected
+}
+
+// lscm flattens a chart using least squares conformal maps, which finds the
+// UV layout that best preserves the angles of each triangle. Two vertices are
+// pinned to their position projected onto the chart's plane to remove the
+// remaining translation, rotation and scale degrees of freedom.
+func lscm(positions []vector3.Float64, triangles []int) []vector2.Float64 {
+	uvs := projectToPlane(positions, triangles)
+	if len(positions) < 3 {
+		return uvs
+	}
+
+	// Pin the two vertices furthest apart along the chart's largest extent
+	minBounds, maxBounds := uvs[0], uvs[0]
+	for _, uv := range uvs {
+		minBounds = vector2.New(math.Min(minBounds.X(), uv.X()), math.Min(minBounds.Y(), uv.Y()))
+		maxBounds = vector2.New(math.Max(maxBounds.X(), uv.X()), math.Max(maxBounds.Y(), uv.Y()))
+	}
+	size := maxBounds.Sub(minBounds)
+	axis := 0
+	if size.Y() > size.X() {
+		axis = 1
+	}
+
+	pinA, pinB := 0, 0
+	for i, uv := range uvs {
+		if uv.Component(axis) < uvs[pinA].Component(axis) {
+			pinA = i
+		}
+		if uv.Component(axis) > uvs[pinB].Component(axis) {
+			pinB = i
+		}
+	}
+	if pinA == pinB {
+		return uvs
+	}
+
+	// Map each vertex's u and v to a column of the system, with pinned
+	// vertices left out
+	columns := make([]int, len(positions))
+	free := 0
+	for i := range positions {
+		if i == pinA || i == pinB {
+			columns[i] = -1
+			continue
+		}
+		columns[i] = free
+		free++
+	}
+
+	// Each triangle contributes the real and imaginary parts of the
+	// Cauchy-Riemann equations, expressed in the triangle's own frame
+	rows := make([]sparseRow, 0, len(triangles)/3*2)
+	rhs := make([]float64, 0, len(triangles)/3*2)
+	for t := 0; t < len(triangles); t += 3 {
+		p0 := positions[triangles[t]]
+		p1 := positions[triangles[t+1]]
+		p2 := positions[triangles[t+2]]
+
+		e1 := p1.Sub(p0)
+		e2 := p2.Sub(p0)
+		e1Length := e1.Length()
+		if e1Length == 0 {
+			continue
+		}
+
+		xAxis := e1.Scale(1. / e1Length)
+		x := [3]float64{0, e1Length, e2.Dot(xAxis)}
+		y := [3]float64{0, 0, e1.Cross(e2).Length() / e1Length}
+		doubleArea := x[1] * y[2]
+		if doubleArea <= 1e-12 {
+			continue
+		}
+		weight := 1. / math.Sqrt(doubleArea)
+
+		real := sparseRow{}
+		imaginary := sparseRow{}
+		realRHS, imaginaryRHS := 0., 0.
+		for j := 0; j < 3; j++ {
+			k, l := (j+1)%3, (j+2)%3
+			a := (y[k] - y[l]) * weight
+			b := (x[l] - x[k]) * weight
+
+			v := triangles[t+j]
+			col := columns[v]
+			if col == -1 {
+				u := uvs[v]
+				realRHS -= a*u.X() - b*u.Y()
+				imaginaryRHS -= b*u.X() + a*u.Y()
+				continue
+			}
+
+			real.cols = append(real.cols, col*2, col*2+1)
+			real.vals = append(real.vals, a, -b)
+			imaginary.cols = append(imaginary.cols, col*2, col*2+1)
+			imaginary.vals = append(imaginary.vals, b, a)
+		}
+
+		rows = append(rows, real, imaginary)
+		rhs = append(rhs, realRHS, imaginaryRHS)
+	}
+
+	if len(rows) == 0 {
+		return uvs
+	}
+
+	// Start from the projection, which is already close for the nearly flat
+	// charts we build
+	solution := make([]float64, free*2)
+	for i, col := range columns {
+		if col != -1 {
+			solution[col*2] = uvs[i].X()
+			solution[col*2+1] = uvs[i].Y()
+		}
+	}
+
+	solveLeastSquares(rows, rhs, solution, max(200, free*4))
+
+	for i, col := range columns {
+		if col != -1 {
+			uvs[i] = vector2.New(solution[col*2], solution[col*2+1])
+		}
+	}
+	return uvs
+}
+
+// solveLeastSquares minimizes |Ax - b| using conjugate gradient on the normal
+// equations AᵀAx = Aᵀb, starting from the x provided
+func solveLeastSquares(rows []sparseRow, b, x []float64, iterations int) {
+	n := len(x)
+
+	mul := func(v []float64) []float64 {
+		out := make([]float64, len(rows))
+		for r, row := range rows {
+			sum := 0.
+			for i, col := range row.cols {
+				sum += row.vals[i] * v[col]
+			}
+			out[r] = sum
+		}
+		return out
+	}
+
+	mulTranspose := func(v []float64) []float64 {
+		out := make([]float64, n)
+		for r, row := range rows {
+			for i, col := range row.cols {
+				out[col] += row.vals[i] * v[r]
+			}
+		}
+		return out
+	}
+
+	dot := func(a, b []float64) float64 {
+		sum := 0.
+		for i := range a {
+			sum += a[i] * b[i]
+		}
+		return sum
+	}
+
+	// r = Aᵀ(b - Ax)
+	ax := mul(x)
+	residual := make([]float64, len(rows))
+	for i := range residual {
+		residual[i] = b[i] - ax[i]
+	}
+	r := mulTranspose(residual)
+	p := append([]float64(nil), r...)
+	rr := dot(r, r)
+
+	tolerance := 1e-20 * math.Max(1, dot(mulTranspose(b), mulTranspose(b)))
+	for i := 0; i < iterations && rr > tolerance; i++ {
+		ap := mulTranspose(mul(p))
+		pap := dot(p, ap)
+		if pap <= 0 {
+			return
+		}
+
+		alpha := rr / pap
+		for j := range x {
+			x[j] += alpha * p[j]
+			r[j] -= alpha * ap[j]
+		}
+
+		next := dot(r, r)
+		beta := next / rr
+		rr = next
+		for j := range p {
+			p[j] = r[j] + beta*p[j]
+		}
+	}
+}
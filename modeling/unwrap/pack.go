@@ -0,0 +1,96 @@
+package unwrap
+
+import (
+	"math"
+	"sort"
+
+	"github.com/EliCDavis/vector/vector2"
+)
+
+// shelfLayout places the rectangles left to right along rows of the width
+// provided, starting a new row whenever the current one runs out of room.
+// Returns the bottom left corner of each rectangle along with the total
+// height used.
+func shelfLayout(sizes []vector2.Float64, order []int, width, padding float64) ([]vector2.Float64, float64) {
+	offsets := make([]vector2.Float64, len(sizes))
+
+	x, y := padding, padding
+	rowHeight := 0.
+	for _, i := range order {
+		size := sizes[i]
+		if x > padding && x+size.X()+padding > width {
+			x = padding
+			y += rowHeight + padding
+			rowHeight = 0
+		}
+
+		offsets[i] = vector2.New(x, y)
+		x += size.X() + padding
+		rowHeight = math.Max(rowHeight, size.Y())
+	}
+
+	return offsets, y + rowHeight + padding
+}
+
+// Pack lays out rectangles of the sizes provided within the unit square
+// without overlap. All rectangles are uniformly scaled by the same amount so
+// they fit, keeping at least padding distance between any two rectangles and
+// between each rectangle and the edge of the square. Returns the bottom left
+// corner of each scaled rectangle, along with the scale applied.
+func Pack(sizes []vector2.Float64, padding float64) ([]vector2.Float64, float64) {
+	if len(sizes) == 0 {
+		return nil, 1
+	}
+
+	// Tallest rectangles first so each row wastes as little space as possible
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return sizes[order[i]].Y() > sizes[order[j]].Y()
+	})
+
+	area := 0.
+	widest := 0.
+	for _, size := range sizes {
+		area += size.X() * size.Y()
+		widest = math.Max(widest, size.X())
+	}
+
+	// Padding is specified in the final unit square, which depends on how
+	// big the layout turns out to be, so we converge on it over a few
+	// iterations
+	side := math.Sqrt(area)
+	var offsets []vector2.Float64
+	for iteration := 0; iteration < 32; iteration++ {
+		layoutPadding := padding * side
+
+		width := math.Max(widest+layoutPadding*2, math.Sqrt(area)+layoutPadding*math.Sqrt(float64(len(sizes))))
+		var height float64
+		for attempt := 0; attempt < 100; attempt++ {
+			offsets, height = shelfLayout(sizes, order, width, layoutPadding)
+			if height <= width {
+				break
+			}
+			width *= 1.05
+		}
+
+		next := math.Max(width, height)
+		if math.Abs(next-side) <= side*1e-9 {
+			side = next
+			break
+		}
+		side = next
+	}
+
+	if side <= 0 {
+		return make([]vector2.Float64, len(sizes)), 1
+	}
+
+	scale := 1. / side
+	for i := range offsets {
+		offsets[i] = offsets[i].Scale(scale)
+	}
+	return offsets, scale
+}
@@ -0,0 +1,14 @@
+package unwrap
+
+import (
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/refutil"
+)
+
+func init() {
+	factory := &refutil.TypeFactory{}
+
+	refutil.RegisterType[Node](factory)
+
+	generator.RegisterTypes(factory)
+}
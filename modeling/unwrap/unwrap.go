@@ -0,0 +1,230 @@
+package unwrap
+
+import (
+	"math"
+
+	"github.com/EliCDavis/iter"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// DefaultMaxChartAngle is the furthest, in radians, a face's normal may turn
+// away from the normal of the face that started it's chart
+const DefaultMaxChartAngle = math.Pi / 3
+
+type Transformer struct {
+	// Attribute to use as the position of each vertex. Defaults to
+	// modeling.PositionAttribute
+	Attribute string
+
+	// Furthest, in radians, a face's normal may turn away from the first
+	// face of it's chart. Values less than or equal to 0 use
+	// DefaultMaxChartAngle
+	MaxChartAngle float64
+
+	// Space left between charts and around the edge of UV space
+	Padding float64
+}
+
+func (t Transformer) Transform(m modeling.Mesh) (results modeling.Mesh, err error) {
+	attribute := t.Attribute
+	if attribute == "" {
+		attribute = modeling.PositionAttribute
+	}
+
+	if err = meshops.RequireTopology(m, modeling.TriangleTopology); err != nil {
+		return
+	}
+
+	if err = meshops.RequireV3Attribute(m, attribute); err != nil {
+		return
+	}
+
+	maxAngle := t.MaxChartAngle
+	if maxAngle <= 0 {
+		maxAngle = DefaultMaxChartAngle
+	}
+
+	return Unwrap(m, attribute, maxAngle, t.Padding), nil
+}
+
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func gather[T any](data map[string][]T, sources []int) map[string][]T {
+	out := make(map[string][]T, len(data))
+	for attr, values := range data {
+		gathered := make([]T, len(sources))
+		for i, source := range sources {
+			gathered[i] = values[source]
+		}
+		out[attr] = gathered
+	}
+	return out
+}
+
+func readAll[T any](attrs []string, reader func(string) *iter.ArrayIterator[T]) map[string][]T {
+	data := make(map[string][]T)
+	for _, attr := range attrs {
+		data[attr] = iter.ReadFull(reader(attr))
+	}
+	return data
+}
+
+// Unwrap builds a new UV layout for the mesh, written to the TexCoord
+// attribute. The mesh is cut into charts of faces facing roughly the same
+// direction, each chart is flattened with least squares conformal maps, and
+// the charts are then packed into 0-1 UV space. Vertices along the seams
+// between charts are duplicated so each chart has it's own, and any existing
+// texture coordinates are replaced.
+func Unwrap(m modeling.Mesh, attribute string, maxChartAngle, padding float64) modeling.Mesh {
+	check(meshops.RequireTopology(m, modeling.TriangleTopology))
+	check(meshops.RequireV3Attribute(m, attribute))
+
+	positions := iter.ReadFull(m.Float3Attribute(attribute))
+	indices := iter.ReadFull(m.Indices())
+	ids := weldedIDs(positions)
+	charts := buildCharts(positions, indices, maxChartAngle)
+
+	// Flatten each chart in terms of welded vertices, so seams that already
+	// exist in the mesh don't tear the chart apart
+	chartUVs := make([]map[int]vector2.Float64, len(charts))
+	sizes := make([]vector2.Float64, len(charts))
+	for c, faces := range charts {
+		local := make(map[int]int)
+		chartPositions := make([]vector3.Float64, 0)
+		triangles := make([]int, 0, len(faces)*3)
+		for _, face := range faces {
+			for i := 0; i < 3; i++ {
+				id := ids[indices[face*3+i]]
+				index, ok := local[id]
+				if !ok {
+					index = len(chartPositions)
+					local[id] = index
+					chartPositions = append(chartPositions, positions[id])
+				}
+				triangles = append(triangles, index)
+			}
+		}
+
+		uvs := normalizeChart(chartPositions, triangles, lscm(chartPositions, triangles))
+
+		chartUVs[c] = make(map[int]vector2.Float64, len(local))
+		for id, index := range local {
+			chartUVs[c][id] = uvs[index]
+			sizes[c] = vector2.New(math.Max(sizes[c].X(), uvs[index].X()), math.Max(sizes[c].Y(), uvs[index].Y()))
+		}
+	}
+
+	offsets, scale := Pack(sizes, padding)
+
+	// Each vertex gets a copy for every chart it's found in
+	type chartVertex struct {
+		vertex, chart int
+	}
+	remapped := make(map[chartVertex]int)
+	sources := make([]int, 0, m.AttributeLength())
+	texCoords := make([]vector2.Float64, 0, m.AttributeLength())
+	newIndices := make([]int, len(indices))
+	for c, faces := range charts {
+		for _, face := range faces {
+			for i := 0; i < 3; i++ {
+				v := indices[face*3+i]
+				key := chartVertex{vertex: v, chart: c}
+				index, ok := remapped[key]
+				if !ok {
+					index = len(sources)
+					remapped[key] = index
+					sources = append(sources, v)
+					texCoords = append(texCoords, chartUVs[c][ids[v]].Scale(scale).Add(offsets[c]))
+				}
+				newIndices[face*3+i] = index
+			}
+		}
+	}
+
+	v2Data := gather(readAll(m.Float2Attributes(), m.Float2Attribute), sources)
+	v2Data[modeling.TexCoordAttribute] = texCoords
+
+	return modeling.NewTriangleMesh(newIndices).
+		SetFloat4Data(gather(readAll(m.Float4Attributes(), m.Float4Attribute), sources)).
+		SetFloat3Data(gather(readAll(m.Float3Attributes(), m.Float3Attribute), sources)).
+		SetFloat2Data(v2Data).
+		SetFloat1Data(gather(readAll(m.Float1Attributes(), m.Float1Attribute), sources)).
+		SetMaterials(m.Materials())
+}
+
+// normalizeChart scales the chart so it's UV area matches it's surface area,
+// keeping texel density consistent across charts, and moves it so it's
+// bounds start at the origin
+func normalizeChart(positions []vector3.Float64, triangles []int, uvs []vector2.Float64) []vector2.Float64 {
+	surfaceArea := 0.
+	uvArea := 0.
+	for t := 0; t < len(triangles); t += 3 {
+		a, b, c := triangles[t], triangles[t+1], triangles[t+2]
+		surfaceArea += positions[b].Sub(positions[a]).Cross(positions[c].Sub(positions[a])).Length() / 2
+		uvArea += cross2D(uvs[b].Sub(uvs[a]), uvs[c].Sub(uvs[a])) / 2
+	}
+
+	// Conformal maps are free to come out mirrored, which we undo so UVs
+	// wind the same direction as the faces they belong to
+	flip := 1.
+	if uvArea < 0 {
+		flip = -1
+		uvArea = -uvArea
+	}
+
+	scale := 1.
+	if uvArea > 0 && surfaceArea > 0 {
+		scale = math.Sqrt(surfaceArea / uvArea)
+	}
+
+	out := make([]vector2.Float64, len(uvs))
+	minBounds := vector2.New(math.Inf(1), math.Inf(1))
+	for i, uv := range uvs {
+		out[i] = vector2.New(uv.X()*flip, uv.Y()).Scale(scale)
+		minBounds = vector2.New(math.Min(minBounds.X(), out[i].X()), math.Min(minBounds.Y(), out[i].Y()))
+	}
+
+	for i := range out {
+		out[i] = out[i].Sub(minBounds)
+	}
+	return out
+}
+
+func cross2D(a, b vector2.Float64) float64 {
+	return a.X()*b.Y() - a.Y()*b.X()
+}
+
+// ============================================================================
+
+type Node = nodes.Struct[modeling.Mesh, NodeData]
+
+type NodeData struct {
+	Mesh          nodes.NodeOutput[modeling.Mesh]
+	Attribute     nodes.NodeOutput[string]
+	MaxChartAngle nodes.NodeOutput[float64]
+	Padding       nodes.NodeOutput[float64]
+}
+
+func (NodeData) Description() string {
+	return "Generates texture coordinates for the mesh by cutting it into charts of similarly facing faces, flattening each chart with least squares conformal maps, and packing the charts into 0-1 UV space. MaxChartAngle is in radians."
+}
+
+func (nd NodeData) Process() (modeling.Mesh, error) {
+	if nd.Mesh == nil {
+		return modeling.EmptyMesh(modeling.TriangleTopology), nil
+	}
+
+	return Transformer{
+		Attribute:     nodes.TryGetOutputValue(nd.Attribute, modeling.PositionAttribute),
+		MaxChartAngle: nodes.TryGetOutputValue(nd.MaxChartAngle, DefaultMaxChartAngle),
+		Padding:       nodes.TryGetOutputValue(nd.Padding, 0.),
+	}.Transform(nd.Mesh.Value())
+}
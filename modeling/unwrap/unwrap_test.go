@@ -0,0 +1,136 @@
+package unwrap_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/primitives"
+	"github.com/EliCDavis/polyform/modeling/unwrap"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/vector/vector2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func uvArea(a, b, c vector2.Float64) float64 {
+	ab := b.Sub(a)
+	ac := c.Sub(a)
+	return (ab.X()*ac.Y() - ab.Y()*ac.X()) / 2
+}
+
+// assertValidUVs checks all texture coordinates land within 0-1 space with
+// the padding provided, that no triangle is flipped, and that texel density
+// stays within the spread provided across the mesh. Conformal maps of curved
+// surfaces trade area for angles, so some spread is expected.
+func assertValidUVs(t *testing.T, m modeling.Mesh, padding, maxDensitySpread float64) {
+	t.Helper()
+	require.True(t, m.HasFloat2Attribute(modeling.TexCoordAttribute))
+
+	uvs := m.Float2Attribute(modeling.TexCoordAttribute)
+	for i := 0; i < uvs.Len(); i++ {
+		uv := uvs.At(i)
+		assert.GreaterOrEqual(t, uv.X(), padding-1e-6)
+		assert.GreaterOrEqual(t, uv.Y(), padding-1e-6)
+		assert.LessOrEqual(t, uv.X(), 1-padding+1e-6)
+		assert.LessOrEqual(t, uv.Y(), 1-padding+1e-6)
+	}
+
+	minDensity, maxDensity := math.Inf(1), 0.
+	for i := 0; i < m.PrimitiveCount(); i++ {
+		tri := m.Tri(i)
+		area := uvArea(uvs.At(tri.P1()), uvs.At(tri.P2()), uvs.At(tri.P3()))
+		assert.Greater(t, area, 0.)
+
+		density := area / tri.Area3D(modeling.PositionAttribute)
+		minDensity = math.Min(minDensity, density)
+		maxDensity = math.Max(maxDensity, density)
+	}
+	assert.LessOrEqual(t, maxDensity/minDensity, maxDensitySpread)
+}
+
+func TestUnwrap_Cube(t *testing.T) {
+	m := primitives.UnitCube().SetMaterial(modeling.Material{Name: "cube"})
+
+	out, err := unwrap.Transformer{Padding: 0.01}.Transform(m)
+	require.NoError(t, err)
+
+	assert.Equal(t, m.PrimitiveCount(), out.PrimitiveCount())
+	assert.Equal(t, 24, out.AttributeLength())
+	require.Len(t, out.Materials(), 1)
+	assert.Equal(t, "cube", out.Materials()[0].Material.Name)
+	assertValidUVs(t, out, 0.01, 1.000001)
+
+	// Each side is flat, so the unwrap should perfectly preserve it's shape
+	uvs := out.Float2Attribute(modeling.TexCoordAttribute)
+	for i := 0; i < out.PrimitiveCount(); i++ {
+		tri := out.Tri(i)
+		a := uvs.At(tri.P1())
+		b := uvs.At(tri.P2())
+		c := uvs.At(tri.P3())
+
+		pa := tri.P1Vec3Attr(modeling.PositionAttribute)
+		pb := tri.P2Vec3Attr(modeling.PositionAttribute)
+		pc := tri.P3Vec3Attr(modeling.PositionAttribute)
+
+		scale := a.Distance(b) / pa.Distance(pb)
+		assert.InDelta(t, scale, a.Distance(c)/pa.Distance(pc), 1e-6)
+		assert.InDelta(t, scale, b.Distance(c)/pb.Distance(pc), 1e-6)
+	}
+}
+
+func TestUnwrap_Sphere(t *testing.T) {
+	m := primitives.UVSphere(1, 16, 16)
+
+	out := unwrap.Unwrap(m, modeling.PositionAttribute, unwrap.DefaultMaxChartAngle, 0.005)
+	assert.Equal(t, m.PrimitiveCount(), out.PrimitiveCount())
+	assert.GreaterOrEqual(t, out.AttributeLength(), m.AttributeLength())
+	assertValidUVs(t, out, 0.005, 3)
+}
+
+func TestUnwrap_RequiresTriangles(t *testing.T) {
+	_, err := unwrap.Transformer{}.Transform(modeling.NewPointCloud(nil, nil, nil, nil, nil))
+	assert.Error(t, err)
+}
+
+func TestPack(t *testing.T) {
+	sizes := []vector2.Float64{
+		vector2.New(1., 1.),
+		vector2.New(2., 0.5),
+		vector2.New(0.25, 3.),
+		vector2.New(0.5, 0.5),
+		vector2.New(1.5, 1.),
+	}
+	padding := 0.02
+
+	offsets, scale := unwrap.Pack(sizes, padding)
+	require.Len(t, offsets, len(sizes))
+
+	type rect struct{ min, max vector2.Float64 }
+	rects := make([]rect, len(sizes))
+	for i, size := range sizes {
+		rects[i] = rect{min: offsets[i], max: offsets[i].Add(size.Scale(scale))}
+		assert.GreaterOrEqual(t, rects[i].min.X(), padding-1e-6)
+		assert.GreaterOrEqual(t, rects[i].min.Y(), padding-1e-6)
+		assert.LessOrEqual(t, rects[i].max.X(), 1-padding+1e-6)
+		assert.LessOrEqual(t, rects[i].max.Y(), 1-padding+1e-6)
+	}
+
+	for i := range rects {
+		for j := i + 1; j < len(rects); j++ {
+			gapX := math.Max(rects[j].min.X()-rects[i].max.X(), rects[i].min.X()-rects[j].max.X())
+			gapY := math.Max(rects[j].min.Y()-rects[i].max.Y(), rects[i].min.Y()-rects[j].max.Y())
+			assert.GreaterOrEqual(t, math.Max(gapX, gapY), padding-1e-6, "rects %d and %d", i, j)
+		}
+	}
+}
+
+func TestNode(t *testing.T) {
+	node := &unwrap.Node{
+		Data: unwrap.NodeData{
+			Mesh:    nodes.Value(primitives.UnitCube()),
+			Padding: nodes.Value(0.01),
+		},
+	}
+	assertValidUVs(t, node.Out().Value(), 0.01, 1.000001)
+}
@@ -50,6 +50,14 @@ func (s StateData) State() NodeState {
 	return s.state
 }
 
+// Errored ====================================================================
+
+type Errored interface {
+	// Err is the error encountered the last time the node was processed, nil
+	// if it processed successfully
+	Err() error
+}
+
 // Subscription ===============================================================
 
 type Subscribable interface {
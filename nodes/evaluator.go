@@ -0,0 +1,189 @@
+package nodes
+
+import (
+	"context"
+	"runtime"
+	"time"
+)
+
+// evaluatable nodes can be processed in isolation, expecting all of their
+// dependencies to have been processed beforehand
+type evaluatable interface {
+	anyValued
+	evaluate()
+
+	// restore sets the node's value as if it had been processed, returning
+	// false if the value isn't of the type the node produces
+	restore(value any) bool
+}
+
+// OutputCache stores the values nodes process to, so they can be restored
+// rather than processed the next time they're needed. Caches are free to
+// ignore values they don't know how to store.
+type OutputCache interface {
+	Load(node Node) (any, bool)
+	Store(node Node, value any)
+}
+
+// Composite nodes are built from a graph of nodes of their own, like
+// subgraphs. The Evaluator processes the composite's graph in its place.
+type Composite interface {
+	Node
+
+	// Roots are the nodes within the composite its outputs come from
+	Roots() []Node
+}
+
+// Evaluator processes the nodes of a graph, working through nodes that don't
+// depend on one another in parallel.
+type Evaluator struct {
+	// Maximum number of nodes processed at once. Defaults to the number of
+	// CPUs when less than 1
+	Workers int
+
+	// Optional cache nodes are restored from in place of processing
+	Cache OutputCache
+}
+
+func (e Evaluator) workers() int {
+	if e.Workers < 1 {
+		return runtime.NumCPU()
+	}
+	return e.Workers
+}
+
+// Evaluate processes every out of date node the roots depend on, along with
+// the roots themselves, returning how long each node processed took. A node
+// is only processed once all of its dependencies have been.
+//
+// Nodes failing to process don't stop the evaluation, as nodes depending on
+// them simply fail alongside them. Their errors are available through
+// Errored. Cancelling the context stops any new nodes from being processed,
+// and the context's error is returned once the nodes already being processed
+// finish.
+func (e Evaluator) Evaluate(ctx context.Context, roots ...Node) (map[Node]time.Duration, error) {
+	// Number of dependencies each node is still waiting on
+	pending := make(map[Node]int)
+	dependents := make(map[Node][]Node)
+
+	var visit func(node Node)
+	visit = func(node Node) {
+		if _, ok := pending[node]; ok {
+			return
+		}
+
+		deps := uniqueDependencies(node)
+		pending[node] = len(deps)
+		for _, dep := range deps {
+			dependents[dep] = append(dependents[dep], node)
+			visit(dep)
+		}
+	}
+
+	for _, root := range roots {
+		visit(root)
+	}
+
+	ready := make([]Node, 0)
+	for node, count := range pending {
+		if count == 0 {
+			ready = append(ready, node)
+		}
+	}
+
+	type result struct {
+		node      Node
+		duration  time.Duration
+		processed bool
+	}
+
+	results := make(chan result)
+	timings := make(map[Node]time.Duration)
+	workers := e.workers()
+	running := 0
+	var err error
+
+	for {
+		for err == nil && running < workers && len(ready) > 0 {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+
+			node := ready[len(ready)-1]
+			ready = ready[:len(ready)-1]
+			running++
+			go func() {
+				duration, processed := e.evaluateNode(ctx, node)
+				results <- result{node: node, duration: duration, processed: processed}
+			}()
+		}
+
+		if running == 0 {
+			break
+		}
+
+		r := <-results
+		running--
+		if r.processed {
+			timings[r.node] = r.duration
+		}
+
+		for _, dependent := range dependents[r.node] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	return timings, err
+}
+
+func (e Evaluator) evaluateNode(ctx context.Context, node Node) (time.Duration, bool) {
+	if composite, ok := node.(Composite); ok {
+		if node.State() != Stale {
+			return 0, false
+		}
+
+		// Cancellation is picked up by the evaluation we're a part of
+		start := time.Now()
+		e.Evaluate(ctx, composite.Roots()...)
+		return time.Since(start), true
+	}
+
+	evaluated, ok := node.(evaluatable)
+	if !ok || node.State() != Stale {
+		return 0, false
+	}
+
+	start := time.Now()
+	if e.Cache != nil {
+		if value, ok := e.Cache.Load(node); ok && evaluated.restore(value) {
+			return time.Since(start), true
+		}
+	}
+
+	evaluated.evaluate()
+	duration := time.Since(start)
+
+	if e.Cache != nil && node.State() == Processed {
+		e.Cache.Store(node, evaluated.anyValue())
+	}
+	return duration, true
+}
+
+// uniqueDependencies lists the nodes the node depends on, only once even if
+// the node depends on multiple outputs of the same node
+func uniqueDependencies(node Node) []Node {
+	seen := make(map[Node]struct{})
+	deps := make([]Node, 0)
+	for _, dep := range node.Dependencies() {
+		n := dep.Dependency()
+		if _, ok := seen[n]; ok {
+			continue
+		}
+		seen[n] = struct{}{}
+		deps = append(deps, n)
+	}
+	return deps
+}
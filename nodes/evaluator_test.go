@@ -0,0 +1,77 @@
+package nodes_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type BarrierNode = nodes.Struct[float64, BarrierData]
+
+// BarrierData only finishes processing once every node sharing the wait group
+// has started processing, which can only happen if they run in parallel
+type BarrierData struct {
+	In      nodes.NodeOutput[float64]
+	Barrier *sync.WaitGroup
+}
+
+func (bd BarrierData) Process() (float64, error) {
+	bd.Barrier.Done()
+
+	done := make(chan struct{})
+	go func() {
+		bd.Barrier.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return bd.In.Value(), nil
+	case <-time.After(5 * time.Second):
+		return 0, errors.New("nodes were not processed in parallel")
+	}
+}
+
+func TestEvaluator_Parallel(t *testing.T) {
+	barrier := &sync.WaitGroup{}
+	barrier.Add(2)
+	a := &BarrierNode{Data: BarrierData{In: nodes.Value(1.), Barrier: barrier}}
+	b := &BarrierNode{Data: BarrierData{In: nodes.Value(2.), Barrier: barrier}}
+	sum := &DivideNode{Data: DivideData{A: a.Out(), B: b.Out()}}
+
+	timings, err := nodes.Evaluator{Workers: 2}.Evaluate(context.Background(), sum)
+	require.NoError(t, err)
+
+	assert.NoError(t, a.Err())
+	assert.NoError(t, b.Err())
+	assert.Equal(t, nodes.Processed, sum.State())
+	assert.Equal(t, 0.5, sum.Out().Value())
+
+	// Only nodes that need processing are timed
+	assert.Len(t, timings, 3)
+	assert.Contains(t, timings, nodes.Node(a))
+	assert.Contains(t, timings, nodes.Node(b))
+	assert.Contains(t, timings, nodes.Node(sum))
+
+	timings, err = nodes.Evaluator{}.Evaluate(context.Background(), sum)
+	require.NoError(t, err)
+	assert.Empty(t, timings)
+}
+
+func TestEvaluator_Cancelled(t *testing.T) {
+	divide := &DivideNode{Data: DivideData{A: nodes.Value(1.), B: nodes.Value(2.)}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	timings, err := nodes.Evaluator{}.Evaluate(ctx, divide)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, timings)
+	assert.Equal(t, nodes.Stale, divide.State())
+}
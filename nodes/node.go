@@ -1,5 +1,7 @@
 package nodes
 
+import "fmt"
+
 type NodeState int
 
 const (
@@ -17,3 +19,22 @@ type Node interface {
 	Outputs() []Output
 	Inputs() []Input
 }
+
+// NodeError is the error of a node that failed to process. Nodes depending on
+// a failed node fail with the same NodeError, so it always refers back to the
+// node where things first went wrong.
+type NodeError struct {
+	Node Node
+	Err  error
+}
+
+func (ne *NodeError) Error() string {
+	if named, ok := ne.Node.(Named); ok {
+		return fmt.Sprintf("%s: %s", named.Name(), ne.Err.Error())
+	}
+	return ne.Err.Error()
+}
+
+func (ne *NodeError) Unwrap() error {
+	return ne.Err
+}
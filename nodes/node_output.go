@@ -1,5 +1,11 @@
 package nodes
 
+import (
+	"fmt"
+
+	"github.com/EliCDavis/polyform/refutil"
+)
+
 type NodeOutput[T any] interface {
 	NodeOutputReference
 	Value() T
@@ -15,3 +21,28 @@ func TryGetOutputValue[T any](output NodeOutput[T], fallback T) T {
 	}
 	return output.Value()
 }
+
+// GetOutput finds the node's output with the port name provided
+func GetOutput(node Node, port string) (Output, error) {
+	for _, output := range node.Outputs() {
+		if output.NodeOutput.Port() == port {
+			return output, nil
+		}
+	}
+	return Output{}, fmt.Errorf("node has no output named %q", port)
+}
+
+// GetNodeOutputPort returns the node's output with the port name provided,
+// panicking if no output by that name produces T
+func GetNodeOutputPort[T any](node Node, port string) NodeOutput[T] {
+	output, err := GetOutput(node, port)
+	if err != nil {
+		panic(err)
+	}
+
+	typed, ok := output.NodeOutput.(NodeOutput[T])
+	if !ok {
+		panic(fmt.Errorf("output %q produces %s, not %s", port, output.Type, refutil.GetTypeWithPackage(new(T))))
+	}
+	return typed
+}
@@ -1,6 +1,7 @@
 package nodes_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/EliCDavis/polyform/modeling"
@@ -8,6 +9,7 @@ import (
 	"github.com/EliCDavis/polyform/modeling/repeat"
 	"github.com/EliCDavis/polyform/nodes"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type CombineNode = nodes.Struct[modeling.Mesh, CombineData]
@@ -104,3 +106,123 @@ func TestNodes(t *testing.T) {
 	assert.Equal(t, "modeling/repeat", repeated.Path())
 	// obj.Save("test.obj", repeat.Value())
 }
+
+type DivideNode = nodes.Struct[float64, DivideData]
+
+type DivideData struct {
+	A nodes.NodeOutput[float64]
+	B nodes.NodeOutput[float64]
+}
+
+func (dd DivideData) Process() (float64, error) {
+	if dd.B.Value() == 0 {
+		return 0, errors.New("divide by zero")
+	}
+	return dd.A.Value() / dd.B.Value(), nil
+}
+
+func TestStruct_ErrorPropagation(t *testing.T) {
+	b := nodes.Value(0.)
+	divide := &DivideNode{
+		Data: DivideData{
+			A: nodes.Value(1.),
+			B: b,
+		},
+	}
+	downstream := &DivideNode{
+		Data: DivideData{
+			A: divide.Out(),
+			B: nodes.Value(2.),
+		},
+	}
+
+	assert.Equal(t, nodes.Stale, downstream.State())
+	assert.Equal(t, 0., downstream.Out().Value())
+	assert.Equal(t, nodes.Error, divide.State())
+	assert.Equal(t, nodes.Error, downstream.State())
+
+	// Downstream nodes fail with the error of the node that caused it
+	var nodeErr *nodes.NodeError
+	assert.ErrorAs(t, downstream.Err(), &nodeErr)
+	assert.Same(t, divide, nodeErr.Node)
+	assert.EqualError(t, downstream.Err(), "DivideData: divide by zero")
+
+	b.Set(4)
+	assert.Equal(t, nodes.Stale, downstream.State())
+	assert.Equal(t, 0.125, downstream.Out().Value())
+	assert.NoError(t, downstream.Err())
+	assert.Equal(t, nodes.Processed, divide.State())
+	assert.Equal(t, nodes.Processed, downstream.State())
+}
+
+type DivModNode = nodes.Struct[DivModOutputs, DivModData]
+
+type DivModData struct {
+	A nodes.NodeOutput[int]
+	B nodes.NodeOutput[int]
+}
+
+type DivModOutputs struct {
+	Quotient  nodes.Port[int]
+	Remainder nodes.Port[int]
+	ignored   nodes.Port[int]
+}
+
+func (dmd DivModData) Process() (DivModOutputs, error) {
+	a, b := dmd.A.Value(), dmd.B.Value()
+	return DivModOutputs{
+		Quotient:  nodes.NewPort(a / b),
+		Remainder: nodes.NewPort(a % b),
+	}, nil
+}
+
+type SumNode = nodes.Struct[int, SumData]
+
+type SumData struct {
+	Values []nodes.NodeOutput[int]
+}
+
+func (sd SumData) Process() (int, error) {
+	total := 0
+	for _, v := range sd.Values {
+		total += v.Value()
+	}
+	return total, nil
+}
+
+func TestStruct_MultipleOutputs(t *testing.T) {
+	a := nodes.Value(17)
+	divMod := &DivModNode{
+		Data: DivModData{
+			A: a,
+			B: nodes.Value(5),
+		},
+	}
+
+	outputs := divMod.Outputs()
+	require.Len(t, outputs, 2)
+	assert.Equal(t, "Quotient", outputs[0].NodeOutput.Port())
+	assert.Equal(t, "int", outputs[0].Type)
+	assert.Equal(t, "Remainder", outputs[1].NodeOutput.Port())
+	assert.Same(t, divMod, outputs[1].NodeOutput.Node())
+
+	_, err := nodes.GetOutput(divMod, "Out")
+	assert.EqualError(t, err, `node has no output named "Out"`)
+	assert.Panics(t, func() {
+		nodes.GetNodeOutputPort[float64](divMod, "Quotient")
+	})
+
+	sum := &SumNode{}
+	remainder, err := nodes.GetOutput(divMod, "Remainder")
+	require.NoError(t, err)
+	sum.SetInput("Values.0", remainder)
+	sum.SetInput("Values.1", nodes.Output{NodeOutput: nodes.GetNodeOutputPort[int](divMod, "Quotient")})
+	assert.Equal(t, 5, sum.Out().Value())
+
+	a.Set(23)
+	assert.Equal(t, 7, sum.Out().Value())
+
+	// Removing an element of the array input
+	sum.SetInput("Values.0", nodes.Output{})
+	assert.Equal(t, 4, sum.Out().Value())
+}
@@ -0,0 +1,96 @@
+package nodes
+
+import (
+	"reflect"
+
+	"github.com/EliCDavis/polyform/refutil"
+)
+
+// Port holds the value of one of many outputs a node produces. Nodes that
+// process to a struct with Port fields expose each of those fields as an
+// output of their own, named after the field, in place of the single "Out"
+// output of the struct as a whole.
+//
+//	type SplitResults struct {
+//		Above nodes.Port[modeling.Mesh]
+//		Below nodes.Port[modeling.Mesh]
+//	}
+type Port[T any] struct {
+	value T
+}
+
+func NewPort[T any](value T) Port[T] {
+	return Port[T]{value: value}
+}
+
+func (p Port[T]) Value() T {
+	return p.value
+}
+
+func (p Port[T]) output(node anyValued, name string) Output {
+	return Output{
+		Type: refutil.GetTypeWithPackage(new(T)),
+		NodeOutput: PortOutput[T]{
+			node: node,
+			name: name,
+		},
+	}
+}
+
+// port lets us build the outputs of Port fields without knowing their types
+type port interface {
+	output(node anyValued, name string) Output
+}
+
+// anyValued nodes can hand out their processed value without us knowing it's
+// type
+type anyValued interface {
+	Node
+	anyValue() any
+}
+
+// PortOutput is the output of a single Port field of the struct a node
+// processes to
+type PortOutput[T any] struct {
+	node anyValued
+	name string
+}
+
+func (po PortOutput[T]) Value() T {
+	value := reflect.ValueOf(po.node.anyValue())
+	return value.FieldByName(po.name).Interface().(Port[T]).Value()
+}
+
+func (po PortOutput[T]) Node() Node {
+	return po.node
+}
+
+func (po PortOutput[T]) Port() string {
+	return po.name
+}
+
+func (po PortOutput[T]) Proxy(node Node, port string) NodeOutputReference {
+	return NewProxyOutput[T](node, port, po)
+}
+
+// portOutputs builds an output for each exported Port field of T, returning
+// nil when T isn't a struct containing any
+func portOutputs[T any](node anyValued) []Output {
+	t := reflect.TypeOf(new(T)).Elem()
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	zero := reflect.New(t).Elem()
+	var outputs []Output
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+
+		if p, ok := zero.Field(i).Interface().(port); ok {
+			outputs = append(outputs, p.output(node, t.Field(i).Name))
+		}
+	}
+	return outputs
+}
@@ -0,0 +1,45 @@
+package nodes
+
+// Proxyable outputs can be handed out as the output of a node other than the
+// one producing them, like a subgraph exposing the outputs of the nodes
+// within it
+type Proxyable interface {
+	Proxy(node Node, port string) NodeOutputReference
+}
+
+// ProxyOutput hands out the value of one node's output as an output of
+// another node
+type ProxyOutput[T any] struct {
+	node   Node
+	port   string
+	output NodeOutput[T]
+}
+
+func NewProxyOutput[T any](node Node, port string, output NodeOutput[T]) ProxyOutput[T] {
+	return ProxyOutput[T]{
+		node:   node,
+		port:   port,
+		output: output,
+	}
+}
+
+func (po ProxyOutput[T]) Value() T {
+	return po.output.Value()
+}
+
+func (po ProxyOutput[T]) Node() Node {
+	return po.node
+}
+
+func (po ProxyOutput[T]) Port() string {
+	return po.port
+}
+
+// Proxied is the output being handed out
+func (po ProxyOutput[T]) Proxied() NodeOutput[T] {
+	return po.output
+}
+
+func (po ProxyOutput[T]) Proxy(node Node, port string) NodeOutputReference {
+	return NewProxyOutput[T](node, port, po)
+}
@@ -1,7 +1,9 @@
 package nodes
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -32,6 +34,10 @@ func (sno StructOutput[T, G]) Port() string {
 	return sno.Name
 }
 
+func (sno StructOutput[T, G]) Proxy(node Node, port string) NodeOutputReference {
+	return NewProxyOutput[T](node, port, sno)
+}
+
 // ============================================================================
 
 // type IStructData[T any] interface {
@@ -69,27 +75,25 @@ type Struct[T any, G StructProcesor[T]] struct {
 }
 
 func (sn *Struct[T, G]) SetInput(input string, output Output) {
-	// HHHHACK for array types atm.
-	// I don't have enough knowledge yet for a proper refactor. I need to add
-	// more features to see where things go fucky
-	if index := strings.Index(input, "."); index != -1 {
-		inputName := input[:index]
-		if output.NodeOutput == nil {
-			index, err := strconv.Atoi(input[index+1:])
-			if err != nil {
-				panic(err)
-			}
+	sn.inputChangedSinceLastProcess = true
 
-			refutil.RemoveFromStructFieldArray(&sn.Data, inputName, index)
-		} else {
-			refutil.AddToStructFieldArray(&sn.Data, inputName, output.NodeOutput)
-		}
-		sn.inputChangedSinceLastProcess = true
+	// Elements of array inputs are addressed as "Input.N"
+	name, element, isElement := strings.Cut(input, ".")
+	if !isElement {
+		refutil.SetStructField(&sn.Data, input, output.NodeOutput)
 		return
 	}
 
-	refutil.SetStructField(&sn.Data, input, output.NodeOutput)
-	sn.inputChangedSinceLastProcess = true
+	if output.NodeOutput != nil {
+		refutil.AddToStructFieldArray(&sn.Data, name, output.NodeOutput)
+		return
+	}
+
+	index, err := strconv.Atoi(element)
+	if err != nil {
+		panic(fmt.Errorf("invalid array input %q: %w", input, err))
+	}
+	refutil.RemoveFromStructFieldArray(&sn.Data, name, index)
 }
 
 func (sn Struct[T, G]) Outdated() bool {
@@ -112,7 +116,7 @@ func (sn Struct[T, G]) Outdated() bool {
 
 	for i, nodeDep := range deps {
 		dep := nodeDep.Dependency()
-		if dep.Version() != sn.depVersions[i] || dep.State() != Processed {
+		if dep.Version() != sn.depVersions[i] || dep.State() == Stale {
 			return true
 		}
 	}
@@ -158,20 +162,10 @@ func (sn *Struct[T, G]) Out() StructOutput[T, G] {
 }
 
 func (sn *Struct[T, G]) Outputs() []Output {
-	// outputs := refutil.FuncValuesOfType[ReferencesNode](tn.Data)
-
-	// outs := make([]Output, len(outputs))
-	// var v *T = new(T)
-	// for i, o := range outputs {
-	// 	outs[i] = Output{
-	// 		Name: o,
-	// 		// Type: fmt.Sprintf("%T", *new(T)),
-	// 		Type: refutil.GetTypeWithPackage(v),
-	// 	}
-	// }
-	// return outs
-
-	// TODO: This is wrong for nodes with more than one output
+	if ports := portOutputs[T](sn); ports != nil {
+		return ports
+	}
+
 	return []Output{
 		{
 			Type: refutil.GetTypeWithPackage(new(T)),
@@ -225,14 +219,35 @@ func (sn Struct[T, G]) Dependencies() []NodeDependency {
 			})
 		}
 	}
+
+	// Fields are gathered from maps, but the order needs to stay consistent
+	// between calls to line up with the dependency versions we've recorded
+	sort.Slice(output, func(i, j int) bool {
+		return output[i].Name() < output[j].Name()
+	})
 	return output
 }
 
 func (sn *Struct[T, G]) Value() T {
+	sn.evaluate()
+	return sn.value
+}
+
+// Err returns the error encountered processing the node, processing it first
+// if it's out of date
+func (sn *Struct[T, G]) Err() error {
+	sn.evaluate()
+	return sn.err
+}
+
+func (sn *Struct[T, G]) evaluate() {
 	if sn.Outdated() {
 		sn.process()
 	}
-	return sn.value
+}
+
+func (sn *Struct[T, G]) anyValue() any {
+	return sn.Value()
 }
 
 func (sn *Struct[T, G]) Node() Node {
@@ -244,11 +259,50 @@ func (sn *Struct[T, G]) Port() string {
 }
 
 func (sn *Struct[T, G]) process() {
-	// tn.value, tn.err = tn.transform(tn.in)
-	sn.value, sn.err = sn.Data.Process()
+	var empty T
+	sn.value, sn.err = empty, sn.dependencyErr()
+
+	if sn.err == nil {
+		value, err := sn.Data.Process()
+		sn.value = value
+
+		var nodeErr *NodeError
+		if err != nil && !errors.As(err, &nodeErr) {
+			err = &NodeError{Node: sn, Err: err}
+		}
+		sn.err = err
+	}
+
+	sn.version++
+	sn.updateUsedDependencyVersions()
+	sn.inputChangedSinceLastProcess = false
+}
+
+func (sn *Struct[T, G]) restore(value any) bool {
+	v, ok := value.(T)
+	if !ok {
+		return false
+	}
+
+	sn.value, sn.err = v, nil
 	sn.version++
 	sn.updateUsedDependencyVersions()
 	sn.inputChangedSinceLastProcess = false
+	return true
+}
+
+// dependencyErr finds the first dependency that failed to process. There's
+// nothing meaningful to build from the output of a failed node, so rather than
+// process we fail alongside it.
+func (sn *Struct[T, G]) dependencyErr() error {
+	for _, dep := range sn.Dependencies() {
+		if errored, ok := dep.Dependency().(Errored); ok {
+			if err := errored.Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 func (sn Struct[T, G]) Name() string {
@@ -288,6 +342,10 @@ func (sn *Struct[T, G]) State() NodeState {
 	if sn.Outdated() {
 		return Stale
 	}
+
+	if sn.err != nil {
+		return Error
+	}
 	return Processed
 }
 
@@ -22,6 +22,10 @@ func (sno ValueNodeOutput[T]) Port() string {
 	return "Out"
 }
 
+func (sno ValueNodeOutput[T]) Proxy(node Node, port string) NodeOutputReference {
+	return NewProxyOutput[T](node, port, sno)
+}
+
 type ValueNode[T any] struct {
 	VersionData
 	subs  []Alertable
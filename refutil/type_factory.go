@@ -12,7 +12,8 @@ type typeEntry struct {
 }
 
 type TypeFactory struct {
-	types map[string]typeEntry
+	types   map[string]typeEntry
+	aliases map[string]string
 }
 
 // type TypeFactoryEntry interface {
@@ -45,7 +46,7 @@ func (tf TypeFactory) KeyRegistered(key string) bool {
 	if tf.types == nil {
 		return false
 	}
-	_, ok := tf.types[key]
+	_, ok := tf.types[tf.Resolve(key)]
 	return ok
 }
 
@@ -59,7 +60,7 @@ func (tf TypeFactory) TypeRegistered(v any) bool {
 
 func (tf TypeFactory) New(key string) any {
 	if tf.types != nil {
-		if entry, ok := tf.types[key]; ok {
+		if entry, ok := tf.types[tf.Resolve(key)]; ok {
 			return entry.builder()
 		}
 	}
@@ -85,6 +86,61 @@ func (factory *TypeFactory) RegisterType(v any) {
 	}
 }
 
+// RegisterBuilder registers a builder under a key of our own choosing, for
+// types that aren't identified by their Go type alone
+func (factory *TypeFactory) RegisterBuilder(key string, builder func() any) {
+	if factory.types == nil {
+		factory.types = make(map[string]typeEntry)
+	}
+
+	factory.types[key] = typeEntry{
+		builder: builder,
+	}
+}
+
+// Unregister removes whatever is registered under the key
+func (factory *TypeFactory) Unregister(key string) {
+	delete(factory.types, key)
+}
+
+// RegisterAlias makes whatever is registered under the key available under
+// the alias as well, so types can be moved or renamed without breaking
+// anything referring to them by their old name
+func (factory *TypeFactory) RegisterAlias(alias, key string) {
+	if factory.aliases == nil {
+		factory.aliases = make(map[string]string)
+	}
+	factory.aliases[alias] = key
+}
+
+// Aliases lists every alias registered, sorted
+func (tf TypeFactory) Aliases() []string {
+	a := make([]string, 0, len(tf.aliases))
+	for alias := range tf.aliases {
+		a = append(a, alias)
+	}
+	slices.Sort(a)
+	return a
+}
+
+// Resolve follows the aliases of the key to the key the type is actually
+// registered under. Keys that aren't aliases are returned as is.
+func (tf TypeFactory) Resolve(key string) string {
+	// Types registered directly take precedence over aliases
+	for steps := 0; steps <= len(tf.aliases); steps++ {
+		if _, ok := tf.types[key]; ok {
+			return key
+		}
+
+		next, ok := tf.aliases[key]
+		if !ok {
+			return key
+		}
+		key = next
+	}
+	panic(fmt.Errorf("type factory aliases form a cycle through '%s'", key))
+}
+
 func (factory TypeFactory) Combine(others ...*TypeFactory) *TypeFactory {
 	newFactory := make(map[string]typeEntry)
 
@@ -102,8 +158,23 @@ func (factory TypeFactory) Combine(others ...*TypeFactory) *TypeFactory {
 		}
 	}
 
+	newAliases := make(map[string]string)
+	for alias, key := range factory.aliases {
+		newAliases[alias] = key
+	}
+
+	for _, f := range others {
+		for alias, key := range f.aliases {
+			if existing, ok := newAliases[alias]; ok && existing != key {
+				panic(fmt.Errorf("combining type factories led to an alias collision: '%s'", alias))
+			}
+			newAliases[alias] = key
+		}
+	}
+
 	return &TypeFactory{
-		types: newFactory,
+		types:   newFactory,
+		aliases: newAliases,
 	}
 }
 
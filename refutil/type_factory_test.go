@@ -103,3 +103,37 @@ func TestTypeFactory_BuildType(t *testing.T) {
 	// ASSERT =================================================================
 	assert.Equal(t, 7, *built)
 }
+
+func TestTypeFactory_RegisterAlias(t *testing.T) {
+	factory := &refutil.TypeFactory{}
+	refutil.RegisterType[int](factory)
+	factory.RegisterAlias("old.Int", "int")
+	factory.RegisterAlias("older.Int", "old.Int")
+
+	assert.True(t, factory.KeyRegistered("old.Int"))
+	assert.True(t, factory.KeyRegistered("older.Int"))
+	assert.False(t, factory.KeyRegistered("other.Int"))
+	assert.Equal(t, "int", factory.Resolve("older.Int"))
+	assert.Equal(t, "other.Int", factory.Resolve("other.Int"))
+
+	_, ok := factory.New("older.Int").(*int)
+	assert.True(t, ok)
+
+	// Aliases aren't types of their own
+	assert.Equal(t, []string{"int"}, factory.Types())
+	assert.Equal(t, []string{"old.Int", "older.Int"}, factory.Aliases())
+
+	// Aliases carry over when combined
+	combined := (&refutil.TypeFactory{}).Combine(factory)
+	assert.Equal(t, "int", combined.Resolve("older.Int"))
+}
+
+func TestTypeFactory_AliasCycle(t *testing.T) {
+	factory := &refutil.TypeFactory{}
+	factory.RegisterAlias("a", "b")
+	factory.RegisterAlias("b", "a")
+
+	assert.Panics(t, func() {
+		factory.Resolve("a")
+	})
+}
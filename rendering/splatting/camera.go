@@ -0,0 +1,65 @@
+package splatting
+
+import (
+	"math"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// Camera is a pinhole camera the splat is rendered from
+type Camera struct {
+	Position vector3.Float64
+	LookAt   vector3.Float64
+	Up       vector3.Float64
+
+	// Vertical field of view, in degrees
+	FieldOfView float64
+}
+
+// basis is the camera's right, up and forward directions
+func (c Camera) basis() (right, up, forward vector3.Float64) {
+	forward = c.LookAt.Sub(c.Position).Normalized()
+
+	worldUp := c.Up
+	if worldUp.LengthSquared() == 0 {
+		worldUp = vector3.Up[float64]()
+	}
+
+	right = forward.Cross(worldUp)
+	if right.LengthSquared() < 1e-12 {
+		// Looking straight along up, any perpendicular will do
+		right = forward.Cross(vector3.Forward[float64]())
+	}
+	right = right.Normalized()
+	up = right.Cross(forward)
+	return
+}
+
+// FrameCamera builds a camera looking down the Z axis at the splat, backed
+// up far enough to fit all of it within view
+func FrameCamera(splat modeling.Mesh) Camera {
+	const fov = 60.
+
+	camera := Camera{
+		Position:    vector3.Forward[float64](),
+		LookAt:      vector3.Zero[float64](),
+		Up:          vector3.Up[float64](),
+		FieldOfView: fov,
+	}
+
+	if splat.PrimitiveCount() == 0 || !splat.HasFloat3Attribute(modeling.PositionAttribute) {
+		return camera
+	}
+
+	bounds := splat.BoundingBox(modeling.PositionAttribute)
+	radius := bounds.Size().Length() / 2
+	if radius == 0 {
+		radius = 1
+	}
+
+	distance := radius / math.Sin((fov*math.Pi/180)/2)
+	camera.LookAt = bounds.Center()
+	camera.Position = bounds.Center().Add(vector3.Forward[float64]().Scale(distance))
+	return camera
+}
@@ -0,0 +1,390 @@
+package splatting
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/modeling/meshops/gausops"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+// Pixels along each side of the square tiles the image is broken up into
+const tileSize = 16
+
+const (
+	nearPlane = 0.01
+
+	// Gaussians contributing less than this are skipped
+	minAlpha = 1. / 255
+
+	// Pixels stop accumulating once this little light makes it through
+	minTransmittance = 1e-4
+)
+
+type RenderOptions struct {
+	Width  int
+	Height int
+
+	// Color behind the splat. Defaults to transparent
+	Background color.Color
+
+	// Number of tiles rendered at once. Defaults to the number of CPUs
+	Workers int
+}
+
+// projected is a gaussian after being projected onto the image
+type projected struct {
+	center  [2]float64
+	conic   [3]float64 // Inverse of the 2D covariance: a, b, c
+	depth   float64
+	opacity float64
+	color   vector3.Float64
+	bounds  image.Rectangle // Tiles covered
+}
+
+// Render rasterizes the gaussian splat from the camera's point of view,
+// following 3D Gaussian Splatting's tile based approach. Each gaussian is
+// projected to a 2D gaussian on the image, and every tile blends the
+// gaussians overlapping it front to back.
+//
+// Position, Scale and Rotation attributes are required. Scale is expected in
+// log space, Rotation as (w, x, y, z), and Opacity, if present, before the
+// sigmoid is applied, the same as 3D Gaussian Splatting's PLY files. Color
+// comes from FDC and any spherical harmonics, falling back to the Color
+// attribute, or white.
+func Render(splat modeling.Mesh, camera Camera, options RenderOptions) (*image.RGBA, error) {
+	if options.Width <= 0 || options.Height <= 0 {
+		return nil, fmt.Errorf("invalid image dimensions: %dx%d", options.Width, options.Height)
+	}
+
+	if splat.Topology() != modeling.PointTopology {
+		return nil, fmt.Errorf("mesh must be point topology, was instead %s", splat.Topology())
+	}
+
+	if camera.FieldOfView <= 0 || camera.FieldOfView >= 180 {
+		return nil, fmt.Errorf("field of view must be between 0 and 180 degrees, got %g", camera.FieldOfView)
+	}
+
+	if camera.Position.Distance(camera.LookAt) == 0 {
+		return nil, errors.New("camera position and look at point can not be the same")
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, options.Width, options.Height))
+
+	var gaussians []projected
+	if splat.PrimitiveCount() > 0 {
+		for _, attr := range []string{modeling.PositionAttribute, modeling.ScaleAttribute} {
+			if !splat.HasFloat3Attribute(attr) {
+				return nil, fmt.Errorf("required attribute not present on mesh: %s", attr)
+			}
+		}
+
+		if !splat.HasFloat4Attribute(modeling.RotationAttribute) {
+			return nil, fmt.Errorf("required attribute not present on mesh: %s", modeling.RotationAttribute)
+		}
+
+		gaussians = project(splat, camera, options.Width, options.Height)
+	}
+
+	tilesX := (options.Width + tileSize - 1) / tileSize
+	tilesY := (options.Height + tileSize - 1) / tileSize
+	tiles := binGaussians(gaussians, tilesX, tilesY)
+
+	background := [4]float64{}
+	if options.Background != nil {
+		r, g, b, a := options.Background.RGBA()
+		background = [4]float64{float64(r) / 0xffff, float64(g) / 0xffff, float64(b) / 0xffff, float64(a) / 0xffff}
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	jobs := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tile := range jobs {
+				renderTile(img, gaussians, tiles[tile], tile%tilesX, tile/tilesX, background)
+			}
+		}()
+	}
+
+	for tile := range tiles {
+		jobs <- tile
+	}
+	close(jobs)
+	wg.Wait()
+
+	return img, nil
+}
+
+func sigmoid(x float64) float64 {
+	return 1. / (1. + math.Exp(-x))
+}
+
+// project transforms every gaussian in front of the camera into image space,
+// ordered front to back
+func project(splat modeling.Mesh, camera Camera, width, height int) []projected {
+	right, up, forward := camera.basis()
+
+	tanFovY := math.Tan((camera.FieldOfView * math.Pi / 180) / 2)
+	tanFovX := tanFovY * float64(width) / float64(height)
+	focalY := float64(height) / (2 * tanFovY)
+	focalX := float64(width) / (2 * tanFovX)
+
+	positions := splat.Float3Attribute(modeling.PositionAttribute)
+	scales := splat.Float3Attribute(modeling.ScaleAttribute)
+	rotations := splat.Float4Attribute(modeling.RotationAttribute)
+
+	opacity := func(int) float64 { return 1 }
+	if splat.HasFloat1Attribute(modeling.OpacityAttribute) {
+		opacities := splat.Float1Attribute(modeling.OpacityAttribute)
+		opacity = func(i int) float64 { return sigmoid(opacities.At(i)) }
+	}
+
+	colorOf := colorer(splat)
+
+	tilesX := (width + tileSize - 1) / tileSize
+	tilesY := (height + tileSize - 1) / tileSize
+
+	gaussians := make([]projected, 0, positions.Len())
+	for i := 0; i < positions.Len(); i++ {
+		world := positions.At(i)
+		relative := world.Sub(camera.Position)
+		view := vector3.New(relative.Dot(right), relative.Dot(up), relative.Dot(forward))
+		if view.Z() < nearPlane {
+			continue
+		}
+
+		cov3D := covariance3D(scales.At(i).Exp(), rotations.At(i).X(), rotations.At(i).Y(), rotations.At(i).Z(), rotations.At(i).W())
+
+		// Jacobian of the perspective projection, with x and y clamped a
+		// little outside the view to keep gaussians far off to the side
+		// from blowing up
+		limitX := 1.3 * tanFovX
+		limitY := 1.3 * tanFovY
+		z := view.Z()
+		x := math.Max(-limitX, math.Min(limitX, view.X()/z)) * z
+		y := math.Max(-limitY, math.Min(limitY, view.Y()/z)) * z
+		j := [2][3]float64{
+			{focalX / z, 0, -(focalX * x) / (z * z)},
+			{0, -focalY / z, (focalY * y) / (z * z)},
+		}
+
+		// T = J * W, where W's rows are the camera basis
+		w := [3]vector3.Float64{right, up, forward}
+		var t [2][3]float64
+		for r := 0; r < 2; r++ {
+			for c := 0; c < 3; c++ {
+				t[r][c] = j[r][0]*w[0].Component(c) + j[r][1]*w[1].Component(c) + j[r][2]*w[2].Component(c)
+			}
+		}
+
+		// Σ' = T Σ Tᵀ
+		var cov2D [2][2]float64
+		for r := 0; r < 2; r++ {
+			for c := 0; c < 2; c++ {
+				sum := 0.
+				for k := 0; k < 3; k++ {
+					for l := 0; l < 3; l++ {
+						sum += t[r][k] * cov3D[k][l] * t[c][l]
+					}
+				}
+				cov2D[r][c] = sum
+			}
+		}
+
+		// Low pass filter, ensuring every gaussian covers at least a pixel
+		a := cov2D[0][0] + 0.3
+		b := cov2D[0][1]
+		c := cov2D[1][1] + 0.3
+
+		det := a*c - b*b
+		if det <= 0 {
+			continue
+		}
+
+		mid := 0.5 * (a + c)
+		lambda := mid + math.Sqrt(math.Max(0.1, mid*mid-det))
+		radius := math.Ceil(3 * math.Sqrt(lambda))
+
+		center := [2]float64{
+			float64(width)/2 + (focalX * view.X() / z),
+			float64(height)/2 - (focalY * view.Y() / z),
+		}
+
+		// Not image.Rect, which would swap the corners of gaussians
+		// entirely off screen rather than leaving them empty
+		bounds := image.Rectangle{
+			Min: image.Pt(
+				int(math.Max(0, math.Floor((center[0]-radius)/tileSize))),
+				int(math.Max(0, math.Floor((center[1]-radius)/tileSize))),
+			),
+			Max: image.Pt(
+				int(math.Min(float64(tilesX), math.Ceil((center[0]+radius)/tileSize))),
+				int(math.Min(float64(tilesY), math.Ceil((center[1]+radius)/tileSize))),
+			),
+		}
+		if bounds.Empty() {
+			continue
+		}
+
+		gaussians = append(gaussians, projected{
+			center:  center,
+			conic:   [3]float64{c / det, -b / det, a / det},
+			depth:   z,
+			opacity: opacity(i),
+			color:   colorOf(i, world.Sub(camera.Position)),
+			bounds:  bounds,
+		})
+	}
+
+	sort.SliceStable(gaussians, func(i, j int) bool {
+		return gaussians[i].depth < gaussians[j].depth
+	})
+	return gaussians
+}
+
+// covariance3D builds RSSᵀRᵀ from the gaussian's scale and (w, x, y, z)
+// rotation
+func covariance3D(scale vector3.Float64, qw, qx, qy, qz float64) [3][3]float64 {
+	length := math.Sqrt(qw*qw + qx*qx + qy*qy + qz*qz)
+	if length == 0 {
+		qw, length = 1, 1
+	}
+	qw, qx, qy, qz = qw/length, qx/length, qy/length, qz/length
+
+	r := [3][3]float64{
+		{1 - 2*(qy*qy+qz*qz), 2 * (qx*qy - qw*qz), 2 * (qx*qz + qw*qy)},
+		{2 * (qx*qy + qw*qz), 1 - 2*(qx*qx+qz*qz), 2 * (qy*qz - qw*qx)},
+		{2 * (qx*qz - qw*qy), 2 * (qy*qz + qw*qx), 1 - 2*(qx*qx+qy*qy)},
+	}
+
+	var m [3][3]float64
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			m[row][col] = r[row][col] * scale.Component(col)
+		}
+	}
+
+	var cov [3][3]float64
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			cov[row][col] = m[row][0]*m[col][0] + m[row][1]*m[col][1] + m[row][2]*m[col][2]
+		}
+	}
+	return cov
+}
+
+// colorer picks how the color of each gaussian is determined, given the
+// direction it's viewed from
+func colorer(splat modeling.Mesh) func(i int, dir vector3.Float64) vector3.Float64 {
+	if splat.HasFloat3Attribute(modeling.FDCAttribute) {
+		fdc := splat.Float3Attribute(modeling.FDCAttribute)
+
+		shDegree := modeling.SHDegree(splat)
+		sh := make([][]vector3.Float64, modeling.SHCoefficients(shDegree))
+		for c := range sh {
+			data := splat.Float3Attribute(modeling.SHAttribute(c))
+			sh[c] = make([]vector3.Float64, data.Len())
+			for i := range sh[c] {
+				sh[c][i] = data.At(i)
+			}
+		}
+
+		return func(i int, dir vector3.Float64) vector3.Float64 {
+			coefficients := make([]vector3.Float64, len(sh))
+			for c := range sh {
+				coefficients[c] = sh[c][i]
+			}
+			return gausops.EvaluateSH(fdc.At(i), coefficients, dir)
+		}
+	}
+
+	if splat.HasFloat3Attribute(modeling.ColorAttribute) {
+		colors := splat.Float3Attribute(modeling.ColorAttribute)
+		return func(i int, dir vector3.Float64) vector3.Float64 {
+			return colors.At(i).Clamp(0, 1)
+		}
+	}
+
+	return func(int, vector3.Float64) vector3.Float64 {
+		return vector3.One[float64]()
+	}
+}
+
+// binGaussians lists the gaussians overlapping each tile, keeping them in
+// the front to back order they were provided in
+func binGaussians(gaussians []projected, tilesX, tilesY int) [][]int {
+	tiles := make([][]int, tilesX*tilesY)
+	for i, g := range gaussians {
+		for y := g.bounds.Min.Y; y < g.bounds.Max.Y; y++ {
+			for x := g.bounds.Min.X; x < g.bounds.Max.X; x++ {
+				tile := (y * tilesX) + x
+				tiles[tile] = append(tiles[tile], i)
+			}
+		}
+	}
+	return tiles
+}
+
+func renderTile(img *image.RGBA, gaussians []projected, overlapping []int, tileX, tileY int, background [4]float64) {
+	bounds := image.Rect(
+		tileX*tileSize,
+		tileY*tileSize,
+		(tileX+1)*tileSize,
+		(tileY+1)*tileSize,
+	).Intersect(img.Bounds())
+
+	for py := bounds.Min.Y; py < bounds.Max.Y; py++ {
+		for px := bounds.Min.X; px < bounds.Max.X; px++ {
+			pixel := [2]float64{float64(px) + 0.5, float64(py) + 0.5}
+
+			transmittance := 1.
+			accumulated := vector3.Zero[float64]()
+			for _, i := range overlapping {
+				g := gaussians[i]
+				dx := g.center[0] - pixel[0]
+				dy := g.center[1] - pixel[1]
+				power := -0.5*(g.conic[0]*dx*dx+g.conic[2]*dy*dy) - g.conic[1]*dx*dy
+				if power > 0 {
+					continue
+				}
+
+				alpha := math.Min(0.99, g.opacity*math.Exp(power))
+				if alpha < minAlpha {
+					continue
+				}
+
+				accumulated = accumulated.Add(g.color.Scale(alpha * transmittance))
+				transmittance *= 1 - alpha
+				if transmittance < minTransmittance {
+					break
+				}
+			}
+
+			// The background is premultiplied, same as the image
+			img.SetRGBA(px, py, color.RGBA{
+				R: toByte(accumulated.X() + transmittance*background[0]),
+				G: toByte(accumulated.Y() + transmittance*background[1]),
+				B: toByte(accumulated.Z() + transmittance*background[2]),
+				A: toByte(1 - transmittance + transmittance*background[3]),
+			})
+		}
+	}
+}
+
+func toByte(v float64) uint8 {
+	return uint8(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
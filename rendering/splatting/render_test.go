@@ -0,0 +1,289 @@
+package splatting_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"image/color"
+	"math"
+	"testing"
+
+	"github.com/EliCDavis/polyform/formats/ply"
+	"github.com/EliCDavis/polyform/formats/spz"
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/polyform/rendering/splatting"
+	"github.com/EliCDavis/vector/vector3"
+	"github.com/EliCDavis/vector/vector4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gaussians(positions []vector3.Float64, colors []vector3.Float64, scale float64) modeling.Mesh {
+	scales := make([]vector3.Float64, len(positions))
+	rotations := make([]vector4.Float64, len(positions))
+	opacities := make([]float64, len(positions))
+	for i := range positions {
+		scales[i] = vector3.Fill(math.Log(scale))
+		rotations[i] = vector4.New(1., 0., 0., 0.)
+		opacities[i] = 10 // Effectively opaque once the sigmoid is applied
+	}
+
+	return modeling.NewPointCloud(
+		map[string][]vector4.Float64{
+			modeling.RotationAttribute: rotations,
+		},
+		map[string][]vector3.Float64{
+			modeling.PositionAttribute: positions,
+			modeling.ScaleAttribute:    scales,
+			modeling.ColorAttribute:    colors,
+		},
+		nil,
+		map[string][]float64{
+			modeling.OpacityAttribute: opacities,
+		},
+		nil,
+	)
+}
+
+func camera() splatting.Camera {
+	return splatting.Camera{
+		Position:    vector3.New(0., 0., 5.),
+		LookAt:      vector3.Zero[float64](),
+		Up:          vector3.Up[float64](),
+		FieldOfView: 60,
+	}
+}
+
+func TestRender_SingleGaussian(t *testing.T) {
+	splat := gaussians(
+		[]vector3.Float64{vector3.Zero[float64]()},
+		[]vector3.Float64{vector3.New(1., 0., 0.)},
+		0.2,
+	)
+
+	img, err := splatting.Render(splat, camera(), splatting.RenderOptions{Width: 64, Height: 64})
+	require.NoError(t, err)
+
+	center := img.RGBAAt(32, 32)
+	assert.Greater(t, center.R, uint8(200))
+	assert.Equal(t, uint8(0), center.G)
+	assert.Greater(t, center.A, uint8(200))
+
+	corner := img.RGBAAt(0, 0)
+	assert.Equal(t, color.RGBA{}, corner)
+}
+
+func TestRender_DepthOrder(t *testing.T) {
+	// The blue gaussian is listed first but sits further from the camera
+	splat := gaussians(
+		[]vector3.Float64{vector3.New(0., 0., -1.), vector3.New(0., 0., 1.)},
+		[]vector3.Float64{vector3.New(0., 0., 1.), vector3.New(1., 0., 0.)},
+		0.3,
+	)
+
+	img, err := splatting.Render(splat, camera(), splatting.RenderOptions{Width: 32, Height: 32})
+	require.NoError(t, err)
+
+	center := img.RGBAAt(16, 16)
+	assert.Greater(t, center.R, uint8(200))
+	assert.Less(t, center.B, uint8(50))
+
+	// From the other side, blue is in front
+	behind := camera()
+	behind.Position = vector3.New(0., 0., -5.)
+	img, err = splatting.Render(splat, behind, splatting.RenderOptions{Width: 32, Height: 32})
+	require.NoError(t, err)
+
+	center = img.RGBAAt(16, 16)
+	assert.Greater(t, center.B, uint8(200))
+	assert.Less(t, center.R, uint8(50))
+}
+
+func TestRender_Background(t *testing.T) {
+	background := color.RGBA{R: 10, G: 20, B: 30, A: 255}
+	img, err := splatting.Render(modeling.EmptyPointcloud(), camera(), splatting.RenderOptions{
+		Width:      8,
+		Height:     4,
+		Background: background,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 8, img.Bounds().Dx())
+	assert.Equal(t, 4, img.Bounds().Dy())
+	assert.Equal(t, background, img.RGBAAt(7, 3))
+}
+
+func TestRender_BehindCamera(t *testing.T) {
+	splat := gaussians(
+		[]vector3.Float64{vector3.New(0., 0., 10.)},
+		[]vector3.Float64{vector3.New(1., 1., 1.)},
+		0.5,
+	)
+
+	img, err := splatting.Render(splat, camera(), splatting.RenderOptions{Width: 16, Height: 16})
+	require.NoError(t, err)
+	for _, v := range img.Pix {
+		require.Equal(t, uint8(0), v)
+	}
+}
+
+// nianticSPZ encodes two red gaussians the way Niantic's encoder lays them
+// out, stretched along X then turned a quarter about Z to lie along Y. The
+// first sits at the origin fully opaque, the second to its right fully
+// transparent.
+func nianticSPZ(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	out := gzip.NewWriter(buf)
+	require.NoError(t, binary.Write(out, binary.LittleEndian, spz.Header{
+		Magic:          0x5053474e,
+		Version:        2,
+		NumPoints:      2,
+		FractionalBits: 12,
+	}))
+
+	data := [][]byte{
+		// 24-bit fixed point positions, (0, 0, 0) and (1.5, 0, 0)
+		{0, 0, 0, 0, 0, 0, 0, 0, 0},
+		{0, 0x18, 0, 0, 0, 0, 0, 0, 0},
+		// Alphas, post-sigmoid
+		{255, 0},
+		// Colors
+		{195, 60, 60, 195, 60, 60},
+		// Log scales of (1, 0.1, 0.1)
+		{160, 123, 123, 160, 123, 123},
+		// Rotations, only the imaginary part, (0, 0, sin(π/4))
+		{128, 128, 218, 128, 128, 218},
+	}
+	for _, d := range data {
+		_, err := out.Write(d)
+		require.NoError(t, err)
+	}
+	require.NoError(t, out.Close())
+	return buf.Bytes()
+}
+
+func TestRender_SPZ(t *testing.T) {
+	cloud, err := spz.Read(bytes.NewReader(nianticSPZ(t)))
+	require.NoError(t, err)
+
+	img, err := splatting.Render(cloud.Mesh, camera(), splatting.RenderOptions{Width: 64, Height: 64})
+	require.NoError(t, err)
+
+	center := img.RGBAAt(32, 32)
+	assert.Greater(t, center.R, center.G+50)
+	assert.Greater(t, center.A, uint8(200))
+
+	// Stretched vertically, not horizontally
+	assert.Greater(t, img.RGBAAt(32, 22).A, uint8(50))
+	assert.Greater(t, img.RGBAAt(32, 42).A, uint8(50))
+	assert.Equal(t, uint8(0), img.RGBAAt(22, 32).A)
+	assert.Equal(t, uint8(0), img.RGBAAt(42, 32).A)
+
+	// The transparent gaussian doesn't show up at all
+	assert.Equal(t, uint8(0), img.RGBAAt(49, 32).A)
+}
+
+func TestRender_Errors(t *testing.T) {
+	valid := gaussians(
+		[]vector3.Float64{vector3.Zero[float64]()},
+		[]vector3.Float64{vector3.New(1., 1., 1.)},
+		0.5,
+	)
+
+	tests := map[string]struct {
+		splat   modeling.Mesh
+		camera  splatting.Camera
+		options splatting.RenderOptions
+		err     string
+	}{
+		"bad dimensions": {
+			splat:   valid,
+			camera:  camera(),
+			options: splatting.RenderOptions{Width: 0, Height: 4},
+			err:     "invalid image dimensions: 0x4",
+		},
+		"bad topology": {
+			splat:   modeling.EmptyMesh(modeling.TriangleTopology),
+			camera:  camera(),
+			options: splatting.RenderOptions{Width: 4, Height: 4},
+			err:     "mesh must be point topology, was instead triangle",
+		},
+		"bad fov": {
+			splat:   valid,
+			camera:  splatting.Camera{Position: vector3.New(0., 0., 1.), FieldOfView: 180},
+			options: splatting.RenderOptions{Width: 4, Height: 4},
+			err:     "field of view must be between 0 and 180 degrees, got 180",
+		},
+		"missing rotation": {
+			splat:   valid.SetFloat4Attribute(modeling.RotationAttribute, nil),
+			camera:  camera(),
+			options: splatting.RenderOptions{Width: 4, Height: 4},
+			err:     "required attribute not present on mesh: Rotation",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := splatting.Render(tc.splat, tc.camera, tc.options)
+			assert.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestFrameCamera(t *testing.T) {
+	splat := gaussians(
+		[]vector3.Float64{vector3.New(-1., -1., -1.), vector3.New(3., 3., 3.)},
+		[]vector3.Float64{vector3.New(1., 1., 1.), vector3.New(1., 1., 1.)},
+		0.1,
+	)
+
+	camera := splatting.FrameCamera(splat)
+	assert.Equal(t, vector3.New(1., 1., 1.), camera.LookAt)
+	assert.Equal(t, 60., camera.FieldOfView)
+	assert.InDelta(t, 2*math.Sqrt(12), camera.Position.Distance(camera.LookAt), 1e-9)
+}
+
+func TestThumbnail(t *testing.T) {
+	splat := gaussians(
+		[]vector3.Float64{vector3.Zero[float64]()},
+		[]vector3.Float64{vector3.New(0., 1., 0.)},
+		0.5,
+	)
+
+	img, ok, err := splatting.Thumbnail(ply.SplatPly{Mesh: splat}, 16, 16)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Greater(t, img.At(8, 8).(color.RGBA).G, uint8(200))
+
+	// Not a splat
+	_, ok, err = splatting.Thumbnail(ply.SplatPly{Mesh: modeling.EmptyMesh(modeling.TriangleTopology)}, 16, 16)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = splatting.Thumbnail(basics.Text{}, 16, 16)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestArtifactNode(t *testing.T) {
+	node := &splatting.ArtifactNode{
+		Data: splatting.ArtifactNodeData{
+			Splat: nodes.Value(gaussians(
+				[]vector3.Float64{vector3.Zero[float64]()},
+				[]vector3.Float64{vector3.New(1., 1., 1.)},
+				0.5,
+			)),
+			Width:  nodes.Value(24),
+			Height: nodes.Value(12),
+		},
+	}
+
+	a := node.Out().Value()
+	require.IsType(t, basics.Image{}, a)
+	assert.Equal(t, "image/png", a.Mime())
+
+	img := a.(basics.Image).Image
+	assert.Equal(t, 24, img.Bounds().Dx())
+	assert.Equal(t, 12, img.Bounds().Dy())
+}
@@ -0,0 +1,119 @@
+package splatting
+
+import (
+	"image"
+
+	"github.com/EliCDavis/polyform/drawing/coloring"
+	"github.com/EliCDavis/polyform/formats/ply"
+	"github.com/EliCDavis/polyform/formats/splat"
+	"github.com/EliCDavis/polyform/formats/spz"
+	"github.com/EliCDavis/polyform/generator"
+	"github.com/EliCDavis/polyform/generator/artifact"
+	"github.com/EliCDavis/polyform/generator/artifact/basics"
+	"github.com/EliCDavis/polyform/modeling"
+	"github.com/EliCDavis/polyform/nodes"
+	"github.com/EliCDavis/polyform/refutil"
+	"github.com/EliCDavis/vector/vector3"
+)
+
+func init() {
+	factory := &refutil.TypeFactory{}
+
+	refutil.RegisterType[RenderNode](factory)
+	refutil.RegisterType[ArtifactNode](factory)
+
+	generator.RegisterTypes(factory)
+	generator.RegisterThumbnailer(Thumbnail)
+}
+
+// Thumbnail renders a preview of the splat artifacts the formats packages
+// produce, framing the entire splat
+func Thumbnail(a artifact.Artifact, width, height int) (image.Image, bool, error) {
+	var mesh modeling.Mesh
+	switch v := a.(type) {
+	case ply.SplatPly:
+		mesh = v.Mesh
+	case splat.Splat:
+		mesh = v.Mesh
+	case spz.Artifact:
+		mesh = v.Mesh
+	default:
+		return nil, false, nil
+	}
+
+	// PLY artifacts aren't always splats
+	if mesh.Topology() != modeling.PointTopology ||
+		!mesh.HasFloat3Attribute(modeling.ScaleAttribute) ||
+		!mesh.HasFloat4Attribute(modeling.RotationAttribute) {
+		return nil, false, nil
+	}
+
+	img, err := Render(mesh, FrameCamera(mesh), RenderOptions{Width: width, Height: height})
+	if err != nil {
+		return nil, true, err
+	}
+	return img, true, nil
+}
+
+type RenderNode = nodes.Struct[image.Image, RenderNodeData]
+
+type RenderNodeData struct {
+	Splat       nodes.NodeOutput[modeling.Mesh]
+	Width       nodes.NodeOutput[int]
+	Height      nodes.NodeOutput[int]
+	Position    nodes.NodeOutput[vector3.Float64]
+	LookAt      nodes.NodeOutput[vector3.Float64]
+	FieldOfView nodes.NodeOutput[float64]
+	Background  nodes.NodeOutput[coloring.WebColor]
+}
+
+func (rnd RenderNodeData) Description() string {
+	return "Renders the gaussian splat from the camera provided, or from a camera framing the entire splat"
+}
+
+func (rnd RenderNodeData) Process() (image.Image, error) {
+	mesh := nodes.TryGetOutputValue(rnd.Splat, modeling.EmptyPointcloud())
+
+	camera := FrameCamera(mesh)
+	camera.Position = nodes.TryGetOutputValue(rnd.Position, camera.Position)
+	camera.LookAt = nodes.TryGetOutputValue(rnd.LookAt, camera.LookAt)
+	camera.FieldOfView = nodes.TryGetOutputValue(rnd.FieldOfView, camera.FieldOfView)
+
+	options := RenderOptions{
+		Width:  nodes.TryGetOutputValue(rnd.Width, 512),
+		Height: nodes.TryGetOutputValue(rnd.Height, 512),
+	}
+	if rnd.Background != nil {
+		options.Background = rnd.Background.Value()
+	}
+
+	img, err := Render(mesh, camera, options)
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+type ArtifactNode = nodes.Struct[artifact.Artifact, ArtifactNodeData]
+
+type ArtifactNodeData struct {
+	Splat       nodes.NodeOutput[modeling.Mesh]
+	Width       nodes.NodeOutput[int]
+	Height      nodes.NodeOutput[int]
+	Position    nodes.NodeOutput[vector3.Float64]
+	LookAt      nodes.NodeOutput[vector3.Float64]
+	FieldOfView nodes.NodeOutput[float64]
+	Background  nodes.NodeOutput[coloring.WebColor]
+}
+
+func (and ArtifactNodeData) Description() string {
+	return "PNG of the gaussian splat rendered from the camera provided, or from a camera framing the entire splat"
+}
+
+func (and ArtifactNodeData) Process() (artifact.Artifact, error) {
+	img, err := RenderNodeData(and).Process()
+	if err != nil {
+		return nil, err
+	}
+	return basics.Image{Image: img}, nil
+}
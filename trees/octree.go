@@ -159,12 +159,13 @@ func (ot OctTree) ClosestPoint(v vector3.Float64) (int, vector3.Float64) {
 					cell: child,
 				})
 			}
-			for _, element := range item.cell.elements {
+			for i := range item.cell.elements {
+				element := &item.cell.elements[i]
 				point := element.primitive.ClosestPoint(v)
 
 				heap.Push(&pq, octDistItem{
 					dist:    point.DistanceSquared(v),
-					element: &element,
+					element: element,
 					point:   point,
 				})
 			}
@@ -175,6 +176,55 @@ func (ot OctTree) ClosestPoint(v vector3.Float64) (int, vector3.Float64) {
 	return -1, vector3.Zero[float64]()
 }
 
+// ClosestPoints finds the k elements closest to the point provided, ordered
+// from nearest to furthest. Fewer are returned if the tree doesn't contain k
+// elements.
+func (ot OctTree) ClosestPoints(v vector3.Float64, k int) []int {
+	if k <= 0 {
+		return nil
+	}
+
+	pq := make(octItemPriorityQueue, 1)
+	pq[0] = octDistItem{
+		dist: ot.bounds.ClosestPoint(v).DistanceSquared(v),
+		cell: &ot,
+	}
+
+	heap.Init(&pq)
+
+	closest := make([]int, 0, k)
+	for pq.Len() > 0 && len(closest) < k {
+		item := heap.Pop(&pq).(octDistItem)
+
+		if item.element != nil {
+			closest = append(closest, item.element.originalIndex)
+			continue
+		}
+
+		for _, child := range item.cell.children {
+			if child == nil {
+				continue
+			}
+			heap.Push(&pq, octDistItem{
+				dist: child.bounds.ClosestPoint(v).DistanceSquared(v),
+				cell: child,
+			})
+		}
+		for i := range item.cell.elements {
+			element := &item.cell.elements[i]
+			point := element.primitive.ClosestPoint(v)
+
+			heap.Push(&pq, octDistItem{
+				dist:    point.DistanceSquared(v),
+				element: element,
+				point:   point,
+			})
+		}
+	}
+
+	return closest
+}
+
 func octreeIndex(center, item vector3.Float64) int {
 	left := 0
 	if item.X() < center.X() {
@@ -3,6 +3,7 @@ package trees_test
 import (
 	"fmt"
 	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/EliCDavis/polyform/math/geometry"
@@ -106,6 +107,39 @@ func TestOctreeLineSphere(t *testing.T) {
 	}
 }
 
+func TestOctreeClosestPoints(t *testing.T) {
+	// ARRANGE ================================================================
+	r := rand.New(rand.NewSource(42))
+	points := make([]vector3.Float64, 500)
+	for i := range points {
+		points[i] = vector3.New(r.Float64(), r.Float64(), r.Float64()).Scale(10)
+	}
+	tree := modeling.NewPointCloud(nil, map[string][]vector3.Float64{
+		modeling.PositionAttribute: points,
+	}, nil, nil, nil).OctTree()
+
+	// ACT / ASSERT ===========================================================
+	for i := 0; i < 20; i++ {
+		query := vector3.New(r.Float64(), r.Float64(), r.Float64()).Scale(10)
+
+		expected := make([]int, len(points))
+		for j := range expected {
+			expected[j] = j
+		}
+		sort.Slice(expected, func(a, b int) bool {
+			return points[expected[a]].DistanceSquared(query) < points[expected[b]].DistanceSquared(query)
+		})
+
+		assert.Equal(t, expected[:8], tree.ClosestPoints(query, 8))
+
+		closest, _ := tree.ClosestPoint(query)
+		assert.Equal(t, expected[0], closest)
+	}
+
+	assert.Len(t, tree.ClosestPoints(vector3.Zero[float64](), 1000), len(points))
+	assert.Nil(t, tree.ClosestPoints(vector3.Zero[float64](), 0))
+}
+
 var result vector3.Float64
 
 func BenchmarkOctreeLineSphere(b *testing.B) {
@@ -153,3 +187,22 @@ func BenchmarkOctreeRay(b *testing.B) {
 	// so the compiler cannot eliminate the Benchmark itself.
 	eleRes = r
 }
+
+func TestOctreeClosestPointIndex(t *testing.T) {
+	// ARRANGE ================================================================
+	r := rand.New(rand.NewSource(7))
+	points := make([]vector3.Float64, 200)
+	for i := range points {
+		points[i] = vector3.New(r.Float64(), r.Float64(), r.Float64()).Scale(10)
+	}
+	tree := modeling.NewPointCloud(nil, map[string][]vector3.Float64{
+		modeling.PositionAttribute: points,
+	}, nil, nil, nil).OctTree()
+
+	// ACT / ASSERT ===========================================================
+	for i, p := range points {
+		closest, point := tree.ClosestPoint(p)
+		assert.Equal(t, i, closest)
+		assert.Equal(t, p, point)
+	}
+}